@@ -31,6 +31,10 @@ type Message struct {
 	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`     // 创建时间 (Unix时间戳)
 	IsRead        bool                   `protobuf:"varint,6,opt,name=is_read,json=isRead,proto3" json:"is_read,omitempty"`              // 是否已读
 	ReadAt        int64                  `protobuf:"varint,7,opt,name=read_at,json=readAt,proto3" json:"read_at,omitempty"`              // 已读时间 (Unix时间戳)
+	MsgType       string                 `protobuf:"bytes,8,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`            // 消息类型
+	Payload       *MessagePayload        `protobuf:"bytes,9,opt,name=payload,proto3" json:"payload,omitempty"`                           // 非文本消息的结构化负载
+	ReplyTo       *ReplySnapshot         `protobuf:"bytes,10,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`           // 引用的消息快照
+	ExpiresAt     int64                  `protobuf:"varint,11,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`    // 过期时间（会话开启定时销毁时，Unix时间戳）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -114,16 +118,50 @@ func (x *Message) GetReadAt() int64 {
 	return 0
 }
 
+func (x *Message) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *Message) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Message) GetReplyTo() *ReplySnapshot {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+func (x *Message) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
 // 群聊消息数据结构
 type GroupMessage struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                     // 消息唯一ID
-	GroupId       string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`            // 群组ID
-	FromUserId    string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"` // 发送者ID
-	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`                           // 消息内容
-	CreatedAt     int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`     // 创建时间
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                 // 消息唯一ID
+	GroupId        string                 `protobuf:"bytes,2,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                        // 群组ID
+	FromUserId     string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`             // 发送者ID
+	Content        string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`                                       // 消息内容
+	CreatedAt      int64                  `protobuf:"varint,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                 // 创建时间
+	MsgType        string                 `protobuf:"bytes,6,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                        // 消息类型
+	Payload        *MessagePayload        `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`                                       // 非文本消息的结构化负载
+	ReplyTo        *ReplySnapshot         `protobuf:"bytes,8,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`                        // 引用的消息快照
+	MentionUserIds []string               `protobuf:"bytes,9,rep,name=mention_user_ids,json=mentionUserIds,proto3" json:"mention_user_ids,omitempty"` // 被 @ 的成员ID
+	MentionAll     bool                   `protobuf:"varint,10,opt,name=mention_all,json=mentionAll,proto3" json:"mention_all,omitempty"`             // 是否 @所有人
+	ExpiresAt      int64                  `protobuf:"varint,11,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                // 过期时间（会话开启定时销毁时）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *GroupMessage) Reset() {
@@ -191,29 +229,74 @@ func (x *GroupMessage) GetCreatedAt() int64 {
 	return 0
 }
 
-// 发送消息的请求
-type SendMessageRequest struct {
+func (x *GroupMessage) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *GroupMessage) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *GroupMessage) GetReplyTo() *ReplySnapshot {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+func (x *GroupMessage) GetMentionUserIds() []string {
+	if x != nil {
+		return x.MentionUserIds
+	}
+	return nil
+}
+
+func (x *GroupMessage) GetMentionAll() bool {
+	if x != nil {
+		return x.MentionAll
+	}
+	return false
+}
+
+func (x *GroupMessage) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 图片消息负载
+type ImagePayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	ToUserId      string                 `protobuf:"bytes,1,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"` // 发送给谁
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                     // 消息内容
+	OssKey        string                 `protobuf:"bytes,1,opt,name=oss_key,json=ossKey,proto3" json:"oss_key,omitempty"` // OSS 对象 key（通过 /upload/signature 获取）
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                     // 访问地址（可选）
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`                // 宽（像素）
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`              // 高（像素）
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`                  // 文件大小（字节）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendMessageRequest) Reset() {
-	*x = SendMessageRequest{}
+func (x *ImagePayload) Reset() {
+	*x = ImagePayload{}
 	mi := &file_message_proto_msgTypes[2]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendMessageRequest) String() string {
+func (x *ImagePayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendMessageRequest) ProtoMessage() {}
+func (*ImagePayload) ProtoMessage() {}
 
-func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
+func (x *ImagePayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[2]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -225,49 +308,72 @@ func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
-func (*SendMessageRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ImagePayload.ProtoReflect.Descriptor instead.
+func (*ImagePayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{2}
 }
 
-func (x *SendMessageRequest) GetToUserId() string {
+func (x *ImagePayload) GetOssKey() string {
 	if x != nil {
-		return x.ToUserId
+		return x.OssKey
 	}
 	return ""
 }
 
-func (x *SendMessageRequest) GetContent() string {
+func (x *ImagePayload) GetUrl() string {
 	if x != nil {
-		return x.Content
+		return x.Url
 	}
 	return ""
 }
 
-// 发送消息的响应
-type SendMessageResponse struct {
+func (x *ImagePayload) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *ImagePayload) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+func (x *ImagePayload) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// 文件消息负载
+type FilePayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Msg           *Message               `protobuf:"bytes,3,opt,name=msg,proto3" json:"msg,omitempty"` // 返回成功存储的消息详情
+	OssKey        string                 `protobuf:"bytes,1,opt,name=oss_key,json=ossKey,proto3" json:"oss_key,omitempty"`       // OSS 对象 key
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                           // 访问地址（可选）
+	FileName      string                 `protobuf:"bytes,3,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"` // 文件名
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                        // 文件大小（字节）
+	MimeType      string                 `protobuf:"bytes,5,opt,name=mime_type,json=mimeType,proto3" json:"mime_type,omitempty"` // MIME 类型
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendMessageResponse) Reset() {
-	*x = SendMessageResponse{}
+func (x *FilePayload) Reset() {
+	*x = FilePayload{}
 	mi := &file_message_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendMessageResponse) String() string {
+func (x *FilePayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendMessageResponse) ProtoMessage() {}
+func (*FilePayload) ProtoMessage() {}
 
-func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
+func (x *FilePayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -279,55 +385,71 @@ func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
-func (*SendMessageResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use FilePayload.ProtoReflect.Descriptor instead.
+func (*FilePayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *SendMessageResponse) GetCode() int32 {
+func (x *FilePayload) GetOssKey() string {
 	if x != nil {
-		return x.Code
+		return x.OssKey
 	}
-	return 0
+	return ""
 }
 
-func (x *SendMessageResponse) GetMessage() string {
+func (x *FilePayload) GetUrl() string {
 	if x != nil {
-		return x.Message
+		return x.Url
 	}
 	return ""
 }
 
-func (x *SendMessageResponse) GetMsg() *Message {
+func (x *FilePayload) GetFileName() string {
 	if x != nil {
-		return x.Msg
+		return x.FileName
 	}
-	return nil
+	return ""
 }
 
-// 发送群聊消息的请求
-type SendGroupMessageRequest struct {
+func (x *FilePayload) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *FilePayload) GetMimeType() string {
+	if x != nil {
+		return x.MimeType
+	}
+	return ""
+}
+
+// 语音消息负载
+type VoicePayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // 群组ID
-	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                // 消息内容
+	OssKey        string                 `protobuf:"bytes,1,opt,name=oss_key,json=ossKey,proto3" json:"oss_key,omitempty"` // OSS 对象 key
+	Url           string                 `protobuf:"bytes,2,opt,name=url,proto3" json:"url,omitempty"`                     // 访问地址（可选）
+	Duration      int32                  `protobuf:"varint,3,opt,name=duration,proto3" json:"duration,omitempty"`          // 时长（秒）
+	Size          int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`                  // 文件大小（字节）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendGroupMessageRequest) Reset() {
-	*x = SendGroupMessageRequest{}
+func (x *VoicePayload) Reset() {
+	*x = VoicePayload{}
 	mi := &file_message_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendGroupMessageRequest) String() string {
+func (x *VoicePayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendGroupMessageRequest) ProtoMessage() {}
+func (*VoicePayload) ProtoMessage() {}
 
-func (x *SendGroupMessageRequest) ProtoReflect() protoreflect.Message {
+func (x *VoicePayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -339,49 +461,64 @@ func (x *SendGroupMessageRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendGroupMessageRequest.ProtoReflect.Descriptor instead.
-func (*SendGroupMessageRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use VoicePayload.ProtoReflect.Descriptor instead.
+func (*VoicePayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *SendGroupMessageRequest) GetGroupId() string {
+func (x *VoicePayload) GetOssKey() string {
 	if x != nil {
-		return x.GroupId
+		return x.OssKey
 	}
 	return ""
 }
 
-func (x *SendGroupMessageRequest) GetContent() string {
+func (x *VoicePayload) GetUrl() string {
 	if x != nil {
-		return x.Content
+		return x.Url
 	}
 	return ""
 }
 
-// 发送群聊消息的响应
-type SendGroupMessageResponse struct {
+func (x *VoicePayload) GetDuration() int32 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *VoicePayload) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+// 位置消息负载
+type LocationPayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Msg           *GroupMessage          `protobuf:"bytes,3,opt,name=msg,proto3" json:"msg,omitempty"` // 返回成功存储的群聊消息详情
+	Latitude      float64                `protobuf:"fixed64,1,opt,name=latitude,proto3" json:"latitude,omitempty"`   // 纬度
+	Longitude     float64                `protobuf:"fixed64,2,opt,name=longitude,proto3" json:"longitude,omitempty"` // 经度
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`             // 地点名称（可选）
+	Address       string                 `protobuf:"bytes,4,opt,name=address,proto3" json:"address,omitempty"`       // 详细地址（可选）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SendGroupMessageResponse) Reset() {
-	*x = SendGroupMessageResponse{}
+func (x *LocationPayload) Reset() {
+	*x = LocationPayload{}
 	mi := &file_message_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SendGroupMessageResponse) String() string {
+func (x *LocationPayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SendGroupMessageResponse) ProtoMessage() {}
+func (*LocationPayload) ProtoMessage() {}
 
-func (x *SendGroupMessageResponse) ProtoReflect() protoreflect.Message {
+func (x *LocationPayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -393,61 +530,63 @@ func (x *SendGroupMessageResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SendGroupMessageResponse.ProtoReflect.Descriptor instead.
-func (*SendGroupMessageResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use LocationPayload.ProtoReflect.Descriptor instead.
+func (*LocationPayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *SendGroupMessageResponse) GetCode() int32 {
+func (x *LocationPayload) GetLatitude() float64 {
 	if x != nil {
-		return x.Code
+		return x.Latitude
 	}
 	return 0
 }
 
-func (x *SendGroupMessageResponse) GetMessage() string {
+func (x *LocationPayload) GetLongitude() float64 {
 	if x != nil {
-		return x.Message
+		return x.Longitude
+	}
+	return 0
+}
+
+func (x *LocationPayload) GetName() string {
+	if x != nil {
+		return x.Name
 	}
 	return ""
 }
 
-func (x *SendGroupMessageResponse) GetMsg() *GroupMessage {
+func (x *LocationPayload) GetAddress() string {
 	if x != nil {
-		return x.Msg
+		return x.Address
 	}
-	return nil
+	return ""
 }
 
-// 会话消息（按会话分组）
-type ConversationMessages struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	ConversationId  string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`       // 会话ID: "private:user_id" 或 "group:group_id"
-	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                                 // 会话类型: "private" 或 "group"
-	PeerId          string                 `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                               // 对方用户ID或群组ID
-	PeerName        string                 `protobuf:"bytes,4,opt,name=peer_name,json=peerName,proto3" json:"peer_name,omitempty"`                         // 对方昵称或群名（可选）
-	PeerAvatar      string                 `protobuf:"bytes,5,opt,name=peer_avatar,json=peerAvatar,proto3" json:"peer_avatar,omitempty"`                   // 对方头像（可选）
-	UnreadCount     int32                  `protobuf:"varint,6,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`               // 该会话未读消息数
-	Messages        []*UnifiedMessage      `protobuf:"bytes,7,rep,name=messages,proto3" json:"messages,omitempty"`                                         // 该会话的消息列表
-	LastMessageTime int64                  `protobuf:"varint,8,opt,name=last_message_time,json=lastMessageTime,proto3" json:"last_message_time,omitempty"` // 最后一条消息时间
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+// 名片消息负载
+type ContactCardPayload struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // 名片对应的用户ID
+	Nickname      string                 `protobuf:"bytes,2,opt,name=nickname,proto3" json:"nickname,omitempty"`           // 昵称（由服务端补充）
+	Avatar        string                 `protobuf:"bytes,3,opt,name=avatar,proto3" json:"avatar,omitempty"`               // 头像（由服务端补充）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ConversationMessages) Reset() {
-	*x = ConversationMessages{}
+func (x *ContactCardPayload) Reset() {
+	*x = ContactCardPayload{}
 	mi := &file_message_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ConversationMessages) String() string {
+func (x *ContactCardPayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ConversationMessages) ProtoMessage() {}
+func (*ContactCardPayload) ProtoMessage() {}
 
-func (x *ConversationMessages) ProtoReflect() protoreflect.Message {
+func (x *ContactCardPayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -459,98 +598,60 @@ func (x *ConversationMessages) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ConversationMessages.ProtoReflect.Descriptor instead.
-func (*ConversationMessages) Descriptor() ([]byte, []int) {
+// Deprecated: Use ContactCardPayload.ProtoReflect.Descriptor instead.
+func (*ContactCardPayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *ConversationMessages) GetConversationId() string {
-	if x != nil {
-		return x.ConversationId
-	}
-	return ""
-}
-
-func (x *ConversationMessages) GetType() string {
-	if x != nil {
-		return x.Type
-	}
-	return ""
-}
-
-func (x *ConversationMessages) GetPeerId() string {
+func (x *ContactCardPayload) GetUserId() string {
 	if x != nil {
-		return x.PeerId
+		return x.UserId
 	}
 	return ""
 }
 
-func (x *ConversationMessages) GetPeerName() string {
+func (x *ContactCardPayload) GetNickname() string {
 	if x != nil {
-		return x.PeerName
+		return x.Nickname
 	}
 	return ""
 }
 
-func (x *ConversationMessages) GetPeerAvatar() string {
+func (x *ContactCardPayload) GetAvatar() string {
 	if x != nil {
-		return x.PeerAvatar
+		return x.Avatar
 	}
 	return ""
 }
 
-func (x *ConversationMessages) GetUnreadCount() int32 {
-	if x != nil {
-		return x.UnreadCount
-	}
-	return 0
-}
-
-func (x *ConversationMessages) GetMessages() []*UnifiedMessage {
-	if x != nil {
-		return x.Messages
-	}
-	return nil
-}
-
-func (x *ConversationMessages) GetLastMessageTime() int64 {
-	if x != nil {
-		return x.LastMessageTime
-	}
-	return 0
-}
-
-// 统一消息格式（支持私聊和群聊）
-type UnifiedMessage struct {
+// 合并转发的聊天记录中的一条消息
+type ChatRecordItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                           // 消息ID
-	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                       // "private" 或 "group"
-	FromUserId    string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`       // 发送者ID
-	FromUserName  string                 `protobuf:"bytes,4,opt,name=from_user_name,json=fromUserName,proto3" json:"from_user_name,omitempty"` // 发送者昵称（可选）
-	ToUserId      string                 `protobuf:"bytes,5,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`             // 接收者ID（私聊）
-	GroupId       string                 `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                  // 群组ID（群聊）
-	Content       string                 `protobuf:"bytes,7,opt,name=content,proto3" json:"content,omitempty"`                                 // 消息内容
-	CreatedAt     int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 时间戳（秒）
-	IsRead        bool                   `protobuf:"varint,9,opt,name=is_read,json=isRead,proto3" json:"is_read,omitempty"`                    // 是否已读
-	StreamId      string                 `protobuf:"bytes,10,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`              // Stream 消息ID（用于分页）
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                        // 原消息ID
+	FromUserId    string                 `protobuf:"bytes,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`       // 原消息发送者ID
+	FromUserName  string                 `protobuf:"bytes,3,opt,name=from_user_name,json=fromUserName,proto3" json:"from_user_name,omitempty"` // 原消息发送者名称
+	MsgType       string                 `protobuf:"bytes,4,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                  // 原消息类型
+	Content       string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`                                 // 原消息内容（编辑过的消息为最新内容）
+	Payload       *MessagePayload        `protobuf:"bytes,6,opt,name=payload,proto3" json:"payload,omitempty"`                                 // 原消息负载
+	CreatedAt     int64                  `protobuf:"varint,7,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 原消息发送时间
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UnifiedMessage) Reset() {
-	*x = UnifiedMessage{}
+func (x *ChatRecordItem) Reset() {
+	*x = ChatRecordItem{}
 	mi := &file_message_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UnifiedMessage) String() string {
+func (x *ChatRecordItem) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UnifiedMessage) ProtoMessage() {}
+func (*ChatRecordItem) ProtoMessage() {}
 
-func (x *UnifiedMessage) ProtoReflect() protoreflect.Message {
+func (x *ChatRecordItem) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -562,106 +663,83 @@ func (x *UnifiedMessage) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UnifiedMessage.ProtoReflect.Descriptor instead.
-func (*UnifiedMessage) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChatRecordItem.ProtoReflect.Descriptor instead.
+func (*ChatRecordItem) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *UnifiedMessage) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *UnifiedMessage) GetType() string {
+func (x *ChatRecordItem) GetMsgId() string {
 	if x != nil {
-		return x.Type
+		return x.MsgId
 	}
 	return ""
 }
 
-func (x *UnifiedMessage) GetFromUserId() string {
+func (x *ChatRecordItem) GetFromUserId() string {
 	if x != nil {
 		return x.FromUserId
 	}
 	return ""
 }
 
-func (x *UnifiedMessage) GetFromUserName() string {
+func (x *ChatRecordItem) GetFromUserName() string {
 	if x != nil {
 		return x.FromUserName
 	}
 	return ""
 }
 
-func (x *UnifiedMessage) GetToUserId() string {
+func (x *ChatRecordItem) GetMsgType() string {
 	if x != nil {
-		return x.ToUserId
+		return x.MsgType
 	}
 	return ""
 }
 
-func (x *UnifiedMessage) GetGroupId() string {
+func (x *ChatRecordItem) GetContent() string {
 	if x != nil {
-		return x.GroupId
+		return x.Content
 	}
 	return ""
 }
 
-func (x *UnifiedMessage) GetContent() string {
+func (x *ChatRecordItem) GetPayload() *MessagePayload {
 	if x != nil {
-		return x.Content
+		return x.Payload
 	}
-	return ""
+	return nil
 }
 
-func (x *UnifiedMessage) GetCreatedAt() int64 {
+func (x *ChatRecordItem) GetCreatedAt() int64 {
 	if x != nil {
 		return x.CreatedAt
 	}
 	return 0
 }
 
-func (x *UnifiedMessage) GetIsRead() bool {
-	if x != nil {
-		return x.IsRead
-	}
-	return false
-}
-
-func (x *UnifiedMessage) GetStreamId() string {
-	if x != nil {
-		return x.StreamId
-	}
-	return ""
-}
-
-// 拉取消息的请求(改为拉取按会话分组的未读消息)
-type PullMessagesRequest struct {
+// 聊天记录消息负载（合并转发）
+type ChatRecordPayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                                    // 每个会话最多拉取的消息数(默认20)
-	AutoMark      bool                   `protobuf:"varint,2,opt,name=auto_mark,json=autoMark,proto3" json:"auto_mark,omitempty"`              // 是否自动标记为已读(默认false)
-	IncludeRead   bool                   `protobuf:"varint,3,opt,name=include_read,json=includeRead,proto3" json:"include_read,omitempty"`     // 是否包含已读消息(默认false,只返回未读)
-	FromStreamId  string                 `protobuf:"bytes,4,opt,name=from_stream_id,json=fromStreamId,proto3" json:"from_stream_id,omitempty"` // 从该 Stream ID 之后开始拉取(用于增量拉取)
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"` // 标题，如"群聊 xxx 的聊天记录"
+	Items         []*ChatRecordItem      `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"` // 按发送时间排列的原消息
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullMessagesRequest) Reset() {
-	*x = PullMessagesRequest{}
+func (x *ChatRecordPayload) Reset() {
+	*x = ChatRecordPayload{}
 	mi := &file_message_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullMessagesRequest) String() string {
+func (x *ChatRecordPayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullMessagesRequest) ProtoMessage() {}
+func (*ChatRecordPayload) ProtoMessage() {}
 
-func (x *PullMessagesRequest) ProtoReflect() protoreflect.Message {
+func (x *ChatRecordPayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -673,65 +751,50 @@ func (x *PullMessagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullMessagesRequest.ProtoReflect.Descriptor instead.
-func (*PullMessagesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use ChatRecordPayload.ProtoReflect.Descriptor instead.
+func (*ChatRecordPayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *PullMessagesRequest) GetLimit() int64 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-func (x *PullMessagesRequest) GetAutoMark() bool {
-	if x != nil {
-		return x.AutoMark
-	}
-	return false
-}
-
-func (x *PullMessagesRequest) GetIncludeRead() bool {
+func (x *ChatRecordPayload) GetTitle() string {
 	if x != nil {
-		return x.IncludeRead
+		return x.Title
 	}
-	return false
+	return ""
 }
 
-func (x *PullMessagesRequest) GetFromStreamId() string {
+func (x *ChatRecordPayload) GetItems() []*ChatRecordItem {
 	if x != nil {
-		return x.FromStreamId
+		return x.Items
 	}
-	return ""
+	return nil
 }
 
-// 拉取消息的响应（返回结构化的会话列表）
-type PullMessagesResponse struct {
-	state             protoimpl.MessageState  `protogen:"open.v1"`
-	Code              int32                   `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message           string                  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Conversations     []*ConversationMessages `protobuf:"bytes,3,rep,name=conversations,proto3" json:"conversations,omitempty"`                                   // 按会话分组的消息
-	TotalUnread       int32                   `protobuf:"varint,4,opt,name=total_unread,json=totalUnread,proto3" json:"total_unread,omitempty"`                   // 总未读消息数
-	ConversationCount int32                   `protobuf:"varint,5,opt,name=conversation_count,json=conversationCount,proto3" json:"conversation_count,omitempty"` // 有未读消息的会话数
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+// 逐条转发的消息来源（由服务端填写，保留原消息的发送者和发送时间）
+type ForwardInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                        // 原消息ID
+	FromUserId    string                 `protobuf:"bytes,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`       // 原消息发送者ID
+	FromUserName  string                 `protobuf:"bytes,3,opt,name=from_user_name,json=fromUserName,proto3" json:"from_user_name,omitempty"` // 原消息发送者名称
+	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 原消息发送时间
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullMessagesResponse) Reset() {
-	*x = PullMessagesResponse{}
+func (x *ForwardInfo) Reset() {
+	*x = ForwardInfo{}
 	mi := &file_message_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullMessagesResponse) String() string {
+func (x *ForwardInfo) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullMessagesResponse) ProtoMessage() {}
+func (*ForwardInfo) ProtoMessage() {}
 
-func (x *PullMessagesResponse) ProtoReflect() protoreflect.Message {
+func (x *ForwardInfo) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -743,67 +806,67 @@ func (x *PullMessagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullMessagesResponse.ProtoReflect.Descriptor instead.
-func (*PullMessagesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ForwardInfo.ProtoReflect.Descriptor instead.
+func (*ForwardInfo) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *PullMessagesResponse) GetCode() int32 {
-	if x != nil {
-		return x.Code
-	}
-	return 0
-}
-
-func (x *PullMessagesResponse) GetMessage() string {
+func (x *ForwardInfo) GetMsgId() string {
 	if x != nil {
-		return x.Message
+		return x.MsgId
 	}
 	return ""
 }
 
-func (x *PullMessagesResponse) GetConversations() []*ConversationMessages {
+func (x *ForwardInfo) GetFromUserId() string {
 	if x != nil {
-		return x.Conversations
+		return x.FromUserId
 	}
-	return nil
+	return ""
 }
 
-func (x *PullMessagesResponse) GetTotalUnread() int32 {
+func (x *ForwardInfo) GetFromUserName() string {
 	if x != nil {
-		return x.TotalUnread
+		return x.FromUserName
 	}
-	return 0
+	return ""
 }
 
-func (x *PullMessagesResponse) GetConversationCount() int32 {
+func (x *ForwardInfo) GetCreatedAt() int64 {
 	if x != nil {
-		return x.ConversationCount
+		return x.CreatedAt
 	}
 	return 0
 }
 
-// 获取未读消息数的请求
-type GetUnreadCountRequest struct {
+// 富消息负载：根据 msg_type 填写对应字段，其余留空
+type MessagePayload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	Image         *ImagePayload          `protobuf:"bytes,1,opt,name=image,proto3" json:"image,omitempty"`
+	File          *FilePayload           `protobuf:"bytes,2,opt,name=file,proto3" json:"file,omitempty"`
+	Voice         *VoicePayload          `protobuf:"bytes,3,opt,name=voice,proto3" json:"voice,omitempty"`
+	Location      *LocationPayload       `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	Contact       *ContactCardPayload    `protobuf:"bytes,5,opt,name=contact,proto3" json:"contact,omitempty"`
+	ChatRecord    *ChatRecordPayload     `protobuf:"bytes,6,opt,name=chat_record,json=chatRecord,proto3" json:"chat_record,omitempty"` // 聊天记录（msg_type 为 chat_record，只能通过转发生成）
+	Forward       *ForwardInfo           `protobuf:"bytes,7,opt,name=forward,proto3" json:"forward,omitempty"`                         // 转发来源（任意类型的转发消息，只能通过转发生成）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUnreadCountRequest) Reset() {
-	*x = GetUnreadCountRequest{}
+func (x *MessagePayload) Reset() {
+	*x = MessagePayload{}
 	mi := &file_message_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUnreadCountRequest) String() string {
+func (x *MessagePayload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUnreadCountRequest) ProtoMessage() {}
+func (*MessagePayload) ProtoMessage() {}
 
-func (x *GetUnreadCountRequest) ProtoReflect() protoreflect.Message {
+func (x *MessagePayload) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -815,35 +878,88 @@ func (x *GetUnreadCountRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUnreadCountRequest.ProtoReflect.Descriptor instead.
-func (*GetUnreadCountRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use MessagePayload.ProtoReflect.Descriptor instead.
+func (*MessagePayload) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{10}
 }
 
-// 获取未读消息数的响应
-type GetUnreadCountResponse struct {
+func (x *MessagePayload) GetImage() *ImagePayload {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetFile() *FilePayload {
+	if x != nil {
+		return x.File
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetVoice() *VoicePayload {
+	if x != nil {
+		return x.Voice
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetLocation() *LocationPayload {
+	if x != nil {
+		return x.Location
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetContact() *ContactCardPayload {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetChatRecord() *ChatRecordPayload {
+	if x != nil {
+		return x.ChatRecord
+	}
+	return nil
+}
+
+func (x *MessagePayload) GetForward() *ForwardInfo {
+	if x != nil {
+		return x.Forward
+	}
+	return nil
+}
+
+// 引用回复时被引用消息的快照（发送时由服务端生成）
+type ReplySnapshot struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	UnreadCount   int32                  `protobuf:"varint,3,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"` // 未读消息总数
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                        // 被引用的消息ID
+	FromUserId    string                 `protobuf:"bytes,2,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`       // 被引用消息的发送者ID
+	FromUserName  string                 `protobuf:"bytes,3,opt,name=from_user_name,json=fromUserName,proto3" json:"from_user_name,omitempty"` // 被引用消息的发送者名称
+	MsgType       string                 `protobuf:"bytes,4,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                  // 被引用消息的类型
+	Preview       string                 `protobuf:"bytes,5,opt,name=preview,proto3" json:"preview,omitempty"`                                 // 截断后的内容预览
+	CreatedAt     int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`           // 被引用消息的发送时间
+	IsRecalled    bool                   `protobuf:"varint,7,opt,name=is_recalled,json=isRecalled,proto3" json:"is_recalled,omitempty"`        // 被引用消息是否已撤回（已撤回时 preview 为空）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetUnreadCountResponse) Reset() {
-	*x = GetUnreadCountResponse{}
+func (x *ReplySnapshot) Reset() {
+	*x = ReplySnapshot{}
 	mi := &file_message_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetUnreadCountResponse) String() string {
+func (x *ReplySnapshot) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUnreadCountResponse) ProtoMessage() {}
+func (*ReplySnapshot) ProtoMessage() {}
 
-func (x *GetUnreadCountResponse) ProtoReflect() protoreflect.Message {
+func (x *ReplySnapshot) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -855,55 +971,88 @@ func (x *GetUnreadCountResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUnreadCountResponse.ProtoReflect.Descriptor instead.
-func (*GetUnreadCountResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ReplySnapshot.ProtoReflect.Descriptor instead.
+func (*ReplySnapshot) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetUnreadCountResponse) GetCode() int32 {
+func (x *ReplySnapshot) GetMsgId() string {
 	if x != nil {
-		return x.Code
+		return x.MsgId
 	}
-	return 0
+	return ""
 }
 
-func (x *GetUnreadCountResponse) GetMessage() string {
+func (x *ReplySnapshot) GetFromUserId() string {
 	if x != nil {
-		return x.Message
+		return x.FromUserId
 	}
 	return ""
 }
 
-func (x *GetUnreadCountResponse) GetUnreadCount() int32 {
+func (x *ReplySnapshot) GetFromUserName() string {
 	if x != nil {
-		return x.UnreadCount
+		return x.FromUserName
+	}
+	return ""
+}
+
+func (x *ReplySnapshot) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *ReplySnapshot) GetPreview() string {
+	if x != nil {
+		return x.Preview
+	}
+	return ""
+}
+
+func (x *ReplySnapshot) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
 	}
 	return 0
 }
 
-// 拉取未读消息的请求
-type PullUnreadMessagesRequest struct {
+func (x *ReplySnapshot) GetIsRecalled() bool {
+	if x != nil {
+		return x.IsRecalled
+	}
+	return false
+}
+
+// 发送消息的请求
+type SendMessageRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                       // 单次拉取上限（建议 100）
-	AutoMark      bool                   `protobuf:"varint,2,opt,name=auto_mark,json=autoMark,proto3" json:"auto_mark,omitempty"` // 是否自动标记为已读
+	ToUserId      string                 `protobuf:"bytes,1,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`               // 发送给谁
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                                   // 消息内容（非文本消息可留空，由服务端生成摘要）
+	MsgType       string                 `protobuf:"bytes,3,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                    // 消息类型: text(默认) / image / file / voice / location / contact（chat_record 只能通过转发生成）
+	Payload       *MessagePayload        `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                   // 非文本消息的结构化负载
+	ReplyToMsgId  string                 `protobuf:"bytes,5,opt,name=reply_to_msg_id,json=replyToMsgId,proto3" json:"reply_to_msg_id,omitempty"` // 引用回复的消息ID（可选，须属于同一会话）
+	ClientMsgId   string                 `protobuf:"bytes,6,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`      // 客户端生成的消息ID（可选），用于重试去重
+	SendAt        int64                  `protobuf:"varint,7,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"`                      // 定时发送时间（秒，可选），晚于当前时间时消息进入定时队列
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullUnreadMessagesRequest) Reset() {
-	*x = PullUnreadMessagesRequest{}
+func (x *SendMessageRequest) Reset() {
+	*x = SendMessageRequest{}
 	mi := &file_message_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullUnreadMessagesRequest) String() string {
+func (x *SendMessageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullUnreadMessagesRequest) ProtoMessage() {}
+func (*SendMessageRequest) ProtoMessage() {}
 
-func (x *PullUnreadMessagesRequest) ProtoReflect() protoreflect.Message {
+func (x *SendMessageRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -915,51 +1064,87 @@ func (x *PullUnreadMessagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullUnreadMessagesRequest.ProtoReflect.Descriptor instead.
-func (*PullUnreadMessagesRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendMessageRequest) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *PullUnreadMessagesRequest) GetLimit() int64 {
+func (x *SendMessageRequest) GetToUserId() string {
 	if x != nil {
-		return x.Limit
+		return x.ToUserId
 	}
-	return 0
+	return ""
 }
 
-func (x *PullUnreadMessagesRequest) GetAutoMark() bool {
+func (x *SendMessageRequest) GetContent() string {
 	if x != nil {
-		return x.AutoMark
+		return x.Content
 	}
-	return false
+	return ""
 }
 
-// 拉取未读消息的响应
-type PullUnreadMessagesResponse struct {
+func (x *SendMessageRequest) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SendMessageRequest) GetReplyToMsgId() string {
+	if x != nil {
+		return x.ReplyToMsgId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *SendMessageRequest) GetSendAt() int64 {
+	if x != nil {
+		return x.SendAt
+	}
+	return 0
+}
+
+// 发送消息的响应
+type SendMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Msgs          []*Message             `protobuf:"bytes,3,rep,name=msgs,proto3" json:"msgs,omitempty"`                                   // 未读消息列表
-	TotalUnread   int32                  `protobuf:"varint,4,opt,name=total_unread,json=totalUnread,proto3" json:"total_unread,omitempty"` // 拉取前的总未读数
-	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`             // 是否还有更多未读消息
+	Msg           *Message               `protobuf:"bytes,3,opt,name=msg,proto3" json:"msg,omitempty"`                           // 返回成功存储的消息详情
+	StreamId      string                 `protobuf:"bytes,4,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"` // 消息在发送者 Stream 中的ID
+	Duplicate     bool                   `protobuf:"varint,5,opt,name=duplicate,proto3" json:"duplicate,omitempty"`              // 是否为重复发送（按 client_msg_id 去重，返回原消息）
+	Scheduled     *ScheduledMessage      `protobuf:"bytes,6,opt,name=scheduled,proto3" json:"scheduled,omitempty"`               // 定时发送时返回定时消息（此时 msg 为空）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullUnreadMessagesResponse) Reset() {
-	*x = PullUnreadMessagesResponse{}
+func (x *SendMessageResponse) Reset() {
+	*x = SendMessageResponse{}
 	mi := &file_message_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullUnreadMessagesResponse) String() string {
+func (x *SendMessageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullUnreadMessagesResponse) ProtoMessage() {}
+func (*SendMessageResponse) ProtoMessage() {}
 
-func (x *PullUnreadMessagesResponse) ProtoReflect() protoreflect.Message {
+func (x *SendMessageResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -971,67 +1156,83 @@ func (x *PullUnreadMessagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullUnreadMessagesResponse.ProtoReflect.Descriptor instead.
-func (*PullUnreadMessagesResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendMessageResponse) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *PullUnreadMessagesResponse) GetCode() int32 {
+func (x *SendMessageResponse) GetCode() int32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *PullUnreadMessagesResponse) GetMessage() string {
+func (x *SendMessageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *PullUnreadMessagesResponse) GetMsgs() []*Message {
+func (x *SendMessageResponse) GetMsg() *Message {
 	if x != nil {
-		return x.Msgs
+		return x.Msg
 	}
 	return nil
 }
 
-func (x *PullUnreadMessagesResponse) GetTotalUnread() int32 {
+func (x *SendMessageResponse) GetStreamId() string {
 	if x != nil {
-		return x.TotalUnread
+		return x.StreamId
 	}
-	return 0
+	return ""
 }
 
-func (x *PullUnreadMessagesResponse) GetHasMore() bool {
+func (x *SendMessageResponse) GetDuplicate() bool {
 	if x != nil {
-		return x.HasMore
+		return x.Duplicate
 	}
 	return false
 }
 
-// 登录时拉取所有未读消息的请求
-type PullAllUnreadOnLoginRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *SendMessageResponse) GetScheduled() *ScheduledMessage {
+	if x != nil {
+		return x.Scheduled
+	}
+	return nil
 }
 
-func (x *PullAllUnreadOnLoginRequest) Reset() {
-	*x = PullAllUnreadOnLoginRequest{}
+// 发送群聊消息的请求
+type SendGroupMessageRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	GroupId        string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                        // 群组ID
+	Content        string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                                       // 消息内容（非文本消息可留空，由服务端生成摘要）
+	MsgType        string                 `protobuf:"bytes,3,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                        // 消息类型: text(默认) / image / file / voice / location / contact
+	Payload        *MessagePayload        `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`                                       // 非文本消息的结构化负载
+	ReplyToMsgId   string                 `protobuf:"bytes,5,opt,name=reply_to_msg_id,json=replyToMsgId,proto3" json:"reply_to_msg_id,omitempty"`     // 引用回复的消息ID（可选，须属于同一群聊）
+	MentionUserIds []string               `protobuf:"bytes,6,rep,name=mention_user_ids,json=mentionUserIds,proto3" json:"mention_user_ids,omitempty"` // 被 @ 的成员ID（须为群成员）
+	MentionAll     bool                   `protobuf:"varint,7,opt,name=mention_all,json=mentionAll,proto3" json:"mention_all,omitempty"`              // @所有人（仅群主/管理员可用）
+	ClientMsgId    string                 `protobuf:"bytes,8,opt,name=client_msg_id,json=clientMsgId,proto3" json:"client_msg_id,omitempty"`          // 客户端生成的消息ID（可选），用于重试去重
+	SendAt         int64                  `protobuf:"varint,9,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"`                          // 定时发送时间（秒，可选），晚于当前时间时消息进入定时队列
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SendGroupMessageRequest) Reset() {
+	*x = SendGroupMessageRequest{}
 	mi := &file_message_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullAllUnreadOnLoginRequest) String() string {
+func (x *SendGroupMessageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullAllUnreadOnLoginRequest) ProtoMessage() {}
+func (*SendGroupMessageRequest) ProtoMessage() {}
 
-func (x *PullAllUnreadOnLoginRequest) ProtoReflect() protoreflect.Message {
+func (x *SendGroupMessageRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1043,35 +1244,101 @@ func (x *PullAllUnreadOnLoginRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullAllUnreadOnLoginRequest.ProtoReflect.Descriptor instead.
-func (*PullAllUnreadOnLoginRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendGroupMessageRequest.ProtoReflect.Descriptor instead.
+func (*SendGroupMessageRequest) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{14}
 }
 
-// 群聊未读消息详情
-type GroupUnreadInfo struct {
+func (x *SendGroupMessageRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *SendGroupMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *SendGroupMessageRequest) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *SendGroupMessageRequest) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *SendGroupMessageRequest) GetReplyToMsgId() string {
+	if x != nil {
+		return x.ReplyToMsgId
+	}
+	return ""
+}
+
+func (x *SendGroupMessageRequest) GetMentionUserIds() []string {
+	if x != nil {
+		return x.MentionUserIds
+	}
+	return nil
+}
+
+func (x *SendGroupMessageRequest) GetMentionAll() bool {
+	if x != nil {
+		return x.MentionAll
+	}
+	return false
+}
+
+func (x *SendGroupMessageRequest) GetClientMsgId() string {
+	if x != nil {
+		return x.ClientMsgId
+	}
+	return ""
+}
+
+func (x *SendGroupMessageRequest) GetSendAt() int64 {
+	if x != nil {
+		return x.SendAt
+	}
+	return 0
+}
+
+// 发送群聊消息的响应
+type SendGroupMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
-	UnreadCount   int32                  `protobuf:"varint,2,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
-	Messages      []*Message             `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Msg           *GroupMessage          `protobuf:"bytes,3,opt,name=msg,proto3" json:"msg,omitempty"`                           // 返回成功存储的群聊消息详情
+	StreamId      string                 `protobuf:"bytes,4,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"` // 消息在发送者 Stream 中的ID
+	Duplicate     bool                   `protobuf:"varint,5,opt,name=duplicate,proto3" json:"duplicate,omitempty"`              // 是否为重复发送（按 client_msg_id 去重，返回原消息）
+	Scheduled     *ScheduledMessage      `protobuf:"bytes,6,opt,name=scheduled,proto3" json:"scheduled,omitempty"`               // 定时发送时返回定时消息（此时 msg 为空）
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GroupUnreadInfo) Reset() {
-	*x = GroupUnreadInfo{}
+func (x *SendGroupMessageResponse) Reset() {
+	*x = SendGroupMessageResponse{}
 	mi := &file_message_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GroupUnreadInfo) String() string {
+func (x *SendGroupMessageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GroupUnreadInfo) ProtoMessage() {}
+func (*SendGroupMessageResponse) ProtoMessage() {}
 
-func (x *GroupUnreadInfo) ProtoReflect() protoreflect.Message {
+func (x *SendGroupMessageResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1083,61 +1350,90 @@ func (x *GroupUnreadInfo) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GroupUnreadInfo.ProtoReflect.Descriptor instead.
-func (*GroupUnreadInfo) Descriptor() ([]byte, []int) {
+// Deprecated: Use SendGroupMessageResponse.ProtoReflect.Descriptor instead.
+func (*SendGroupMessageResponse) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *GroupUnreadInfo) GetGroupId() string {
+func (x *SendGroupMessageResponse) GetCode() int32 {
 	if x != nil {
-		return x.GroupId
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SendGroupMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
 	}
 	return ""
 }
 
-func (x *GroupUnreadInfo) GetUnreadCount() int32 {
+func (x *SendGroupMessageResponse) GetMsg() *GroupMessage {
 	if x != nil {
-		return x.UnreadCount
+		return x.Msg
 	}
-	return 0
+	return nil
 }
 
-func (x *GroupUnreadInfo) GetMessages() []*Message {
+func (x *SendGroupMessageResponse) GetStreamId() string {
 	if x != nil {
-		return x.Messages
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *SendGroupMessageResponse) GetDuplicate() bool {
+	if x != nil {
+		return x.Duplicate
+	}
+	return false
+}
+
+func (x *SendGroupMessageResponse) GetScheduled() *ScheduledMessage {
+	if x != nil {
+		return x.Scheduled
 	}
 	return nil
 }
 
-// 登录时拉取所有未读消息的响应
-type PullAllUnreadOnLoginResponse struct {
-	state              protoimpl.MessageState      `protogen:"open.v1"`
-	Code               int32                       `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message            string                      `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	PrivateMessages    []*Message                  `protobuf:"bytes,3,rep,name=private_messages,json=privateMessages,proto3" json:"private_messages,omitempty"`
-	PrivateUnreadCount int32                       `protobuf:"varint,4,opt,name=private_unread_count,json=privateUnreadCount,proto3" json:"private_unread_count,omitempty"`
-	GroupMessages      map[string]*GroupUnreadInfo `protobuf:"bytes,5,rep,name=group_messages,json=groupMessages,proto3" json:"group_messages,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	GroupUnreadCount   int32                       `protobuf:"varint,6,opt,name=group_unread_count,json=groupUnreadCount,proto3" json:"group_unread_count,omitempty"`
-	TotalUnreadCount   int32                       `protobuf:"varint,7,opt,name=total_unread_count,json=totalUnreadCount,proto3" json:"total_unread_count,omitempty"`
-	PulledAt           string                      `protobuf:"bytes,8,opt,name=pulled_at,json=pulledAt,proto3" json:"pulled_at,omitempty"`
-	unknownFields      protoimpl.UnknownFields
-	sizeCache          protoimpl.SizeCache
+// 定时消息
+type ScheduledMessage struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Id               string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                     // 定时消息ID
+	ConversationType string                 `protobuf:"bytes,2,opt,name=conversation_type,json=conversationType,proto3" json:"conversation_type,omitempty"` // "private" 或 "group"
+	ToUserId         string                 `protobuf:"bytes,3,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`                       // 接收者ID（私聊）
+	GroupId          string                 `protobuf:"bytes,4,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                            // 群组ID（群聊）
+	Content          string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`
+	MsgType          string                 `protobuf:"bytes,6,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`
+	Payload          *MessagePayload        `protobuf:"bytes,7,opt,name=payload,proto3" json:"payload,omitempty"`
+	ReplyToMsgId     string                 `protobuf:"bytes,8,opt,name=reply_to_msg_id,json=replyToMsgId,proto3" json:"reply_to_msg_id,omitempty"`
+	MentionUserIds   []string               `protobuf:"bytes,9,rep,name=mention_user_ids,json=mentionUserIds,proto3" json:"mention_user_ids,omitempty"`
+	MentionAll       bool                   `protobuf:"varint,10,opt,name=mention_all,json=mentionAll,proto3" json:"mention_all,omitempty"`
+	SendAt           int64                  `protobuf:"varint,11,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"` // 计划发送时间（秒）
+	Status           string                 `protobuf:"bytes,12,opt,name=status,proto3" json:"status,omitempty"`                // pending / sending / sent / canceled / failed
+	MsgId            string                 `protobuf:"bytes,13,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`     // 发送成功后的消息ID
+	Error            string                 `protobuf:"bytes,14,opt,name=error,proto3" json:"error,omitempty"`                  // 发送失败的原因
+	CreatedAt        int64                  `protobuf:"varint,15,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt        int64                  `protobuf:"varint,16,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
 }
 
-func (x *PullAllUnreadOnLoginResponse) Reset() {
-	*x = PullAllUnreadOnLoginResponse{}
+func (x *ScheduledMessage) Reset() {
+	*x = ScheduledMessage{}
 	mi := &file_message_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullAllUnreadOnLoginResponse) String() string {
+func (x *ScheduledMessage) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullAllUnreadOnLoginResponse) ProtoMessage() {}
+func (*ScheduledMessage) ProtoMessage() {}
 
-func (x *PullAllUnreadOnLoginResponse) ProtoReflect() protoreflect.Message {
+func (x *ScheduledMessage) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1149,89 +1445,146 @@ func (x *PullAllUnreadOnLoginResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullAllUnreadOnLoginResponse.ProtoReflect.Descriptor instead.
-func (*PullAllUnreadOnLoginResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use ScheduledMessage.ProtoReflect.Descriptor instead.
+func (*ScheduledMessage) Descriptor() ([]byte, []int) {
 	return file_message_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetCode() int32 {
+func (x *ScheduledMessage) GetId() string {
 	if x != nil {
-		return x.Code
+		return x.Id
 	}
-	return 0
+	return ""
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetMessage() string {
+func (x *ScheduledMessage) GetConversationType() string {
 	if x != nil {
-		return x.Message
+		return x.ConversationType
 	}
 	return ""
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetPrivateMessages() []*Message {
+func (x *ScheduledMessage) GetToUserId() string {
 	if x != nil {
-		return x.PrivateMessages
+		return x.ToUserId
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
 	}
 	return nil
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetPrivateUnreadCount() int32 {
+func (x *ScheduledMessage) GetReplyToMsgId() string {
 	if x != nil {
-		return x.PrivateUnreadCount
+		return x.ReplyToMsgId
 	}
-	return 0
+	return ""
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetGroupMessages() map[string]*GroupUnreadInfo {
+func (x *ScheduledMessage) GetMentionUserIds() []string {
 	if x != nil {
-		return x.GroupMessages
+		return x.MentionUserIds
 	}
 	return nil
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetGroupUnreadCount() int32 {
+func (x *ScheduledMessage) GetMentionAll() bool {
 	if x != nil {
-		return x.GroupUnreadCount
+		return x.MentionAll
 	}
-	return 0
+	return false
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetTotalUnreadCount() int32 {
+func (x *ScheduledMessage) GetSendAt() int64 {
 	if x != nil {
-		return x.TotalUnreadCount
+		return x.SendAt
 	}
 	return 0
 }
 
-func (x *PullAllUnreadOnLoginResponse) GetPulledAt() string {
+func (x *ScheduledMessage) GetStatus() string {
 	if x != nil {
-		return x.PulledAt
+		return x.Status
 	}
 	return ""
 }
 
-// 标记私聊消息已读请求
-type MarkPrivateMessageAsReadRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *ScheduledMessage) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
 }
 
-func (x *MarkPrivateMessageAsReadRequest) Reset() {
-	*x = MarkPrivateMessageAsReadRequest{}
+func (x *ScheduledMessage) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *ScheduledMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ScheduledMessage) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// 查询定时消息的请求
+type ListScheduledMessagesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Status         string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`                                       // 按状态筛选（默认 pending）
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 限定会话（可选）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListScheduledMessagesRequest) Reset() {
+	*x = ListScheduledMessagesRequest{}
 	mi := &file_message_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkPrivateMessageAsReadRequest) String() string {
+func (x *ListScheduledMessagesRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkPrivateMessageAsReadRequest) ProtoMessage() {}
+func (*ListScheduledMessagesRequest) ProtoMessage() {}
 
-func (x *MarkPrivateMessageAsReadRequest) ProtoReflect() protoreflect.Message {
+func (x *ListScheduledMessagesRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_message_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1243,42 +1596,3385 @@ func (x *MarkPrivateMessageAsReadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkPrivateMessageAsReadRequest.ProtoReflect.Descriptor instead.
-func (*MarkPrivateMessageAsReadRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{17}
+// Deprecated: Use ListScheduledMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ListScheduledMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ListScheduledMessagesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListScheduledMessagesRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// 查询定时消息的响应
+type ListScheduledMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Messages      []*ScheduledMessage    `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"` // 按计划发送时间升序
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduledMessagesResponse) Reset() {
+	*x = ListScheduledMessagesResponse{}
+	mi := &file_message_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledMessagesResponse) ProtoMessage() {}
+
+func (x *ListScheduledMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduledMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ListScheduledMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ListScheduledMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListScheduledMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListScheduledMessagesResponse) GetMessages() []*ScheduledMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// 修改定时消息的请求（只能修改尚未发送的消息）
+type UpdateScheduledMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduledId   string                 `protobuf:"bytes,1,opt,name=scheduled_id,json=scheduledId,proto3" json:"scheduled_id,omitempty"`
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`              // 新内容（为空且未提供 payload 时不修改）
+	Payload       *MessagePayload        `protobuf:"bytes,3,opt,name=payload,proto3" json:"payload,omitempty"`              // 新负载（非文本消息）
+	SendAt        int64                  `protobuf:"varint,4,opt,name=send_at,json=sendAt,proto3" json:"send_at,omitempty"` // 新的发送时间（秒，0 表示不修改）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateScheduledMessageRequest) Reset() {
+	*x = UpdateScheduledMessageRequest{}
+	mi := &file_message_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateScheduledMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateScheduledMessageRequest) ProtoMessage() {}
+
+func (x *UpdateScheduledMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateScheduledMessageRequest.ProtoReflect.Descriptor instead.
+func (*UpdateScheduledMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *UpdateScheduledMessageRequest) GetScheduledId() string {
+	if x != nil {
+		return x.ScheduledId
+	}
+	return ""
+}
+
+func (x *UpdateScheduledMessageRequest) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UpdateScheduledMessageRequest) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UpdateScheduledMessageRequest) GetSendAt() int64 {
+	if x != nil {
+		return x.SendAt
+	}
+	return 0
+}
+
+// 修改定时消息的响应
+type UpdateScheduledMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Scheduled     *ScheduledMessage      `protobuf:"bytes,3,opt,name=scheduled,proto3" json:"scheduled,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateScheduledMessageResponse) Reset() {
+	*x = UpdateScheduledMessageResponse{}
+	mi := &file_message_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateScheduledMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateScheduledMessageResponse) ProtoMessage() {}
+
+func (x *UpdateScheduledMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateScheduledMessageResponse.ProtoReflect.Descriptor instead.
+func (*UpdateScheduledMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *UpdateScheduledMessageResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UpdateScheduledMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateScheduledMessageResponse) GetScheduled() *ScheduledMessage {
+	if x != nil {
+		return x.Scheduled
+	}
+	return nil
+}
+
+// 取消定时消息的请求
+type CancelScheduledMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ScheduledId   string                 `protobuf:"bytes,1,opt,name=scheduled_id,json=scheduledId,proto3" json:"scheduled_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledMessageRequest) Reset() {
+	*x = CancelScheduledMessageRequest{}
+	mi := &file_message_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledMessageRequest) ProtoMessage() {}
+
+func (x *CancelScheduledMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledMessageRequest.ProtoReflect.Descriptor instead.
+func (*CancelScheduledMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CancelScheduledMessageRequest) GetScheduledId() string {
+	if x != nil {
+		return x.ScheduledId
+	}
+	return ""
+}
+
+// 取消定时消息的响应
+type CancelScheduledMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledMessageResponse) Reset() {
+	*x = CancelScheduledMessageResponse{}
+	mi := &file_message_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledMessageResponse) ProtoMessage() {}
+
+func (x *CancelScheduledMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledMessageResponse.ProtoReflect.Descriptor instead.
+func (*CancelScheduledMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CancelScheduledMessageResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *CancelScheduledMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 查询群消息已读情况的请求
+type GetGroupMessageReadStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 群聊消息ID
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupMessageReadStatusRequest) Reset() {
+	*x = GetGroupMessageReadStatusRequest{}
+	mi := &file_message_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupMessageReadStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupMessageReadStatusRequest) ProtoMessage() {}
+
+func (x *GetGroupMessageReadStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupMessageReadStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetGroupMessageReadStatusRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *GetGroupMessageReadStatusRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// 群消息已读情况中的成员
+type GroupReadMember struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Username      string                 `protobuf:"bytes,2,opt,name=username,proto3" json:"username,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupReadMember) Reset() {
+	*x = GroupReadMember{}
+	mi := &file_message_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupReadMember) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupReadMember) ProtoMessage() {}
+
+func (x *GroupReadMember) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupReadMember.ProtoReflect.Descriptor instead.
+func (*GroupReadMember) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *GroupReadMember) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GroupReadMember) GetUsername() string {
+	if x != nil {
+		return x.Username
+	}
+	return ""
+}
+
+// 查询群消息已读情况的响应（不含发送者本人，也不含消息发送后才入群的成员）
+type GetGroupMessageReadStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	GroupId       string                 `protobuf:"bytes,3,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	ReadCount     int32                  `protobuf:"varint,4,opt,name=read_count,json=readCount,proto3" json:"read_count,omitempty"`
+	UnreadCount   int32                  `protobuf:"varint,5,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
+	ReadMembers   []*GroupReadMember     `protobuf:"bytes,6,rep,name=read_members,json=readMembers,proto3" json:"read_members,omitempty"`
+	UnreadMembers []*GroupReadMember     `protobuf:"bytes,7,rep,name=unread_members,json=unreadMembers,proto3" json:"unread_members,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetGroupMessageReadStatusResponse) Reset() {
+	*x = GetGroupMessageReadStatusResponse{}
+	mi := &file_message_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetGroupMessageReadStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetGroupMessageReadStatusResponse) ProtoMessage() {}
+
+func (x *GetGroupMessageReadStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetGroupMessageReadStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetGroupMessageReadStatusResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetReadCount() int32 {
+	if x != nil {
+		return x.ReadCount
+	}
+	return 0
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetReadMembers() []*GroupReadMember {
+	if x != nil {
+		return x.ReadMembers
+	}
+	return nil
+}
+
+func (x *GetGroupMessageReadStatusResponse) GetUnreadMembers() []*GroupReadMember {
+	if x != nil {
+		return x.UnreadMembers
+	}
+	return nil
+}
+
+// 转发消息的请求
+type ForwardMessagesRequest struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	MessageIds            []string               `protobuf:"bytes,1,rep,name=message_ids,json=messageIds,proto3" json:"message_ids,omitempty"`                                    // 要转发的消息ID（须为当前用户可见的消息）
+	TargetConversationIds []string               `protobuf:"bytes,2,rep,name=target_conversation_ids,json=targetConversationIds,proto3" json:"target_conversation_ids,omitempty"` // 目标会话ID: "private:user_id" 或 "group:group_id"
+	Mode                  string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`                                                                  // single(默认): 逐条转发; merged: 合并为一条聊天记录
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *ForwardMessagesRequest) Reset() {
+	*x = ForwardMessagesRequest{}
+	mi := &file_message_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardMessagesRequest) ProtoMessage() {}
+
+func (x *ForwardMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ForwardMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *ForwardMessagesRequest) GetMessageIds() []string {
+	if x != nil {
+		return x.MessageIds
+	}
+	return nil
+}
+
+func (x *ForwardMessagesRequest) GetTargetConversationIds() []string {
+	if x != nil {
+		return x.TargetConversationIds
+	}
+	return nil
+}
+
+func (x *ForwardMessagesRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+// 转发到一个目标会话的结果
+type ForwardResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 目标会话ID
+	MsgIds         []string               `protobuf:"bytes,2,rep,name=msg_ids,json=msgIds,proto3" json:"msg_ids,omitempty"`                         // 在目标会话中生成的消息ID
+	Error          string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`                                         // 发送失败的原因（成功时为空）
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ForwardResult) Reset() {
+	*x = ForwardResult{}
+	mi := &file_message_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardResult) ProtoMessage() {}
+
+func (x *ForwardResult) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardResult.ProtoReflect.Descriptor instead.
+func (*ForwardResult) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ForwardResult) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ForwardResult) GetMsgIds() []string {
+	if x != nil {
+		return x.MsgIds
+	}
+	return nil
+}
+
+func (x *ForwardResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// 转发消息的响应
+type ForwardMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Results       []*ForwardResult       `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ForwardMessagesResponse) Reset() {
+	*x = ForwardMessagesResponse{}
+	mi := &file_message_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ForwardMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ForwardMessagesResponse) ProtoMessage() {}
+
+func (x *ForwardMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ForwardMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ForwardMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ForwardMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ForwardMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ForwardMessagesResponse) GetResults() []*ForwardResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+// 会话内的置顶消息
+type PinnedMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	MsgId          string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID（相对于当前用户）: "private:user_id" 或 "group:group_id"
+	FromUserId     string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`           // 消息发送者ID
+	MsgType        string                 `protobuf:"bytes,4,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`
+	Content        string                 `protobuf:"bytes,5,opt,name=content,proto3" json:"content,omitempty"`                       // 消息内容（编辑过的消息为最新内容）
+	CreatedAt      int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 消息发送时间
+	PinnedBy       string                 `protobuf:"bytes,7,opt,name=pinned_by,json=pinnedBy,proto3" json:"pinned_by,omitempty"`     // 置顶操作者ID
+	PinnedAt       int64                  `protobuf:"varint,8,opt,name=pinned_at,json=pinnedAt,proto3" json:"pinned_at,omitempty"`    // 置顶时间
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PinnedMessage) Reset() {
+	*x = PinnedMessage{}
+	mi := &file_message_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinnedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinnedMessage) ProtoMessage() {}
+
+func (x *PinnedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinnedMessage.ProtoReflect.Descriptor instead.
+func (*PinnedMessage) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *PinnedMessage) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetFromUserId() string {
+	if x != nil {
+		return x.FromUserId
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *PinnedMessage) GetPinnedBy() string {
+	if x != nil {
+		return x.PinnedBy
+	}
+	return ""
+}
+
+func (x *PinnedMessage) GetPinnedAt() int64 {
+	if x != nil {
+		return x.PinnedAt
+	}
+	return 0
+}
+
+// 置顶消息的请求
+type PinMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinMessageRequest) Reset() {
+	*x = PinMessageRequest{}
+	mi := &file_message_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinMessageRequest) ProtoMessage() {}
+
+func (x *PinMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinMessageRequest.ProtoReflect.Descriptor instead.
+func (*PinMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *PinMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// 置顶消息的响应
+type PinMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Pinned        *PinnedMessage         `protobuf:"bytes,3,opt,name=pinned,proto3" json:"pinned,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PinMessageResponse) Reset() {
+	*x = PinMessageResponse{}
+	mi := &file_message_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PinMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PinMessageResponse) ProtoMessage() {}
+
+func (x *PinMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PinMessageResponse.ProtoReflect.Descriptor instead.
+func (*PinMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *PinMessageResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PinMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PinMessageResponse) GetPinned() *PinnedMessage {
+	if x != nil {
+		return x.Pinned
+	}
+	return nil
+}
+
+// 取消置顶消息的请求
+type UnpinMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinMessageRequest) Reset() {
+	*x = UnpinMessageRequest{}
+	mi := &file_message_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinMessageRequest) ProtoMessage() {}
+
+func (x *UnpinMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinMessageRequest.ProtoReflect.Descriptor instead.
+func (*UnpinMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *UnpinMessageRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// 取消置顶消息的响应
+type UnpinMessageResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UnpinMessageResponse) Reset() {
+	*x = UnpinMessageResponse{}
+	mi := &file_message_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnpinMessageResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnpinMessageResponse) ProtoMessage() {}
+
+func (x *UnpinMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnpinMessageResponse.ProtoReflect.Descriptor instead.
+func (*UnpinMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *UnpinMessageResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UnpinMessageResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 查询会话置顶消息的请求
+type ListPinnedMessagesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListPinnedMessagesRequest) Reset() {
+	*x = ListPinnedMessagesRequest{}
+	mi := &file_message_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPinnedMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPinnedMessagesRequest) ProtoMessage() {}
+
+func (x *ListPinnedMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPinnedMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ListPinnedMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ListPinnedMessagesRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// 查询会话置顶消息的响应（按置顶时间倒序）
+type ListPinnedMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Pins          []*PinnedMessage       `protobuf:"bytes,3,rep,name=pins,proto3" json:"pins,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListPinnedMessagesResponse) Reset() {
+	*x = ListPinnedMessagesResponse{}
+	mi := &file_message_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListPinnedMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListPinnedMessagesResponse) ProtoMessage() {}
+
+func (x *ListPinnedMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListPinnedMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ListPinnedMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ListPinnedMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *ListPinnedMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ListPinnedMessagesResponse) GetPins() []*PinnedMessage {
+	if x != nil {
+		return x.Pins
+	}
+	return nil
+}
+
+// 会话的消息定时销毁设置
+type ConversationTTL struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID: "private:user_id" 或 "group:group_id"
+	TtlSeconds     int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`            // 消息存活时长（秒），0 表示未开启
+	UpdatedBy      string                 `protobuf:"bytes,3,opt,name=updated_by,json=updatedBy,proto3" json:"updated_by,omitempty"`                // 最后修改者
+	UpdatedAt      int64                  `protobuf:"varint,4,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`               // 最后修改时间
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ConversationTTL) Reset() {
+	*x = ConversationTTL{}
+	mi := &file_message_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationTTL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationTTL) ProtoMessage() {}
+
+func (x *ConversationTTL) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationTTL.ProtoReflect.Descriptor instead.
+func (*ConversationTTL) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ConversationTTL) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ConversationTTL) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *ConversationTTL) GetUpdatedBy() string {
+	if x != nil {
+		return x.UpdatedBy
+	}
+	return ""
+}
+
+func (x *ConversationTTL) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// 设置消息定时销毁的请求
+type SetConversationTTLRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID
+	TtlSeconds     int64                  `protobuf:"varint,2,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`            // 消息存活时长（秒），0 表示关闭
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SetConversationTTLRequest) Reset() {
+	*x = SetConversationTTLRequest{}
+	mi := &file_message_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConversationTTLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConversationTTLRequest) ProtoMessage() {}
+
+func (x *SetConversationTTLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConversationTTLRequest.ProtoReflect.Descriptor instead.
+func (*SetConversationTTLRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *SetConversationTTLRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SetConversationTTLRequest) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+// 设置消息定时销毁的响应
+type SetConversationTTLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Setting       *ConversationTTL       `protobuf:"bytes,3,opt,name=setting,proto3" json:"setting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SetConversationTTLResponse) Reset() {
+	*x = SetConversationTTLResponse{}
+	mi := &file_message_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SetConversationTTLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SetConversationTTLResponse) ProtoMessage() {}
+
+func (x *SetConversationTTLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SetConversationTTLResponse.ProtoReflect.Descriptor instead.
+func (*SetConversationTTLResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SetConversationTTLResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SetConversationTTLResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SetConversationTTLResponse) GetSetting() *ConversationTTL {
+	if x != nil {
+		return x.Setting
+	}
+	return nil
+}
+
+// 查询消息定时销毁设置的请求
+type GetConversationTTLRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetConversationTTLRequest) Reset() {
+	*x = GetConversationTTLRequest{}
+	mi := &file_message_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationTTLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationTTLRequest) ProtoMessage() {}
+
+func (x *GetConversationTTLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationTTLRequest.ProtoReflect.Descriptor instead.
+func (*GetConversationTTLRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetConversationTTLRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+// 查询消息定时销毁设置的响应
+type GetConversationTTLResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Setting       *ConversationTTL       `protobuf:"bytes,3,opt,name=setting,proto3" json:"setting,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetConversationTTLResponse) Reset() {
+	*x = GetConversationTTLResponse{}
+	mi := &file_message_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetConversationTTLResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetConversationTTLResponse) ProtoMessage() {}
+
+func (x *GetConversationTTLResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetConversationTTLResponse.ProtoReflect.Descriptor instead.
+func (*GetConversationTTLResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetConversationTTLResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetConversationTTLResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetConversationTTLResponse) GetSetting() *ConversationTTL {
+	if x != nil {
+		return x.Setting
+	}
+	return nil
+}
+
+// 会话消息（按会话分组）
+type ConversationMessages struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId  string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"`       // 会话ID: "private:user_id" 或 "group:group_id"
+	Type            string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                                 // 会话类型: "private" 或 "group"
+	PeerId          string                 `protobuf:"bytes,3,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                               // 对方用户ID或群组ID
+	PeerName        string                 `protobuf:"bytes,4,opt,name=peer_name,json=peerName,proto3" json:"peer_name,omitempty"`                         // 对方昵称或群名（可选）
+	PeerAvatar      string                 `protobuf:"bytes,5,opt,name=peer_avatar,json=peerAvatar,proto3" json:"peer_avatar,omitempty"`                   // 对方头像（可选）
+	UnreadCount     int32                  `protobuf:"varint,6,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`               // 该会话未读消息数
+	Messages        []*UnifiedMessage      `protobuf:"bytes,7,rep,name=messages,proto3" json:"messages,omitempty"`                                         // 该会话的消息列表
+	LastMessageTime int64                  `protobuf:"varint,8,opt,name=last_message_time,json=lastMessageTime,proto3" json:"last_message_time,omitempty"` // 最后一条消息时间
+	HasMention      bool                   `protobuf:"varint,9,opt,name=has_mention,json=hasMention,proto3" json:"has_mention,omitempty"`                  // 本次拉取的消息中是否有 @ 当前用户的消息
+	ReadUpTo        *ReadMarker            `protobuf:"bytes,10,opt,name=read_up_to,json=readUpTo,proto3" json:"read_up_to,omitempty"`                      // 私聊中对方已读到的位置（我发送的消息中该条及之前的均已被对方读取）
+	DeliveredUpTo   *DeliveryMarker        `protobuf:"bytes,11,opt,name=delivered_up_to,json=deliveredUpTo,proto3" json:"delivered_up_to,omitempty"`       // 私聊中对方设备已收到的位置（我发送的消息中该条及之前的均已送达）
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ConversationMessages) Reset() {
+	*x = ConversationMessages{}
+	mi := &file_message_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConversationMessages) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConversationMessages) ProtoMessage() {}
+
+func (x *ConversationMessages) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConversationMessages.ProtoReflect.Descriptor instead.
+func (*ConversationMessages) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ConversationMessages) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *ConversationMessages) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ConversationMessages) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *ConversationMessages) GetPeerName() string {
+	if x != nil {
+		return x.PeerName
+	}
+	return ""
+}
+
+func (x *ConversationMessages) GetPeerAvatar() string {
+	if x != nil {
+		return x.PeerAvatar
+	}
+	return ""
+}
+
+func (x *ConversationMessages) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *ConversationMessages) GetMessages() []*UnifiedMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *ConversationMessages) GetLastMessageTime() int64 {
+	if x != nil {
+		return x.LastMessageTime
+	}
+	return 0
+}
+
+func (x *ConversationMessages) GetHasMention() bool {
+	if x != nil {
+		return x.HasMention
+	}
+	return false
+}
+
+func (x *ConversationMessages) GetReadUpTo() *ReadMarker {
+	if x != nil {
+		return x.ReadUpTo
+	}
+	return nil
+}
+
+func (x *ConversationMessages) GetDeliveredUpTo() *DeliveryMarker {
+	if x != nil {
+		return x.DeliveredUpTo
+	}
+	return nil
+}
+
+// 私聊已读位置（对方已读到我发送的哪一条消息）
+type ReadMarker struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`              // 对方已读的最后一条消息ID
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // 该消息的发送时间（秒）
+	ReadAt        int64                  `protobuf:"varint,3,opt,name=read_at,json=readAt,proto3" json:"read_at,omitempty"`          // 已读时间（秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReadMarker) Reset() {
+	*x = ReadMarker{}
+	mi := &file_message_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReadMarker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReadMarker) ProtoMessage() {}
+
+func (x *ReadMarker) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReadMarker.ProtoReflect.Descriptor instead.
+func (*ReadMarker) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ReadMarker) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *ReadMarker) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *ReadMarker) GetReadAt() int64 {
+	if x != nil {
+		return x.ReadAt
+	}
+	return 0
+}
+
+// 私聊送达位置（对方设备已收到我发送的哪一条消息）
+type DeliveryMarker struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MsgId         string                 `protobuf:"bytes,1,opt,name=msg_id,json=msgId,proto3" json:"msg_id,omitempty"`                    // 对方设备确认收到的最后一条消息ID
+	CreatedAt     int64                  `protobuf:"varint,2,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`       // 该消息的发送时间（秒）
+	DeliveredAt   int64                  `protobuf:"varint,3,opt,name=delivered_at,json=deliveredAt,proto3" json:"delivered_at,omitempty"` // 送达时间（秒）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeliveryMarker) Reset() {
+	*x = DeliveryMarker{}
+	mi := &file_message_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeliveryMarker) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeliveryMarker) ProtoMessage() {}
+
+func (x *DeliveryMarker) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeliveryMarker.ProtoReflect.Descriptor instead.
+func (*DeliveryMarker) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *DeliveryMarker) GetMsgId() string {
+	if x != nil {
+		return x.MsgId
+	}
+	return ""
+}
+
+func (x *DeliveryMarker) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DeliveryMarker) GetDeliveredAt() int64 {
+	if x != nil {
+		return x.DeliveredAt
+	}
+	return 0
+}
+
+// 统一消息格式（支持私聊和群聊）
+type UnifiedMessage struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`                                                  // 消息ID
+	Type           string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`                                              // "private" 或 "group"
+	FromUserId     string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`              // 发送者ID
+	FromUserName   string                 `protobuf:"bytes,4,opt,name=from_user_name,json=fromUserName,proto3" json:"from_user_name,omitempty"`        // 发送者昵称（可选）
+	ToUserId       string                 `protobuf:"bytes,5,opt,name=to_user_id,json=toUserId,proto3" json:"to_user_id,omitempty"`                    // 接收者ID（私聊）
+	GroupId        string                 `protobuf:"bytes,6,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`                         // 群组ID（群聊）
+	Content        string                 `protobuf:"bytes,7,opt,name=content,proto3" json:"content,omitempty"`                                        // 消息内容
+	CreatedAt      int64                  `protobuf:"varint,8,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`                  // 时间戳（秒）
+	IsRead         bool                   `protobuf:"varint,9,opt,name=is_read,json=isRead,proto3" json:"is_read,omitempty"`                           // 是否已读
+	StreamId       string                 `protobuf:"bytes,10,opt,name=stream_id,json=streamId,proto3" json:"stream_id,omitempty"`                     // Stream 消息ID（用于分页）
+	IsRecalled     bool                   `protobuf:"varint,11,opt,name=is_recalled,json=isRecalled,proto3" json:"is_recalled,omitempty"`              // 是否已撤回（已撤回的消息 content 为空）
+	IsEdited       bool                   `protobuf:"varint,12,opt,name=is_edited,json=isEdited,proto3" json:"is_edited,omitempty"`                    // 是否被编辑过（content 为最新内容）
+	EditedAt       int64                  `protobuf:"varint,13,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"`                    // 最后编辑时间（秒）
+	MsgType        string                 `protobuf:"bytes,14,opt,name=msg_type,json=msgType,proto3" json:"msg_type,omitempty"`                        // 消息类型: text / image / file / voice / location / contact
+	Payload        *MessagePayload        `protobuf:"bytes,15,opt,name=payload,proto3" json:"payload,omitempty"`                                       // 非文本消息的结构化负载
+	ReplyTo        *ReplySnapshot         `protobuf:"bytes,16,opt,name=reply_to,json=replyTo,proto3" json:"reply_to,omitempty"`                        // 引用的消息快照
+	Reactions      []*Reaction            `protobuf:"bytes,17,rep,name=reactions,proto3" json:"reactions,omitempty"`                                   // 表情回应（按表情聚合）
+	MentionUserIds []string               `protobuf:"bytes,18,rep,name=mention_user_ids,json=mentionUserIds,proto3" json:"mention_user_ids,omitempty"` // 被 @ 的成员ID（仅群聊）
+	MentionAll     bool                   `protobuf:"varint,19,opt,name=mention_all,json=mentionAll,proto3" json:"mention_all,omitempty"`              // 是否 @所有人（仅群聊）
+	ExpiresAt      int64                  `protobuf:"varint,20,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`                 // 过期时间（秒，会话开启定时销毁时），到期后消息被删除
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UnifiedMessage) Reset() {
+	*x = UnifiedMessage{}
+	mi := &file_message_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UnifiedMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UnifiedMessage) ProtoMessage() {}
+
+func (x *UnifiedMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UnifiedMessage.ProtoReflect.Descriptor instead.
+func (*UnifiedMessage) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UnifiedMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetFromUserId() string {
+	if x != nil {
+		return x.FromUserId
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetFromUserName() string {
+	if x != nil {
+		return x.FromUserName
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetToUserId() string {
+	if x != nil {
+		return x.ToUserId
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *UnifiedMessage) GetIsRead() bool {
+	if x != nil {
+		return x.IsRead
+	}
+	return false
+}
+
+func (x *UnifiedMessage) GetStreamId() string {
+	if x != nil {
+		return x.StreamId
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetIsRecalled() bool {
+	if x != nil {
+		return x.IsRecalled
+	}
+	return false
+}
+
+func (x *UnifiedMessage) GetIsEdited() bool {
+	if x != nil {
+		return x.IsEdited
+	}
+	return false
+}
+
+func (x *UnifiedMessage) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+func (x *UnifiedMessage) GetMsgType() string {
+	if x != nil {
+		return x.MsgType
+	}
+	return ""
+}
+
+func (x *UnifiedMessage) GetPayload() *MessagePayload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *UnifiedMessage) GetReplyTo() *ReplySnapshot {
+	if x != nil {
+		return x.ReplyTo
+	}
+	return nil
+}
+
+func (x *UnifiedMessage) GetReactions() []*Reaction {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
+}
+
+func (x *UnifiedMessage) GetMentionUserIds() []string {
+	if x != nil {
+		return x.MentionUserIds
+	}
+	return nil
+}
+
+func (x *UnifiedMessage) GetMentionAll() bool {
+	if x != nil {
+		return x.MentionAll
+	}
+	return false
+}
+
+func (x *UnifiedMessage) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+// 拉取消息的请求(改为拉取按会话分组的未读消息)
+type PullMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                                    // 每页最多读取的 Stream 条目数(默认20,最大100)
+	AutoMark      bool                   `protobuf:"varint,2,opt,name=auto_mark,json=autoMark,proto3" json:"auto_mark,omitempty"`              // 是否自动标记为已读(默认false)
+	IncludeRead   bool                   `protobuf:"varint,3,opt,name=include_read,json=includeRead,proto3" json:"include_read,omitempty"`     // 是否包含已读消息(默认false,只返回未读)
+	FromStreamId  string                 `protobuf:"bytes,4,opt,name=from_stream_id,json=fromStreamId,proto3" json:"from_stream_id,omitempty"` // 从该 Stream ID 之后开始拉取(用于增量拉取和翻页)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullMessagesRequest) Reset() {
+	*x = PullMessagesRequest{}
+	mi := &file_message_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullMessagesRequest) ProtoMessage() {}
+
+func (x *PullMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullMessagesRequest.ProtoReflect.Descriptor instead.
+func (*PullMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *PullMessagesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PullMessagesRequest) GetAutoMark() bool {
+	if x != nil {
+		return x.AutoMark
+	}
+	return false
+}
+
+func (x *PullMessagesRequest) GetIncludeRead() bool {
+	if x != nil {
+		return x.IncludeRead
+	}
+	return false
+}
+
+func (x *PullMessagesRequest) GetFromStreamId() string {
+	if x != nil {
+		return x.FromStreamId
+	}
+	return ""
+}
+
+// 拉取消息的响应（返回结构化的会话列表）
+type PullMessagesResponse struct {
+	state             protoimpl.MessageState  `protogen:"open.v1"`
+	Code              int32                   `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message           string                  `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Conversations     []*ConversationMessages `protobuf:"bytes,3,rep,name=conversations,proto3" json:"conversations,omitempty"`                                   // 按会话分组的消息
+	TotalUnread       int32                   `protobuf:"varint,4,opt,name=total_unread,json=totalUnread,proto3" json:"total_unread,omitempty"`                   // 本页的未读消息数
+	ConversationCount int32                   `protobuf:"varint,5,opt,name=conversation_count,json=conversationCount,proto3" json:"conversation_count,omitempty"` // 本页有未读消息的会话数
+	HasMore           bool                    `protobuf:"varint,6,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`                               // 是否还有更新的消息未拉取
+	NextFromStreamId  string                  `protobuf:"bytes,7,opt,name=next_from_stream_id,json=nextFromStreamId,proto3" json:"next_from_stream_id,omitempty"` // 下一页的 from_stream_id（has_more 为 true 时有效）
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *PullMessagesResponse) Reset() {
+	*x = PullMessagesResponse{}
+	mi := &file_message_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullMessagesResponse) ProtoMessage() {}
+
+func (x *PullMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullMessagesResponse.ProtoReflect.Descriptor instead.
+func (*PullMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *PullMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PullMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PullMessagesResponse) GetConversations() []*ConversationMessages {
+	if x != nil {
+		return x.Conversations
+	}
+	return nil
+}
+
+func (x *PullMessagesResponse) GetTotalUnread() int32 {
+	if x != nil {
+		return x.TotalUnread
+	}
+	return 0
+}
+
+func (x *PullMessagesResponse) GetConversationCount() int32 {
+	if x != nil {
+		return x.ConversationCount
+	}
+	return 0
+}
+
+func (x *PullMessagesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+func (x *PullMessagesResponse) GetNextFromStreamId() string {
+	if x != nil {
+		return x.NextFromStreamId
+	}
+	return ""
+}
+
+// 分页拉取会话历史消息的请求（向前翻页）
+type PullHistoryRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	ConversationId string                 `protobuf:"bytes,1,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 会话ID: "private:{user_id}" 或 "group:{group_id}"
+	BeforeId       string                 `protobuf:"bytes,2,opt,name=before_id,json=beforeId,proto3" json:"before_id,omitempty"`                   // 翻页游标：上一页返回的 next_before_id，为空表示从最新消息开始
+	Limit          int64                  `protobuf:"varint,3,opt,name=limit,proto3" json:"limit,omitempty"`                                        // 每页消息数(默认20，最大100)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *PullHistoryRequest) Reset() {
+	*x = PullHistoryRequest{}
+	mi := &file_message_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullHistoryRequest) ProtoMessage() {}
+
+func (x *PullHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullHistoryRequest.ProtoReflect.Descriptor instead.
+func (*PullHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *PullHistoryRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *PullHistoryRequest) GetBeforeId() string {
+	if x != nil {
+		return x.BeforeId
+	}
+	return ""
+}
+
+func (x *PullHistoryRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 分页拉取会话历史消息的响应
+type PullHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Messages      []*UnifiedMessage      `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`                               // 本页消息（按时间升序）
+	NextBeforeId  string                 `protobuf:"bytes,4,opt,name=next_before_id,json=nextBeforeId,proto3" json:"next_before_id,omitempty"` // 下一页的翻页游标
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`                 // 是否还有更早的消息
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullHistoryResponse) Reset() {
+	*x = PullHistoryResponse{}
+	mi := &file_message_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullHistoryResponse) ProtoMessage() {}
+
+func (x *PullHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullHistoryResponse.ProtoReflect.Descriptor instead.
+func (*PullHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *PullHistoryResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PullHistoryResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PullHistoryResponse) GetMessages() []*UnifiedMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *PullHistoryResponse) GetNextBeforeId() string {
+	if x != nil {
+		return x.NextBeforeId
+	}
+	return ""
+}
+
+func (x *PullHistoryResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 搜索消息的请求
+type SearchMessagesRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Query          string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`                                         // 搜索关键词（至少2个字符）
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 限定会话（可选）: "private:{user_id}" 或 "group:{group_id}"
+	FromUserId     string                 `protobuf:"bytes,3,opt,name=from_user_id,json=fromUserId,proto3" json:"from_user_id,omitempty"`           // 限定发送者（可选）
+	Before         string                 `protobuf:"bytes,4,opt,name=before,proto3" json:"before,omitempty"`                                       // 翻页游标：上一页返回的 next_before，为空表示从最新消息开始
+	Limit          int64                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`                                        // 每页结果数(默认20，最大50)
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *SearchMessagesRequest) Reset() {
+	*x = SearchMessagesRequest{}
+	mi := &file_message_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMessagesRequest) ProtoMessage() {}
+
+func (x *SearchMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMessagesRequest.ProtoReflect.Descriptor instead.
+func (*SearchMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *SearchMessagesRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchMessagesRequest) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *SearchMessagesRequest) GetFromUserId() string {
+	if x != nil {
+		return x.FromUserId
+	}
+	return ""
+}
+
+func (x *SearchMessagesRequest) GetBefore() string {
+	if x != nil {
+		return x.Before
+	}
+	return ""
+}
+
+func (x *SearchMessagesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+// 高亮区间（按字符计，相对于 snippet）
+type HighlightRange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         int32                  `protobuf:"varint,1,opt,name=start,proto3" json:"start,omitempty"`
+	Length        int32                  `protobuf:"varint,2,opt,name=length,proto3" json:"length,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HighlightRange) Reset() {
+	*x = HighlightRange{}
+	mi := &file_message_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HighlightRange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HighlightRange) ProtoMessage() {}
+
+func (x *HighlightRange) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HighlightRange.ProtoReflect.Descriptor instead.
+func (*HighlightRange) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *HighlightRange) GetStart() int32 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *HighlightRange) GetLength() int32 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+// 单条搜索结果
+type MessageSearchResult struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Message        *UnifiedMessage        `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`                                     // 命中的消息
+	ConversationId string                 `protobuf:"bytes,2,opt,name=conversation_id,json=conversationId,proto3" json:"conversation_id,omitempty"` // 所属会话
+	Snippet        string                 `protobuf:"bytes,3,opt,name=snippet,proto3" json:"snippet,omitempty"`                                     // 命中位置附近的内容片段
+	Highlights     []*HighlightRange      `protobuf:"bytes,4,rep,name=highlights,proto3" json:"highlights,omitempty"`                               // snippet 中关键词的位置
+	PrevMsgId      string                 `protobuf:"bytes,5,opt,name=prev_msg_id,json=prevMsgId,proto3" json:"prev_msg_id,omitempty"`              // 同一会话中的上一条消息ID（用于定位上下文）
+	NextMsgId      string                 `protobuf:"bytes,6,opt,name=next_msg_id,json=nextMsgId,proto3" json:"next_msg_id,omitempty"`              // 同一会话中的下一条消息ID
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *MessageSearchResult) Reset() {
+	*x = MessageSearchResult{}
+	mi := &file_message_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageSearchResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSearchResult) ProtoMessage() {}
+
+func (x *MessageSearchResult) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageSearchResult.ProtoReflect.Descriptor instead.
+func (*MessageSearchResult) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *MessageSearchResult) GetMessage() *UnifiedMessage {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *MessageSearchResult) GetConversationId() string {
+	if x != nil {
+		return x.ConversationId
+	}
+	return ""
+}
+
+func (x *MessageSearchResult) GetSnippet() string {
+	if x != nil {
+		return x.Snippet
+	}
+	return ""
+}
+
+func (x *MessageSearchResult) GetHighlights() []*HighlightRange {
+	if x != nil {
+		return x.Highlights
+	}
+	return nil
+}
+
+func (x *MessageSearchResult) GetPrevMsgId() string {
+	if x != nil {
+		return x.PrevMsgId
+	}
+	return ""
+}
+
+func (x *MessageSearchResult) GetNextMsgId() string {
+	if x != nil {
+		return x.NextMsgId
+	}
+	return ""
+}
+
+// 搜索消息的响应
+type SearchMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Results       []*MessageSearchResult `protobuf:"bytes,3,rep,name=results,proto3" json:"results,omitempty"`                         // 按时间倒序
+	NextBefore    string                 `protobuf:"bytes,4,opt,name=next_before,json=nextBefore,proto3" json:"next_before,omitempty"` // 下一页的翻页游标
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`         // 是否还有更多结果
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchMessagesResponse) Reset() {
+	*x = SearchMessagesResponse{}
+	mi := &file_message_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchMessagesResponse) ProtoMessage() {}
+
+func (x *SearchMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchMessagesResponse.ProtoReflect.Descriptor instead.
+func (*SearchMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *SearchMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *SearchMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *SearchMessagesResponse) GetResults() []*MessageSearchResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+func (x *SearchMessagesResponse) GetNextBefore() string {
+	if x != nil {
+		return x.NextBefore
+	}
+	return ""
+}
+
+func (x *SearchMessagesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 获取未读消息数的请求
+type GetUnreadCountRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUnreadCountRequest) Reset() {
+	*x = GetUnreadCountRequest{}
+	mi := &file_message_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUnreadCountRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUnreadCountRequest) ProtoMessage() {}
+
+func (x *GetUnreadCountRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUnreadCountRequest.ProtoReflect.Descriptor instead.
+func (*GetUnreadCountRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{53}
+}
+
+// 获取未读消息数的响应
+type GetUnreadCountResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	UnreadCount   int32                  `protobuf:"varint,3,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"` // 未读消息总数
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUnreadCountResponse) Reset() {
+	*x = GetUnreadCountResponse{}
+	mi := &file_message_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUnreadCountResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUnreadCountResponse) ProtoMessage() {}
+
+func (x *GetUnreadCountResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUnreadCountResponse.ProtoReflect.Descriptor instead.
+func (*GetUnreadCountResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *GetUnreadCountResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *GetUnreadCountResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *GetUnreadCountResponse) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+// 拉取未读消息的请求
+type PullUnreadMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Limit         int64                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                       // 单次拉取上限（建议 100）
+	AutoMark      bool                   `protobuf:"varint,2,opt,name=auto_mark,json=autoMark,proto3" json:"auto_mark,omitempty"` // 是否自动标记为已读
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullUnreadMessagesRequest) Reset() {
+	*x = PullUnreadMessagesRequest{}
+	mi := &file_message_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullUnreadMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullUnreadMessagesRequest) ProtoMessage() {}
+
+func (x *PullUnreadMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullUnreadMessagesRequest.ProtoReflect.Descriptor instead.
+func (*PullUnreadMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{55}
+}
+
+func (x *PullUnreadMessagesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PullUnreadMessagesRequest) GetAutoMark() bool {
+	if x != nil {
+		return x.AutoMark
+	}
+	return false
+}
+
+// 拉取未读消息的响应
+type PullUnreadMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Msgs          []*Message             `protobuf:"bytes,3,rep,name=msgs,proto3" json:"msgs,omitempty"`                                   // 未读消息列表
+	TotalUnread   int32                  `protobuf:"varint,4,opt,name=total_unread,json=totalUnread,proto3" json:"total_unread,omitempty"` // 拉取前的总未读数
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"`             // 是否还有更多未读消息
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullUnreadMessagesResponse) Reset() {
+	*x = PullUnreadMessagesResponse{}
+	mi := &file_message_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullUnreadMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullUnreadMessagesResponse) ProtoMessage() {}
+
+func (x *PullUnreadMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullUnreadMessagesResponse.ProtoReflect.Descriptor instead.
+func (*PullUnreadMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *PullUnreadMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PullUnreadMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PullUnreadMessagesResponse) GetMsgs() []*Message {
+	if x != nil {
+		return x.Msgs
+	}
+	return nil
+}
+
+func (x *PullUnreadMessagesResponse) GetTotalUnread() int32 {
+	if x != nil {
+		return x.TotalUnread
+	}
+	return 0
+}
+
+func (x *PullUnreadMessagesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 登录时拉取所有未读消息的请求
+type PullAllUnreadOnLoginRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullAllUnreadOnLoginRequest) Reset() {
+	*x = PullAllUnreadOnLoginRequest{}
+	mi := &file_message_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullAllUnreadOnLoginRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullAllUnreadOnLoginRequest) ProtoMessage() {}
+
+func (x *PullAllUnreadOnLoginRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullAllUnreadOnLoginRequest.ProtoReflect.Descriptor instead.
+func (*PullAllUnreadOnLoginRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{57}
+}
+
+// 群聊未读消息详情
+type GroupUnreadInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	UnreadCount   int32                  `protobuf:"varint,2,opt,name=unread_count,json=unreadCount,proto3" json:"unread_count,omitempty"`
+	Messages      []*Message             `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GroupUnreadInfo) Reset() {
+	*x = GroupUnreadInfo{}
+	mi := &file_message_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GroupUnreadInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GroupUnreadInfo) ProtoMessage() {}
+
+func (x *GroupUnreadInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GroupUnreadInfo.ProtoReflect.Descriptor instead.
+func (*GroupUnreadInfo) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *GroupUnreadInfo) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *GroupUnreadInfo) GetUnreadCount() int32 {
+	if x != nil {
+		return x.UnreadCount
+	}
+	return 0
+}
+
+func (x *GroupUnreadInfo) GetMessages() []*Message {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+// 登录时拉取所有未读消息的响应
+type PullAllUnreadOnLoginResponse struct {
+	state              protoimpl.MessageState      `protogen:"open.v1"`
+	Code               int32                       `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message            string                      `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	PrivateMessages    []*Message                  `protobuf:"bytes,3,rep,name=private_messages,json=privateMessages,proto3" json:"private_messages,omitempty"`
+	PrivateUnreadCount int32                       `protobuf:"varint,4,opt,name=private_unread_count,json=privateUnreadCount,proto3" json:"private_unread_count,omitempty"`
+	GroupMessages      map[string]*GroupUnreadInfo `protobuf:"bytes,5,rep,name=group_messages,json=groupMessages,proto3" json:"group_messages,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	GroupUnreadCount   int32                       `protobuf:"varint,6,opt,name=group_unread_count,json=groupUnreadCount,proto3" json:"group_unread_count,omitempty"`
+	TotalUnreadCount   int32                       `protobuf:"varint,7,opt,name=total_unread_count,json=totalUnreadCount,proto3" json:"total_unread_count,omitempty"`
+	PulledAt           string                      `protobuf:"bytes,8,opt,name=pulled_at,json=pulledAt,proto3" json:"pulled_at,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *PullAllUnreadOnLoginResponse) Reset() {
+	*x = PullAllUnreadOnLoginResponse{}
+	mi := &file_message_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullAllUnreadOnLoginResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullAllUnreadOnLoginResponse) ProtoMessage() {}
+
+func (x *PullAllUnreadOnLoginResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullAllUnreadOnLoginResponse.ProtoReflect.Descriptor instead.
+func (*PullAllUnreadOnLoginResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetPrivateMessages() []*Message {
+	if x != nil {
+		return x.PrivateMessages
+	}
+	return nil
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetPrivateUnreadCount() int32 {
+	if x != nil {
+		return x.PrivateUnreadCount
+	}
+	return 0
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetGroupMessages() map[string]*GroupUnreadInfo {
+	if x != nil {
+		return x.GroupMessages
+	}
+	return nil
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetGroupUnreadCount() int32 {
+	if x != nil {
+		return x.GroupUnreadCount
+	}
+	return 0
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetTotalUnreadCount() int32 {
+	if x != nil {
+		return x.TotalUnreadCount
+	}
+	return 0
+}
+
+func (x *PullAllUnreadOnLoginResponse) GetPulledAt() string {
+	if x != nil {
+		return x.PulledAt
+	}
+	return ""
+}
+
+// 标记私聊消息已读请求
+type MarkPrivateMessageAsReadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkPrivateMessageAsReadRequest) Reset() {
+	*x = MarkPrivateMessageAsReadRequest{}
+	mi := &file_message_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkPrivateMessageAsReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkPrivateMessageAsReadRequest) ProtoMessage() {}
+
+func (x *MarkPrivateMessageAsReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkPrivateMessageAsReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkPrivateMessageAsReadRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *MarkPrivateMessageAsReadRequest) GetMessageId() string {
+	if x != nil {
+		return x.MessageId
+	}
+	return ""
+}
+
+// 标记私聊消息已读响应
+type MarkPrivateMessageAsReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkPrivateMessageAsReadResponse) Reset() {
+	*x = MarkPrivateMessageAsReadResponse{}
+	mi := &file_message_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkPrivateMessageAsReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkPrivateMessageAsReadResponse) ProtoMessage() {}
+
+func (x *MarkPrivateMessageAsReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkPrivateMessageAsReadResponse.ProtoReflect.Descriptor instead.
+func (*MarkPrivateMessageAsReadResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *MarkPrivateMessageAsReadResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *MarkPrivateMessageAsReadResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 标记群聊消息已读请求
+type MarkGroupMessageAsReadRequest struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	GroupId           string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
+	LastReadMessageId string                 `protobuf:"bytes,2,opt,name=last_read_message_id,json=lastReadMessageId,proto3" json:"last_read_message_id,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *MarkGroupMessageAsReadRequest) Reset() {
+	*x = MarkGroupMessageAsReadRequest{}
+	mi := &file_message_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkGroupMessageAsReadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkGroupMessageAsReadRequest) ProtoMessage() {}
+
+func (x *MarkGroupMessageAsReadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkGroupMessageAsReadRequest.ProtoReflect.Descriptor instead.
+func (*MarkGroupMessageAsReadRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *MarkGroupMessageAsReadRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *MarkGroupMessageAsReadRequest) GetLastReadMessageId() string {
+	if x != nil {
+		return x.LastReadMessageId
+	}
+	return ""
+}
+
+// 标记群聊消息已读响应
+type MarkGroupMessageAsReadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MarkGroupMessageAsReadResponse) Reset() {
+	*x = MarkGroupMessageAsReadResponse{}
+	mi := &file_message_proto_msgTypes[63]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MarkGroupMessageAsReadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MarkGroupMessageAsReadResponse) ProtoMessage() {}
+
+func (x *MarkGroupMessageAsReadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[63]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MarkGroupMessageAsReadResponse.ProtoReflect.Descriptor instead.
+func (*MarkGroupMessageAsReadResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{63}
+}
+
+func (x *MarkGroupMessageAsReadResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *MarkGroupMessageAsReadResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// 拉取群聊消息请求
+type PullGroupMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // 群组ID
+	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                   // 单次拉取上限
+	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`                 // 分页偏移量
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullGroupMessagesRequest) Reset() {
+	*x = PullGroupMessagesRequest{}
+	mi := &file_message_proto_msgTypes[64]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullGroupMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullGroupMessagesRequest) ProtoMessage() {}
+
+func (x *PullGroupMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[64]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullGroupMessagesRequest.ProtoReflect.Descriptor instead.
+func (*PullGroupMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{64}
+}
+
+func (x *PullGroupMessagesRequest) GetGroupId() string {
+	if x != nil {
+		return x.GroupId
+	}
+	return ""
+}
+
+func (x *PullGroupMessagesRequest) GetLimit() int64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *PullGroupMessagesRequest) GetOffset() int64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+// 拉取群聊消息响应
+type PullGroupMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Messages      []*GroupMessage        `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`               // 群聊消息列表
+	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`                    // 总消息数（可选）
+	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"` // 是否还有更多消息
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PullGroupMessagesResponse) Reset() {
+	*x = PullGroupMessagesResponse{}
+	mi := &file_message_proto_msgTypes[65]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PullGroupMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PullGroupMessagesResponse) ProtoMessage() {}
+
+func (x *PullGroupMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[65]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PullGroupMessagesResponse.ProtoReflect.Descriptor instead.
+func (*PullGroupMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{65}
+}
+
+func (x *PullGroupMessagesResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *PullGroupMessagesResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *PullGroupMessagesResponse) GetMessages() []*GroupMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+func (x *PullGroupMessagesResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *PullGroupMessagesResponse) GetHasMore() bool {
+	if x != nil {
+		return x.HasMore
+	}
+	return false
+}
+
+// 更新已读游标请求
+type UpdateLastSeenCursorRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	ConversationType string                 `protobuf:"bytes,1,opt,name=conversation_type,json=conversationType,proto3" json:"conversation_type,omitempty"`     // "private" 或 "group"
+	PeerId           string                 `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                                   // 对方用户ID或群组ID
+	LastSeenStreamId string                 `protobuf:"bytes,3,opt,name=last_seen_stream_id,json=lastSeenStreamId,proto3" json:"last_seen_stream_id,omitempty"` // 最后已读的 Stream ID
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *UpdateLastSeenCursorRequest) Reset() {
+	*x = UpdateLastSeenCursorRequest{}
+	mi := &file_message_proto_msgTypes[66]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLastSeenCursorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLastSeenCursorRequest) ProtoMessage() {}
+
+func (x *UpdateLastSeenCursorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[66]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLastSeenCursorRequest.ProtoReflect.Descriptor instead.
+func (*UpdateLastSeenCursorRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{66}
+}
+
+func (x *UpdateLastSeenCursorRequest) GetConversationType() string {
+	if x != nil {
+		return x.ConversationType
+	}
+	return ""
+}
+
+func (x *UpdateLastSeenCursorRequest) GetPeerId() string {
+	if x != nil {
+		return x.PeerId
+	}
+	return ""
+}
+
+func (x *UpdateLastSeenCursorRequest) GetLastSeenStreamId() string {
+	if x != nil {
+		return x.LastSeenStreamId
+	}
+	return ""
+}
+
+// 更新已读游标响应
+type UpdateLastSeenCursorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"` // 更新后的游标值
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateLastSeenCursorResponse) Reset() {
+	*x = UpdateLastSeenCursorResponse{}
+	mi := &file_message_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateLastSeenCursorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateLastSeenCursorResponse) ProtoMessage() {}
+
+func (x *UpdateLastSeenCursorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[67]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateLastSeenCursorResponse.ProtoReflect.Descriptor instead.
+func (*UpdateLastSeenCursorResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{67}
+}
+
+func (x *UpdateLastSeenCursorResponse) GetCode() int32 {
+	if x != nil {
+		return x.Code
+	}
+	return 0
+}
+
+func (x *UpdateLastSeenCursorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *UpdateLastSeenCursorResponse) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+// 撤回消息请求
+type RecallMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 要撤回的消息ID（私聊或群聊）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RecallMessageRequest) Reset() {
+	*x = RecallMessageRequest{}
+	mi := &file_message_proto_msgTypes[68]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RecallMessageRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RecallMessageRequest) ProtoMessage() {}
+
+func (x *RecallMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[68]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RecallMessageRequest.ProtoReflect.Descriptor instead.
+func (*RecallMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *MarkPrivateMessageAsReadRequest) GetMessageId() string {
+func (x *RecallMessageRequest) GetMessageId() string {
 	if x != nil {
 		return x.MessageId
 	}
 	return ""
 }
 
-// 标记私聊消息已读响应
-type MarkPrivateMessageAsReadResponse struct {
+// 撤回消息响应
+type RecallMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	RecalledAt    int64                  `protobuf:"varint,3,opt,name=recalled_at,json=recalledAt,proto3" json:"recalled_at,omitempty"` // 撤回时间 (Unix时间戳)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkPrivateMessageAsReadResponse) Reset() {
-	*x = MarkPrivateMessageAsReadResponse{}
-	mi := &file_message_proto_msgTypes[18]
+func (x *RecallMessageResponse) Reset() {
+	*x = RecallMessageResponse{}
+	mi := &file_message_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkPrivateMessageAsReadResponse) String() string {
+func (x *RecallMessageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkPrivateMessageAsReadResponse) ProtoMessage() {}
+func (*RecallMessageResponse) ProtoMessage() {}
 
-func (x *MarkPrivateMessageAsReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[18]
+func (x *RecallMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1289,49 +4985,56 @@ func (x *MarkPrivateMessageAsReadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkPrivateMessageAsReadResponse.ProtoReflect.Descriptor instead.
-func (*MarkPrivateMessageAsReadResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use RecallMessageResponse.ProtoReflect.Descriptor instead.
+func (*RecallMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *MarkPrivateMessageAsReadResponse) GetCode() int32 {
+func (x *RecallMessageResponse) GetCode() int32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *MarkPrivateMessageAsReadResponse) GetMessage() string {
+func (x *RecallMessageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-// 标记群聊消息已读请求
-type MarkGroupMessageAsReadRequest struct {
-	state             protoimpl.MessageState `protogen:"open.v1"`
-	GroupId           string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"`
-	LastReadMessageId string                 `protobuf:"bytes,2,opt,name=last_read_message_id,json=lastReadMessageId,proto3" json:"last_read_message_id,omitempty"`
-	unknownFields     protoimpl.UnknownFields
-	sizeCache         protoimpl.SizeCache
+func (x *RecallMessageResponse) GetRecalledAt() int64 {
+	if x != nil {
+		return x.RecalledAt
+	}
+	return 0
 }
 
-func (x *MarkGroupMessageAsReadRequest) Reset() {
-	*x = MarkGroupMessageAsReadRequest{}
-	mi := &file_message_proto_msgTypes[19]
+// 编辑消息请求
+type EditMessageRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 要编辑的消息ID（私聊或群聊）
+	Content       string                 `protobuf:"bytes,2,opt,name=content,proto3" json:"content,omitempty"`                      // 新的消息内容
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EditMessageRequest) Reset() {
+	*x = EditMessageRequest{}
+	mi := &file_message_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkGroupMessageAsReadRequest) String() string {
+func (x *EditMessageRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkGroupMessageAsReadRequest) ProtoMessage() {}
+func (*EditMessageRequest) ProtoMessage() {}
 
-func (x *MarkGroupMessageAsReadRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[19]
+func (x *EditMessageRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1342,49 +5045,50 @@ func (x *MarkGroupMessageAsReadRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkGroupMessageAsReadRequest.ProtoReflect.Descriptor instead.
-func (*MarkGroupMessageAsReadRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use EditMessageRequest.ProtoReflect.Descriptor instead.
+func (*EditMessageRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *MarkGroupMessageAsReadRequest) GetGroupId() string {
+func (x *EditMessageRequest) GetMessageId() string {
 	if x != nil {
-		return x.GroupId
+		return x.MessageId
 	}
 	return ""
 }
 
-func (x *MarkGroupMessageAsReadRequest) GetLastReadMessageId() string {
+func (x *EditMessageRequest) GetContent() string {
 	if x != nil {
-		return x.LastReadMessageId
+		return x.Content
 	}
 	return ""
 }
 
-// 标记群聊消息已读响应
-type MarkGroupMessageAsReadResponse struct {
+// 编辑消息响应
+type EditMessageResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	EditedAt      int64                  `protobuf:"varint,3,opt,name=edited_at,json=editedAt,proto3" json:"edited_at,omitempty"` // 编辑时间 (Unix时间戳)
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *MarkGroupMessageAsReadResponse) Reset() {
-	*x = MarkGroupMessageAsReadResponse{}
-	mi := &file_message_proto_msgTypes[20]
+func (x *EditMessageResponse) Reset() {
+	*x = EditMessageResponse{}
+	mi := &file_message_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *MarkGroupMessageAsReadResponse) String() string {
+func (x *EditMessageResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MarkGroupMessageAsReadResponse) ProtoMessage() {}
+func (*EditMessageResponse) ProtoMessage() {}
 
-func (x *MarkGroupMessageAsReadResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[20]
+func (x *EditMessageResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1395,50 +5099,57 @@ func (x *MarkGroupMessageAsReadResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MarkGroupMessageAsReadResponse.ProtoReflect.Descriptor instead.
-func (*MarkGroupMessageAsReadResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use EditMessageResponse.ProtoReflect.Descriptor instead.
+func (*EditMessageResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *MarkGroupMessageAsReadResponse) GetCode() int32 {
+func (x *EditMessageResponse) GetCode() int32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *MarkGroupMessageAsReadResponse) GetMessage() string {
+func (x *EditMessageResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-// 拉取群聊消息请求
-type PullGroupMessagesRequest struct {
+func (x *EditMessageResponse) GetEditedAt() int64 {
+	if x != nil {
+		return x.EditedAt
+	}
+	return 0
+}
+
+// 消息的表情回应（按表情聚合）
+type Reaction struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	GroupId       string                 `protobuf:"bytes,1,opt,name=group_id,json=groupId,proto3" json:"group_id,omitempty"` // 群组ID
-	Limit         int64                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`                   // 单次拉取上限
-	Offset        int64                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`                 // 分页偏移量
+	Emoji         string                 `protobuf:"bytes,1,opt,name=emoji,proto3" json:"emoji,omitempty"`                                   // 表情
+	Count         int32                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`                                  // 回应人数
+	ReactedByMe   bool                   `protobuf:"varint,3,opt,name=reacted_by_me,json=reactedByMe,proto3" json:"reacted_by_me,omitempty"` // 当前用户是否回应过
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullGroupMessagesRequest) Reset() {
-	*x = PullGroupMessagesRequest{}
-	mi := &file_message_proto_msgTypes[21]
+func (x *Reaction) Reset() {
+	*x = Reaction{}
+	mi := &file_message_proto_msgTypes[72]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullGroupMessagesRequest) String() string {
+func (x *Reaction) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullGroupMessagesRequest) ProtoMessage() {}
+func (*Reaction) ProtoMessage() {}
 
-func (x *PullGroupMessagesRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[21]
+func (x *Reaction) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[72]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1449,59 +5160,56 @@ func (x *PullGroupMessagesRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullGroupMessagesRequest.ProtoReflect.Descriptor instead.
-func (*PullGroupMessagesRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use Reaction.ProtoReflect.Descriptor instead.
+func (*Reaction) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{72}
 }
 
-func (x *PullGroupMessagesRequest) GetGroupId() string {
+func (x *Reaction) GetEmoji() string {
 	if x != nil {
-		return x.GroupId
+		return x.Emoji
 	}
 	return ""
 }
 
-func (x *PullGroupMessagesRequest) GetLimit() int64 {
+func (x *Reaction) GetCount() int32 {
 	if x != nil {
-		return x.Limit
+		return x.Count
 	}
 	return 0
 }
 
-func (x *PullGroupMessagesRequest) GetOffset() int64 {
+func (x *Reaction) GetReactedByMe() bool {
 	if x != nil {
-		return x.Offset
+		return x.ReactedByMe
 	}
-	return 0
+	return false
 }
 
-// 拉取群聊消息响应
-type PullGroupMessagesResponse struct {
+// 添加表情回应请求
+type AddReactionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Messages      []*GroupMessage        `protobuf:"bytes,3,rep,name=messages,proto3" json:"messages,omitempty"`               // 群聊消息列表
-	Total         int32                  `protobuf:"varint,4,opt,name=total,proto3" json:"total,omitempty"`                    // 总消息数（可选）
-	HasMore       bool                   `protobuf:"varint,5,opt,name=has_more,json=hasMore,proto3" json:"has_more,omitempty"` // 是否还有更多消息
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 消息ID（私聊或群聊）
+	Emoji         string                 `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`                          // 表情
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PullGroupMessagesResponse) Reset() {
-	*x = PullGroupMessagesResponse{}
-	mi := &file_message_proto_msgTypes[22]
+func (x *AddReactionRequest) Reset() {
+	*x = AddReactionRequest{}
+	mi := &file_message_proto_msgTypes[73]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PullGroupMessagesResponse) String() string {
+func (x *AddReactionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PullGroupMessagesResponse) ProtoMessage() {}
+func (*AddReactionRequest) ProtoMessage() {}
 
-func (x *PullGroupMessagesResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[22]
+func (x *AddReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[73]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1512,71 +5220,110 @@ func (x *PullGroupMessagesResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PullGroupMessagesResponse.ProtoReflect.Descriptor instead.
-func (*PullGroupMessagesResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use AddReactionRequest.ProtoReflect.Descriptor instead.
+func (*AddReactionRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{73}
 }
 
-func (x *PullGroupMessagesResponse) GetCode() int32 {
+func (x *AddReactionRequest) GetMessageId() string {
 	if x != nil {
-		return x.Code
+		return x.MessageId
 	}
-	return 0
+	return ""
 }
 
-func (x *PullGroupMessagesResponse) GetMessage() string {
+func (x *AddReactionRequest) GetEmoji() string {
 	if x != nil {
-		return x.Message
+		return x.Emoji
 	}
 	return ""
 }
 
-func (x *PullGroupMessagesResponse) GetMessages() []*GroupMessage {
+// 添加表情回应响应
+type AddReactionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Reactions     []*Reaction            `protobuf:"bytes,3,rep,name=reactions,proto3" json:"reactions,omitempty"` // 该消息最新的回应汇总
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddReactionResponse) Reset() {
+	*x = AddReactionResponse{}
+	mi := &file_message_proto_msgTypes[74]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddReactionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddReactionResponse) ProtoMessage() {}
+
+func (x *AddReactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[74]
 	if x != nil {
-		return x.Messages
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *PullGroupMessagesResponse) GetTotal() int32 {
+// Deprecated: Use AddReactionResponse.ProtoReflect.Descriptor instead.
+func (*AddReactionResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{74}
+}
+
+func (x *AddReactionResponse) GetCode() int32 {
 	if x != nil {
-		return x.Total
+		return x.Code
 	}
 	return 0
 }
 
-func (x *PullGroupMessagesResponse) GetHasMore() bool {
+func (x *AddReactionResponse) GetMessage() string {
 	if x != nil {
-		return x.HasMore
+		return x.Message
 	}
-	return false
+	return ""
 }
 
-// 更新已读游标请求
-type UpdateLastSeenCursorRequest struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	ConversationType string                 `protobuf:"bytes,1,opt,name=conversation_type,json=conversationType,proto3" json:"conversation_type,omitempty"`     // "private" 或 "group"
-	PeerId           string                 `protobuf:"bytes,2,opt,name=peer_id,json=peerId,proto3" json:"peer_id,omitempty"`                                   // 对方用户ID或群组ID
-	LastSeenStreamId string                 `protobuf:"bytes,3,opt,name=last_seen_stream_id,json=lastSeenStreamId,proto3" json:"last_seen_stream_id,omitempty"` // 最后已读的 Stream ID
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+func (x *AddReactionResponse) GetReactions() []*Reaction {
+	if x != nil {
+		return x.Reactions
+	}
+	return nil
 }
 
-func (x *UpdateLastSeenCursorRequest) Reset() {
-	*x = UpdateLastSeenCursorRequest{}
-	mi := &file_message_proto_msgTypes[23]
+// 取消表情回应请求
+type RemoveReactionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MessageId     string                 `protobuf:"bytes,1,opt,name=message_id,json=messageId,proto3" json:"message_id,omitempty"` // 消息ID（私聊或群聊）
+	Emoji         string                 `protobuf:"bytes,2,opt,name=emoji,proto3" json:"emoji,omitempty"`                          // 表情
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveReactionRequest) Reset() {
+	*x = RemoveReactionRequest{}
+	mi := &file_message_proto_msgTypes[75]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateLastSeenCursorRequest) String() string {
+func (x *RemoveReactionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateLastSeenCursorRequest) ProtoMessage() {}
+func (*RemoveReactionRequest) ProtoMessage() {}
 
-func (x *UpdateLastSeenCursorRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[23]
+func (x *RemoveReactionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[75]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1587,57 +5334,50 @@ func (x *UpdateLastSeenCursorRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateLastSeenCursorRequest.ProtoReflect.Descriptor instead.
-func (*UpdateLastSeenCursorRequest) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{23}
-}
-
-func (x *UpdateLastSeenCursorRequest) GetConversationType() string {
-	if x != nil {
-		return x.ConversationType
-	}
-	return ""
+// Deprecated: Use RemoveReactionRequest.ProtoReflect.Descriptor instead.
+func (*RemoveReactionRequest) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{75}
 }
 
-func (x *UpdateLastSeenCursorRequest) GetPeerId() string {
+func (x *RemoveReactionRequest) GetMessageId() string {
 	if x != nil {
-		return x.PeerId
+		return x.MessageId
 	}
 	return ""
 }
 
-func (x *UpdateLastSeenCursorRequest) GetLastSeenStreamId() string {
+func (x *RemoveReactionRequest) GetEmoji() string {
 	if x != nil {
-		return x.LastSeenStreamId
+		return x.Emoji
 	}
 	return ""
 }
 
-// 更新已读游标响应
-type UpdateLastSeenCursorResponse struct {
+// 取消表情回应响应
+type RemoveReactionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Code          int32                  `protobuf:"varint,1,opt,name=code,proto3" json:"code,omitempty"`
 	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Cursor        string                 `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"` // 更新后的游标值
+	Reactions     []*Reaction            `protobuf:"bytes,3,rep,name=reactions,proto3" json:"reactions,omitempty"` // 该消息最新的回应汇总
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateLastSeenCursorResponse) Reset() {
-	*x = UpdateLastSeenCursorResponse{}
-	mi := &file_message_proto_msgTypes[24]
+func (x *RemoveReactionResponse) Reset() {
+	*x = RemoveReactionResponse{}
+	mi := &file_message_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateLastSeenCursorResponse) String() string {
+func (x *RemoveReactionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateLastSeenCursorResponse) ProtoMessage() {}
+func (*RemoveReactionResponse) ProtoMessage() {}
 
-func (x *UpdateLastSeenCursorResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_message_proto_msgTypes[24]
+func (x *RemoveReactionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_message_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1648,37 +5388,37 @@ func (x *UpdateLastSeenCursorResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateLastSeenCursorResponse.ProtoReflect.Descriptor instead.
-func (*UpdateLastSeenCursorResponse) Descriptor() ([]byte, []int) {
-	return file_message_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use RemoveReactionResponse.ProtoReflect.Descriptor instead.
+func (*RemoveReactionResponse) Descriptor() ([]byte, []int) {
+	return file_message_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *UpdateLastSeenCursorResponse) GetCode() int32 {
+func (x *RemoveReactionResponse) GetCode() int32 {
 	if x != nil {
 		return x.Code
 	}
 	return 0
 }
 
-func (x *UpdateLastSeenCursorResponse) GetMessage() string {
+func (x *RemoveReactionResponse) GetMessage() string {
 	if x != nil {
 		return x.Message
 	}
 	return ""
 }
 
-func (x *UpdateLastSeenCursorResponse) GetCursor() string {
+func (x *RemoveReactionResponse) GetReactions() []*Reaction {
 	if x != nil {
-		return x.Cursor
+		return x.Reactions
 	}
-	return ""
+	return nil
 }
 
 var File_message_proto protoreflect.FileDescriptor
 
 const file_message_proto_rawDesc = "" +
 	"\n" +
-	"\rmessage.proto\x12\rproto.message\"\xc4\x01\n" +
+	"\rmessage.proto\x12\rproto.message\"\xf0\x02\n" +
 	"\aMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12 \n" +
 	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
@@ -1689,7 +5429,13 @@ const file_message_proto_rawDesc = "" +
 	"\n" +
 	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x17\n" +
 	"\ais_read\x18\x06 \x01(\bR\x06isRead\x12\x17\n" +
-	"\aread_at\x18\a \x01(\x03R\x06readAt\"\x94\x01\n" +
+	"\aread_at\x18\a \x01(\x03R\x06readAt\x12\x19\n" +
+	"\bmsg_type\x18\b \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\t \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x127\n" +
+	"\breply_to\x18\n" +
+	" \x01(\v2\x1c.proto.message.ReplySnapshotR\areplyTo\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\v \x01(\x03R\texpiresAt\"\x8b\x03\n" +
 	"\fGroupMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
 	"\bgroup_id\x18\x02 \x01(\tR\agroupId\x12 \n" +
@@ -1697,22 +5443,239 @@ const file_message_proto_rawDesc = "" +
 	"fromUserId\x12\x18\n" +
 	"\acontent\x18\x04 \x01(\tR\acontent\x12\x1d\n" +
 	"\n" +
-	"created_at\x18\x05 \x01(\x03R\tcreatedAt\"L\n" +
+	"created_at\x18\x05 \x01(\x03R\tcreatedAt\x12\x19\n" +
+	"\bmsg_type\x18\x06 \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\a \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x127\n" +
+	"\breply_to\x18\b \x01(\v2\x1c.proto.message.ReplySnapshotR\areplyTo\x12(\n" +
+	"\x10mention_user_ids\x18\t \x03(\tR\x0ementionUserIds\x12\x1f\n" +
+	"\vmention_all\x18\n" +
+	" \x01(\bR\n" +
+	"mentionAll\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\v \x01(\x03R\texpiresAt\"{\n" +
+	"\fImagePayload\x12\x17\n" +
+	"\aoss_key\x18\x01 \x01(\tR\x06ossKey\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\"\x86\x01\n" +
+	"\vFilePayload\x12\x17\n" +
+	"\aoss_key\x18\x01 \x01(\tR\x06ossKey\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1b\n" +
+	"\tfile_name\x18\x03 \x01(\tR\bfileName\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1b\n" +
+	"\tmime_type\x18\x05 \x01(\tR\bmimeType\"i\n" +
+	"\fVoicePayload\x12\x17\n" +
+	"\aoss_key\x18\x01 \x01(\tR\x06ossKey\x12\x10\n" +
+	"\x03url\x18\x02 \x01(\tR\x03url\x12\x1a\n" +
+	"\bduration\x18\x03 \x01(\x05R\bduration\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\"y\n" +
+	"\x0fLocationPayload\x12\x1a\n" +
+	"\blatitude\x18\x01 \x01(\x01R\blatitude\x12\x1c\n" +
+	"\tlongitude\x18\x02 \x01(\x01R\tlongitude\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aaddress\x18\x04 \x01(\tR\aaddress\"a\n" +
+	"\x12ContactCardPayload\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\bnickname\x18\x02 \x01(\tR\bnickname\x12\x16\n" +
+	"\x06avatar\x18\x03 \x01(\tR\x06avatar\"\xfc\x01\n" +
+	"\x0eChatRecordItem\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
+	"fromUserId\x12$\n" +
+	"\x0efrom_user_name\x18\x03 \x01(\tR\ffromUserName\x12\x19\n" +
+	"\bmsg_type\x18\x04 \x01(\tR\amsgType\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x127\n" +
+	"\apayload\x18\x06 \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\a \x01(\x03R\tcreatedAt\"^\n" +
+	"\x11ChatRecordPayload\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x123\n" +
+	"\x05items\x18\x02 \x03(\v2\x1d.proto.message.ChatRecordItemR\x05items\"\x8b\x01\n" +
+	"\vForwardInfo\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
+	"fromUserId\x12$\n" +
+	"\x0efrom_user_name\x18\x03 \x01(\tR\ffromUserName\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\"\x98\x03\n" +
+	"\x0eMessagePayload\x121\n" +
+	"\x05image\x18\x01 \x01(\v2\x1b.proto.message.ImagePayloadR\x05image\x12.\n" +
+	"\x04file\x18\x02 \x01(\v2\x1a.proto.message.FilePayloadR\x04file\x121\n" +
+	"\x05voice\x18\x03 \x01(\v2\x1b.proto.message.VoicePayloadR\x05voice\x12:\n" +
+	"\blocation\x18\x04 \x01(\v2\x1e.proto.message.LocationPayloadR\blocation\x12;\n" +
+	"\acontact\x18\x05 \x01(\v2!.proto.message.ContactCardPayloadR\acontact\x12A\n" +
+	"\vchat_record\x18\x06 \x01(\v2 .proto.message.ChatRecordPayloadR\n" +
+	"chatRecord\x124\n" +
+	"\aforward\x18\a \x01(\v2\x1a.proto.message.ForwardInfoR\aforward\"\xe3\x01\n" +
+	"\rReplySnapshot\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12 \n" +
+	"\ffrom_user_id\x18\x02 \x01(\tR\n" +
+	"fromUserId\x12$\n" +
+	"\x0efrom_user_name\x18\x03 \x01(\tR\ffromUserName\x12\x19\n" +
+	"\bmsg_type\x18\x04 \x01(\tR\amsgType\x12\x18\n" +
+	"\apreview\x18\x05 \x01(\tR\apreview\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1f\n" +
+	"\vis_recalled\x18\a \x01(\bR\n" +
+	"isRecalled\"\x84\x02\n" +
 	"\x12SendMessageRequest\x12\x1c\n" +
 	"\n" +
 	"to_user_id\x18\x01 \x01(\tR\btoUserId\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"m\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x19\n" +
+	"\bmsg_type\x18\x03 \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\x04 \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x12%\n" +
+	"\x0freply_to_msg_id\x18\x05 \x01(\tR\freplyToMsgId\x12\"\n" +
+	"\rclient_msg_id\x18\x06 \x01(\tR\vclientMsgId\x12\x17\n" +
+	"\asend_at\x18\a \x01(\x03R\x06sendAt\"\xe7\x01\n" +
 	"\x13SendMessageResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12(\n" +
-	"\x03msg\x18\x03 \x01(\v2\x16.proto.message.MessageR\x03msg\"N\n" +
+	"\x03msg\x18\x03 \x01(\v2\x16.proto.message.MessageR\x03msg\x12\x1b\n" +
+	"\tstream_id\x18\x04 \x01(\tR\bstreamId\x12\x1c\n" +
+	"\tduplicate\x18\x05 \x01(\bR\tduplicate\x12=\n" +
+	"\tscheduled\x18\x06 \x01(\v2\x1f.proto.message.ScheduledMessageR\tscheduled\"\xd1\x02\n" +
 	"\x17SendGroupMessageRequest\x12\x19\n" +
 	"\bgroup_id\x18\x01 \x01(\tR\agroupId\x12\x18\n" +
-	"\acontent\x18\x02 \x01(\tR\acontent\"w\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x12\x19\n" +
+	"\bmsg_type\x18\x03 \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\x04 \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x12%\n" +
+	"\x0freply_to_msg_id\x18\x05 \x01(\tR\freplyToMsgId\x12(\n" +
+	"\x10mention_user_ids\x18\x06 \x03(\tR\x0ementionUserIds\x12\x1f\n" +
+	"\vmention_all\x18\a \x01(\bR\n" +
+	"mentionAll\x12\"\n" +
+	"\rclient_msg_id\x18\b \x01(\tR\vclientMsgId\x12\x17\n" +
+	"\asend_at\x18\t \x01(\x03R\x06sendAt\"\xf1\x01\n" +
 	"\x18SendGroupMessageResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12-\n" +
-	"\x03msg\x18\x03 \x01(\v2\x1b.proto.message.GroupMessageR\x03msg\"\xb4\x02\n" +
+	"\x03msg\x18\x03 \x01(\v2\x1b.proto.message.GroupMessageR\x03msg\x12\x1b\n" +
+	"\tstream_id\x18\x04 \x01(\tR\bstreamId\x12\x1c\n" +
+	"\tduplicate\x18\x05 \x01(\bR\tduplicate\x12=\n" +
+	"\tscheduled\x18\x06 \x01(\v2\x1f.proto.message.ScheduledMessageR\tscheduled\"\x84\x04\n" +
+	"\x10ScheduledMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12+\n" +
+	"\x11conversation_type\x18\x02 \x01(\tR\x10conversationType\x12\x1c\n" +
+	"\n" +
+	"to_user_id\x18\x03 \x01(\tR\btoUserId\x12\x19\n" +
+	"\bgroup_id\x18\x04 \x01(\tR\agroupId\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12\x19\n" +
+	"\bmsg_type\x18\x06 \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\a \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x12%\n" +
+	"\x0freply_to_msg_id\x18\b \x01(\tR\freplyToMsgId\x12(\n" +
+	"\x10mention_user_ids\x18\t \x03(\tR\x0ementionUserIds\x12\x1f\n" +
+	"\vmention_all\x18\n" +
+	" \x01(\bR\n" +
+	"mentionAll\x12\x17\n" +
+	"\asend_at\x18\v \x01(\x03R\x06sendAt\x12\x16\n" +
+	"\x06status\x18\f \x01(\tR\x06status\x12\x15\n" +
+	"\x06msg_id\x18\r \x01(\tR\x05msgId\x12\x14\n" +
+	"\x05error\x18\x0e \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x0f \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x10 \x01(\x03R\tupdatedAt\"_\n" +
+	"\x1cListScheduledMessagesRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\"\x8a\x01\n" +
+	"\x1dListScheduledMessagesResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12;\n" +
+	"\bmessages\x18\x03 \x03(\v2\x1f.proto.message.ScheduledMessageR\bmessages\"\xae\x01\n" +
+	"\x1dUpdateScheduledMessageRequest\x12!\n" +
+	"\fscheduled_id\x18\x01 \x01(\tR\vscheduledId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\x127\n" +
+	"\apayload\x18\x03 \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x12\x17\n" +
+	"\asend_at\x18\x04 \x01(\x03R\x06sendAt\"\x8d\x01\n" +
+	"\x1eUpdateScheduledMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12=\n" +
+	"\tscheduled\x18\x03 \x01(\v2\x1f.proto.message.ScheduledMessageR\tscheduled\"B\n" +
+	"\x1dCancelScheduledMessageRequest\x12!\n" +
+	"\fscheduled_id\x18\x01 \x01(\tR\vscheduledId\"N\n" +
+	"\x1eCancelScheduledMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"A\n" +
+	" GetGroupMessageReadStatusRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\"F\n" +
+	"\x0fGroupReadMember\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x1a\n" +
+	"\busername\x18\x02 \x01(\tR\busername\"\xb8\x02\n" +
+	"!GetGroupMessageReadStatusResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x19\n" +
+	"\bgroup_id\x18\x03 \x01(\tR\agroupId\x12\x1d\n" +
+	"\n" +
+	"read_count\x18\x04 \x01(\x05R\treadCount\x12!\n" +
+	"\funread_count\x18\x05 \x01(\x05R\vunreadCount\x12A\n" +
+	"\fread_members\x18\x06 \x03(\v2\x1e.proto.message.GroupReadMemberR\vreadMembers\x12E\n" +
+	"\x0eunread_members\x18\a \x03(\v2\x1e.proto.message.GroupReadMemberR\runreadMembers\"\x85\x01\n" +
+	"\x16ForwardMessagesRequest\x12\x1f\n" +
+	"\vmessage_ids\x18\x01 \x03(\tR\n" +
+	"messageIds\x126\n" +
+	"\x17target_conversation_ids\x18\x02 \x03(\tR\x15targetConversationIds\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\"g\n" +
+	"\rForwardResult\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x17\n" +
+	"\amsg_ids\x18\x02 \x03(\tR\x06msgIds\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"\x7f\n" +
+	"\x17ForwardMessagesResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x126\n" +
+	"\aresults\x18\x03 \x03(\v2\x1c.proto.message.ForwardResultR\aresults\"\xff\x01\n" +
+	"\rPinnedMessage\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12 \n" +
+	"\ffrom_user_id\x18\x03 \x01(\tR\n" +
+	"fromUserId\x12\x19\n" +
+	"\bmsg_type\x18\x04 \x01(\tR\amsgType\x12\x18\n" +
+	"\acontent\x18\x05 \x01(\tR\acontent\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1b\n" +
+	"\tpinned_by\x18\a \x01(\tR\bpinnedBy\x12\x1b\n" +
+	"\tpinned_at\x18\b \x01(\x03R\bpinnedAt\"2\n" +
+	"\x11PinMessageRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\"x\n" +
+	"\x12PinMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x124\n" +
+	"\x06pinned\x18\x03 \x01(\v2\x1c.proto.message.PinnedMessageR\x06pinned\"4\n" +
+	"\x13UnpinMessageRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\"D\n" +
+	"\x14UnpinMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"D\n" +
+	"\x19ListPinnedMessagesRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"|\n" +
+	"\x1aListPinnedMessagesResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x120\n" +
+	"\x04pins\x18\x03 \x03(\v2\x1c.proto.message.PinnedMessageR\x04pins\"\x99\x01\n" +
+	"\x0fConversationTTL\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\x12\x1d\n" +
+	"\n" +
+	"updated_by\x18\x03 \x01(\tR\tupdatedBy\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\x04 \x01(\x03R\tupdatedAt\"e\n" +
+	"\x19SetConversationTTLRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1f\n" +
+	"\vttl_seconds\x18\x02 \x01(\x03R\n" +
+	"ttlSeconds\"\x84\x01\n" +
+	"\x1aSetConversationTTLResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x128\n" +
+	"\asetting\x18\x03 \x01(\v2\x1e.proto.message.ConversationTTLR\asetting\"D\n" +
+	"\x19GetConversationTTLRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\"\x84\x01\n" +
+	"\x1aGetConversationTTLResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x128\n" +
+	"\asetting\x18\x03 \x01(\v2\x1e.proto.message.ConversationTTLR\asetting\"\xd5\x03\n" +
 	"\x14ConversationMessages\x12'\n" +
 	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12\x17\n" +
@@ -1722,7 +5685,24 @@ const file_message_proto_rawDesc = "" +
 	"peerAvatar\x12!\n" +
 	"\funread_count\x18\x06 \x01(\x05R\vunreadCount\x129\n" +
 	"\bmessages\x18\a \x03(\v2\x1d.proto.message.UnifiedMessageR\bmessages\x12*\n" +
-	"\x11last_message_time\x18\b \x01(\x03R\x0flastMessageTime\"\xa4\x02\n" +
+	"\x11last_message_time\x18\b \x01(\x03R\x0flastMessageTime\x12\x1f\n" +
+	"\vhas_mention\x18\t \x01(\bR\n" +
+	"hasMention\x127\n" +
+	"\n" +
+	"read_up_to\x18\n" +
+	" \x01(\v2\x19.proto.message.ReadMarkerR\breadUpTo\x12E\n" +
+	"\x0fdelivered_up_to\x18\v \x01(\v2\x1d.proto.message.DeliveryMarkerR\rdeliveredUpTo\"[\n" +
+	"\n" +
+	"ReadMarker\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12\x17\n" +
+	"\aread_at\x18\x03 \x01(\x03R\x06readAt\"i\n" +
+	"\x0eDeliveryMarker\x12\x15\n" +
+	"\x06msg_id\x18\x01 \x01(\tR\x05msgId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x02 \x01(\x03R\tcreatedAt\x12!\n" +
+	"\fdelivered_at\x18\x03 \x01(\x03R\vdeliveredAt\"\xad\x05\n" +
 	"\x0eUnifiedMessage\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
 	"\x04type\x18\x02 \x01(\tR\x04type\x12 \n" +
@@ -1737,18 +5717,69 @@ const file_message_proto_rawDesc = "" +
 	"created_at\x18\b \x01(\x03R\tcreatedAt\x12\x17\n" +
 	"\ais_read\x18\t \x01(\bR\x06isRead\x12\x1b\n" +
 	"\tstream_id\x18\n" +
-	" \x01(\tR\bstreamId\"\x91\x01\n" +
+	" \x01(\tR\bstreamId\x12\x1f\n" +
+	"\vis_recalled\x18\v \x01(\bR\n" +
+	"isRecalled\x12\x1b\n" +
+	"\tis_edited\x18\f \x01(\bR\bisEdited\x12\x1b\n" +
+	"\tedited_at\x18\r \x01(\x03R\beditedAt\x12\x19\n" +
+	"\bmsg_type\x18\x0e \x01(\tR\amsgType\x127\n" +
+	"\apayload\x18\x0f \x01(\v2\x1d.proto.message.MessagePayloadR\apayload\x127\n" +
+	"\breply_to\x18\x10 \x01(\v2\x1c.proto.message.ReplySnapshotR\areplyTo\x125\n" +
+	"\treactions\x18\x11 \x03(\v2\x17.proto.message.ReactionR\treactions\x12(\n" +
+	"\x10mention_user_ids\x18\x12 \x03(\tR\x0ementionUserIds\x12\x1f\n" +
+	"\vmention_all\x18\x13 \x01(\bR\n" +
+	"mentionAll\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x14 \x01(\x03R\texpiresAt\"\x91\x01\n" +
 	"\x13PullMessagesRequest\x12\x14\n" +
 	"\x05limit\x18\x01 \x01(\x03R\x05limit\x12\x1b\n" +
 	"\tauto_mark\x18\x02 \x01(\bR\bautoMark\x12!\n" +
 	"\finclude_read\x18\x03 \x01(\bR\vincludeRead\x12$\n" +
-	"\x0efrom_stream_id\x18\x04 \x01(\tR\ffromStreamId\"\xe1\x01\n" +
+	"\x0efrom_stream_id\x18\x04 \x01(\tR\ffromStreamId\"\xab\x02\n" +
 	"\x14PullMessagesResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12I\n" +
 	"\rconversations\x18\x03 \x03(\v2#.proto.message.ConversationMessagesR\rconversations\x12!\n" +
 	"\ftotal_unread\x18\x04 \x01(\x05R\vtotalUnread\x12-\n" +
-	"\x12conversation_count\x18\x05 \x01(\x05R\x11conversationCount\"\x17\n" +
+	"\x12conversation_count\x18\x05 \x01(\x05R\x11conversationCount\x12\x19\n" +
+	"\bhas_more\x18\x06 \x01(\bR\ahasMore\x12-\n" +
+	"\x13next_from_stream_id\x18\a \x01(\tR\x10nextFromStreamId\"p\n" +
+	"\x12PullHistoryRequest\x12'\n" +
+	"\x0fconversation_id\x18\x01 \x01(\tR\x0econversationId\x12\x1b\n" +
+	"\tbefore_id\x18\x02 \x01(\tR\bbeforeId\x12\x14\n" +
+	"\x05limit\x18\x03 \x01(\x03R\x05limit\"\xbf\x01\n" +
+	"\x13PullHistoryResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x129\n" +
+	"\bmessages\x18\x03 \x03(\v2\x1d.proto.message.UnifiedMessageR\bmessages\x12$\n" +
+	"\x0enext_before_id\x18\x04 \x01(\tR\fnextBeforeId\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\"\xa6\x01\n" +
+	"\x15SearchMessagesRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12 \n" +
+	"\ffrom_user_id\x18\x03 \x01(\tR\n" +
+	"fromUserId\x12\x16\n" +
+	"\x06before\x18\x04 \x01(\tR\x06before\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x03R\x05limit\">\n" +
+	"\x0eHighlightRange\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x05R\x05start\x12\x16\n" +
+	"\x06length\x18\x02 \x01(\x05R\x06length\"\x90\x02\n" +
+	"\x13MessageSearchResult\x127\n" +
+	"\amessage\x18\x01 \x01(\v2\x1d.proto.message.UnifiedMessageR\amessage\x12'\n" +
+	"\x0fconversation_id\x18\x02 \x01(\tR\x0econversationId\x12\x18\n" +
+	"\asnippet\x18\x03 \x01(\tR\asnippet\x12=\n" +
+	"\n" +
+	"highlights\x18\x04 \x03(\v2\x1d.proto.message.HighlightRangeR\n" +
+	"highlights\x12\x1e\n" +
+	"\vprev_msg_id\x18\x05 \x01(\tR\tprevMsgId\x12\x1e\n" +
+	"\vnext_msg_id\x18\x06 \x01(\tR\tnextMsgId\"\xc0\x01\n" +
+	"\x16SearchMessagesResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12<\n" +
+	"\aresults\x18\x03 \x03(\v2\".proto.message.MessageSearchResultR\aresults\x12\x1f\n" +
+	"\vnext_before\x18\x04 \x01(\tR\n" +
+	"nextBefore\x12\x19\n" +
+	"\bhas_more\x18\x05 \x01(\bR\ahasMore\"\x17\n" +
 	"\x15GetUnreadCountRequest\"i\n" +
 	"\x16GetUnreadCountResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
@@ -1809,7 +5840,43 @@ const file_message_proto_rawDesc = "" +
 	"\x1cUpdateLastSeenCursorResponse\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
 	"\amessage\x18\x02 \x01(\tR\amessage\x12\x16\n" +
-	"\x06cursor\x18\x03 \x01(\tR\x06cursor2\xac\b\n" +
+	"\x06cursor\x18\x03 \x01(\tR\x06cursor\"5\n" +
+	"\x14RecallMessageRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\"f\n" +
+	"\x15RecallMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1f\n" +
+	"\vrecalled_at\x18\x03 \x01(\x03R\n" +
+	"recalledAt\"M\n" +
+	"\x12EditMessageRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x18\n" +
+	"\acontent\x18\x02 \x01(\tR\acontent\"`\n" +
+	"\x13EditMessageResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x1b\n" +
+	"\tedited_at\x18\x03 \x01(\x03R\beditedAt\"Z\n" +
+	"\bReaction\x12\x14\n" +
+	"\x05emoji\x18\x01 \x01(\tR\x05emoji\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\x12\"\n" +
+	"\rreacted_by_me\x18\x03 \x01(\bR\vreactedByMe\"I\n" +
+	"\x12AddReactionRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\"z\n" +
+	"\x13AddReactionResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x125\n" +
+	"\treactions\x18\x03 \x03(\v2\x17.proto.message.ReactionR\treactions\"L\n" +
+	"\x15RemoveReactionRequest\x12\x1d\n" +
+	"\n" +
+	"message_id\x18\x01 \x01(\tR\tmessageId\x12\x14\n" +
+	"\x05emoji\x18\x02 \x01(\tR\x05emoji\"}\n" +
+	"\x16RemoveReactionResponse\x12\x12\n" +
+	"\x04code\x18\x01 \x01(\x05R\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x125\n" +
+	"\treactions\x18\x03 \x03(\v2\x17.proto.message.ReactionR\treactions2\xf9\x14\n" +
 	"\x0eMessageService\x12T\n" +
 	"\vSendMessage\x12!.proto.message.SendMessageRequest\x1a\".proto.message.SendMessageResponse\x12c\n" +
 	"\x10SendGroupMessage\x12&.proto.message.SendGroupMessageRequest\x1a'.proto.message.SendGroupMessageResponse\x12W\n" +
@@ -1820,7 +5887,24 @@ const file_message_proto_rawDesc = "" +
 	"\x14PullAllUnreadOnLogin\x12*.proto.message.PullAllUnreadOnLoginRequest\x1a+.proto.message.PullAllUnreadOnLoginResponse\x12{\n" +
 	"\x18MarkPrivateMessageAsRead\x12..proto.message.MarkPrivateMessageAsReadRequest\x1a/.proto.message.MarkPrivateMessageAsReadResponse\x12u\n" +
 	"\x16MarkGroupMessageAsRead\x12,.proto.message.MarkGroupMessageAsReadRequest\x1a-.proto.message.MarkGroupMessageAsReadResponse\x12f\n" +
-	"\x11PullGroupMessages\x12'.proto.message.PullGroupMessagesRequest\x1a(.proto.message.PullGroupMessagesResponseB\x1aZ\x18ChatIM/api/proto/messageb\x06proto3"
+	"\x11PullGroupMessages\x12'.proto.message.PullGroupMessagesRequest\x1a(.proto.message.PullGroupMessagesResponse\x12Z\n" +
+	"\rRecallMessage\x12#.proto.message.RecallMessageRequest\x1a$.proto.message.RecallMessageResponse\x12T\n" +
+	"\vEditMessage\x12!.proto.message.EditMessageRequest\x1a\".proto.message.EditMessageResponse\x12T\n" +
+	"\vAddReaction\x12!.proto.message.AddReactionRequest\x1a\".proto.message.AddReactionResponse\x12]\n" +
+	"\x0eRemoveReaction\x12$.proto.message.RemoveReactionRequest\x1a%.proto.message.RemoveReactionResponse\x12T\n" +
+	"\vPullHistory\x12!.proto.message.PullHistoryRequest\x1a\".proto.message.PullHistoryResponse\x12]\n" +
+	"\x0eSearchMessages\x12$.proto.message.SearchMessagesRequest\x1a%.proto.message.SearchMessagesResponse\x12r\n" +
+	"\x15ListScheduledMessages\x12+.proto.message.ListScheduledMessagesRequest\x1a,.proto.message.ListScheduledMessagesResponse\x12u\n" +
+	"\x16UpdateScheduledMessage\x12,.proto.message.UpdateScheduledMessageRequest\x1a-.proto.message.UpdateScheduledMessageResponse\x12u\n" +
+	"\x16CancelScheduledMessage\x12,.proto.message.CancelScheduledMessageRequest\x1a-.proto.message.CancelScheduledMessageResponse\x12i\n" +
+	"\x12SetConversationTTL\x12(.proto.message.SetConversationTTLRequest\x1a).proto.message.SetConversationTTLResponse\x12i\n" +
+	"\x12GetConversationTTL\x12(.proto.message.GetConversationTTLRequest\x1a).proto.message.GetConversationTTLResponse\x12~\n" +
+	"\x19GetGroupMessageReadStatus\x12/.proto.message.GetGroupMessageReadStatusRequest\x1a0.proto.message.GetGroupMessageReadStatusResponse\x12`\n" +
+	"\x0fForwardMessages\x12%.proto.message.ForwardMessagesRequest\x1a&.proto.message.ForwardMessagesResponse\x12Q\n" +
+	"\n" +
+	"PinMessage\x12 .proto.message.PinMessageRequest\x1a!.proto.message.PinMessageResponse\x12W\n" +
+	"\fUnpinMessage\x12\".proto.message.UnpinMessageRequest\x1a#.proto.message.UnpinMessageResponse\x12i\n" +
+	"\x12ListPinnedMessages\x12(.proto.message.ListPinnedMessagesRequest\x1a).proto.message.ListPinnedMessagesResponseB\x1aZ\x18ChatIM/api/proto/messageb\x06proto3"
 
 var (
 	file_message_proto_rawDescOnce sync.Once
@@ -1834,71 +5918,194 @@ func file_message_proto_rawDescGZIP() []byte {
 	return file_message_proto_rawDescData
 }
 
-var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 26)
+var file_message_proto_msgTypes = make([]protoimpl.MessageInfo, 78)
 var file_message_proto_goTypes = []any{
-	(*Message)(nil),                          // 0: proto.message.Message
-	(*GroupMessage)(nil),                     // 1: proto.message.GroupMessage
-	(*SendMessageRequest)(nil),               // 2: proto.message.SendMessageRequest
-	(*SendMessageResponse)(nil),              // 3: proto.message.SendMessageResponse
-	(*SendGroupMessageRequest)(nil),          // 4: proto.message.SendGroupMessageRequest
-	(*SendGroupMessageResponse)(nil),         // 5: proto.message.SendGroupMessageResponse
-	(*ConversationMessages)(nil),             // 6: proto.message.ConversationMessages
-	(*UnifiedMessage)(nil),                   // 7: proto.message.UnifiedMessage
-	(*PullMessagesRequest)(nil),              // 8: proto.message.PullMessagesRequest
-	(*PullMessagesResponse)(nil),             // 9: proto.message.PullMessagesResponse
-	(*GetUnreadCountRequest)(nil),            // 10: proto.message.GetUnreadCountRequest
-	(*GetUnreadCountResponse)(nil),           // 11: proto.message.GetUnreadCountResponse
-	(*PullUnreadMessagesRequest)(nil),        // 12: proto.message.PullUnreadMessagesRequest
-	(*PullUnreadMessagesResponse)(nil),       // 13: proto.message.PullUnreadMessagesResponse
-	(*PullAllUnreadOnLoginRequest)(nil),      // 14: proto.message.PullAllUnreadOnLoginRequest
-	(*GroupUnreadInfo)(nil),                  // 15: proto.message.GroupUnreadInfo
-	(*PullAllUnreadOnLoginResponse)(nil),     // 16: proto.message.PullAllUnreadOnLoginResponse
-	(*MarkPrivateMessageAsReadRequest)(nil),  // 17: proto.message.MarkPrivateMessageAsReadRequest
-	(*MarkPrivateMessageAsReadResponse)(nil), // 18: proto.message.MarkPrivateMessageAsReadResponse
-	(*MarkGroupMessageAsReadRequest)(nil),    // 19: proto.message.MarkGroupMessageAsReadRequest
-	(*MarkGroupMessageAsReadResponse)(nil),   // 20: proto.message.MarkGroupMessageAsReadResponse
-	(*PullGroupMessagesRequest)(nil),         // 21: proto.message.PullGroupMessagesRequest
-	(*PullGroupMessagesResponse)(nil),        // 22: proto.message.PullGroupMessagesResponse
-	(*UpdateLastSeenCursorRequest)(nil),      // 23: proto.message.UpdateLastSeenCursorRequest
-	(*UpdateLastSeenCursorResponse)(nil),     // 24: proto.message.UpdateLastSeenCursorResponse
-	nil,                                      // 25: proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry
+	(*Message)(nil),                           // 0: proto.message.Message
+	(*GroupMessage)(nil),                      // 1: proto.message.GroupMessage
+	(*ImagePayload)(nil),                      // 2: proto.message.ImagePayload
+	(*FilePayload)(nil),                       // 3: proto.message.FilePayload
+	(*VoicePayload)(nil),                      // 4: proto.message.VoicePayload
+	(*LocationPayload)(nil),                   // 5: proto.message.LocationPayload
+	(*ContactCardPayload)(nil),                // 6: proto.message.ContactCardPayload
+	(*ChatRecordItem)(nil),                    // 7: proto.message.ChatRecordItem
+	(*ChatRecordPayload)(nil),                 // 8: proto.message.ChatRecordPayload
+	(*ForwardInfo)(nil),                       // 9: proto.message.ForwardInfo
+	(*MessagePayload)(nil),                    // 10: proto.message.MessagePayload
+	(*ReplySnapshot)(nil),                     // 11: proto.message.ReplySnapshot
+	(*SendMessageRequest)(nil),                // 12: proto.message.SendMessageRequest
+	(*SendMessageResponse)(nil),               // 13: proto.message.SendMessageResponse
+	(*SendGroupMessageRequest)(nil),           // 14: proto.message.SendGroupMessageRequest
+	(*SendGroupMessageResponse)(nil),          // 15: proto.message.SendGroupMessageResponse
+	(*ScheduledMessage)(nil),                  // 16: proto.message.ScheduledMessage
+	(*ListScheduledMessagesRequest)(nil),      // 17: proto.message.ListScheduledMessagesRequest
+	(*ListScheduledMessagesResponse)(nil),     // 18: proto.message.ListScheduledMessagesResponse
+	(*UpdateScheduledMessageRequest)(nil),     // 19: proto.message.UpdateScheduledMessageRequest
+	(*UpdateScheduledMessageResponse)(nil),    // 20: proto.message.UpdateScheduledMessageResponse
+	(*CancelScheduledMessageRequest)(nil),     // 21: proto.message.CancelScheduledMessageRequest
+	(*CancelScheduledMessageResponse)(nil),    // 22: proto.message.CancelScheduledMessageResponse
+	(*GetGroupMessageReadStatusRequest)(nil),  // 23: proto.message.GetGroupMessageReadStatusRequest
+	(*GroupReadMember)(nil),                   // 24: proto.message.GroupReadMember
+	(*GetGroupMessageReadStatusResponse)(nil), // 25: proto.message.GetGroupMessageReadStatusResponse
+	(*ForwardMessagesRequest)(nil),            // 26: proto.message.ForwardMessagesRequest
+	(*ForwardResult)(nil),                     // 27: proto.message.ForwardResult
+	(*ForwardMessagesResponse)(nil),           // 28: proto.message.ForwardMessagesResponse
+	(*PinnedMessage)(nil),                     // 29: proto.message.PinnedMessage
+	(*PinMessageRequest)(nil),                 // 30: proto.message.PinMessageRequest
+	(*PinMessageResponse)(nil),                // 31: proto.message.PinMessageResponse
+	(*UnpinMessageRequest)(nil),               // 32: proto.message.UnpinMessageRequest
+	(*UnpinMessageResponse)(nil),              // 33: proto.message.UnpinMessageResponse
+	(*ListPinnedMessagesRequest)(nil),         // 34: proto.message.ListPinnedMessagesRequest
+	(*ListPinnedMessagesResponse)(nil),        // 35: proto.message.ListPinnedMessagesResponse
+	(*ConversationTTL)(nil),                   // 36: proto.message.ConversationTTL
+	(*SetConversationTTLRequest)(nil),         // 37: proto.message.SetConversationTTLRequest
+	(*SetConversationTTLResponse)(nil),        // 38: proto.message.SetConversationTTLResponse
+	(*GetConversationTTLRequest)(nil),         // 39: proto.message.GetConversationTTLRequest
+	(*GetConversationTTLResponse)(nil),        // 40: proto.message.GetConversationTTLResponse
+	(*ConversationMessages)(nil),              // 41: proto.message.ConversationMessages
+	(*ReadMarker)(nil),                        // 42: proto.message.ReadMarker
+	(*DeliveryMarker)(nil),                    // 43: proto.message.DeliveryMarker
+	(*UnifiedMessage)(nil),                    // 44: proto.message.UnifiedMessage
+	(*PullMessagesRequest)(nil),               // 45: proto.message.PullMessagesRequest
+	(*PullMessagesResponse)(nil),              // 46: proto.message.PullMessagesResponse
+	(*PullHistoryRequest)(nil),                // 47: proto.message.PullHistoryRequest
+	(*PullHistoryResponse)(nil),               // 48: proto.message.PullHistoryResponse
+	(*SearchMessagesRequest)(nil),             // 49: proto.message.SearchMessagesRequest
+	(*HighlightRange)(nil),                    // 50: proto.message.HighlightRange
+	(*MessageSearchResult)(nil),               // 51: proto.message.MessageSearchResult
+	(*SearchMessagesResponse)(nil),            // 52: proto.message.SearchMessagesResponse
+	(*GetUnreadCountRequest)(nil),             // 53: proto.message.GetUnreadCountRequest
+	(*GetUnreadCountResponse)(nil),            // 54: proto.message.GetUnreadCountResponse
+	(*PullUnreadMessagesRequest)(nil),         // 55: proto.message.PullUnreadMessagesRequest
+	(*PullUnreadMessagesResponse)(nil),        // 56: proto.message.PullUnreadMessagesResponse
+	(*PullAllUnreadOnLoginRequest)(nil),       // 57: proto.message.PullAllUnreadOnLoginRequest
+	(*GroupUnreadInfo)(nil),                   // 58: proto.message.GroupUnreadInfo
+	(*PullAllUnreadOnLoginResponse)(nil),      // 59: proto.message.PullAllUnreadOnLoginResponse
+	(*MarkPrivateMessageAsReadRequest)(nil),   // 60: proto.message.MarkPrivateMessageAsReadRequest
+	(*MarkPrivateMessageAsReadResponse)(nil),  // 61: proto.message.MarkPrivateMessageAsReadResponse
+	(*MarkGroupMessageAsReadRequest)(nil),     // 62: proto.message.MarkGroupMessageAsReadRequest
+	(*MarkGroupMessageAsReadResponse)(nil),    // 63: proto.message.MarkGroupMessageAsReadResponse
+	(*PullGroupMessagesRequest)(nil),          // 64: proto.message.PullGroupMessagesRequest
+	(*PullGroupMessagesResponse)(nil),         // 65: proto.message.PullGroupMessagesResponse
+	(*UpdateLastSeenCursorRequest)(nil),       // 66: proto.message.UpdateLastSeenCursorRequest
+	(*UpdateLastSeenCursorResponse)(nil),      // 67: proto.message.UpdateLastSeenCursorResponse
+	(*RecallMessageRequest)(nil),              // 68: proto.message.RecallMessageRequest
+	(*RecallMessageResponse)(nil),             // 69: proto.message.RecallMessageResponse
+	(*EditMessageRequest)(nil),                // 70: proto.message.EditMessageRequest
+	(*EditMessageResponse)(nil),               // 71: proto.message.EditMessageResponse
+	(*Reaction)(nil),                          // 72: proto.message.Reaction
+	(*AddReactionRequest)(nil),                // 73: proto.message.AddReactionRequest
+	(*AddReactionResponse)(nil),               // 74: proto.message.AddReactionResponse
+	(*RemoveReactionRequest)(nil),             // 75: proto.message.RemoveReactionRequest
+	(*RemoveReactionResponse)(nil),            // 76: proto.message.RemoveReactionResponse
+	nil,                                       // 77: proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry
 }
 var file_message_proto_depIdxs = []int32{
-	0,  // 0: proto.message.SendMessageResponse.msg:type_name -> proto.message.Message
-	1,  // 1: proto.message.SendGroupMessageResponse.msg:type_name -> proto.message.GroupMessage
-	7,  // 2: proto.message.ConversationMessages.messages:type_name -> proto.message.UnifiedMessage
-	6,  // 3: proto.message.PullMessagesResponse.conversations:type_name -> proto.message.ConversationMessages
-	0,  // 4: proto.message.PullUnreadMessagesResponse.msgs:type_name -> proto.message.Message
-	0,  // 5: proto.message.GroupUnreadInfo.messages:type_name -> proto.message.Message
-	0,  // 6: proto.message.PullAllUnreadOnLoginResponse.private_messages:type_name -> proto.message.Message
-	25, // 7: proto.message.PullAllUnreadOnLoginResponse.group_messages:type_name -> proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry
-	1,  // 8: proto.message.PullGroupMessagesResponse.messages:type_name -> proto.message.GroupMessage
-	15, // 9: proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry.value:type_name -> proto.message.GroupUnreadInfo
-	2,  // 10: proto.message.MessageService.SendMessage:input_type -> proto.message.SendMessageRequest
-	4,  // 11: proto.message.MessageService.SendGroupMessage:input_type -> proto.message.SendGroupMessageRequest
-	8,  // 12: proto.message.MessageService.PullMessages:input_type -> proto.message.PullMessagesRequest
-	10, // 13: proto.message.MessageService.GetUnreadCount:input_type -> proto.message.GetUnreadCountRequest
-	23, // 14: proto.message.MessageService.UpdateLastSeenCursor:input_type -> proto.message.UpdateLastSeenCursorRequest
-	12, // 15: proto.message.MessageService.PullUnreadMessages:input_type -> proto.message.PullUnreadMessagesRequest
-	14, // 16: proto.message.MessageService.PullAllUnreadOnLogin:input_type -> proto.message.PullAllUnreadOnLoginRequest
-	17, // 17: proto.message.MessageService.MarkPrivateMessageAsRead:input_type -> proto.message.MarkPrivateMessageAsReadRequest
-	19, // 18: proto.message.MessageService.MarkGroupMessageAsRead:input_type -> proto.message.MarkGroupMessageAsReadRequest
-	21, // 19: proto.message.MessageService.PullGroupMessages:input_type -> proto.message.PullGroupMessagesRequest
-	3,  // 20: proto.message.MessageService.SendMessage:output_type -> proto.message.SendMessageResponse
-	5,  // 21: proto.message.MessageService.SendGroupMessage:output_type -> proto.message.SendGroupMessageResponse
-	9,  // 22: proto.message.MessageService.PullMessages:output_type -> proto.message.PullMessagesResponse
-	11, // 23: proto.message.MessageService.GetUnreadCount:output_type -> proto.message.GetUnreadCountResponse
-	24, // 24: proto.message.MessageService.UpdateLastSeenCursor:output_type -> proto.message.UpdateLastSeenCursorResponse
-	13, // 25: proto.message.MessageService.PullUnreadMessages:output_type -> proto.message.PullUnreadMessagesResponse
-	16, // 26: proto.message.MessageService.PullAllUnreadOnLogin:output_type -> proto.message.PullAllUnreadOnLoginResponse
-	18, // 27: proto.message.MessageService.MarkPrivateMessageAsRead:output_type -> proto.message.MarkPrivateMessageAsReadResponse
-	20, // 28: proto.message.MessageService.MarkGroupMessageAsRead:output_type -> proto.message.MarkGroupMessageAsReadResponse
-	22, // 29: proto.message.MessageService.PullGroupMessages:output_type -> proto.message.PullGroupMessagesResponse
-	20, // [20:30] is the sub-list for method output_type
-	10, // [10:20] is the sub-list for method input_type
-	10, // [10:10] is the sub-list for extension type_name
-	10, // [10:10] is the sub-list for extension extendee
-	0,  // [0:10] is the sub-list for field type_name
+	10, // 0: proto.message.Message.payload:type_name -> proto.message.MessagePayload
+	11, // 1: proto.message.Message.reply_to:type_name -> proto.message.ReplySnapshot
+	10, // 2: proto.message.GroupMessage.payload:type_name -> proto.message.MessagePayload
+	11, // 3: proto.message.GroupMessage.reply_to:type_name -> proto.message.ReplySnapshot
+	10, // 4: proto.message.ChatRecordItem.payload:type_name -> proto.message.MessagePayload
+	7,  // 5: proto.message.ChatRecordPayload.items:type_name -> proto.message.ChatRecordItem
+	2,  // 6: proto.message.MessagePayload.image:type_name -> proto.message.ImagePayload
+	3,  // 7: proto.message.MessagePayload.file:type_name -> proto.message.FilePayload
+	4,  // 8: proto.message.MessagePayload.voice:type_name -> proto.message.VoicePayload
+	5,  // 9: proto.message.MessagePayload.location:type_name -> proto.message.LocationPayload
+	6,  // 10: proto.message.MessagePayload.contact:type_name -> proto.message.ContactCardPayload
+	8,  // 11: proto.message.MessagePayload.chat_record:type_name -> proto.message.ChatRecordPayload
+	9,  // 12: proto.message.MessagePayload.forward:type_name -> proto.message.ForwardInfo
+	10, // 13: proto.message.SendMessageRequest.payload:type_name -> proto.message.MessagePayload
+	0,  // 14: proto.message.SendMessageResponse.msg:type_name -> proto.message.Message
+	16, // 15: proto.message.SendMessageResponse.scheduled:type_name -> proto.message.ScheduledMessage
+	10, // 16: proto.message.SendGroupMessageRequest.payload:type_name -> proto.message.MessagePayload
+	1,  // 17: proto.message.SendGroupMessageResponse.msg:type_name -> proto.message.GroupMessage
+	16, // 18: proto.message.SendGroupMessageResponse.scheduled:type_name -> proto.message.ScheduledMessage
+	10, // 19: proto.message.ScheduledMessage.payload:type_name -> proto.message.MessagePayload
+	16, // 20: proto.message.ListScheduledMessagesResponse.messages:type_name -> proto.message.ScheduledMessage
+	10, // 21: proto.message.UpdateScheduledMessageRequest.payload:type_name -> proto.message.MessagePayload
+	16, // 22: proto.message.UpdateScheduledMessageResponse.scheduled:type_name -> proto.message.ScheduledMessage
+	24, // 23: proto.message.GetGroupMessageReadStatusResponse.read_members:type_name -> proto.message.GroupReadMember
+	24, // 24: proto.message.GetGroupMessageReadStatusResponse.unread_members:type_name -> proto.message.GroupReadMember
+	27, // 25: proto.message.ForwardMessagesResponse.results:type_name -> proto.message.ForwardResult
+	29, // 26: proto.message.PinMessageResponse.pinned:type_name -> proto.message.PinnedMessage
+	29, // 27: proto.message.ListPinnedMessagesResponse.pins:type_name -> proto.message.PinnedMessage
+	36, // 28: proto.message.SetConversationTTLResponse.setting:type_name -> proto.message.ConversationTTL
+	36, // 29: proto.message.GetConversationTTLResponse.setting:type_name -> proto.message.ConversationTTL
+	44, // 30: proto.message.ConversationMessages.messages:type_name -> proto.message.UnifiedMessage
+	42, // 31: proto.message.ConversationMessages.read_up_to:type_name -> proto.message.ReadMarker
+	43, // 32: proto.message.ConversationMessages.delivered_up_to:type_name -> proto.message.DeliveryMarker
+	10, // 33: proto.message.UnifiedMessage.payload:type_name -> proto.message.MessagePayload
+	11, // 34: proto.message.UnifiedMessage.reply_to:type_name -> proto.message.ReplySnapshot
+	72, // 35: proto.message.UnifiedMessage.reactions:type_name -> proto.message.Reaction
+	41, // 36: proto.message.PullMessagesResponse.conversations:type_name -> proto.message.ConversationMessages
+	44, // 37: proto.message.PullHistoryResponse.messages:type_name -> proto.message.UnifiedMessage
+	44, // 38: proto.message.MessageSearchResult.message:type_name -> proto.message.UnifiedMessage
+	50, // 39: proto.message.MessageSearchResult.highlights:type_name -> proto.message.HighlightRange
+	51, // 40: proto.message.SearchMessagesResponse.results:type_name -> proto.message.MessageSearchResult
+	0,  // 41: proto.message.PullUnreadMessagesResponse.msgs:type_name -> proto.message.Message
+	0,  // 42: proto.message.GroupUnreadInfo.messages:type_name -> proto.message.Message
+	0,  // 43: proto.message.PullAllUnreadOnLoginResponse.private_messages:type_name -> proto.message.Message
+	77, // 44: proto.message.PullAllUnreadOnLoginResponse.group_messages:type_name -> proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry
+	1,  // 45: proto.message.PullGroupMessagesResponse.messages:type_name -> proto.message.GroupMessage
+	72, // 46: proto.message.AddReactionResponse.reactions:type_name -> proto.message.Reaction
+	72, // 47: proto.message.RemoveReactionResponse.reactions:type_name -> proto.message.Reaction
+	58, // 48: proto.message.PullAllUnreadOnLoginResponse.GroupMessagesEntry.value:type_name -> proto.message.GroupUnreadInfo
+	12, // 49: proto.message.MessageService.SendMessage:input_type -> proto.message.SendMessageRequest
+	14, // 50: proto.message.MessageService.SendGroupMessage:input_type -> proto.message.SendGroupMessageRequest
+	45, // 51: proto.message.MessageService.PullMessages:input_type -> proto.message.PullMessagesRequest
+	53, // 52: proto.message.MessageService.GetUnreadCount:input_type -> proto.message.GetUnreadCountRequest
+	66, // 53: proto.message.MessageService.UpdateLastSeenCursor:input_type -> proto.message.UpdateLastSeenCursorRequest
+	55, // 54: proto.message.MessageService.PullUnreadMessages:input_type -> proto.message.PullUnreadMessagesRequest
+	57, // 55: proto.message.MessageService.PullAllUnreadOnLogin:input_type -> proto.message.PullAllUnreadOnLoginRequest
+	60, // 56: proto.message.MessageService.MarkPrivateMessageAsRead:input_type -> proto.message.MarkPrivateMessageAsReadRequest
+	62, // 57: proto.message.MessageService.MarkGroupMessageAsRead:input_type -> proto.message.MarkGroupMessageAsReadRequest
+	64, // 58: proto.message.MessageService.PullGroupMessages:input_type -> proto.message.PullGroupMessagesRequest
+	68, // 59: proto.message.MessageService.RecallMessage:input_type -> proto.message.RecallMessageRequest
+	70, // 60: proto.message.MessageService.EditMessage:input_type -> proto.message.EditMessageRequest
+	73, // 61: proto.message.MessageService.AddReaction:input_type -> proto.message.AddReactionRequest
+	75, // 62: proto.message.MessageService.RemoveReaction:input_type -> proto.message.RemoveReactionRequest
+	47, // 63: proto.message.MessageService.PullHistory:input_type -> proto.message.PullHistoryRequest
+	49, // 64: proto.message.MessageService.SearchMessages:input_type -> proto.message.SearchMessagesRequest
+	17, // 65: proto.message.MessageService.ListScheduledMessages:input_type -> proto.message.ListScheduledMessagesRequest
+	19, // 66: proto.message.MessageService.UpdateScheduledMessage:input_type -> proto.message.UpdateScheduledMessageRequest
+	21, // 67: proto.message.MessageService.CancelScheduledMessage:input_type -> proto.message.CancelScheduledMessageRequest
+	37, // 68: proto.message.MessageService.SetConversationTTL:input_type -> proto.message.SetConversationTTLRequest
+	39, // 69: proto.message.MessageService.GetConversationTTL:input_type -> proto.message.GetConversationTTLRequest
+	23, // 70: proto.message.MessageService.GetGroupMessageReadStatus:input_type -> proto.message.GetGroupMessageReadStatusRequest
+	26, // 71: proto.message.MessageService.ForwardMessages:input_type -> proto.message.ForwardMessagesRequest
+	30, // 72: proto.message.MessageService.PinMessage:input_type -> proto.message.PinMessageRequest
+	32, // 73: proto.message.MessageService.UnpinMessage:input_type -> proto.message.UnpinMessageRequest
+	34, // 74: proto.message.MessageService.ListPinnedMessages:input_type -> proto.message.ListPinnedMessagesRequest
+	13, // 75: proto.message.MessageService.SendMessage:output_type -> proto.message.SendMessageResponse
+	15, // 76: proto.message.MessageService.SendGroupMessage:output_type -> proto.message.SendGroupMessageResponse
+	46, // 77: proto.message.MessageService.PullMessages:output_type -> proto.message.PullMessagesResponse
+	54, // 78: proto.message.MessageService.GetUnreadCount:output_type -> proto.message.GetUnreadCountResponse
+	67, // 79: proto.message.MessageService.UpdateLastSeenCursor:output_type -> proto.message.UpdateLastSeenCursorResponse
+	56, // 80: proto.message.MessageService.PullUnreadMessages:output_type -> proto.message.PullUnreadMessagesResponse
+	59, // 81: proto.message.MessageService.PullAllUnreadOnLogin:output_type -> proto.message.PullAllUnreadOnLoginResponse
+	61, // 82: proto.message.MessageService.MarkPrivateMessageAsRead:output_type -> proto.message.MarkPrivateMessageAsReadResponse
+	63, // 83: proto.message.MessageService.MarkGroupMessageAsRead:output_type -> proto.message.MarkGroupMessageAsReadResponse
+	65, // 84: proto.message.MessageService.PullGroupMessages:output_type -> proto.message.PullGroupMessagesResponse
+	69, // 85: proto.message.MessageService.RecallMessage:output_type -> proto.message.RecallMessageResponse
+	71, // 86: proto.message.MessageService.EditMessage:output_type -> proto.message.EditMessageResponse
+	74, // 87: proto.message.MessageService.AddReaction:output_type -> proto.message.AddReactionResponse
+	76, // 88: proto.message.MessageService.RemoveReaction:output_type -> proto.message.RemoveReactionResponse
+	48, // 89: proto.message.MessageService.PullHistory:output_type -> proto.message.PullHistoryResponse
+	52, // 90: proto.message.MessageService.SearchMessages:output_type -> proto.message.SearchMessagesResponse
+	18, // 91: proto.message.MessageService.ListScheduledMessages:output_type -> proto.message.ListScheduledMessagesResponse
+	20, // 92: proto.message.MessageService.UpdateScheduledMessage:output_type -> proto.message.UpdateScheduledMessageResponse
+	22, // 93: proto.message.MessageService.CancelScheduledMessage:output_type -> proto.message.CancelScheduledMessageResponse
+	38, // 94: proto.message.MessageService.SetConversationTTL:output_type -> proto.message.SetConversationTTLResponse
+	40, // 95: proto.message.MessageService.GetConversationTTL:output_type -> proto.message.GetConversationTTLResponse
+	25, // 96: proto.message.MessageService.GetGroupMessageReadStatus:output_type -> proto.message.GetGroupMessageReadStatusResponse
+	28, // 97: proto.message.MessageService.ForwardMessages:output_type -> proto.message.ForwardMessagesResponse
+	31, // 98: proto.message.MessageService.PinMessage:output_type -> proto.message.PinMessageResponse
+	33, // 99: proto.message.MessageService.UnpinMessage:output_type -> proto.message.UnpinMessageResponse
+	35, // 100: proto.message.MessageService.ListPinnedMessages:output_type -> proto.message.ListPinnedMessagesResponse
+	75, // [75:101] is the sub-list for method output_type
+	49, // [49:75] is the sub-list for method input_type
+	49, // [49:49] is the sub-list for extension type_name
+	49, // [49:49] is the sub-list for extension extendee
+	0,  // [0:49] is the sub-list for field type_name
 }
 
 func init() { file_message_proto_init() }
@@ -1912,7 +6119,7 @@ func file_message_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_message_proto_rawDesc), len(file_message_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   26,
+			NumMessages:   78,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
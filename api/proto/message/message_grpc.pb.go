@@ -19,16 +19,32 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MessageService_SendMessage_FullMethodName              = "/proto.message.MessageService/SendMessage"
-	MessageService_SendGroupMessage_FullMethodName         = "/proto.message.MessageService/SendGroupMessage"
-	MessageService_PullMessages_FullMethodName             = "/proto.message.MessageService/PullMessages"
-	MessageService_GetUnreadCount_FullMethodName           = "/proto.message.MessageService/GetUnreadCount"
-	MessageService_UpdateLastSeenCursor_FullMethodName     = "/proto.message.MessageService/UpdateLastSeenCursor"
-	MessageService_PullUnreadMessages_FullMethodName       = "/proto.message.MessageService/PullUnreadMessages"
-	MessageService_PullAllUnreadOnLogin_FullMethodName     = "/proto.message.MessageService/PullAllUnreadOnLogin"
-	MessageService_MarkPrivateMessageAsRead_FullMethodName = "/proto.message.MessageService/MarkPrivateMessageAsRead"
-	MessageService_MarkGroupMessageAsRead_FullMethodName   = "/proto.message.MessageService/MarkGroupMessageAsRead"
-	MessageService_PullGroupMessages_FullMethodName        = "/proto.message.MessageService/PullGroupMessages"
+	MessageService_SendMessage_FullMethodName               = "/proto.message.MessageService/SendMessage"
+	MessageService_SendGroupMessage_FullMethodName          = "/proto.message.MessageService/SendGroupMessage"
+	MessageService_PullMessages_FullMethodName              = "/proto.message.MessageService/PullMessages"
+	MessageService_GetUnreadCount_FullMethodName            = "/proto.message.MessageService/GetUnreadCount"
+	MessageService_UpdateLastSeenCursor_FullMethodName      = "/proto.message.MessageService/UpdateLastSeenCursor"
+	MessageService_PullUnreadMessages_FullMethodName        = "/proto.message.MessageService/PullUnreadMessages"
+	MessageService_PullAllUnreadOnLogin_FullMethodName      = "/proto.message.MessageService/PullAllUnreadOnLogin"
+	MessageService_MarkPrivateMessageAsRead_FullMethodName  = "/proto.message.MessageService/MarkPrivateMessageAsRead"
+	MessageService_MarkGroupMessageAsRead_FullMethodName    = "/proto.message.MessageService/MarkGroupMessageAsRead"
+	MessageService_PullGroupMessages_FullMethodName         = "/proto.message.MessageService/PullGroupMessages"
+	MessageService_RecallMessage_FullMethodName             = "/proto.message.MessageService/RecallMessage"
+	MessageService_EditMessage_FullMethodName               = "/proto.message.MessageService/EditMessage"
+	MessageService_AddReaction_FullMethodName               = "/proto.message.MessageService/AddReaction"
+	MessageService_RemoveReaction_FullMethodName            = "/proto.message.MessageService/RemoveReaction"
+	MessageService_PullHistory_FullMethodName               = "/proto.message.MessageService/PullHistory"
+	MessageService_SearchMessages_FullMethodName            = "/proto.message.MessageService/SearchMessages"
+	MessageService_ListScheduledMessages_FullMethodName     = "/proto.message.MessageService/ListScheduledMessages"
+	MessageService_UpdateScheduledMessage_FullMethodName    = "/proto.message.MessageService/UpdateScheduledMessage"
+	MessageService_CancelScheduledMessage_FullMethodName    = "/proto.message.MessageService/CancelScheduledMessage"
+	MessageService_SetConversationTTL_FullMethodName        = "/proto.message.MessageService/SetConversationTTL"
+	MessageService_GetConversationTTL_FullMethodName        = "/proto.message.MessageService/GetConversationTTL"
+	MessageService_GetGroupMessageReadStatus_FullMethodName = "/proto.message.MessageService/GetGroupMessageReadStatus"
+	MessageService_ForwardMessages_FullMethodName           = "/proto.message.MessageService/ForwardMessages"
+	MessageService_PinMessage_FullMethodName                = "/proto.message.MessageService/PinMessage"
+	MessageService_UnpinMessage_FullMethodName              = "/proto.message.MessageService/UnpinMessage"
+	MessageService_ListPinnedMessages_FullMethodName        = "/proto.message.MessageService/ListPinnedMessages"
 )
 
 // MessageServiceClient is the client API for MessageService service.
@@ -62,6 +78,38 @@ type MessageServiceClient interface {
 	MarkGroupMessageAsRead(ctx context.Context, in *MarkGroupMessageAsReadRequest, opts ...grpc.CallOption) (*MarkGroupMessageAsReadResponse, error)
 	// 拉取群聊消息
 	PullGroupMessages(ctx context.Context, in *PullGroupMessagesRequest, opts ...grpc.CallOption) (*PullGroupMessagesResponse, error)
+	// 撤回一条自己发送的消息（私聊或群聊，需在撤回时限内）
+	RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error)
+	// 编辑一条自己发送的消息（保留编辑历史）
+	EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error)
+	// 对消息添加表情回应
+	AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*AddReactionResponse, error)
+	// 取消对消息的表情回应
+	RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*RemoveReactionResponse, error)
+	// 分页拉取会话历史消息（超出 Stream 保留范围时回退到数据库）
+	PullHistory(ctx context.Context, in *PullHistoryRequest, opts ...grpc.CallOption) (*PullHistoryResponse, error)
+	// 全文搜索当前用户所在会话的历史消息
+	SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error)
+	// 查询自己的定时消息
+	ListScheduledMessages(ctx context.Context, in *ListScheduledMessagesRequest, opts ...grpc.CallOption) (*ListScheduledMessagesResponse, error)
+	// 修改尚未发送的定时消息
+	UpdateScheduledMessage(ctx context.Context, in *UpdateScheduledMessageRequest, opts ...grpc.CallOption) (*UpdateScheduledMessageResponse, error)
+	// 取消尚未发送的定时消息
+	CancelScheduledMessage(ctx context.Context, in *CancelScheduledMessageRequest, opts ...grpc.CallOption) (*CancelScheduledMessageResponse, error)
+	// 设置会话的消息定时销毁时长（私聊双方均可设置，群聊仅群主/管理员）
+	SetConversationTTL(ctx context.Context, in *SetConversationTTLRequest, opts ...grpc.CallOption) (*SetConversationTTLResponse, error)
+	// 查询会话的消息定时销毁设置
+	GetConversationTTL(ctx context.Context, in *GetConversationTTLRequest, opts ...grpc.CallOption) (*GetConversationTTLResponse, error)
+	// 查询群消息的已读/未读成员（仅发送者和群主/管理员可查询）
+	GetGroupMessageReadStatus(ctx context.Context, in *GetGroupMessageReadStatusRequest, opts ...grpc.CallOption) (*GetGroupMessageReadStatusResponse, error)
+	// 转发消息到一个或多个会话（逐条转发或合并为聊天记录）
+	ForwardMessages(ctx context.Context, in *ForwardMessagesRequest, opts ...grpc.CallOption) (*ForwardMessagesResponse, error)
+	// 在会话内置顶一条消息（私聊双方均可，群聊仅群主/管理员）
+	PinMessage(ctx context.Context, in *PinMessageRequest, opts ...grpc.CallOption) (*PinMessageResponse, error)
+	// 取消置顶消息
+	UnpinMessage(ctx context.Context, in *UnpinMessageRequest, opts ...grpc.CallOption) (*UnpinMessageResponse, error)
+	// 查询会话的置顶消息
+	ListPinnedMessages(ctx context.Context, in *ListPinnedMessagesRequest, opts ...grpc.CallOption) (*ListPinnedMessagesResponse, error)
 }
 
 type messageServiceClient struct {
@@ -172,6 +220,166 @@ func (c *messageServiceClient) PullGroupMessages(ctx context.Context, in *PullGr
 	return out, nil
 }
 
+func (c *messageServiceClient) RecallMessage(ctx context.Context, in *RecallMessageRequest, opts ...grpc.CallOption) (*RecallMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RecallMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_RecallMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) EditMessage(ctx context.Context, in *EditMessageRequest, opts ...grpc.CallOption) (*EditMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(EditMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) AddReaction(ctx context.Context, in *AddReactionRequest, opts ...grpc.CallOption) (*AddReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AddReactionResponse)
+	err := c.cc.Invoke(ctx, MessageService_AddReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) RemoveReaction(ctx context.Context, in *RemoveReactionRequest, opts ...grpc.CallOption) (*RemoveReactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RemoveReactionResponse)
+	err := c.cc.Invoke(ctx, MessageService_RemoveReaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) PullHistory(ctx context.Context, in *PullHistoryRequest, opts ...grpc.CallOption) (*PullHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PullHistoryResponse)
+	err := c.cc.Invoke(ctx, MessageService_PullHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) SearchMessages(ctx context.Context, in *SearchMessagesRequest, opts ...grpc.CallOption) (*SearchMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_SearchMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) ListScheduledMessages(ctx context.Context, in *ListScheduledMessagesRequest, opts ...grpc.CallOption) (*ListScheduledMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListScheduledMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_ListScheduledMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) UpdateScheduledMessage(ctx context.Context, in *UpdateScheduledMessageRequest, opts ...grpc.CallOption) (*UpdateScheduledMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateScheduledMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_UpdateScheduledMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) CancelScheduledMessage(ctx context.Context, in *CancelScheduledMessageRequest, opts ...grpc.CallOption) (*CancelScheduledMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelScheduledMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_CancelScheduledMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) SetConversationTTL(ctx context.Context, in *SetConversationTTLRequest, opts ...grpc.CallOption) (*SetConversationTTLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SetConversationTTLResponse)
+	err := c.cc.Invoke(ctx, MessageService_SetConversationTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) GetConversationTTL(ctx context.Context, in *GetConversationTTLRequest, opts ...grpc.CallOption) (*GetConversationTTLResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetConversationTTLResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetConversationTTL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) GetGroupMessageReadStatus(ctx context.Context, in *GetGroupMessageReadStatusRequest, opts ...grpc.CallOption) (*GetGroupMessageReadStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetGroupMessageReadStatusResponse)
+	err := c.cc.Invoke(ctx, MessageService_GetGroupMessageReadStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) ForwardMessages(ctx context.Context, in *ForwardMessagesRequest, opts ...grpc.CallOption) (*ForwardMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ForwardMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_ForwardMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) PinMessage(ctx context.Context, in *PinMessageRequest, opts ...grpc.CallOption) (*PinMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PinMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_PinMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) UnpinMessage(ctx context.Context, in *UnpinMessageRequest, opts ...grpc.CallOption) (*UnpinMessageResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UnpinMessageResponse)
+	err := c.cc.Invoke(ctx, MessageService_UnpinMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messageServiceClient) ListPinnedMessages(ctx context.Context, in *ListPinnedMessagesRequest, opts ...grpc.CallOption) (*ListPinnedMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListPinnedMessagesResponse)
+	err := c.cc.Invoke(ctx, MessageService_ListPinnedMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessageServiceServer is the server API for MessageService service.
 // All implementations must embed UnimplementedMessageServiceServer
 // for forward compatibility.
@@ -203,6 +411,38 @@ type MessageServiceServer interface {
 	MarkGroupMessageAsRead(context.Context, *MarkGroupMessageAsReadRequest) (*MarkGroupMessageAsReadResponse, error)
 	// 拉取群聊消息
 	PullGroupMessages(context.Context, *PullGroupMessagesRequest) (*PullGroupMessagesResponse, error)
+	// 撤回一条自己发送的消息（私聊或群聊，需在撤回时限内）
+	RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error)
+	// 编辑一条自己发送的消息（保留编辑历史）
+	EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error)
+	// 对消息添加表情回应
+	AddReaction(context.Context, *AddReactionRequest) (*AddReactionResponse, error)
+	// 取消对消息的表情回应
+	RemoveReaction(context.Context, *RemoveReactionRequest) (*RemoveReactionResponse, error)
+	// 分页拉取会话历史消息（超出 Stream 保留范围时回退到数据库）
+	PullHistory(context.Context, *PullHistoryRequest) (*PullHistoryResponse, error)
+	// 全文搜索当前用户所在会话的历史消息
+	SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error)
+	// 查询自己的定时消息
+	ListScheduledMessages(context.Context, *ListScheduledMessagesRequest) (*ListScheduledMessagesResponse, error)
+	// 修改尚未发送的定时消息
+	UpdateScheduledMessage(context.Context, *UpdateScheduledMessageRequest) (*UpdateScheduledMessageResponse, error)
+	// 取消尚未发送的定时消息
+	CancelScheduledMessage(context.Context, *CancelScheduledMessageRequest) (*CancelScheduledMessageResponse, error)
+	// 设置会话的消息定时销毁时长（私聊双方均可设置，群聊仅群主/管理员）
+	SetConversationTTL(context.Context, *SetConversationTTLRequest) (*SetConversationTTLResponse, error)
+	// 查询会话的消息定时销毁设置
+	GetConversationTTL(context.Context, *GetConversationTTLRequest) (*GetConversationTTLResponse, error)
+	// 查询群消息的已读/未读成员（仅发送者和群主/管理员可查询）
+	GetGroupMessageReadStatus(context.Context, *GetGroupMessageReadStatusRequest) (*GetGroupMessageReadStatusResponse, error)
+	// 转发消息到一个或多个会话（逐条转发或合并为聊天记录）
+	ForwardMessages(context.Context, *ForwardMessagesRequest) (*ForwardMessagesResponse, error)
+	// 在会话内置顶一条消息（私聊双方均可，群聊仅群主/管理员）
+	PinMessage(context.Context, *PinMessageRequest) (*PinMessageResponse, error)
+	// 取消置顶消息
+	UnpinMessage(context.Context, *UnpinMessageRequest) (*UnpinMessageResponse, error)
+	// 查询会话的置顶消息
+	ListPinnedMessages(context.Context, *ListPinnedMessagesRequest) (*ListPinnedMessagesResponse, error)
 	mustEmbedUnimplementedMessageServiceServer()
 }
 
@@ -243,6 +483,54 @@ func (UnimplementedMessageServiceServer) MarkGroupMessageAsRead(context.Context,
 func (UnimplementedMessageServiceServer) PullGroupMessages(context.Context, *PullGroupMessagesRequest) (*PullGroupMessagesResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method PullGroupMessages not implemented")
 }
+func (UnimplementedMessageServiceServer) RecallMessage(context.Context, *RecallMessageRequest) (*RecallMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RecallMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) EditMessage(context.Context, *EditMessageRequest) (*EditMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method EditMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) AddReaction(context.Context, *AddReactionRequest) (*AddReactionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddReaction not implemented")
+}
+func (UnimplementedMessageServiceServer) RemoveReaction(context.Context, *RemoveReactionRequest) (*RemoveReactionResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveReaction not implemented")
+}
+func (UnimplementedMessageServiceServer) PullHistory(context.Context, *PullHistoryRequest) (*PullHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PullHistory not implemented")
+}
+func (UnimplementedMessageServiceServer) SearchMessages(context.Context, *SearchMessagesRequest) (*SearchMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchMessages not implemented")
+}
+func (UnimplementedMessageServiceServer) ListScheduledMessages(context.Context, *ListScheduledMessagesRequest) (*ListScheduledMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListScheduledMessages not implemented")
+}
+func (UnimplementedMessageServiceServer) UpdateScheduledMessage(context.Context, *UpdateScheduledMessageRequest) (*UpdateScheduledMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateScheduledMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) CancelScheduledMessage(context.Context, *CancelScheduledMessageRequest) (*CancelScheduledMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelScheduledMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) SetConversationTTL(context.Context, *SetConversationTTLRequest) (*SetConversationTTLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SetConversationTTL not implemented")
+}
+func (UnimplementedMessageServiceServer) GetConversationTTL(context.Context, *GetConversationTTLRequest) (*GetConversationTTLResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetConversationTTL not implemented")
+}
+func (UnimplementedMessageServiceServer) GetGroupMessageReadStatus(context.Context, *GetGroupMessageReadStatusRequest) (*GetGroupMessageReadStatusResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetGroupMessageReadStatus not implemented")
+}
+func (UnimplementedMessageServiceServer) ForwardMessages(context.Context, *ForwardMessagesRequest) (*ForwardMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ForwardMessages not implemented")
+}
+func (UnimplementedMessageServiceServer) PinMessage(context.Context, *PinMessageRequest) (*PinMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method PinMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) UnpinMessage(context.Context, *UnpinMessageRequest) (*UnpinMessageResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method UnpinMessage not implemented")
+}
+func (UnimplementedMessageServiceServer) ListPinnedMessages(context.Context, *ListPinnedMessagesRequest) (*ListPinnedMessagesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListPinnedMessages not implemented")
+}
 func (UnimplementedMessageServiceServer) mustEmbedUnimplementedMessageServiceServer() {}
 func (UnimplementedMessageServiceServer) testEmbeddedByValue()                        {}
 
@@ -444,6 +732,294 @@ func _MessageService_PullGroupMessages_Handler(srv interface{}, ctx context.Cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessageService_RecallMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RecallMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).RecallMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_RecallMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).RecallMessage(ctx, req.(*RecallMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EditMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).EditMessage(ctx, req.(*EditMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_AddReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).AddReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_AddReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).AddReaction(ctx, req.(*AddReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_RemoveReaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).RemoveReaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_RemoveReaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).RemoveReaction(ctx, req.(*RemoveReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_PullHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PullHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).PullHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_PullHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).PullHistory(ctx, req.(*PullHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_SearchMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).SearchMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_SearchMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).SearchMessages(ctx, req.(*SearchMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_ListScheduledMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).ListScheduledMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_ListScheduledMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).ListScheduledMessages(ctx, req.(*ListScheduledMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_UpdateScheduledMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateScheduledMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).UpdateScheduledMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_UpdateScheduledMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).UpdateScheduledMessage(ctx, req.(*UpdateScheduledMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_CancelScheduledMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).CancelScheduledMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_CancelScheduledMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).CancelScheduledMessage(ctx, req.(*CancelScheduledMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_SetConversationTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetConversationTTLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).SetConversationTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_SetConversationTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).SetConversationTTL(ctx, req.(*SetConversationTTLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_GetConversationTTL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetConversationTTLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetConversationTTL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetConversationTTL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetConversationTTL(ctx, req.(*GetConversationTTLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_GetGroupMessageReadStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetGroupMessageReadStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).GetGroupMessageReadStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_GetGroupMessageReadStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).GetGroupMessageReadStatus(ctx, req.(*GetGroupMessageReadStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_ForwardMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ForwardMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).ForwardMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_ForwardMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).ForwardMessages(ctx, req.(*ForwardMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_PinMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).PinMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_PinMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).PinMessage(ctx, req.(*PinMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_UnpinMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UnpinMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).UnpinMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_UnpinMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).UnpinMessage(ctx, req.(*UnpinMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessageService_ListPinnedMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPinnedMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessageServiceServer).ListPinnedMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MessageService_ListPinnedMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessageServiceServer).ListPinnedMessages(ctx, req.(*ListPinnedMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MessageService_ServiceDesc is the grpc.ServiceDesc for MessageService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -491,6 +1067,70 @@ var MessageService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "PullGroupMessages",
 			Handler:    _MessageService_PullGroupMessages_Handler,
 		},
+		{
+			MethodName: "RecallMessage",
+			Handler:    _MessageService_RecallMessage_Handler,
+		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _MessageService_EditMessage_Handler,
+		},
+		{
+			MethodName: "AddReaction",
+			Handler:    _MessageService_AddReaction_Handler,
+		},
+		{
+			MethodName: "RemoveReaction",
+			Handler:    _MessageService_RemoveReaction_Handler,
+		},
+		{
+			MethodName: "PullHistory",
+			Handler:    _MessageService_PullHistory_Handler,
+		},
+		{
+			MethodName: "SearchMessages",
+			Handler:    _MessageService_SearchMessages_Handler,
+		},
+		{
+			MethodName: "ListScheduledMessages",
+			Handler:    _MessageService_ListScheduledMessages_Handler,
+		},
+		{
+			MethodName: "UpdateScheduledMessage",
+			Handler:    _MessageService_UpdateScheduledMessage_Handler,
+		},
+		{
+			MethodName: "CancelScheduledMessage",
+			Handler:    _MessageService_CancelScheduledMessage_Handler,
+		},
+		{
+			MethodName: "SetConversationTTL",
+			Handler:    _MessageService_SetConversationTTL_Handler,
+		},
+		{
+			MethodName: "GetConversationTTL",
+			Handler:    _MessageService_GetConversationTTL_Handler,
+		},
+		{
+			MethodName: "GetGroupMessageReadStatus",
+			Handler:    _MessageService_GetGroupMessageReadStatus_Handler,
+		},
+		{
+			MethodName: "ForwardMessages",
+			Handler:    _MessageService_ForwardMessages_Handler,
+		},
+		{
+			MethodName: "PinMessage",
+			Handler:    _MessageService_PinMessage_Handler,
+		},
+		{
+			MethodName: "UnpinMessage",
+			Handler:    _MessageService_UnpinMessage_Handler,
+		},
+		{
+			MethodName: "ListPinnedMessages",
+			Handler:    _MessageService_ListPinnedMessages_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "message.proto",
@@ -1,5 +1,5 @@
 import request from '@/utils/request'
-import type { LoginResponse, ApiResponse, FlatResponse, User, Conversation, Message, Group, GroupMember } from '@/types'
+import type { LoginResponse, ApiResponse, FlatResponse, User, Conversation, Message, MessageType, MessagePayload, Reaction, MessageSearchResult, ScheduledMessage, ConversationTTL, PinnedMessage, GroupMessageReadStatus, ForwardResult, Group, GroupMember } from '@/types'
 
 export const authApi = {
   login(data: any) {
@@ -26,8 +26,8 @@ export const userApi = {
 }
 
 export const messageApi = {
-  sendPrivateMessage(data: { to_user_id: string, content: string }) {
-    return request.post<any, FlatResponse<{ msg: Message }>>('/messages/send', data)
+  sendPrivateMessage(data: { to_user_id: string, content?: string, msg_type?: MessageType, payload?: MessagePayload, reply_to_msg_id?: string, client_msg_id?: string, send_at?: number }) {
+    return request.post<any, FlatResponse<{ msg?: Message, stream_id?: string, duplicate?: boolean, scheduled?: ScheduledMessage }>>('/messages/send', data)
   },
   getConversations() {
     return request.get<any, FlatResponse<{ conversations: Conversation[], total: number }>>('/conversations')
@@ -38,7 +38,41 @@ export const messageApi = {
   getMessages(params: { from_stream_id?: string, limit?: number }) {
     return request.get<any, FlatResponse<{ conversations: Conversation[], total_unread: number }>>('/messages', { params })
   },
-  updateLastSeenCursor(data: { last_seen_stream_id: string, conversation_type?: string, peer_id?: string }) {
+  searchMessages(params: { q: string, conversation_id?: string, from?: string, before?: string, limit?: number }) {
+    return request.get<any, FlatResponse<{ results: MessageSearchResult[], next_before?: string, has_more?: boolean }>>('/search/messages', { params })
+  },
+  getHistory(conversationId: string, params: { before_id?: string, limit?: number }) {
+    return request.get<any, FlatResponse<{ messages: Message[], next_before_id?: string, has_more?: boolean }>>(`/conversations/${conversationId}/messages`, { params })
+  },
+  getScheduledMessages(params: { status?: ScheduledMessage['status'], conversation_id?: string } = {}) {
+    return request.get<any, FlatResponse<{ messages: ScheduledMessage[] }>>('/messages/scheduled', { params })
+  },
+  updateScheduledMessage(scheduledId: string, data: { content?: string, payload?: MessagePayload, send_at?: number }) {
+    return request.put<any, FlatResponse<{ scheduled: ScheduledMessage }>>(`/messages/scheduled/${scheduledId}`, data)
+  },
+  cancelScheduledMessage(scheduledId: string) {
+    return request.delete<any, FlatResponse<{}>>(`/messages/scheduled/${scheduledId}`)
+  },
+  recallMessage(messageId: string) {
+    return request.post<any, FlatResponse<{ recalled_at: number }>>(`/messages/${messageId}/recall`)
+  },
+  editMessage(messageId: string, content: string) {
+    return request.post<any, FlatResponse<{ edited_at: number }>>(`/messages/${messageId}/edit`, { content })
+  },
+  addReaction(messageId: string, emoji: string) {
+    return request.post<any, FlatResponse<{ reactions: Reaction[] }>>(`/messages/${messageId}/reactions`, { emoji })
+  },
+  removeReaction(messageId: string, emoji: string) {
+    return request.delete<any, FlatResponse<{ reactions: Reaction[] }>>(`/messages/${messageId}/reactions`, { data: { emoji } })
+  },
+  getGroupMessageReadStatus(messageId: string) {
+    return request.get<any, FlatResponse<GroupMessageReadStatus>>(`/messages/${messageId}/read-status`)
+  },
+  // 转发消息：single 逐条转发，merged 合并为一条聊天记录（源消息须来自同一会话）
+  forwardMessages(data: { message_ids: string[], target_conversation_ids: string[], mode?: 'single' | 'merged' }) {
+    return request.post<any, FlatResponse<{ results: ForwardResult[] }>>('/messages/forward', data)
+  },
+  updateLastSeenCursor(data: { last_seen_stream_id: string, conversation_type: 'private' | 'group', peer_id: string }) {
     return request.post<any, FlatResponse<{ cursor: string }>>('/messages/cursor', data)
   },
   markPrivateMessageAsRead(messageId: string) {
@@ -50,6 +84,22 @@ export const messageApi = {
   unpinConversation(conversationId: string) {
     return request.delete<any, FlatResponse<{}>>(`/conversations/${conversationId}/pin`)
   },
+  getConversationTTL(conversationId: string) {
+    return request.get<any, FlatResponse<{ setting: ConversationTTL }>>(`/conversations/${conversationId}/ttl`)
+  },
+  setConversationTTL(conversationId: string, ttlSeconds: number) {
+    return request.put<any, FlatResponse<{ setting: ConversationTTL }>>(`/conversations/${conversationId}/ttl`, { ttl_seconds: ttlSeconds })
+  },
+  // 会话内的置顶消息（群聊仅群主/管理员可置顶）
+  getPinnedMessages(conversationId: string) {
+    return request.get<any, FlatResponse<{ pins: PinnedMessage[] }>>(`/conversations/${conversationId}/pins`)
+  },
+  pinMessage(messageId: string) {
+    return request.post<any, FlatResponse<{ pinned: PinnedMessage }>>(`/messages/${messageId}/pin`)
+  },
+  unpinMessage(messageId: string) {
+    return request.delete<any, FlatResponse<{}>>(`/messages/${messageId}/pin`)
+  },
   deleteConversation(conversationId: string) {
     return request.delete<any, FlatResponse<{}>>(`/conversations/${conversationId}`)
   }
@@ -65,8 +115,8 @@ export const groupApi = {
   getGroupMembers(groupId: string) {
     return request.get<any, FlatResponse<{ members: GroupMember[], total: number }>>(`/groups/${groupId}/members`)
   },
-  sendGroupMessage(data: { group_id: string, content: string }) {
-    return request.post<any, FlatResponse<{ msg: Message }>>('/groups/messages', data)
+  sendGroupMessage(data: { group_id: string, content?: string, msg_type?: MessageType, payload?: MessagePayload, reply_to_msg_id?: string, mention_user_ids?: string[], mention_all?: boolean, client_msg_id?: string, send_at?: number }) {
+    return request.post<any, FlatResponse<{ msg?: Message, stream_id?: string, duplicate?: boolean, scheduled?: ScheduledMessage }>>('/groups/messages', data)
   },
   joinGroup(groupId: string, message: string) {
     return request.post<any, FlatResponse<{}>>('/groups/join-requests', { group_id: groupId, message })
@@ -1,9 +1,18 @@
 import { defineStore } from 'pinia'
 import { ref, watch } from 'vue'
-import type { Conversation, Message, User, Group } from '@/types'
+import type { Conversation, Message, User, Group, ReadMarker, DeliveryMarker, PinnedMessage } from '@/types'
 import { messageApi, userApi } from '@/api'
 import { useUserStore } from './user'
 
+// 按数值比较 Redis Stream ID（毫秒时间戳-序号），字符串比较在位数不同时会出错
+function compareStreamId(a: string, b: string): number {
+  const [ams = 0n, aseq = 0n] = a.split('-').map(v => BigInt(v || 0))
+  const [bms = 0n, bseq = 0n] = b.split('-').map(v => BigInt(v || 0))
+  if (ams !== bms) return ams < bms ? -1 : 1
+  if (aseq !== bseq) return aseq < bseq ? -1 : 1
+  return 0
+}
+
 export const useChatStore = defineStore('chat', () => {
   const conversations = ref<Conversation[]>([])
   const currentConversation = ref<Conversation | null>(null)
@@ -11,6 +20,15 @@ export const useChatStore = defineStore('chat', () => {
   const unreadCount = ref(0)
   const lastStreamId = ref<string>('0-0') // 存储最后的 stream_id
   const userCache = ref<Record<string, { username: string, avatar?: string }>>({})
+  // 私聊会话中对方的已读位置（key 为会话ID）
+  const readReceipts = ref<Record<string, ReadMarker>>({})
+  // 私聊会话中对方设备的送达位置（key 为会话ID）
+  const deliveryReceipts = ref<Record<string, DeliveryMarker>>({})
+  // 会话内的置顶消息（key 为会话ID，按置顶时间倒序）
+  const pinnedMessages = ref<Record<string, PinnedMessage[]>>({})
+  // 正在输入的用户：会话ID -> 用户ID -> 提示过期时间（毫秒）
+  const typingUsers = ref<Record<string, Record<string, number>>>({})
+  const typingTimers: Record<string, ReturnType<typeof setTimeout>> = {}
 
   // Persistence
   const STORAGE_KEY_MESSAGES = 'chatim_messages'
@@ -166,13 +184,13 @@ export const useChatStore = defineStore('chat', () => {
     console.log('Handling new message:', msg, 'Current User:', userStore.currentUser)
     
     const currentUserId = userStore.currentUser?.id || userStore.currentUser?.user_id
+    const fromMe = msg.from_user_id === currentUserId
     
     // Determine conversation ID based on message type
     let conversationId = ''
     let peerId = ''
     if (msg.type === 'private') {
-        const isMe = msg.from_user_id === currentUserId
-        peerId = isMe ? msg.to_user_id! : msg.from_user_id
+        peerId = fromMe ? msg.to_user_id! : msg.from_user_id
         conversationId = `private:${peerId}`
     } else {
         // group message
@@ -182,6 +200,11 @@ export const useChatStore = defineStore('chat', () => {
     
     console.log('Derived Conversation ID:', conversationId)
 
+    // 对方的消息已到达，清除其输入提示
+    if (typingUsers.value[conversationId]?.[msg.from_user_id]) {
+      setTyping(conversationId, msg.from_user_id, 0)
+    }
+
     if (!messages.value[conversationId]) {
       messages.value[conversationId] = []
     }
@@ -235,7 +258,8 @@ export const useChatStore = defineStore('chat', () => {
       conv.last_message_time = msg.created_at
       
       // Only increment unread count if user is NOT in this conversation
-      if (currentConversation.value?.conversation_id !== conversationId) {
+      // 自己在其他设备上发送的消息（多设备同步）不计入未读
+      if (!fromMe && currentConversation.value?.conversation_id !== conversationId) {
         conv.unread_count = (conv.unread_count || 0) + 1
       }
       
@@ -259,7 +283,7 @@ export const useChatStore = defineStore('chat', () => {
         peer_id: peerId,
         peer_name: msg.type === 'group' ? (msg.from_user_name || 'Unknown') : peerName,
         peer_avatar: peerAvatar,
-        unread_count: currentConversation.value?.conversation_id === conversationId ? 0 : 1,
+        unread_count: fromMe || currentConversation.value?.conversation_id === conversationId ? 0 : 1,
         last_message_time: msg.created_at,
         messages: [],
         last_message: msg.content
@@ -271,6 +295,173 @@ export const useChatStore = defineStore('chat', () => {
     unreadCount.value = conversations.value.reduce((sum, c) => sum + (c.unread_count || 0), 0)
   }
 
+  // 判断我在私聊中发送的消息是否已被对方读取
+  function isReadByPeer(conversationId: string, msg: Message) {
+    const marker = readReceipts.value[conversationId]
+    if (!marker || typeof msg.created_at !== 'number') return false
+    return msg.id === marker.msg_id || msg.created_at <= marker.created_at
+  }
+
+  // 判断我在私聊中发送的消息是否已送达对方设备
+  function isDeliveredToPeer(conversationId: string, msg: Message) {
+    const marker = deliveryReceipts.value[conversationId]
+    if (!marker || typeof msg.created_at !== 'number') return false
+    return msg.id === marker.msg_id || msg.created_at <= marker.created_at
+  }
+
+  function setTyping(conversationId: string, userId: string, expiresIn: number) {
+    const timerKey = `${conversationId}|${userId}`
+    clearTimeout(typingTimers[timerKey])
+    delete typingTimers[timerKey]
+
+    const users = { ...(typingUsers.value[conversationId] || {}) }
+    if (expiresIn > 0) {
+      users[userId] = Date.now() + expiresIn * 1000
+      // 超时未收到新的 typing_start 时自动清除
+      typingTimers[timerKey] = setTimeout(() => setTyping(conversationId, userId, 0), expiresIn * 1000)
+    } else {
+      delete users[userId]
+    }
+    typingUsers.value[conversationId] = users
+  }
+
+  // 会话中正在输入的用户ID列表
+  function typingUserIds(conversationId: string) {
+    return Object.keys(typingUsers.value[conversationId] || {})
+  }
+
+  async function fetchPinnedMessages(conversationId: string) {
+    try {
+      const res = await messageApi.getPinnedMessages(conversationId)
+      pinnedMessages.value[conversationId] = res.pins || []
+    } catch (e) {
+      console.error('Failed to fetch pinned messages', e)
+    }
+  }
+
+  // 处理 WebSocket 推送的非消息类事件
+  function handleEvent(event: any) {
+    switch (event.type) {
+      case 'recall':
+        for (const [conversationId, pins] of Object.entries(pinnedMessages.value)) {
+          pinnedMessages.value[conversationId] = pins.filter(p => p.msg_id !== event.id)
+        }
+        for (const list of Object.values(messages.value)) {
+          const target = list.find(m => m.id === event.id)
+          if (target) {
+            target.is_recalled = true
+            target.content = ''
+          }
+          for (const m of list) {
+            if (m.reply_to?.msg_id === event.id) {
+              m.reply_to.is_recalled = true
+              m.reply_to.preview = ''
+            }
+          }
+        }
+        break
+      case 'edit':
+        for (const list of Object.values(messages.value)) {
+          const target = list.find(m => m.id === event.id)
+          if (target) {
+            target.content = event.content
+            target.is_edited = true
+            target.edited_at = event.edited_at
+          }
+        }
+        break
+      case 'expire': {
+        // 定时销毁：移除到期的消息，并用剩余的最后一条消息刷新会话预览
+        const conversationId = event.conversation_type === 'group' ? `group:${event.group_id}` : `private:${event.peer_id}`
+        const list = messages.value[conversationId]
+        if (list) {
+          messages.value[conversationId] = list.filter(m => m.id !== event.id)
+        }
+        const conv = conversations.value.find(c => c.conversation_id === conversationId)
+        if (conv) {
+          const remaining = messages.value[conversationId] || []
+          conv.last_message = remaining.length > 0 ? remaining[remaining.length - 1].content : ''
+        }
+        break
+      }
+      case 'read_receipt':
+        readReceipts.value[`private:${event.peer_id}`] = {
+          msg_id: event.id,
+          created_at: event.created_at,
+          read_at: event.read_at
+        }
+        break
+      case 'delivered':
+        deliveryReceipts.value[`private:${event.peer_id}`] = {
+          msg_id: event.id,
+          created_at: event.created_at,
+          delivered_at: event.delivered_at
+        }
+        break
+      case 'resync':
+        // 服务端有消息未能送达本设备，补拉
+        syncMessages()
+        break
+      case 'message_pin': {
+        const conversationId = event.conversation_type === 'group' ? `group:${event.group_id}` : `private:${event.peer_id}`
+        const pins = (pinnedMessages.value[conversationId] || []).filter(p => p.msg_id !== event.id)
+        if (event.action === 'pin') {
+          pins.unshift({
+            msg_id: event.id,
+            conversation_id: conversationId,
+            from_user_id: event.from_user_id,
+            msg_type: event.msg_type,
+            content: event.content,
+            created_at: event.created_at,
+            pinned_by: event.pinned_by,
+            pinned_at: event.pinned_at
+          })
+        }
+        pinnedMessages.value[conversationId] = pins
+        break
+      }
+      case 'typing_start':
+      case 'typing_stop': {
+        const conversationId = event.conversation_type === 'group' ? `group:${event.group_id}` : `private:${event.peer_id}`
+        setTyping(conversationId, event.from_user_id, event.type === 'typing_start' ? (event.expires_in || 6) : 0)
+        if (event.type === 'typing_start') {
+          ensureUserInfo(event.from_user_id)
+        }
+        break
+      }
+      case 'group_read': {
+        const list = messages.value[`group:${event.group_id}`] || []
+        const target = list.find(m => m.id === event.id)
+        if (target) {
+          target.read_count = event.read_count
+          target.unread_count = event.unread_count
+        }
+        break
+      }
+      case 'mention': {
+        const conv = conversations.value.find(c => c.conversation_id === `group:${event.group_id}`)
+        if (conv) conv.has_mention = true
+        break
+      }
+      case 'reaction':
+        for (const list of Object.values(messages.value)) {
+          const target = list.find(m => m.id === event.id)
+          if (!target) continue
+          const reactions = target.reactions ?? []
+          const existing = reactions.find(r => r.emoji === event.emoji)
+          if (existing) {
+            existing.count = event.count
+          } else if (event.count > 0) {
+            reactions.push({ emoji: event.emoji, count: event.count })
+          }
+          target.reactions = reactions.filter(r => r.count > 0)
+        }
+        break
+      default:
+        console.log('Unhandled websocket event:', event.type)
+    }
+  }
+
   async function syncMessages() {
     try {
       const res = await messageApi.getMessages({ 
@@ -279,6 +470,12 @@ export const useChatStore = defineStore('chat', () => {
       })
       if (res.conversations) {
         for (const c of res.conversations) {
+          if (c.read_up_to) {
+            readReceipts.value[c.conversation_id] = c.read_up_to
+          }
+          if (c.delivered_up_to) {
+            deliveryReceipts.value[c.conversation_id] = c.delivered_up_to
+          }
           if (c.messages && c.messages.length > 0) {
             const existing = messages.value[c.conversation_id] || []
             const incoming = c.messages
@@ -299,7 +496,7 @@ export const useChatStore = defineStore('chat', () => {
               bucket[keyOf(m)] = m
 
               // 更新最后的 stream_id
-              if (m.stream_id && m.stream_id > lastStreamId.value) {
+              if (m.stream_id && compareStreamId(m.stream_id, lastStreamId.value) > 0) {
                 lastStreamId.value = m.stream_id
                 localStorage.setItem(STORAGE_KEY_LAST_STREAM_ID, m.stream_id)
               }
@@ -316,12 +513,7 @@ export const useChatStore = defineStore('chat', () => {
         // 计算总未读数（完全由前端维护，不使用后端返回值）
         unreadCount.value = conversations.value.reduce((sum, c) => sum + (c.unread_count || 0), 0)
 
-        // 同步完成后，上报最新的 stream_id 给服务端，避免下次重复拉取
-        if (lastStreamId.value && lastStreamId.value !== '0-0') {
-          messageApi.updateLastSeenCursor({
-            last_seen_stream_id: lastStreamId.value
-          }).catch(e => console.error('Failed to update last seen cursor', e))
-        }
+        // 拉取位置由本地 lastStreamId 维护；已读游标按会话在查看消息时上报
       }
     } catch (e) {
       console.error('Failed to sync messages', e)
@@ -375,8 +567,17 @@ export const useChatStore = defineStore('chat', () => {
     messages,
     unreadCount,
     lastStreamId,
+    readReceipts,
+    isReadByPeer,
+    deliveryReceipts,
+    isDeliveredToPeer,
+    userCache,
+    typingUserIds,
+    pinnedMessages,
+    fetchPinnedMessages,
     fetchConversations,
     handleNewMessage,
+    handleEvent,
     syncMessages,
     startConversation,
     enterGroupChat,
@@ -19,6 +19,131 @@ export interface Message {
   read_at?: number
   stream_id?: string
   is_sender?: boolean
+  is_recalled?: boolean
+  is_edited?: boolean
+  edited_at?: number
+  msg_type?: MessageType
+  payload?: MessagePayload
+  reply_to?: ReplySnapshot
+  reactions?: Reaction[]
+  mention_user_ids?: string[]
+  mention_all?: boolean
+  expires_at?: number
+  read_count?: number   // 群消息已读人数（仅自己发送的消息，由 group_read 事件更新）
+  unread_count?: number
+}
+
+export interface GroupReadMember {
+  user_id: string
+  username: string
+}
+
+export interface GroupMessageReadStatus {
+  group_id: string
+  read_count: number
+  unread_count: number
+  read_members: GroupReadMember[]
+  unread_members: GroupReadMember[]
+}
+
+// 会话内的置顶消息
+export interface PinnedMessage {
+  msg_id: string
+  conversation_id: string
+  from_user_id: string
+  msg_type: MessageType
+  content: string
+  created_at: number
+  pinned_by: string
+  pinned_at: number
+}
+
+export interface ConversationTTL {
+  conversation_id: string
+  ttl_seconds: number
+  updated_by?: string
+  updated_at?: number
+}
+
+export interface Reaction {
+  emoji: string
+  count: number
+  reacted_by_me?: boolean
+}
+
+export interface MessageSearchResult {
+  message: Message
+  conversation_id: string
+  snippet: string
+  highlights?: { start: number, length: number }[]
+  prev_msg_id?: string
+  next_msg_id?: string
+}
+
+export interface ScheduledMessage {
+  id: string
+  conversation_type: 'private' | 'group'
+  to_user_id?: string
+  group_id?: string
+  content: string
+  msg_type?: MessageType
+  payload?: MessagePayload
+  reply_to_msg_id?: string
+  mention_user_ids?: string[]
+  mention_all?: boolean
+  send_at: number
+  status: 'pending' | 'sending' | 'sent' | 'canceled' | 'failed'
+  msg_id?: string
+  error?: string
+  created_at: number
+  updated_at: number
+}
+
+export interface ReplySnapshot {
+  msg_id: string
+  from_user_id: string
+  from_user_name?: string
+  msg_type: MessageType
+  preview: string
+  created_at: number
+  is_recalled?: boolean
+}
+
+export type MessageType = 'text' | 'image' | 'file' | 'voice' | 'location' | 'contact' | 'chat_record'
+
+// 合并转发的聊天记录中的一条消息
+export interface ChatRecordItem {
+  msg_id: string
+  from_user_id: string
+  from_user_name: string
+  msg_type: MessageType
+  content: string
+  payload?: MessagePayload
+  created_at: number
+}
+
+// 逐条转发的消息来源
+export interface ForwardInfo {
+  msg_id: string
+  from_user_id: string
+  from_user_name: string
+  created_at: number
+}
+
+export interface ForwardResult {
+  conversation_id: string
+  msg_ids?: string[]
+  error?: string
+}
+
+export interface MessagePayload {
+  image?: { oss_key: string, url?: string, width: number, height: number, size?: number }
+  file?: { oss_key: string, url?: string, file_name: string, size: number, mime_type: string }
+  voice?: { oss_key: string, url?: string, duration: number, size?: number }
+  location?: { latitude: number, longitude: number, name?: string, address?: string }
+  contact?: { user_id: string, nickname?: string, avatar?: string }
+  chat_record?: { title: string, items: ChatRecordItem[] }
+  forward?: ForwardInfo
 }
 
 export interface Conversation {
@@ -34,6 +159,23 @@ export interface Conversation {
   avatar?: string
   last_message?: string
   is_pinned?: boolean
+  has_mention?: boolean
+  read_up_to?: ReadMarker
+  delivered_up_to?: DeliveryMarker
+}
+
+// 私聊中对方已读到的位置：我发送的消息中该条及之前的均已被读取
+export interface ReadMarker {
+  msg_id: string
+  created_at: number
+  read_at?: number
+}
+
+// 私聊中对方设备已收到的位置：我发送的消息中该条及之前的均已送达
+export interface DeliveryMarker {
+  msg_id: string
+  created_at: number
+  delivered_at?: number
 }
 
 export interface Group {
@@ -0,0 +1,16 @@
+const STORAGE_KEY_DEVICE_ID = 'device_id'
+
+// 当前浏览器的设备ID，首次使用时生成并保存在 localStorage 中
+// 服务端据此区分同一用户的多个连接，并在多设备同步时跳过发起请求的设备
+export function getDeviceId(): string {
+  let deviceId = localStorage.getItem(STORAGE_KEY_DEVICE_ID)
+  if (!deviceId) {
+    deviceId = typeof crypto !== 'undefined' && 'randomUUID' in crypto
+      ? crypto.randomUUID()
+      : `${Date.now().toString(36)}-${Math.random().toString(36).slice(2, 10)}`
+    localStorage.setItem(STORAGE_KEY_DEVICE_ID, deviceId)
+  }
+  return deviceId
+}
+
+export const DEVICE_PLATFORM = 'web'
@@ -1,6 +1,7 @@
 import axios from 'axios'
 import { ElMessage } from 'element-plus'
 import router from '@/router'
+import { getDeviceId } from '@/utils/device'
 
 const service = axios.create({
   baseURL: '/api/v1',
@@ -13,6 +14,7 @@ service.interceptors.request.use(
     if (token) {
       config.headers['Authorization'] = `Bearer ${token}`
     }
+    config.headers['X-Device-ID'] = getDeviceId()
     return config
   },
   (error) => {
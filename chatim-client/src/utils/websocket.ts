@@ -1,4 +1,15 @@
 import { useChatStore } from '@/stores/chat'
+import { DEVICE_PLATFORM, getDeviceId } from '@/utils/device'
+
+// /ws 请求帧协议版本，见服务端 internal/websocket/protocol.go
+const PROTOCOL_VERSION = 1
+const REQUEST_TIMEOUT = 10000
+
+interface PendingRequest {
+  resolve: (data: any) => void
+  reject: (error: Error) => void
+  timer: ReturnType<typeof setTimeout>
+}
 
 export class WebSocketManager {
   private ws: WebSocket | null = null
@@ -7,6 +18,8 @@ export class WebSocketManager {
   private maxReconnectAttempts = 5
   private reconnectTimer: any = null
   private token: string | null = null
+  private seq = 0
+  private pending = new Map<number, PendingRequest>()
 
   constructor() {
     const protocol = window.location.protocol === 'https:' ? 'wss:' : 'ws:'
@@ -24,7 +37,7 @@ export class WebSocketManager {
       this.ws.close()
     }
 
-    this.ws = new WebSocket(`${this.url}?token=${token}`)
+    this.ws = new WebSocket(`${this.url}?token=${token}&device_id=${encodeURIComponent(getDeviceId())}&platform=${DEVICE_PLATFORM}`)
 
     this.ws.onopen = () => {
       console.log('WebSocket connected')
@@ -39,8 +52,23 @@ export class WebSocketManager {
       try {
         console.log('WS Received:', event.data)
         const message = JSON.parse(event.data)
+        if (message.op === 'ack') {
+          this.handleAck(message)
+          return
+        }
         const chatStore = useChatStore()
-        chatStore.handleNewMessage(message)
+        if (message.type === 'private' || message.type === 'group') {
+          // 确认收到推送，服务端据此停止重发并向发送者回送达回执
+          // 推送中的 stream_id 只用于确认，拉取位置和已读游标仍分别由 sync 和查看消息时推进
+          if (message.stream_id) {
+            this.send({ v: PROTOCOL_VERSION, op: 'delivered', data: { stream_ids: [message.stream_id] } })
+            delete message.stream_id
+          }
+          chatStore.handleNewMessage(message)
+        } else {
+          // 非消息类事件（撤回等）
+          chatStore.handleEvent(message)
+        }
       } catch (e) {
         console.error('Failed to parse websocket message', e)
       }
@@ -48,6 +76,7 @@ export class WebSocketManager {
 
     this.ws.onclose = () => {
       console.log('WebSocket disconnected')
+      this.rejectPending('WebSocket disconnected')
       this.reconnect()
     }
 
@@ -57,8 +86,66 @@ export class WebSocketManager {
     }
   }
 
+  // 向服务端发送一帧（typing_start / typing_stop、送达确认），未连接时直接丢弃
+  send(frame: Record<string, unknown>) {
+    if (this.ws && this.ws.readyState === WebSocket.OPEN) {
+      this.ws.send(JSON.stringify(frame))
+    }
+  }
+
+  get connected() {
+    return this.ws !== null && this.ws.readyState === WebSocket.OPEN
+  }
+
+  // 通过 WebSocket 发送请求帧（如 send.private / send.group / read.cursor / sync / history），
+  // 服务端调用对应接口后回复 ack，data 与对应 HTTP 接口的响应一致
+  request<T = any>(op: string, data: Record<string, unknown>): Promise<T> {
+    if (!this.connected) {
+      return Promise.reject(new Error('WebSocket is not connected'))
+    }
+    const seq = ++this.seq
+    return new Promise<T>((resolve, reject) => {
+      const timer = setTimeout(() => {
+        this.pending.delete(seq)
+        reject(new Error(`WebSocket request ${op} timed out`))
+      }, REQUEST_TIMEOUT)
+      this.pending.set(seq, { resolve, reject, timer })
+      this.ws!.send(JSON.stringify({ v: PROTOCOL_VERSION, op, seq, data }))
+    })
+  }
+
+  private handleAck(ack: { seq: number, ok: boolean, data?: any, error?: { code: string, message: string } }) {
+    const pending = this.pending.get(ack.seq)
+    if (!pending) return
+    this.pending.delete(ack.seq)
+    clearTimeout(pending.timer)
+    if (ack.ok) {
+      pending.resolve(ack.data || {})
+    } else {
+      pending.reject(new Error(ack.error?.message || ack.error?.code || 'Request failed'))
+    }
+  }
+
+  private rejectPending(reason: string) {
+    for (const pending of this.pending.values()) {
+      clearTimeout(pending.timer)
+      pending.reject(new Error(reason))
+    }
+    this.pending.clear()
+  }
+
+  // 通知会话对方/群成员我正在输入或已停止输入（服务端负责限流）
+  sendTyping(conversationType: 'private' | 'group', peerId: string, typing: boolean) {
+    this.send({
+      type: typing ? 'typing_start' : 'typing_stop',
+      conversation_type: conversationType,
+      ...(conversationType === 'group' ? { group_id: peerId } : { peer_id: peerId })
+    })
+  }
+
   disconnect() {
     this.token = null
+    this.rejectPending('WebSocket disconnected')
     if (this.reconnectTimer) {
       clearTimeout(this.reconnectTimer)
       this.reconnectTimer = null
@@ -44,11 +44,15 @@ func main() {
 	profiling.InitProfiling("6060")
 
 	// 启动 Prometheus Metrics 服务（独立端口）
+	metricsPort := cfg.Server.APIMetricsPort
+	if metricsPort == "" {
+		metricsPort = ":9090"
+	}
 	go func() {
 		metricsRouter := gin.New()
 		metricsRouter.GET("/metrics", gin.WrapH(promhttp.Handler()))
-		logger.Info("📊 Prometheus metrics server started at http://localhost:9090/metrics")
-		if err := metricsRouter.Run(":9090"); err != nil {
+		logger.Info("📊 Prometheus metrics server started at http://localhost" + metricsPort + "/metrics")
+		if err := metricsRouter.Run(metricsPort); err != nil {
 			logger.Error("❌ Failed to start metrics server", zap.Error(err))
 		}
 	}()
@@ -59,6 +63,22 @@ func main() {
 	r.Use(middleware.PrometheusMiddleware())
 
 	hub := websocket.NewHub()
+	// /ws 请求帧（发送消息、标记已读、拉取消息）由 Hub 代替用户调用 MessageService
+	messageAddr := cfg.Server.MessageGRPCAddr
+	if messageAddr == "" {
+		messageAddr = "127.0.0.1" + cfg.Server.MessageGRPCPort
+	}
+	if err := hub.DialMessageService(messageAddr); err != nil {
+		logger.Fatal("Failed to connect WebSocket hub to message service", zap.Error(err))
+	}
+	// 输入状态转发时群成员缓存未命中，由 Hub 通过 GroupService 加载群成员
+	groupAddr := cfg.Server.GroupGRPCAddr
+	if groupAddr == "" {
+		groupAddr = "127.0.0.1" + cfg.Server.GroupGRPCPort
+	}
+	if err := hub.DialGroupService(groupAddr); err != nil {
+		logger.Fatal("Failed to connect WebSocket hub to group service", zap.Error(err))
+	}
 	go hub.Run()
 	go websocket.StartSubscriber(hub)
 
@@ -146,6 +166,17 @@ func main() {
 			// 标记消息为已读
 			protected.POST("/messages/read", userHandler.MarkPrivateMessageAsRead)
 			protected.POST("/groups/:group_id/read", userHandler.MarkGroupMessageAsRead)
+			protected.POST("/messages/forward", userHandler.ForwardMessages)                  // 转发消息
+			protected.POST("/messages/:id/pin", userHandler.PinMessage)                       // 置顶消息
+			protected.DELETE("/messages/:id/pin", userHandler.UnpinMessage)                   // 取消置顶消息
+			protected.POST("/messages/:id/recall", userHandler.RecallMessage)                 // 撤回消息
+			protected.POST("/messages/:id/edit", userHandler.EditMessage)                     // 编辑消息
+			protected.POST("/messages/:id/reactions", userHandler.AddReaction)                // 添加表情回应
+			protected.DELETE("/messages/:id/reactions", userHandler.RemoveReaction)           // 取消表情回应
+			protected.GET("/messages/:id/read-status", userHandler.GetGroupMessageReadStatus) // 群消息已读情况
+			protected.GET("/messages/scheduled", userHandler.ListScheduledMessages)           // 定时消息列表
+			protected.PUT("/messages/scheduled/:id", userHandler.UpdateScheduledMessage)      // 修改定时消息
+			protected.DELETE("/messages/scheduled/:id", userHandler.CancelScheduledMessage)   // 取消定时消息
 			// NOTE: `/messages/unread/pull` and `/unread/all` have been deprecated and removed from routes.
 			// 登录时请改为调用 `/messages` (PullMessage) 并结合 `/messages/unread` (GetUnreadCount)。
 
@@ -172,8 +203,9 @@ func main() {
 			protected.GET("/groups/:group_id/members", userHandler.GetGroupMembers)      // 📌 获取群成员列表
 
 			// ========== 搜索功能路由 ==========
-			protected.GET("/search/users", userHandler.SearchUsers)   // 📌 搜索用户
-			protected.GET("/search/groups", userHandler.SearchGroups) // 📌 搜索群组
+			protected.GET("/search/users", userHandler.SearchUsers)       // 📌 搜索用户
+			protected.GET("/search/groups", userHandler.SearchGroups)     // 📌 搜索群组
+			protected.GET("/search/messages", userHandler.SearchMessages) // 📌 搜索消息
 
 			// ========== 文件上传路由 ==========
 			protected.GET("/upload/signature", userHandler.GetUploadSignature) // 📌 获取OSS上传签名
@@ -190,6 +222,10 @@ func main() {
 			protected.POST("/conversations/:conversation_id/pin", conversationHandler.PinConversation)     // 📌 置顶会话
 			protected.DELETE("/conversations/:conversation_id/pin", conversationHandler.UnpinConversation) // 📌 取消置顶
 			protected.DELETE("/conversations/:conversation_id", conversationHandler.DeleteConversation)    // 📌 删除会话
+			protected.GET("/conversations/:conversation_id/messages", userHandler.PullHistory)             // 📌 分页拉取会话历史消息
+			protected.GET("/conversations/:conversation_id/ttl", userHandler.GetConversationTTL)           // 📌 查询消息定时销毁设置
+			protected.PUT("/conversations/:conversation_id/ttl", userHandler.SetConversationTTL)           // 📌 设置消息定时销毁
+			protected.GET("/conversations/:conversation_id/pins", userHandler.ListPinnedMessages)          // 📌 查询会话置顶消息
 		}
 	}
 	r.GET("/ws", middleware.AuthMiddleware(), hub.HandleWebSocket)
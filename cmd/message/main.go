@@ -4,8 +4,11 @@ import (
 	"ChatIM/pkg/config"
 	"ChatIM/pkg/database"
 	"ChatIM/pkg/logger"
+	"context"
 	"net"
+	"net/http"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
@@ -13,6 +16,8 @@ import (
 
 	pb "ChatIM/api/proto/message"
 	"ChatIM/internal/message_service/handler"
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/internal/message_service/retention"
 )
 
 func main() {
@@ -57,10 +62,38 @@ func main() {
 			zap.Error(err))
 	}
 
+	// 启动消息落库 Worker（消费 Redis 落库队列，批量写入 MySQL）
+	go persister.NewWorker(db, rdb, cfg.Message.Persister).Run(context.Background())
+
+	// 启动用户消息流和读扩散群消息流定期裁剪任务
+	if cfg.Message.Retention.Enabled {
+		go retention.NewJob(rdb, cfg.Message.Retention).Run(context.Background())
+	}
+
+	// 启动 Prometheus Metrics 服务（独立端口，与 API Gateway 的指标端口区分）
+	metricsPort := cfg.Server.MessageMetricsPort
+	if metricsPort == "" {
+		metricsPort = ":9092"
+	}
+	go func() {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		logger.Info("📊 Prometheus metrics server started at http://localhost" + metricsPort + "/metrics")
+		if err := http.ListenAndServe(metricsPort, mux); err != nil {
+			logger.Error("❌ Failed to start metrics server", zap.Error(err))
+		}
+	}()
+
 	// 3. 注册服务
-	pb.RegisterMessageServiceServer(grpcSrv, handler.NewMessageHandler(db, rdb))
+	messageHandler := handler.NewMessageHandler(db, rdb, cfg.Message)
+	pb.RegisterMessageServiceServer(grpcSrv, messageHandler)
 	reflection.Register(grpcSrv)
 
+	// 启动定时消息分发器（到期后按正常流程发送）
+	go messageHandler.RunScheduledDispatcher(context.Background())
+	// 启动定时销毁清理任务（删除到期消息并通知客户端）
+	go messageHandler.RunExpireSweeper(context.Background())
+
 	logger.Info("🚀 Message Service gRPC server started",
 		zap.String("port", cfg.Server.MessageGRPCPort))
 
@@ -63,6 +63,8 @@ func main() {
 
 	// 4. 创建 gRPC 服务
 	userHandler := handler.NewUserHandler(db, rdb)
+	// 将网关发布的上线/下线事件同步到 users 表
+	go userHandler.RunPresenceSync(ctx)
 	grpcSrv := grpc.NewServer()
 	pb.RegisterUserServiceServer(grpcSrv, userHandler)
 
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	grpPb "ChatIM/api/proto/group"
@@ -87,6 +88,7 @@ type ConversationResponse struct {
 	LastMessage     string                 `json:"last_message"`      // 最后一条消息内容
 	LastMessageTime int64                  `json:"last_message_time"` // 毫秒时间戳
 	UnreadCount     int                    `json:"unread_count"`
+	HasMention      bool                   `json:"has_mention"` // 群聊中是否有未读的 @ 我
 	IsPinned        bool                   `json:"is_pinned"`
 	Extra           map[string]interface{} `json:"extra,omitempty"` // 额外信息
 }
@@ -118,10 +120,25 @@ func (h *ConversationHandler) GetConversationList(c *gin.Context) {
 		return
 	}
 
+	// 获取各会话的已读游标（用于计算未读数）
+	cursors, err := h.streamOp.GetConversationCursors(c.Request.Context(), userID)
+	if err != nil {
+		logger.Warn("Failed to get conversation cursors", zap.String("user_id", userID), zap.Error(err))
+	}
+
+	// 获取各群未读的 @ 提及次数
+	mentionCounts, err := h.streamOp.GetMentionCounts(c.Request.Context(), userID)
+	if err != nil {
+		logger.Warn("Failed to get mention counts", zap.String("user_id", userID), zap.Error(err))
+	}
+
 	// 补充会话详细信息
 	var responseList []ConversationResponse
 	for _, conv := range conversations {
-		response := h.enrichConversationInfo(c.Request.Context(), userID, conv)
+		response := h.enrichConversationInfo(c.Request.Context(), userID, conv, cursors.Get(conv.ConversationID))
+		if response.Type == "group" {
+			response.HasMention = mentionCounts[response.PeerID] > 0
+		}
 		responseList = append(responseList, response)
 	}
 
@@ -243,7 +260,8 @@ func (h *ConversationHandler) DeleteConversation(c *gin.Context) {
 }
 
 // enrichConversationInfo 补充会话详细信息（标题、头像、最后消息等）
-func (h *ConversationHandler) enrichConversationInfo(ctx context.Context, userID string, conv stream.ConversationItem) ConversationResponse {
+// cursor 为该会话的已读游标
+func (h *ConversationHandler) enrichConversationInfo(ctx context.Context, userID string, conv stream.ConversationItem, cursor string) ConversationResponse {
 	response := ConversationResponse{
 		ConversationID:  conv.ConversationID,
 		LastMessageTime: conv.LastMessageTime,
@@ -275,7 +293,7 @@ func (h *ConversationHandler) enrichConversationInfo(ctx context.Context, userID
 	response.LastMessage = lastMsg
 
 	// 获取未读数（从 Stream 统计）
-	response.UnreadCount = h.getUnreadCount(ctx, userID, conv.ConversationID)
+	response.UnreadCount = h.getUnreadCount(ctx, userID, conv.ConversationID, cursor)
 
 	return response
 }
@@ -336,7 +354,7 @@ func (h *ConversationHandler) getGroupInfo(ctx context.Context, groupID string)
 
 // getLastMessage 获取最后一条消息内容
 func (h *ConversationHandler) getLastMessage(ctx context.Context, userID, conversationID string) string {
-	streamKey := fmt.Sprintf("stream:private:%s", userID)
+	streamKey := h.conversationStreamKey(ctx, userID, conversationID)
 
 	// 读取最后一条消息
 	messages, err := h.rdb.XRevRangeN(ctx, streamKey, "+", "-", 20).Result()
@@ -344,41 +362,56 @@ func (h *ConversationHandler) getLastMessage(ctx context.Context, userID, conver
 		return ""
 	}
 
-	// 查找该会话的最后一条消息
+	// 查找该会话的最后一条消息（跳过已过期、尚未被清理的消息）
+	now := time.Now().Unix()
 	for _, msg := range messages {
+		if v, ok := msg.Values["expires_at"].(string); ok {
+			if expiresAt, _ := strconv.ParseInt(v, 10, 64); expiresAt > 0 && expiresAt <= now {
+				continue
+			}
+		}
+
+		matched := false
 		if conversationID[:8] == "private:" {
 			// 私聊消息
-			if msg.Values["to_user_id"] == conversationID[8:] || msg.Values["from_user_id"] == conversationID[8:] {
-				if content, ok := msg.Values["content"].(string); ok {
-					return truncateString(content, 50)
-				}
-			}
+			matched = msg.Values["to_user_id"] == conversationID[8:] || msg.Values["from_user_id"] == conversationID[8:]
 		} else if conversationID[:6] == "group:" {
 			// 群聊消息
-			if msg.Values["group_id"] == conversationID[6:] {
-				if content, ok := msg.Values["content"].(string); ok {
-					return truncateString(content, 50)
-				}
+			matched = msg.Values["group_id"] == conversationID[6:]
+		}
+		if !matched {
+			continue
+		}
+
+		if msgID, ok := msg.Values["id"].(string); ok {
+			if recalled, _ := h.streamOp.IsMessageRecalled(ctx, msgID); recalled {
+				return "[消息已撤回]"
+			}
+			if edits, _ := h.streamOp.GetMessageEdits(ctx, []string{msgID}); len(edits) > 0 {
+				return truncateString(edits[msgID].Content, 50)
 			}
 		}
+		if content, ok := msg.Values["content"].(string); ok {
+			return truncateString(content, 50)
+		}
 	}
 
 	return ""
 }
 
-// getUnreadCount 获取未读消息数
-func (h *ConversationHandler) getUnreadCount(ctx context.Context, userID, conversationID string) int {
-	streamKey := fmt.Sprintf("stream:private:%s", userID)
+// getUnreadCount 获取未读消息数（会话已读游标之后、由他人发送的消息，最多统计最近 100 条）
+func (h *ConversationHandler) getUnreadCount(ctx context.Context, userID, conversationID, cursor string) int {
+	streamKey := h.conversationStreamKey(ctx, userID, conversationID)
 
-	messages, err := h.rdb.XRevRangeN(ctx, streamKey, "+", "-", 100).Result()
+	// 只读取游标之后的消息
+	messages, err := h.rdb.XRevRangeN(ctx, streamKey, "+", "("+cursor, 100).Result()
 	if err != nil {
 		return 0
 	}
 
 	count := 0
 	for _, msg := range messages {
-		isRead := msg.Values["is_read"] == "true"
-		if isRead {
+		if msg.Values["from_user_id"] == userID {
 			continue
 		}
 
@@ -397,6 +430,16 @@ func (h *ConversationHandler) getUnreadCount(ctx context.Context, userID, conver
 	return count
 }
 
+// conversationStreamKey 返回读取会话消息使用的 Stream：读扩散的超大群为 stream:group:{group_id}，其余为用户个人流
+func (h *ConversationHandler) conversationStreamKey(ctx context.Context, userID, conversationID string) string {
+	if groupID, ok := strings.CutPrefix(conversationID, "group:"); ok {
+		if readFanout, _ := h.streamOp.IsReadFanoutGroup(ctx, groupID); readFanout {
+			return stream.GroupStreamKey(groupID)
+		}
+	}
+	return fmt.Sprintf("stream:private:%s", userID)
+}
+
 // truncateString 截断字符串
 func truncateString(s string, maxLen int) string {
 	runes := []rune(s)
@@ -31,6 +31,14 @@ func withAuthMetadata(c *gin.Context) context.Context {
 	return metadata.NewOutgoingContext(c.Request.Context(), md)
 }
 
+// withDeviceMetadata forwards the X-Device-ID header so that multi-device sync can skip the originating device.
+func withDeviceMetadata(c *gin.Context, md metadata.MD) metadata.MD {
+	if deviceID := c.GetHeader("X-Device-ID"); deviceID != "" {
+		md.Set("x-device-id", deviceID)
+	}
+	return md
+}
+
 type UserGatewayHandler struct {
 	userClient       pb.UserServiceClient
 	messageClient    msgPb.MessageServiceClient
@@ -352,9 +360,10 @@ func (h *UserGatewayHandler) CheckUserOnline(c *gin.Context) {
 	}
 
 	c.JSON(statusCode, gin.H{
-		"code":      res.Code,
-		"message":   res.Message,
-		"is_online": res.IsOnline,
+		"code":         res.Code,
+		"message":      res.Message,
+		"is_online":    res.IsOnline,
+		"last_seen_at": res.LastSeenAt,
 	})
 }
 
@@ -375,7 +384,7 @@ func (h *UserGatewayHandler) SendMessage(c *gin.Context) {
 
 	// 👇 2. 创建 gRPC metadata，key 必须是 "authorization"
 	//    value 就是完整的 Token 字符串
-	md := metadata.New(map[string]string{"authorization": authHeader})
+	md := withDeviceMetadata(c, metadata.New(map[string]string{"authorization": authHeader}))
 	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
 
 	// 👇 3. 使用这个带 metadata 的新上下文进行 gRPC 调用
@@ -407,7 +416,7 @@ func (h *UserGatewayHandler) SendGroupMessage(c *gin.Context) {
 		return
 	}
 
-	md := metadata.New(map[string]string{"authorization": authHeader})
+	md := withDeviceMetadata(c, metadata.New(map[string]string{"authorization": authHeader}))
 	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
 
 	res, err := h.messageClient.SendGroupMessage(ctx, &req)
@@ -472,6 +481,322 @@ func (h *UserGatewayHandler) PullMessage(c *gin.Context) {
 	c.JSON(statusCode, res)
 }
 
+// PullHistory 处理 GET /api/v1/conversations/:conversation_id/messages 的请求
+// 分页拉取会话历史消息，before_id 为上一页返回的 next_before_id
+func (h *UserGatewayHandler) PullHistory(c *gin.Context) {
+	conversationID := c.Param("conversation_id")
+	beforeID := c.Query("before_id")
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit parameter"})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.PullHistory(ctx, &msgPb.PullHistoryRequest{
+		ConversationId: conversationID,
+		BeforeId:       beforeID,
+		Limit:          limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// ListScheduledMessages 处理 GET /api/v1/messages/scheduled 的请求
+// 参数：status 状态筛选（默认 pending），conversation_id 限定会话
+func (h *UserGatewayHandler) ListScheduledMessages(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.ListScheduledMessages(ctx, &msgPb.ListScheduledMessagesRequest{
+		Status:         c.Query("status"),
+		ConversationId: c.Query("conversation_id"),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// UpdateScheduledMessage 处理 PUT /api/v1/messages/scheduled/:id 的请求
+// 请求体：{"content": "...", "payload": {...}, "send_at": 1700000000}
+func (h *UserGatewayHandler) UpdateScheduledMessage(c *gin.Context) {
+	var req msgPb.UpdateScheduledMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	req.ScheduledId = c.Param("id")
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.UpdateScheduledMessage(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// CancelScheduledMessage 处理 DELETE /api/v1/messages/scheduled/:id 的请求
+func (h *UserGatewayHandler) CancelScheduledMessage(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.CancelScheduledMessage(ctx, &msgPb.CancelScheduledMessageRequest{ScheduledId: c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// GetConversationTTL 处理 GET /api/v1/conversations/:conversation_id/ttl 的请求
+func (h *UserGatewayHandler) GetConversationTTL(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.GetConversationTTL(ctx, &msgPb.GetConversationTTLRequest{ConversationId: c.Param("conversation_id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// SetConversationTTL 处理 PUT /api/v1/conversations/:conversation_id/ttl 的请求
+// 请求体：{"ttl_seconds": 86400}，0 表示关闭定时销毁
+func (h *UserGatewayHandler) SetConversationTTL(c *gin.Context) {
+	var req msgPb.SetConversationTTLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	req.ConversationId = c.Param("conversation_id")
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.SetConversationTTL(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// GetGroupMessageReadStatus 处理 GET /api/v1/messages/:id/read-status 的请求
+// 返回群消息的已读/未读成员（仅发送者和群主/管理员可查询）
+func (h *UserGatewayHandler) GetGroupMessageReadStatus(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.GetGroupMessageReadStatus(ctx, &msgPb.GetGroupMessageReadStatusRequest{MessageId: c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// ForwardMessages 处理 POST /api/v1/messages/forward 的请求
+// 请求体：{"message_ids": [...], "target_conversation_ids": ["private:u1", "group:g1"], "mode": "single|merged"}
+func (h *UserGatewayHandler) ForwardMessages(c *gin.Context) {
+	var req msgPb.ForwardMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := withDeviceMetadata(c, metadata.New(map[string]string{"authorization": authHeader}))
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.ForwardMessages(ctx, &req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// PinMessage 处理 POST /api/v1/messages/:id/pin 的请求
+func (h *UserGatewayHandler) PinMessage(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.PinMessage(ctx, &msgPb.PinMessageRequest{MessageId: c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// UnpinMessage 处理 DELETE /api/v1/messages/:id/pin 的请求
+func (h *UserGatewayHandler) UnpinMessage(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.UnpinMessage(ctx, &msgPb.UnpinMessageRequest{MessageId: c.Param("id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// ListPinnedMessages 处理 GET /api/v1/conversations/:conversation_id/pins 的请求
+func (h *UserGatewayHandler) ListPinnedMessages(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.ListPinnedMessages(ctx, &msgPb.ListPinnedMessagesRequest{ConversationId: c.Param("conversation_id")})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
 // GetUnreadCount 获取未读消息数
 func (h *UserGatewayHandler) GetUnreadCount(c *gin.Context) {
 	authHeader := c.GetHeader("Authorization")
@@ -603,6 +928,167 @@ func (h *UserGatewayHandler) MarkGroupMessageAsRead(c *gin.Context) {
 	c.JSON(statusCode, res)
 }
 
+// RecallMessage 撤回消息
+// POST /api/v1/messages/:id/recall
+func (h *UserGatewayHandler) RecallMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message id is required in path"})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.RecallMessage(ctx, &msgPb.RecallMessageRequest{MessageId: messageID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// EditMessage 编辑消息
+// POST /api/v1/messages/:id/edit
+func (h *UserGatewayHandler) EditMessage(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message id is required in path"})
+		return
+	}
+
+	var body struct {
+		Content string `json:"content" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.EditMessage(ctx, &msgPb.EditMessageRequest{
+		MessageId: messageID,
+		Content:   body.Content,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// AddReaction 对消息添加表情回应
+// POST /api/v1/messages/:id/reactions
+func (h *UserGatewayHandler) AddReaction(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message id is required in path"})
+		return
+	}
+
+	var body struct {
+		Emoji string `json:"emoji" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.AddReaction(ctx, &msgPb.AddReactionRequest{
+		MessageId: messageID,
+		Emoji:     body.Emoji,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
+// RemoveReaction 取消对消息的表情回应
+// DELETE /api/v1/messages/:id/reactions
+func (h *UserGatewayHandler) RemoveReaction(c *gin.Context) {
+	messageID := c.Param("id")
+	if messageID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "message id is required in path"})
+		return
+	}
+
+	var body struct {
+		Emoji string `json:"emoji" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.RemoveReaction(ctx, &msgPb.RemoveReactionRequest{
+		MessageId: messageID,
+		Emoji:     body.Emoji,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, res)
+}
+
 // PullUnreadMessages 拉取所有未读消息
 func (h *UserGatewayHandler) PullUnreadMessages(c *gin.Context) {
 	limitStr := c.DefaultQuery("limit", "100")
@@ -906,6 +1392,39 @@ func (h *UserGatewayHandler) SearchUsers(c *gin.Context) {
 	c.JSON(statusCode, res)
 }
 
+// SearchMessages 处理 GET /api/v1/search/messages 的请求
+// 参数：q 关键词，conversation_id 限定会话，from 限定发送者，before 翻页游标
+func (h *UserGatewayHandler) SearchMessages(c *gin.Context) {
+	limit, _ := strconv.ParseInt(c.DefaultQuery("limit", "20"), 10, 64)
+
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
+		return
+	}
+
+	md := metadata.New(map[string]string{"authorization": authHeader})
+	ctx := metadata.NewOutgoingContext(c.Request.Context(), md)
+
+	res, err := h.messageClient.SearchMessages(ctx, &msgPb.SearchMessagesRequest{
+		Query:          c.Query("q"),
+		ConversationId: c.Query("conversation_id"),
+		FromUserId:     c.Query("from"),
+		Before:         c.Query("before"),
+		Limit:          limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	statusCode := http.StatusOK
+	if res.Code != 0 {
+		statusCode = http.StatusInternalServerError
+	}
+	c.JSON(statusCode, res)
+}
+
 // SearchGroups 搜索群组
 func (h *UserGatewayHandler) SearchGroups(c *gin.Context) {
 	keyword := c.Query("keyword")
@@ -945,22 +1464,23 @@ func (h *UserGatewayHandler) SearchGroups(c *gin.Context) {
 
 // GetUploadSignature 获取OSS上传签名
 func (h *UserGatewayHandler) GetUploadSignature(c *gin.Context) {
-	fileType := c.DefaultQuery("type", "file") // image 或 file
+	fileType := c.DefaultQuery("type", "file") // image、voice 或 file
 
 	// 验证文件类型
-	if fileType != "image" && fileType != "file" {
+	if fileType != "image" && fileType != "voice" && fileType != "file" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"code":    1001,
-			"message": "无效的文件类型，只支持 image 或 file",
+			"message": "无效的文件类型，只支持 image、voice 或 file",
 		})
 		return
 	}
 
 	// 设置文件大小限制
 	var maxSize int64
-	if fileType == "image" {
+	switch fileType {
+	case "image", "voice":
 		maxSize = 10 * 1024 * 1024 // 10MB
-	} else {
+	default:
 		maxSize = 50 * 1024 * 1024 // 50MB
 	}
 
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// maxClientMsgIDLength client_msg_id 的最大长度
+const maxClientMsgIDLength = 64
+
+// reserveClientMsgID 为本次发送占用 client_msg_id，record 为本次发送的消息，kind 为 private / group
+// 返回 nil 表示首次发送；否则返回首次发送的消息记录：去重窗口内的记录来自 Redis，
+// 窗口已过期但消息已落库时来自数据库（唯一索引 uk_from_client_msg 会拒绝重复写入，因此不能再写入 Stream）
+func (h *MessageHandler) reserveClientMsgID(ctx context.Context, kind, fromUserID, clientMsgID string, record stream.SentMessageRecord) (*stream.SentMessageRecord, error) {
+	if len(clientMsgID) > maxClientMsgIDLength {
+		return nil, status.Errorf(codes.InvalidArgument, "client_msg_id must be at most %d characters", maxClientMsgIDLength)
+	}
+
+	sent, err := h.streamOp.ReserveClientMsgID(ctx, fromUserID, clientMsgID, record, h.dedupWindow)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check duplicate message")
+	}
+	if sent != nil {
+		return sent, nil
+	}
+
+	persisted, err := h.findPersistedClientMsg(ctx, kind, fromUserID, clientMsgID)
+	if err != nil {
+		logger.Error("Failed to check persisted client msg id", zap.String("client_msg_id", clientMsgID), zap.Error(err))
+		h.releaseClientMsgID(fromUserID, clientMsgID)
+		return nil, status.Errorf(codes.Internal, "Failed to check duplicate message")
+	}
+	if persisted != nil {
+		// 占位改为已落库的消息，窗口内的后续重试不再查询数据库
+		h.completeClientMsgID(ctx, fromUserID, clientMsgID, *persisted)
+	}
+	return persisted, nil
+}
+
+// findPersistedClientMsg 按 (from_user_id, client_msg_id) 查询已落库的消息，不存在时返回 nil
+func (h *MessageHandler) findPersistedClientMsg(ctx context.Context, kind, fromUserID, clientMsgID string) (*stream.SentMessageRecord, error) {
+	var (
+		sent           stream.SentMessageRecord
+		mentionUserIDs sql.NullString
+		err            error
+	)
+	if kind == "group" {
+		err = h.db.QueryRowContext(ctx, `
+			SELECT id, group_id, IFNULL(content, ''), IFNULL(msg_type, 'text'), IFNULL(payload, ''),
+				mention_user_ids, IFNULL(mention_all, FALSE), UNIX_TIMESTAMP(created_at)
+			FROM group_messages WHERE from_user_id = ? AND client_msg_id = ?`, fromUserID, clientMsgID).
+			Scan(&sent.MsgID, &sent.GroupID, &sent.Content, &sent.MsgType, &sent.Payload, &mentionUserIDs, &sent.MentionAll, &sent.CreatedAt)
+	} else {
+		err = h.db.QueryRowContext(ctx, `
+			SELECT id, to_user_id, IFNULL(content, ''), IFNULL(msg_type, 'text'), IFNULL(payload, ''), UNIX_TIMESTAMP(created_at)
+			FROM messages WHERE from_user_id = ? AND client_msg_id = ?`, fromUserID, clientMsgID).
+			Scan(&sent.MsgID, &sent.ToUserID, &sent.Content, &sent.MsgType, &sent.Payload, &sent.CreatedAt)
+	}
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mentionUserIDs.Valid {
+		_ = json.Unmarshal([]byte(mentionUserIDs.String), &sent.MentionUserIDs)
+	}
+	return &sent, nil
+}
+
+// completeClientMsgID 消息写入 Stream 后记录其 Stream ID，重复请求可返回相同的 stream_id
+func (h *MessageHandler) completeClientMsgID(ctx context.Context, fromUserID, clientMsgID string, record stream.SentMessageRecord) {
+	if clientMsgID == "" {
+		return
+	}
+
+	if err := h.streamOp.CompleteClientMsgID(ctx, fromUserID, clientMsgID, record); err != nil {
+		logger.Warn("Failed to record sent message for dedup", zap.String("client_msg_id", clientMsgID), zap.Error(err))
+	}
+}
+
+// releaseClientMsgID 消息写入失败时释放占位，使客户端的下一次重试能正常发送
+func (h *MessageHandler) releaseClientMsgID(fromUserID, clientMsgID string) {
+	if clientMsgID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	if err := h.streamOp.ReleaseClientMsgID(ctx, fromUserID, clientMsgID); err != nil {
+		logger.Warn("Failed to release client msg id", zap.String("client_msg_id", clientMsgID), zap.Error(err))
+	}
+}
+
+// duplicateMessage 按首次发送的记录构造私聊消息，重复请求返回与首次发送相同的内容
+func duplicateMessage(fromUserID string, sent *stream.SentMessageRecord) *pb.Message {
+	payload, reply := decodeStoredPayload(sent.Payload)
+	return &pb.Message{
+		Id:         sent.MsgID,
+		FromUserId: fromUserID,
+		ToUserId:   sent.ToUserID,
+		Content:    sent.Content,
+		CreatedAt:  sent.CreatedAt,
+		MsgType:    msgTypeOrText(sent.MsgType),
+		Payload:    payload,
+		ReplyTo:    reply,
+	}
+}
+
+// duplicateGroupMessage 按首次发送的记录构造群聊消息
+func duplicateGroupMessage(fromUserID string, sent *stream.SentMessageRecord) *pb.GroupMessage {
+	payload, reply := decodeStoredPayload(sent.Payload)
+	return &pb.GroupMessage{
+		Id:             sent.MsgID,
+		GroupId:        sent.GroupID,
+		FromUserId:     fromUserID,
+		Content:        sent.Content,
+		CreatedAt:      sent.CreatedAt,
+		MsgType:        msgTypeOrText(sent.MsgType),
+		Payload:        payload,
+		ReplyTo:        reply,
+		MentionUserIds: sent.MentionUserIDs,
+		MentionAll:     sent.MentionAll,
+	}
+}
@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/pkg/stream"
+)
+
+func TestReserveClientMsgID(t *testing.T) {
+	ctx := context.Background()
+	const from = "user-a"
+
+	first := stream.SentMessageRecord{
+		MsgID:     "msg-1",
+		CreatedAt: 1700000000,
+		ToUserID:  "user-b",
+		Content:   "hello",
+		MsgType:   MsgTypeText,
+	}
+	retry := stream.SentMessageRecord{
+		MsgID:     "msg-2",
+		CreatedAt: 1700000005,
+		ToUserID:  "user-c",
+		Content:   "edited before retry",
+		MsgType:   MsgTypeText,
+	}
+
+	tests := []struct {
+		name        string
+		clientMsgID string
+		setup       func(h *MessageHandler, mr *miniredis.Miniredis)
+		persisted   stubQuery // 数据库中按 client_msg_id 查询到的消息
+		wantCode    codes.Code
+		wantSent    *stream.SentMessageRecord
+	}{
+		{
+			name:        "first send reserves",
+			clientMsgID: "c-1",
+		},
+		{
+			name:        "retry returns first send",
+			clientMsgID: "c-1",
+			setup: func(h *MessageHandler, _ *miniredis.Miniredis) {
+				h.reserveClientMsgID(ctx, "private", from, "c-1", first)
+			},
+			wantSent: &first,
+		},
+		{
+			name:        "retry after completion returns stream id",
+			clientMsgID: "c-1",
+			setup: func(h *MessageHandler, _ *miniredis.Miniredis) {
+				h.reserveClientMsgID(ctx, "private", from, "c-1", first)
+				completed := first
+				completed.StreamID = "1700000000000-0"
+				h.completeClientMsgID(ctx, from, "c-1", completed)
+			},
+			wantSent: &stream.SentMessageRecord{
+				MsgID: first.MsgID, StreamID: "1700000000000-0", CreatedAt: first.CreatedAt,
+				ToUserID: first.ToUserID, Content: first.Content, MsgType: first.MsgType,
+			},
+		},
+		{
+			name:        "released reservation sends again",
+			clientMsgID: "c-1",
+			setup: func(h *MessageHandler, _ *miniredis.Miniredis) {
+				h.reserveClientMsgID(ctx, "private", from, "c-1", first)
+				h.releaseClientMsgID(from, "c-1")
+			},
+		},
+		{
+			name:        "expired window sends again",
+			clientMsgID: "c-1",
+			setup: func(h *MessageHandler, mr *miniredis.Miniredis) {
+				h.reserveClientMsgID(ctx, "private", from, "c-1", first)
+				mr.FastForward(h.dedupWindow + time.Second)
+			},
+		},
+		{
+			name:        "expired window returns persisted message",
+			clientMsgID: "c-1",
+			persisted: func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				if !strings.Contains(query, "FROM messages") || args[0] != from || args[1] != "c-1" {
+					return nil, nil, nil
+				}
+				return []string{"id", "to_user_id", "content", "msg_type", "payload", "created_at"},
+					[][]driver.Value{{"msg-1", "user-b", "hello", "text", "", int64(1700000000)}}, nil
+			},
+			wantSent: &first,
+		},
+		{
+			name:        "persisted lookup failure releases reservation",
+			clientMsgID: "c-1",
+			persisted: func(string, []driver.Value) ([]string, [][]driver.Value, error) {
+				return nil, nil, errors.New("connection refused")
+			},
+			wantCode: codes.Internal,
+		},
+		{
+			name:        "other client msg id is independent",
+			clientMsgID: "c-2",
+			setup: func(h *MessageHandler, _ *miniredis.Miniredis) {
+				h.reserveClientMsgID(ctx, "private", from, "c-1", first)
+			},
+		},
+		{
+			name:        "too long",
+			clientMsgID: strings.Repeat("x", maxClientMsgIDLength+1),
+			wantCode:    codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, mr := newTestHandler(t, tt.persisted)
+			if tt.setup != nil {
+				tt.setup(h, mr)
+			}
+
+			sent, err := h.reserveClientMsgID(ctx, "private", from, tt.clientMsgID, retry)
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %s", err, tt.wantCode)
+				}
+				if exists := mr.Exists("msg:dedup:" + from + ":" + tt.clientMsgID); exists {
+					t.Errorf("reservation kept after error, retry would be reported as duplicate")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantSent == nil {
+				if sent != nil {
+					t.Fatalf("sent = %+v, want nil (first send)", sent)
+				}
+				return
+			}
+			if sent == nil {
+				t.Fatalf("sent = nil, want %+v", tt.wantSent)
+			}
+			if sent.MsgID != tt.wantSent.MsgID || sent.StreamID != tt.wantSent.StreamID || sent.CreatedAt != tt.wantSent.CreatedAt ||
+				sent.ToUserID != tt.wantSent.ToUserID || sent.Content != tt.wantSent.Content {
+				t.Errorf("sent = %+v, want %+v", sent, tt.wantSent)
+			}
+		})
+	}
+}
+
+func TestDuplicateMessageUsesFirstSend(t *testing.T) {
+	sent := &stream.SentMessageRecord{
+		MsgID:          "msg-1",
+		StreamID:       "1700000000000-0",
+		CreatedAt:      1700000000,
+		GroupID:        "group-1",
+		Content:        "[图片]",
+		MsgType:        MsgTypeImage,
+		Payload:        `{"image":{"oss_key":"images/a.png","width":10,"height":20},"reply":{"msg_id":"msg-0","content":"hi"}}`,
+		MentionUserIDs: []string{"user-b"},
+	}
+
+	msg := duplicateGroupMessage("user-a", sent)
+	if msg.Id != sent.MsgID || msg.GroupId != sent.GroupID || msg.Content != sent.Content || msg.MsgType != MsgTypeImage {
+		t.Errorf("group message = %+v, want fields from %+v", msg, sent)
+	}
+	if msg.Payload.GetImage().GetOssKey() != "images/a.png" {
+		t.Errorf("payload = %+v, want image images/a.png", msg.Payload)
+	}
+	if msg.ReplyTo.GetMsgId() != "msg-0" {
+		t.Errorf("reply_to = %+v, want msg-0", msg.ReplyTo)
+	}
+	if len(msg.MentionUserIds) != 1 || msg.MentionUserIds[0] != "user-b" {
+		t.Errorf("mention_user_ids = %v, want [user-b]", msg.MentionUserIds)
+	}
+
+	private := duplicateMessage("user-a", &stream.SentMessageRecord{MsgID: "msg-2", ToUserID: "user-b", Content: "hi"})
+	if private.ToUserId != "user-b" || private.Content != "hi" || private.MsgType != MsgTypeText {
+		t.Errorf("private message = %+v, want to user-b text hi", private)
+	}
+}
+
+func TestSendFailsWhenPersistQueueUnavailable(t *testing.T) {
+	h, mr := newTestHandler(t, nil)
+	ctx := userContext(t, "a")
+	req := &pb.SendMessageRequest{ToUserId: "b", Content: "hi", ClientMsgId: "c-1"}
+
+	// 落库队列的 key 类型错误，XADD 每次都失败
+	mr.Set(persister.QueueStream, "not a stream")
+	_, err := h.SendMessage(ctx, req)
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("got %v, want Internal", err)
+	}
+
+	// 发送失败后 client_msg_id 不记录为已发送，重试重新发送并落库
+	mr.Del(persister.QueueStream)
+	res, err := h.SendMessage(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Duplicate {
+		t.Fatal("retry after failed send returned a duplicate")
+	}
+	if n, _ := h.rdb.XLen(context.Background(), persister.QueueStream).Result(); n != 1 {
+		t.Fatalf("queued %d messages, want 1", n)
+	}
+}
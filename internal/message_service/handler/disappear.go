@@ -0,0 +1,307 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+const (
+	// conversationTTLMin / conversationTTLMax 定时销毁时长的取值范围
+	conversationTTLMin = 5 * time.Second
+	conversationTTLMax = 7 * 24 * time.Hour
+	// expireSweepInterval 清理任务检查过期消息的间隔
+	expireSweepInterval = 2 * time.Second
+	// expireSweepBatchSize 每次最多清理的过期消息数
+	expireSweepBatchSize = 200
+	// expireDBSweepInterval 清理数据库中过期消息的间隔（包括清理时尚未落库、之后才写入的消息）
+	expireDBSweepInterval = 30 * time.Second
+	// expireDBDeleteLimit 每条 DELETE 语句最多删除的行数，避免长时间锁表
+	expireDBDeleteLimit = 1000
+)
+
+// SetConversationTTL 设置会话的消息定时销毁时长
+// 私聊双方均可设置（双方共用一个设置），群聊仅群主/管理员可设置；ttl_seconds 为 0 表示关闭
+// 设置只对之后发送的消息生效
+func (h *MessageHandler) SetConversationTTL(ctx context.Context, req *pb.SetConversationTTLRequest) (*pb.SetConversationTTLResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	convType, peerID, ok := strings.Cut(req.ConversationId, ":")
+	if !ok || peerID == "" || (convType != "private" && convType != "group") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+	}
+
+	if err := validateConversationTTL(req.TtlSeconds); err != nil {
+		return nil, err
+	}
+
+	// 权限校验，同时确定需要通知的成员
+	var members []string
+	if convType == "group" {
+		role, err := h.groupMemberRole(ctx, peerID, userID)
+		if err != nil {
+			return nil, err
+		}
+		// 群主创建群时即为管理员
+		if role != "admin" {
+			return nil, status.Errorf(codes.PermissionDenied, "only group owner or admins can change disappearing messages")
+		}
+
+		members, err = h.getGroupMembers(ctx, peerID)
+		if err != nil {
+			logger.Warn("Failed to get group members for ttl notification", zap.Error(err))
+		}
+	} else {
+		var exists int
+		err := h.db.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = ?", peerID).Scan(&exists)
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		if err != nil {
+			logger.Error("Failed to check peer user", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to check peer user")
+		}
+	}
+
+	setting := stream.ConversationTTL{
+		TTLSeconds: req.TtlSeconds,
+		UpdatedBy:  userID,
+		UpdatedAt:  time.Now().Unix(),
+	}
+	if err := h.streamOp.SetConversationTTL(ctx, stream.ConversationKey(convType, userID, peerID), setting); err != nil {
+		logger.Error("Failed to save conversation ttl", zap.String("conversation_id", req.ConversationId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to save setting")
+	}
+
+	// 通知会话成员（私聊时各自的会话ID不同，分别通知）
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		event := map[string]interface{}{
+			"type":              "conversation_ttl",
+			"conversation_type": convType,
+			"ttl_seconds":       setting.TTLSeconds,
+			"updated_by":        userID,
+			"updated_at":        setting.UpdatedAt,
+		}
+		if convType == "group" {
+			event["group_id"] = peerID
+			h.publishEvent(notificationCtx, members, event)
+			return
+		}
+
+		event["peer_id"] = peerID
+		h.publishEvent(notificationCtx, []string{userID}, event)
+		if peerID != userID {
+			event["peer_id"] = userID
+			h.publishEvent(notificationCtx, []string{peerID}, event)
+		}
+	}()
+
+	logger.Info("Conversation ttl updated",
+		zap.String("user_id", userID),
+		zap.String("conversation_id", req.ConversationId),
+		zap.Int64("ttl_seconds", req.TtlSeconds))
+
+	return &pb.SetConversationTTLResponse{
+		Code:    0,
+		Message: "设置成功",
+		Setting: &pb.ConversationTTL{
+			ConversationId: req.ConversationId,
+			TtlSeconds:     setting.TTLSeconds,
+			UpdatedBy:      setting.UpdatedBy,
+			UpdatedAt:      setting.UpdatedAt,
+		},
+	}, nil
+}
+
+// GetConversationTTL 查询会话的消息定时销毁设置
+func (h *MessageHandler) GetConversationTTL(ctx context.Context, req *pb.GetConversationTTLRequest) (*pb.GetConversationTTLResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	convType, peerID, ok := strings.Cut(req.ConversationId, ":")
+	if !ok || peerID == "" || (convType != "private" && convType != "group") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+	}
+	if convType == "group" {
+		if err := h.checkGroupMember(ctx, peerID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	setting, err := h.streamOp.GetConversationTTL(ctx, stream.ConversationKey(convType, userID, peerID))
+	if err != nil {
+		logger.Error("Failed to get conversation ttl", zap.String("conversation_id", req.ConversationId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to get setting")
+	}
+
+	res := &pb.GetConversationTTLResponse{
+		Code:    0,
+		Message: "查询成功",
+		Setting: &pb.ConversationTTL{ConversationId: req.ConversationId},
+	}
+	if setting != nil {
+		res.Setting.TtlSeconds = setting.TTLSeconds
+		res.Setting.UpdatedBy = setting.UpdatedBy
+		res.Setting.UpdatedAt = setting.UpdatedAt
+	}
+	return res, nil
+}
+
+// validateConversationTTL 校验定时销毁时长：0 表示关闭，否则必须在 [conversationTTLMin, conversationTTLMax] 范围内
+func validateConversationTTL(ttlSeconds int64) error {
+	if ttlSeconds == 0 {
+		return nil
+	}
+	if ttlSeconds < int64(conversationTTLMin/time.Second) || ttlSeconds > int64(conversationTTLMax/time.Second) {
+		return status.Errorf(codes.InvalidArgument, "ttl_seconds must be 0 or between %d and %d",
+			int64(conversationTTLMin/time.Second), int64(conversationTTLMax/time.Second))
+	}
+	return nil
+}
+
+// messageExpiresAt 返回在该会话中此刻发送的消息的过期时间，未开启定时销毁时返回 0
+// 读取设置失败时按未开启处理，不影响发送
+func (h *MessageHandler) messageExpiresAt(ctx context.Context, key string) int64 {
+	setting, err := h.streamOp.GetConversationTTL(ctx, key)
+	if err != nil {
+		logger.Warn("Failed to get conversation ttl", zap.String("conversation", key), zap.Error(err))
+		return 0
+	}
+	if setting == nil {
+		return 0
+	}
+	return time.Now().Unix() + setting.TTLSeconds
+}
+
+// RunExpireSweeper 定期清理过期消息直到 ctx 结束
+// 从所有成员的 Stream 中删除过期消息并推送 expire 事件，同时删除数据库中的过期记录
+func (h *MessageHandler) RunExpireSweeper(ctx context.Context) {
+	logger.Info("Disappearing message sweeper started")
+
+	ticker := time.NewTicker(expireSweepInterval)
+	defer ticker.Stop()
+
+	var lastDBSweep time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		h.sweepExpiredStreamEntries(ctx)
+
+		if time.Since(lastDBSweep) >= expireDBSweepInterval {
+			h.sweepExpiredRows(ctx)
+			lastDBSweep = time.Now()
+		}
+	}
+}
+
+// sweepExpiredStreamEntries 删除到期消息的 Stream 条目并通知相关用户
+func (h *MessageHandler) sweepExpiredStreamEntries(ctx context.Context) {
+	ids, err := h.streamOp.DueExpiredMessages(ctx, time.Now().Unix(), expireSweepBatchSize)
+	if err != nil {
+		logger.Warn("Failed to get expired messages", zap.Error(err))
+		return
+	}
+
+	for _, id := range ids {
+		expired, err := h.streamOp.PurgeExpiredMessage(ctx, id)
+		if err != nil {
+			logger.Warn("Failed to purge expired message", zap.String("msg_id", id), zap.Error(err))
+			continue
+		}
+		if expired == nil {
+			continue // 已由其他实例清理
+		}
+
+		convType, rest, _ := strings.Cut(expired.ConversationKey, ":")
+		event := map[string]interface{}{
+			"type":              "expire",
+			"msg_id":            expired.MsgID,
+			"conversation_type": convType,
+		}
+		if convType == "group" {
+			event["group_id"] = rest
+			recipients := expired.UserIDs
+			if expired.GroupStream {
+				// 读扩散群的消息只在群消息流中，通知当前所有成员
+				if members, err := h.getGroupMembers(ctx, rest); err == nil {
+					recipients = members
+				}
+			}
+			h.publishEvent(ctx, recipients, event)
+			continue
+		}
+
+		// 私聊：为每个接收者填充对方ID，便于客户端定位会话
+		userA, userB, _ := strings.Cut(rest, ":")
+		for _, uid := range expired.UserIDs {
+			event["peer_id"] = userA
+			if uid == userA {
+				event["peer_id"] = userB
+			}
+			h.publishEvent(ctx, []string{uid}, event)
+		}
+	}
+
+	if len(ids) > 0 {
+		logger.Debug("Expired messages swept", zap.Int("count", len(ids)))
+	}
+}
+
+// sweepExpiredRows 分批删除数据库中已过期的消息
+func (h *MessageHandler) sweepExpiredRows(ctx context.Context) {
+	for _, table := range []string{"messages", "group_messages"} {
+		for {
+			res, err := h.db.ExecContext(ctx,
+				"DELETE FROM "+table+" WHERE expires_at IS NOT NULL AND expires_at <= NOW() LIMIT ?",
+				expireDBDeleteLimit)
+			if err != nil {
+				logger.Warn("Failed to delete expired messages", zap.String("table", table), zap.Error(err))
+				break
+			}
+			n, _ := res.RowsAffected()
+			if n > 0 {
+				logger.Info("Expired messages deleted from database", zap.String("table", table), zap.Int64("count", n))
+			}
+			if n < expireDBDeleteLimit {
+				break
+			}
+		}
+	}
+}
+
+// entryExpired 判断 Stream 条目是否已过期（清理任务尚未删除时由读取方过滤）
+func entryExpired(entry redis.XMessage, now int64) bool {
+	expiresAt := getInt64(entry.Values["expires_at"])
+	return expiresAt > 0 && expiresAt <= now
+}
+
+// formatExpiresAt 将过期时间格式化为落库格式，未设置时为空
+func formatExpiresAt(expiresAt int64) string {
+	if expiresAt <= 0 {
+		return ""
+	}
+	return time.Unix(expiresAt, 0).Format("2006-01-02 15:04:05")
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ChatIM/pkg/stream"
+)
+
+func TestValidateConversationTTL(t *testing.T) {
+	tests := []struct {
+		name       string
+		ttlSeconds int64
+		wantErr    bool
+	}{
+		{name: "disabled", ttlSeconds: 0},
+		{name: "minimum", ttlSeconds: 5},
+		{name: "one day", ttlSeconds: 86400},
+		{name: "maximum", ttlSeconds: 7 * 86400},
+		{name: "below minimum", ttlSeconds: 4, wantErr: true},
+		{name: "one second", ttlSeconds: 1, wantErr: true},
+		{name: "above maximum", ttlSeconds: 7*86400 + 1, wantErr: true},
+		{name: "negative", ttlSeconds: -1, wantErr: true},
+		{name: "overflows duration", ttlSeconds: math.MaxInt64 / 1000, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateConversationTTL(tt.ttlSeconds)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateConversationTTL(%d) = %v, wantErr %v", tt.ttlSeconds, err, tt.wantErr)
+			}
+			if err != nil && status.Code(err) != codes.InvalidArgument {
+				t.Errorf("code = %v, want InvalidArgument", status.Code(err))
+			}
+		})
+	}
+}
+
+func TestMessageExpiresAt(t *testing.T) {
+	ctx := context.Background()
+	h, _ := newTestHandler(t, nil)
+
+	key := stream.ConversationKey("private", "a", "b")
+	if key != stream.ConversationKey("private", "b", "a") {
+		t.Fatalf("private conversation key differs between the two users")
+	}
+	if got := h.messageExpiresAt(ctx, key); got != 0 {
+		t.Errorf("expires_at without setting = %d, want 0", got)
+	}
+
+	if err := h.streamOp.SetConversationTTL(ctx, key, stream.ConversationTTL{TTLSeconds: 60, UpdatedBy: "a"}); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	if got := h.messageExpiresAt(ctx, key); got < now+60 || got > now+61 {
+		t.Errorf("expires_at = %d, want %d", got, now+60)
+	}
+
+	// 关闭后新消息不再过期
+	if err := h.streamOp.SetConversationTTL(ctx, key, stream.ConversationTTL{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := h.messageExpiresAt(ctx, key); got != 0 {
+		t.Errorf("expires_at after disabling = %d, want 0", got)
+	}
+}
+
+func TestEntryExpired(t *testing.T) {
+	const now = 1700000000
+	tests := []struct {
+		name      string
+		expiresAt interface{}
+		want      bool
+	}{
+		{name: "no expiry field"},
+		{name: "zero", expiresAt: "0"},
+		{name: "future", expiresAt: "1700000001"},
+		{name: "now", expiresAt: "1700000000", want: true},
+		{name: "past", expiresAt: "1699999999", want: true},
+		{name: "malformed", expiresAt: "soon"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := redis.XMessage{ID: "1-0", Values: map[string]interface{}{"id": "m1"}}
+			if tt.expiresAt != nil {
+				entry.Values["expires_at"] = tt.expiresAt
+			}
+			if got := entryExpired(entry, now); got != tt.want {
+				t.Errorf("entryExpired(%v) = %v, want %v", tt.expiresAt, got, tt.want)
+			}
+		})
+	}
+}
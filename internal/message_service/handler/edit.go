@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// EditMessage 编辑一条自己发送的消息（私聊或群聊）
+// Stream 中的原始内容保持不变，最新内容记录在 msg:edit:{id} 中，读取时覆盖；
+// 数据库中更新 content / edited_at 并追加一条编辑历史
+func (h *MessageHandler) EditMessage(ctx context.Context, req *pb.EditMessageRequest) (*pb.EditMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MessageId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "content is required")
+	}
+
+	logger.Info("Editing message",
+		zap.String("msg_id", req.MessageId),
+		zap.String("user_id", userID))
+
+	// 1. 查找消息并校验归属
+	ref, err := h.locateMessage(ctx, userID, req.MessageId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.FromUserID != userID {
+		return nil, status.Errorf(codes.PermissionDenied, "only the sender can edit this message")
+	}
+	if ref.MsgType != MsgTypeText {
+		return nil, status.Errorf(codes.FailedPrecondition, "only text messages can be edited")
+	}
+	if payload, _ := decodeStoredPayload(ref.Payload); payload.GetForward() != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "forwarded messages cannot be edited")
+	}
+
+	recalled, err := h.isRecalled(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	if recalled {
+		return nil, status.Errorf(codes.FailedPrecondition, "message has been recalled")
+	}
+
+	// 2. 确定编辑前的内容（可能已经被编辑过）
+	oldContent := ref.Content
+	edits, err := h.streamOp.GetMessageEdits(ctx, []string{ref.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to get message edits")
+	}
+	if edit, ok := edits[ref.ID]; ok {
+		oldContent = edit.Content
+	}
+
+	if oldContent == req.Content {
+		return &pb.EditMessageResponse{
+			Code:     0,
+			Message:  "消息内容未变化",
+			EditedAt: edits[ref.ID].EditedAt,
+		}, nil
+	}
+
+	// 3. 保存最新内容
+	editedAt := time.Now().Unix()
+	err = h.streamOp.SaveMessageEdit(ctx, ref.ID, stream.MessageEdit{
+		Content:  req.Content,
+		EditedBy: userID,
+		EditedAt: editedAt,
+	}, ref.CreatedAt)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to edit message")
+	}
+
+	// 4. 异步更新数据库并记录编辑历史
+	go func() {
+		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// 消息可能仍在落库队列中，此时不影响任何行，由 persister 落库时按最新编辑内容补上
+		updated, err := persister.ApplyEdit(dbCtx, h.db, ref.Type, ref.ID, req.Content, editedAt)
+		if err != nil {
+			logger.Warn("Failed to update edited message in database", zap.Error(err))
+		} else if !updated {
+			logger.Debug("Edited message not persisted yet or has a newer edit", zap.String("msg_id", ref.ID))
+		}
+
+		_, err = h.db.ExecContext(dbCtx, `
+			INSERT INTO message_edit_history (message_id, conversation_type, editor_id, old_content, new_content, edited_at)
+			VALUES (?, ?, ?, ?, ?, FROM_UNIXTIME(?))
+		`, ref.ID, ref.Type, userID, oldContent, req.Content, editedAt)
+		if err != nil {
+			logger.Warn("Failed to save message edit history", zap.Error(err))
+		}
+	}()
+
+	// 5. 通知会话中的其他成员
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		members, err := h.conversationMembers(notificationCtx, ref)
+		if err != nil {
+			logger.Warn("Failed to get conversation members for edit notification", zap.Error(err))
+			return
+		}
+
+		var recipients []string
+		for _, memberID := range members {
+			if memberID != userID {
+				recipients = append(recipients, memberID)
+			}
+		}
+
+		h.publishEvent(notificationCtx, recipients, map[string]interface{}{
+			"type":              "edit",
+			"msg_id":            ref.ID,
+			"conversation_type": ref.Type,
+			"from_user_id":      ref.FromUserID,
+			"group_id":          ref.GroupID,
+			"content":           req.Content,
+			"edited_at":         editedAt,
+		})
+	}()
+
+	logger.Info("Message edited",
+		zap.String("msg_id", ref.ID),
+		zap.String("type", ref.Type))
+
+	return &pb.EditMessageResponse{
+		Code:     0,
+		Message:  "消息已编辑",
+		EditedAt: editedAt,
+	}, nil
+}
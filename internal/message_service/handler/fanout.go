@@ -0,0 +1,104 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// 超大群的读扩散：成员数超过 groupFanoutThreshold 的群，消息只写入一次 stream:group:{group_id}，
+// 不再写入每个成员的 stream:private:{user_id}。成员拉取消息时按 group:{group_id} 会话游标读取群消息流，
+// 并与个人流合并；两类 Stream 的 ID 都以 Redis 服务器的毫秒时间戳开头，可以直接比较先后。
+
+// readFanoutGroupEntries 读取用户所在的读扩散群消息流中的增量消息
+// 起点为 fromStreamID（未指定时为该群会话的已读游标），且不早于用户的入群时间；每个群最多读取 count 条
+// 成员关系和入群时间读取缓存，未命中的群从数据库加载后写入缓存
+// complete 为 false 表示有群因读取失败被跳过，返回的条目不完整
+func (h *MessageHandler) readFanoutGroupEntries(ctx context.Context, userID, fromStreamID string, cursors *stream.ConversationCursors, count int64) (entries []redis.XMessage, complete bool) {
+	groupIDs, err := h.streamOp.ListReadFanoutGroups(ctx)
+	if err != nil {
+		return nil, false
+	}
+	if len(groupIDs) == 0 {
+		return nil, true
+	}
+
+	joinedAt, missing, err := h.streamOp.GetCachedJoinTimes(ctx, userID, groupIDs)
+	if err != nil {
+		return nil, false
+	}
+	complete = true
+	for _, groupID := range missing {
+		members, err := h.loadGroupMembers(ctx, groupID)
+		if err != nil {
+			logger.Warn("Failed to load group members", zap.String("group_id", groupID), zap.Error(err))
+			complete = false
+			continue
+		}
+		if joined, ok := members[userID]; ok {
+			joinedAt[groupID] = joined
+		}
+	}
+	if len(joinedAt) == 0 {
+		return nil, complete
+	}
+
+	pipe := h.rdb.Pipeline()
+	cmds := make(map[string]*redis.XMessageSliceCmd, len(joinedAt))
+	for groupID, joined := range joinedAt {
+		start := fromStreamID
+		if start == "" {
+			start = cursors.Get("group:" + groupID)
+		}
+		from := "(" + start
+		if joinedID := fmt.Sprintf("%d-0", joined*1000); stream.CompareStreamIDs(start, joinedID) < 0 {
+			from = joinedID
+		}
+		cmds[groupID] = pipe.XRangeN(ctx, stream.GroupStreamKey(groupID), from, "+", count)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		logger.Warn("Failed to read group streams", zap.String("user_id", userID), zap.Error(err))
+		complete = false
+	}
+
+	for _, cmd := range cmds {
+		entries = append(entries, cmd.Val()...)
+	}
+	return entries, complete
+}
+
+// groupMessageStreamKey 返回读取群消息使用的 Stream：读扩散群为群消息流，其余为用户个人流
+func (h *MessageHandler) groupMessageStreamKey(ctx context.Context, userID, groupID string) string {
+	readFanout, err := h.streamOp.IsReadFanoutGroup(ctx, groupID)
+	if err != nil {
+		logger.Warn("Failed to check group fan-out mode", zap.String("group_id", groupID), zap.Error(err))
+	}
+	if readFanout {
+		return stream.GroupStreamKey(groupID)
+	}
+	return fmt.Sprintf("stream:private:%s", userID)
+}
+
+// groupJoinTime 返回用户在群中的入群时间（Unix 秒），读取缓存，未命中时从数据库加载后写入缓存
+// 用户不是群成员时 ok 为 false
+func (h *MessageHandler) groupJoinTime(ctx context.Context, userID, groupID string) (joined int64, ok bool, err error) {
+	joinedAt, missing, err := h.streamOp.GetCachedJoinTimes(ctx, userID, []string{groupID})
+	if err != nil {
+		return 0, false, err
+	}
+	if len(missing) == 0 {
+		joined, ok = joinedAt[groupID]
+		return joined, ok, nil
+	}
+	members, err := h.loadGroupMembers(ctx, groupID)
+	if err != nil {
+		return 0, false, err
+	}
+	joined, ok = members[userID]
+	return joined, ok, nil
+}
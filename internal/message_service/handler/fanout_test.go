@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestReadFanoutGroupEntries(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour).Unix()
+
+	var memberQueries atomic.Int32
+	h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "FROM group_members WHERE group_id = ?") {
+			return nil, nil, nil
+		}
+		memberQueries.Add(1)
+		columns := []string{"user_id", "joined_at"}
+		switch args[0] {
+		case "big":
+			return columns, [][]driver.Value{{"a", int64(0)}, {"late", future}}, nil
+		case "other":
+			return columns, [][]driver.Value{{"b", int64(0)}}, nil
+		}
+		return columns, nil, nil
+	})
+
+	for _, groupID := range []string{"big", "other"} {
+		if _, err := h.streamOp.AddGroupMessage(ctx, "m-"+groupID, groupID, "a", "hi", "text", "", nil, 0, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name        string
+		userID      string
+		wantMsgIDs  []string
+		wantQueries int32 // 本次拉取累计的群成员查询次数
+	}{
+		{name: "member loads groups from database", userID: "a", wantMsgIDs: []string{"m-big"}, wantQueries: 2},
+		{name: "member served from cache", userID: "a", wantMsgIDs: []string{"m-big"}, wantQueries: 2},
+		{name: "other group member", userID: "b", wantMsgIDs: []string{"m-other"}, wantQueries: 2},
+		{name: "joined after message", userID: "late", wantQueries: 2},
+		{name: "not a member", userID: "c", wantQueries: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cursors, err := h.streamOp.GetConversationCursors(ctx, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			entries, _ := h.readFanoutGroupEntries(ctx, tt.userID, "", cursors, 100)
+			for _, entry := range entries {
+				got = append(got, entry.Values["id"].(string))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantMsgIDs, ",") {
+				t.Errorf("entries = %v, want %v", got, tt.wantMsgIDs)
+			}
+			if n := memberQueries.Load(); n != tt.wantQueries {
+				t.Errorf("group member queries = %d, want %d", n, tt.wantQueries)
+			}
+		})
+	}
+}
+
+func TestReadFanoutGroupHidesMessagesBeforeJoin(t *testing.T) {
+	ctx := context.Background()
+	future := time.Now().Add(time.Hour).Unix()
+	joinedAt := map[string]int64{"a": 0, "late": future}
+
+	h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "FROM group_members WHERE group_id = ? AND user_id = ?"):
+			if joined, ok := joinedAt[args[1].(string)]; ok {
+				return []string{"joined_at"}, [][]driver.Value{{joined}}, nil
+			}
+			return []string{"joined_at"}, nil, nil
+		case strings.Contains(query, "FROM group_members WHERE group_id = ?"):
+			return []string{"user_id", "joined_at"}, [][]driver.Value{{"a", int64(0)}, {"late", future}}, nil
+		}
+		return nil, nil, nil
+	})
+
+	if _, err := h.streamOp.AddGroupMessage(ctx, "m1", "big", "a", "hi", "text", "", nil, 0, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		userID   string
+		wantCode codes.Code
+		wantMsgs int
+	}{
+		{name: "member before the message", userID: "a", wantMsgs: 1},
+		{name: "joined after the message", userID: "late", wantCode: codes.NotFound},
+		{name: "not a member", userID: "c", wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := h.locateMessage(ctx, tt.userID, "m1"); status.Code(err) != tt.wantCode {
+				t.Errorf("locateMessage err = %v, want code %v", err, tt.wantCode)
+			}
+			if tt.userID == "c" {
+				return
+			}
+			msgs, _, _, err := h.historyFromStream(ctx, tt.userID, "group", "big", "", 20)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(msgs) != tt.wantMsgs {
+				t.Errorf("history = %d messages, want %d", len(msgs), tt.wantMsgs)
+			}
+		})
+	}
+}
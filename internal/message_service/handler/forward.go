@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+)
+
+const (
+	forwardModeSingle = "single"
+	forwardModeMerged = "merged"
+
+	// forwardMaxTargets 一次最多转发到的会话数
+	forwardMaxTargets = 9
+	// forwardMaxSingle 逐条转发时一次最多转发的消息数
+	forwardMaxSingle = 30
+	// forwardMaxMerged 合并转发时一条聊天记录最多包含的消息数
+	forwardMaxMerged = 100
+)
+
+// forwardSource 校验通过的待转发消息
+type forwardSource struct {
+	Ref          *messageRef
+	Content      string             // 最新内容（编辑过的消息为编辑后的内容）
+	Payload      *pb.MessagePayload // 原消息负载，不含引用快照
+	FromUserName string
+}
+
+// forwardTarget 转发的目标会话
+type forwardTarget struct {
+	ConversationID string
+	Type           string // "private" 或 "group"
+	PeerID         string // 对方用户ID或群组ID
+}
+
+// ForwardMessages 将当前用户可见的消息转发到一个或多个会话
+// single 模式逐条重新发送（保留原发送者和发送时间），merged 模式将同一会话中的消息打包为一条聊天记录
+// 已撤回、已过期和开启了定时销毁的消息不能转发
+func (h *MessageHandler) ForwardMessages(ctx context.Context, req *pb.ForwardMessagesRequest) (*pb.ForwardMessagesResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	mode := req.Mode
+	if mode == "" {
+		mode = forwardModeSingle
+	}
+	maxMessages := forwardMaxSingle
+	switch mode {
+	case forwardModeSingle:
+	case forwardModeMerged:
+		maxMessages = forwardMaxMerged
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "mode must be single or merged")
+	}
+
+	msgIDs := uniqueNonEmpty(req.MessageIds)
+	if len(msgIDs) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "message_ids is required")
+	}
+	if len(msgIDs) > maxMessages {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d messages can be forwarded in %s mode", maxMessages, mode)
+	}
+
+	targetIDs := uniqueNonEmpty(req.TargetConversationIds)
+	if len(targetIDs) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "target_conversation_ids is required")
+	}
+	if len(targetIDs) > forwardMaxTargets {
+		return nil, status.Errorf(codes.InvalidArgument, "at most %d target conversations are allowed", forwardMaxTargets)
+	}
+
+	// 1. 校验目标会话（群聊须为群成员）
+	targets := make([]forwardTarget, 0, len(targetIDs))
+	for _, id := range targetIDs {
+		target, err := h.checkForwardTarget(ctx, userID, id)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+
+	// 2. 校验源消息对当前用户可见并读取内容
+	sources, err := h.loadForwardSources(ctx, userID, msgIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 3. 生成要发送的消息
+	var bodies []*messageBody
+	if mode == forwardModeMerged {
+		body, err := h.buildChatRecord(ctx, sources)
+		if err != nil {
+			return nil, err
+		}
+		bodies = []*messageBody{body}
+	} else {
+		for _, src := range sources {
+			bodies = append(bodies, forwardedBody(src))
+		}
+	}
+
+	// 4. 逐个目标发送，某个目标失败不影响其他目标
+	res := &pb.ForwardMessagesResponse{Code: 0, Message: "转发成功"}
+	failed := 0
+	for _, target := range targets {
+		result := &pb.ForwardResult{ConversationId: target.ConversationID}
+		for _, body := range bodies {
+			msgID, err := h.sendForwarded(ctx, userID, target, body)
+			if err != nil {
+				logger.Warn("Failed to forward message",
+					zap.String("user_id", userID),
+					zap.String("conversation_id", target.ConversationID),
+					zap.Error(err))
+				result.Error = status.Convert(err).Message()
+				failed++
+				break
+			}
+			result.MsgIds = append(result.MsgIds, msgID)
+		}
+		res.Results = append(res.Results, result)
+	}
+
+	if failed == len(targets) {
+		return nil, status.Errorf(codes.Internal, "Failed to forward messages")
+	}
+	if failed > 0 {
+		res.Message = "部分会话转发失败"
+	}
+
+	logger.Info("Messages forwarded",
+		zap.String("user_id", userID),
+		zap.String("mode", mode),
+		zap.Int("message_count", len(sources)),
+		zap.Int("target_count", len(targets)),
+		zap.Int("failed", failed))
+
+	return res, nil
+}
+
+// checkForwardTarget 解析并校验目标会话ID
+func (h *MessageHandler) checkForwardTarget(ctx context.Context, userID, conversationID string) (forwardTarget, error) {
+	convType, peerID, ok := strings.Cut(conversationID, ":")
+	if !ok || peerID == "" || (convType != "private" && convType != "group") {
+		return forwardTarget{}, status.Errorf(codes.InvalidArgument, "invalid conversation_id: %s", conversationID)
+	}
+	target := forwardTarget{ConversationID: conversationID, Type: convType, PeerID: peerID}
+
+	if convType == "group" {
+		if err := h.checkGroupMember(ctx, peerID, userID); err != nil {
+			return forwardTarget{}, err
+		}
+		return target, nil
+	}
+
+	var exists int
+	err := h.db.QueryRowContext(ctx, "SELECT 1 FROM users WHERE id = ?", peerID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return forwardTarget{}, status.Errorf(codes.NotFound, "user not found: %s", peerID)
+	}
+	if err != nil {
+		logger.Error("Failed to check forward target user", zap.Error(err))
+		return forwardTarget{}, status.Errorf(codes.Internal, "Failed to check target user")
+	}
+	return target, nil
+}
+
+// loadForwardSources 按请求顺序读取待转发的消息，任一消息不可见或不可转发时返回错误
+func (h *MessageHandler) loadForwardSources(ctx context.Context, userID string, msgIDs []string) ([]*forwardSource, error) {
+	recalled, err := h.streamOp.GetRecalledMessages(ctx, msgIDs)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	edits, err := h.streamOp.GetMessageEdits(ctx, msgIDs)
+	if err != nil {
+		logger.Warn("Failed to get forwarded message edits", zap.Error(err))
+	}
+
+	names := map[string]string{}
+	sources := make([]*forwardSource, 0, len(msgIDs))
+	for _, id := range msgIDs {
+		// locateMessage 只返回当前用户所在会话中的消息
+		ref, err := h.locateMessage(ctx, userID, id)
+		if err != nil {
+			if status.Code(err) == codes.NotFound {
+				return nil, status.Errorf(codes.NotFound, "message not found: %s", id)
+			}
+			return nil, err
+		}
+		if recalled[id] {
+			return nil, status.Errorf(codes.FailedPrecondition, "message has been recalled: %s", id)
+		}
+		if ref.ExpiresAt > 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "disappearing messages cannot be forwarded: %s", id)
+		}
+
+		src := &forwardSource{Ref: ref, Content: ref.Content}
+		if edit, ok := edits[id]; ok {
+			src.Content = edit.Content
+		}
+		src.Payload, _ = decodeStoredPayload(ref.Payload)
+
+		name, ok := names[ref.FromUserID]
+		if !ok {
+			name = h.forwardUserName(ctx, ref.FromUserID)
+			names[ref.FromUserID] = name
+		}
+		src.FromUserName = name
+
+		sources = append(sources, src)
+	}
+	return sources, nil
+}
+
+// forwardedBody 逐条转发：保持原消息的类型、内容和负载，并记录转发来源
+// 转发的消息再次转发时保留最初的来源
+func forwardedBody(src *forwardSource) *messageBody {
+	payload := src.Payload
+	if payload == nil {
+		payload = &pb.MessagePayload{}
+	}
+	if payload.Forward == nil {
+		payload.Forward = &pb.ForwardInfo{
+			MsgId:        src.Ref.ID,
+			FromUserId:   src.Ref.FromUserID,
+			FromUserName: src.FromUserName,
+			CreatedAt:    src.Ref.CreatedAt,
+		}
+	}
+	return &messageBody{
+		MsgType: src.Ref.MsgType,
+		Content: src.Content,
+		Payload: payload,
+	}
+}
+
+// buildChatRecord 合并转发：将同一会话中的消息按发送时间打包为一条聊天记录消息
+func (h *MessageHandler) buildChatRecord(ctx context.Context, sources []*forwardSource) (*messageBody, error) {
+	first := sources[0].Ref
+	for _, src := range sources[1:] {
+		if !sameConversation(src.Ref, first.FromUserID, first.Type, refPeerID(first)) {
+			return nil, status.Errorf(codes.InvalidArgument, "merged forwarding requires messages from the same conversation")
+		}
+	}
+
+	sorted := make([]*forwardSource, len(sources))
+	copy(sorted, sources)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Ref.CreatedAt < sorted[j].Ref.CreatedAt
+	})
+
+	record := &pb.ChatRecordPayload{Title: h.chatRecordTitle(ctx, first, sources)}
+	for _, src := range sorted {
+		record.Items = append(record.Items, &pb.ChatRecordItem{
+			MsgId:        src.Ref.ID,
+			FromUserId:   src.Ref.FromUserID,
+			FromUserName: src.FromUserName,
+			MsgType:      src.Ref.MsgType,
+			Content:      src.Content,
+			Payload:      src.Payload,
+			CreatedAt:    src.Ref.CreatedAt,
+		})
+	}
+
+	return &messageBody{
+		MsgType: MsgTypeChatRecord,
+		Content: "[聊天记录] " + record.Title,
+		Payload: &pb.MessagePayload{ChatRecord: record},
+	}, nil
+}
+
+// refPeerID 返回消息所在会话相对于其发送者的 peerID（私聊为接收者，群聊为群组ID）
+func refPeerID(ref *messageRef) string {
+	if ref.Type == "group" {
+		return ref.GroupID
+	}
+	return ref.ToUserID
+}
+
+// chatRecordTitle 生成聊天记录标题：群聊为"群名 的聊天记录"，私聊为"A 和 B 的聊天记录"
+func (h *MessageHandler) chatRecordTitle(ctx context.Context, ref *messageRef, sources []*forwardSource) string {
+	if ref.Type == "group" {
+		var name string
+		if err := h.db.QueryRowContext(ctx, "SELECT name FROM `groups` WHERE id = ?", ref.GroupID).Scan(&name); err != nil || name == "" {
+			return "群聊的聊天记录"
+		}
+		return fmt.Sprintf("%s 的聊天记录", name)
+	}
+
+	names := map[string]string{}
+	for _, src := range sources {
+		names[src.Ref.FromUserID] = src.FromUserName
+	}
+	nameOf := func(id string) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		return h.forwardUserName(ctx, id)
+	}
+	if ref.FromUserID == ref.ToUserID {
+		return fmt.Sprintf("%s 的聊天记录", nameOf(ref.FromUserID))
+	}
+	return fmt.Sprintf("%s 和 %s 的聊天记录", nameOf(ref.FromUserID), nameOf(ref.ToUserID))
+}
+
+// sendForwarded 将转发生成的消息发送到目标会话，返回新消息ID
+func (h *MessageHandler) sendForwarded(ctx context.Context, userID string, target forwardTarget, body *messageBody) (string, error) {
+	if target.Type == "group" {
+		res, err := h.deliverGroupMessage(ctx, userID, &pb.SendGroupMessageRequest{GroupId: target.PeerID}, body)
+		if err != nil {
+			return "", err
+		}
+		return res.Msg.GetId(), nil
+	}
+
+	res, err := h.deliverPrivateMessage(ctx, userID, &pb.SendMessageRequest{ToUserId: target.PeerID}, body)
+	if err != nil {
+		return "", err
+	}
+	return res.Msg.GetId(), nil
+}
+
+// forwardUserName 查询用户名，查询失败时为空
+func (h *MessageHandler) forwardUserName(ctx context.Context, userID string) string {
+	var username string
+	if err := h.db.QueryRowContext(ctx, "SELECT username FROM users WHERE id = ?", userID).Scan(&username); err != nil {
+		logger.Warn("Failed to query username for forwarding", zap.String("user_id", userID), zap.Error(err))
+	}
+	return username
+}
+
+// uniqueNonEmpty 去除空值和重复值，保持原有顺序
+func uniqueNonEmpty(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+)
+
+const (
+	// groupReadScanLimit 游标前进时在成员 Stream 中查找新读到的群消息的最大条目数
+	groupReadScanLimit = 500
+	// groupReadNotifyLimit 一次游标前进最多推送已读数变化的消息数（取最新的消息）
+	groupReadNotifyLimit = 50
+)
+
+// groupReadMessage 成员新读到的一条群消息
+type groupReadMessage struct {
+	ID         string
+	FromUserID string
+	CreatedAt  int64
+}
+
+// groupRecipient 群成员及其入群时间（入群前发送的消息才计入该成员的已读/未读）
+type groupRecipient struct {
+	UserID   string
+	Username string
+	JoinedAt int64
+}
+
+// GetGroupMessageReadStatus 查询群消息的已读/未读成员列表及人数
+// 仅消息发送者和群主/管理员可以查询；发送者本人和消息发送后才入群的成员不计入
+func (h *MessageHandler) GetGroupMessageReadStatus(ctx context.Context, req *pb.GetGroupMessageReadStatusRequest) (*pb.GetGroupMessageReadStatusResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.MessageId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+
+	ref, err := h.locateMessage(ctx, userID, req.MessageId)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type != "group" {
+		return nil, status.Errorf(codes.InvalidArgument, "not a group message")
+	}
+
+	if ref.FromUserID != userID {
+		role, err := h.groupMemberRole(ctx, ref.GroupID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role != "admin" {
+			return nil, status.Errorf(codes.PermissionDenied, "only the sender or group admins can view read status")
+		}
+	}
+
+	recipients, err := h.groupRecipients(ctx, ref.GroupID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to get group members")
+	}
+	positions := h.groupReadPositions(ctx, ref.GroupID)
+
+	res := &pb.GetGroupMessageReadStatusResponse{
+		Code:          0,
+		Message:       "查询成功",
+		GroupId:       ref.GroupID,
+		ReadMembers:   []*pb.GroupReadMember{},
+		UnreadMembers: []*pb.GroupReadMember{},
+	}
+	for _, r := range recipients {
+		if r.UserID == ref.FromUserID || r.JoinedAt > ref.CreatedAt {
+			continue
+		}
+		member := &pb.GroupReadMember{UserId: r.UserID, Username: r.Username}
+		if positions[r.UserID] >= ref.CreatedAt {
+			res.ReadMembers = append(res.ReadMembers, member)
+		} else {
+			res.UnreadMembers = append(res.UnreadMembers, member)
+		}
+	}
+	res.ReadCount = int32(len(res.ReadMembers))
+	res.UnreadCount = int32(len(res.UnreadMembers))
+
+	return res, nil
+}
+
+// advanceGroupReadPosition 成员的群聊游标从 oldCursor 前进到 newCursor（成员 Stream 或读扩散群消息流中的ID）时，
+// 找出这段区间内新读到的群消息，更新成员的已读位置并向这些消息的发送者推送最新的已读人数
+func (h *MessageHandler) advanceGroupReadPosition(ctx context.Context, userID, groupID, oldCursor, newCursor string) {
+	start := "-"
+	if oldCursor != "" {
+		start = "(" + oldCursor
+	}
+
+	streamKey := h.groupMessageStreamKey(ctx, userID, groupID)
+	entries, err := h.rdb.XRevRangeN(ctx, streamKey, newCursor, start, groupReadScanLimit).Result()
+	if err != nil {
+		logger.Warn("Failed to read stream for group read position", zap.String("user_id", userID), zap.Error(err))
+		return
+	}
+
+	var msgs []groupReadMessage
+	for _, entry := range entries {
+		if getString(entry.Values["type"]) != "group" || getString(entry.Values["group_id"]) != groupID {
+			continue
+		}
+		msgs = append(msgs, groupReadMessage{
+			ID:         getString(entry.Values["id"]),
+			FromUserID: getString(entry.Values["from_user_id"]),
+			CreatedAt:  getInt64(entry.Values["created_at"]),
+		})
+	}
+	if len(msgs) == 0 {
+		return
+	}
+
+	// msgs 按新->旧排列，第一条即为读到的最新消息
+	prev, advanced := h.saveGroupReadPosition(ctx, groupID, userID, msgs[0])
+	if !advanced {
+		return
+	}
+
+	// 已读位置之前的消息此前已计入，只推送本次新读到的他人消息
+	var changed []groupReadMessage
+	for _, m := range msgs {
+		if m.CreatedAt > prev && m.FromUserID != userID {
+			changed = append(changed, m)
+		}
+	}
+	h.publishGroupReadCounts(ctx, groupID, userID, changed)
+}
+
+// saveGroupReadPosition 将成员的已读位置前进到 msg 并备份到 group_read_states，返回原位置以及是否有更新
+func (h *MessageHandler) saveGroupReadPosition(ctx context.Context, groupID, userID string, msg groupReadMessage) (int64, bool) {
+	prev, advanced, err := h.streamOp.AdvanceGroupReadPosition(ctx, groupID, userID, msg.CreatedAt)
+	if err != nil || !advanced {
+		return prev, false
+	}
+
+	go func() {
+		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_, err := h.db.ExecContext(dbCtx, `
+			INSERT INTO group_read_states (group_id, user_id, last_read_msg_id, last_read_at)
+			VALUES (?, ?, ?, NOW())
+			ON DUPLICATE KEY UPDATE
+				last_read_msg_id = VALUES(last_read_msg_id),
+				last_read_at = NOW()
+		`, groupID, userID, msg.ID)
+		if err != nil {
+			logger.Warn("Failed to save group read state", zap.String("group_id", groupID), zap.Error(err))
+		}
+	}()
+
+	return prev, true
+}
+
+// publishGroupReadCounts 向消息发送者推送 group_read 事件，携带消息最新的已读/未读人数
+func (h *MessageHandler) publishGroupReadCounts(ctx context.Context, groupID, readerID string, msgs []groupReadMessage) {
+	if len(msgs) == 0 {
+		return
+	}
+	if len(msgs) > groupReadNotifyLimit {
+		msgs = msgs[:groupReadNotifyLimit]
+	}
+
+	recipients, err := h.groupRecipients(ctx, groupID)
+	if err != nil {
+		return
+	}
+	positions := h.groupReadPositions(ctx, groupID)
+
+	for _, m := range msgs {
+		var readCount, unreadCount int
+		for _, r := range recipients {
+			if r.UserID == m.FromUserID || r.JoinedAt > m.CreatedAt {
+				continue
+			}
+			if positions[r.UserID] >= m.CreatedAt {
+				readCount++
+			} else {
+				unreadCount++
+			}
+		}
+
+		h.publishEvent(ctx, []string{m.FromUserID}, map[string]interface{}{
+			"type":         "group_read",
+			"msg_id":       m.ID,
+			"group_id":     groupID,
+			"reader_id":    readerID,
+			"read_count":   readCount,
+			"unread_count": unreadCount,
+		})
+	}
+}
+
+// groupReadPositions 获取群成员的已读位置；Redis 中没有记录时从 group_read_states 恢复
+func (h *MessageHandler) groupReadPositions(ctx context.Context, groupID string) map[string]int64 {
+	positions, err := h.streamOp.GetGroupReadPositions(ctx, groupID)
+	if err != nil {
+		logger.Warn("Failed to get group read positions", zap.String("group_id", groupID), zap.Error(err))
+	}
+	if len(positions) > 0 {
+		return positions
+	}
+
+	// 备份中记录的是最后已读的消息ID，按该消息的发送时间还原已读位置
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT rs.user_id, UNIX_TIMESTAMP(gm.created_at)
+		FROM group_read_states rs
+		JOIN group_messages gm ON gm.id = rs.last_read_msg_id
+		WHERE rs.group_id = ?`, groupID)
+	if err != nil {
+		logger.Warn("Failed to load group read states", zap.String("group_id", groupID), zap.Error(err))
+		return map[string]int64{}
+	}
+	defer rows.Close()
+
+	positions = map[string]int64{}
+	for rows.Next() {
+		var (
+			userID string
+			pos    int64
+		)
+		if err := rows.Scan(&userID, &pos); err != nil {
+			continue
+		}
+		positions[userID] = pos
+	}
+
+	if err := h.streamOp.RestoreGroupReadPositions(ctx, groupID, positions); err != nil {
+		logger.Warn("Failed to restore group read positions", zap.String("group_id", groupID), zap.Error(err))
+	}
+	return positions
+}
+
+// groupRecipients 查询群成员及其入群时间
+func (h *MessageHandler) groupRecipients(ctx context.Context, groupID string) ([]groupRecipient, error) {
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT m.user_id, IFNULL(u.username, ''), IFNULL(UNIX_TIMESTAMP(m.joined_at), 0)
+		FROM group_members m
+		LEFT JOIN users u ON u.id = m.user_id
+		WHERE m.group_id = ? AND m.is_deleted = 0`, groupID)
+	if err != nil {
+		logger.Error("Failed to query group members", zap.String("group_id", groupID), zap.Error(err))
+		return nil, err
+	}
+	defer rows.Close()
+
+	var recipients []groupRecipient
+	for rows.Next() {
+		var r groupRecipient
+		if err := rows.Scan(&r.UserID, &r.Username, &r.JoinedAt); err != nil {
+			continue
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, rows.Err()
+}
+
+// groupMemberRole 查询用户在群内的角色，不是群成员时返回 PermissionDenied
+func (h *MessageHandler) groupMemberRole(ctx context.Context, groupID, userID string) (string, error) {
+	var role string
+	err := h.db.QueryRowContext(ctx,
+		"SELECT role FROM group_members WHERE group_id = ? AND user_id = ? AND is_deleted = 0",
+		groupID, userID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", status.Errorf(codes.PermissionDenied, "not a member of this group")
+	}
+	if err != nil {
+		logger.Error("Failed to check member role", zap.Error(err))
+		return "", status.Errorf(codes.Internal, "Failed to check member role")
+	}
+	return role, nil
+}
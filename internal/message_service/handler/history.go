@@ -0,0 +1,412 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+const (
+	// historyScanBatch 每次从 Stream 倒序读取的条目数
+	historyScanBatch = 200
+	// historyDBCursorPrefix 数据库阶段翻页游标的前缀，格式为 db:{created_at}:{seq}，
+	// 尚不知道 seq 时（Stream 中的消息、旧版游标）为 db:{created_at}:{msg_id}
+	historyDBCursorPrefix = "db:"
+)
+
+// historyCursor 数据库阶段的翻页位置：早于 (CreatedAt, Seq) 的消息
+// Seq 为 0 且 MsgID 不为空时，翻页前按 MsgID 查询 seq
+type historyCursor struct {
+	CreatedAt int64
+	Seq       int64
+	MsgID     string
+}
+
+func (c historyCursor) String() string {
+	if c.Seq > 0 {
+		return fmt.Sprintf("%s%d:%d", historyDBCursorPrefix, c.CreatedAt, c.Seq)
+	}
+	return fmt.Sprintf("%s%d:%s", historyDBCursorPrefix, c.CreatedAt, c.MsgID)
+}
+
+// parseHistoryCursor 解析数据库阶段的翻页游标
+func parseHistoryCursor(v string) (historyCursor, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(v, historyDBCursorPrefix), ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return historyCursor{}, false
+	}
+	createdAt, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return historyCursor{}, false
+	}
+	if seq, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+		if seq <= 0 {
+			return historyCursor{}, false
+		}
+		return historyCursor{CreatedAt: createdAt, Seq: seq}, true
+	}
+	return historyCursor{CreatedAt: createdAt, MsgID: parts[1]}, true
+}
+
+// PullHistory 分页拉取会话历史消息（向前翻页）
+// 先倒序读取当前用户的 Stream，Stream 读完（已被裁剪或过期）后透明地回退到 messages / group_messages 表
+func (h *MessageHandler) PullHistory(ctx context.Context, req *pb.PullHistoryRequest) (*pb.PullHistoryResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	convType, peerID, ok := strings.Cut(req.ConversationId, ":")
+	if !ok || peerID == "" || (convType != "private" && convType != "group") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	if convType == "group" {
+		if err := h.checkGroupMember(ctx, peerID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	var (
+		msgs    []*pb.UnifiedMessage
+		hasMore bool
+		next    string
+	)
+
+	switch {
+	case strings.HasPrefix(req.BeforeId, historyDBCursorPrefix):
+		cursor, ok := parseHistoryCursor(req.BeforeId)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid before_id")
+		}
+		var last historyCursor
+		msgs, hasMore, last, err = h.historyFromDB(ctx, userID, convType, peerID, cursor, nil, limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(msgs) > 0 {
+			next = last.String()
+		}
+
+	case req.BeforeId == "" || stream.ValidStreamID(req.BeforeId):
+		msgs, hasMore, next, err = h.historyFromStream(ctx, userID, convType, peerID, req.BeforeId, limit)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid before_id")
+	}
+
+	if !hasMore {
+		next = ""
+	}
+
+	// 查询结果为新->旧，返回时按时间升序，便于客户端直接插入到列表顶部
+	for i, j := 0, len(msgs)-1; i < j; i, j = i+1, j-1 {
+		msgs[i], msgs[j] = msgs[j], msgs[i]
+	}
+	h.applyMessageStates(ctx, userID, msgs)
+
+	logger.Info("History pulled",
+		zap.String("user_id", userID),
+		zap.String("conversation_id", req.ConversationId),
+		zap.String("before_id", req.BeforeId),
+		zap.Int("count", len(msgs)),
+		zap.Bool("has_more", hasMore))
+
+	return &pb.PullHistoryResponse{
+		Code:         0,
+		Message:      "历史消息拉取成功",
+		Messages:     msgs,
+		NextBeforeId: next,
+		HasMore:      hasMore,
+	}, nil
+}
+
+// historyFromStream 从 Stream 中倒序读取指定会话早于 beforeID 的消息（新->旧）
+// 一直扫描到本页已满或 Stream 读完（Stream 长度由 retention 任务限制），Stream 读完且本页未满时回退到数据库补齐
+func (h *MessageHandler) historyFromStream(ctx context.Context, userID, convType, peerID, beforeID string, limit int64) ([]*pb.UnifiedMessage, bool, string, error) {
+	conversationID := convType + ":" + peerID
+	cursors, err := h.streamOp.GetConversationCursors(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get conversation cursors", zap.Error(err))
+	}
+	readCursor := cursors.Get(conversationID)
+
+	var (
+		msgs      []*pb.UnifiedMessage
+		lastID    string
+		exhausted bool
+		now       = time.Now().Unix()
+	)
+
+	streamKey := fmt.Sprintf("stream:private:%s", userID)
+	start := "-"
+	if convType == "group" {
+		streamKey = h.groupMessageStreamKey(ctx, userID, peerID)
+	}
+	if streamKey == stream.GroupStreamKey(peerID) {
+		// 读扩散群的消息流包含入群之前的消息，只读取到入群时间为止（与增量拉取一致）
+		joined, ok, err := h.groupJoinTime(ctx, userID, peerID)
+		if err != nil {
+			logger.Error("Failed to get group join time", zap.String("user_id", userID), zap.String("group_id", peerID), zap.Error(err))
+			return nil, false, "", status.Errorf(codes.Internal, "Failed to read history")
+		}
+		if !ok {
+			// 成员缓存尚未包含刚入群的用户：不读取群消息流，由数据库补齐
+			joined = now
+		}
+		start = fmt.Sprintf("%d-0", joined*1000)
+	}
+	end := "+"
+	if beforeID != "" {
+		end = "(" + beforeID
+	}
+
+scan:
+	for {
+		entries, err := h.rdb.XRevRangeN(ctx, streamKey, end, start, historyScanBatch).Result()
+		if err != nil {
+			logger.Error("Failed to read history from stream", zap.String("user_id", userID), zap.Error(err))
+			return nil, false, "", status.Errorf(codes.Internal, "Failed to read history")
+		}
+
+		for i, entry := range entries {
+			lastID = entry.ID
+			if entryConversationID(entry, userID) != conversationID || entryExpired(entry, now) {
+				continue
+			}
+
+			m := streamEntryToUnified(entry)
+			m.IsRead = m.FromUserId == userID || stream.CompareStreamIDs(entry.ID, readCursor) <= 0
+			msgs = append(msgs, m)
+
+			if int64(len(msgs)) >= limit {
+				// 本页已满；恰好读到 Stream 末尾时下一页直接从数据库开始
+				exhausted = i == len(entries)-1 && len(entries) < historyScanBatch
+				break scan
+			}
+		}
+
+		if len(entries) < historyScanBatch {
+			exhausted = true
+			break
+		}
+		end = "(" + lastID
+	}
+
+	if !exhausted {
+		// 本页已满：从最后扫描到的位置继续
+		return msgs, true, lastID, nil
+	}
+
+	// Stream 已读完，后续消息从数据库读取（此时还不知道最后一条消息的 seq，下一页按消息ID查询）
+	if int64(len(msgs)) >= limit {
+		last := msgs[len(msgs)-1]
+		return msgs, true, historyCursor{CreatedAt: last.CreatedAt, MsgID: last.Id}.String(), nil
+	}
+
+	// 本页已有 Stream 消息时，从最早一条的同一秒开始读取并跳过已返回的消息；
+	// 否则以 beforeID 的时间为界（同一秒内可能与上一页重复，客户端按消息ID去重）
+	boundary := historyCursor{}
+	seen := make(map[string]bool, len(msgs))
+	if len(msgs) > 0 {
+		oldest := msgs[len(msgs)-1]
+		boundary = historyCursor{CreatedAt: oldest.CreatedAt}
+		for _, m := range msgs {
+			seen[m.Id] = true
+		}
+	} else if beforeID != "" {
+		ms, _ := strconv.ParseInt(strings.SplitN(beforeID, "-", 2)[0], 10, 64)
+		boundary = historyCursor{CreatedAt: ms / 1000}
+	}
+
+	dbMsgs, hasMore, last, err := h.historyFromDB(ctx, userID, convType, peerID, boundary, seen, limit-int64(len(msgs)))
+	if err != nil {
+		return nil, false, "", err
+	}
+	msgs = append(msgs, dbMsgs...)
+
+	next := ""
+	if len(dbMsgs) > 0 {
+		next = last.String()
+	} else if len(msgs) > 0 {
+		oldest := msgs[len(msgs)-1]
+		next = historyCursor{CreatedAt: oldest.CreatedAt, MsgID: oldest.Id}.String()
+	}
+	return msgs, hasMore, next, nil
+}
+
+// historyFromDB 从数据库读取指定会话早于 cursor 的消息（新->旧），同一秒内的消息按 seq 排序
+// cursor 只有 CreatedAt 时包含同一秒内的消息，并跳过 exclude 中已从 Stream 返回的消息；
+// cursor.CreatedAt 为 0 时从最新消息开始。返回本页最后一条消息的翻页位置
+func (h *MessageHandler) historyFromDB(ctx context.Context, userID, convType, peerID string, cursor historyCursor, exclude map[string]bool, limit int64) ([]*pb.UnifiedMessage, bool, historyCursor, error) {
+	var (
+		query string
+		args  []interface{}
+		last  historyCursor
+	)
+
+	if cursor.Seq == 0 && cursor.MsgID != "" {
+		seq, err := h.messageSeq(ctx, convType, cursor.MsgID)
+		if err != nil {
+			return nil, false, last, err
+		}
+		// 消息尚未落库时只能按秒定位，同一秒内的消息可能与上一页重复（客户端按消息ID去重）
+		cursor.Seq = seq
+	}
+
+	if convType == "private" {
+		query = `
+			SELECT id, from_user_id, to_user_id, '', IFNULL(content, ''), IFNULL(msg_type, 'text'), IFNULL(payload, ''),
+				IFNULL(is_recalled, FALSE), UNIX_TIMESTAMP(created_at), UNIX_TIMESTAMP(edited_at), NULL, FALSE,
+				IFNULL(UNIX_TIMESTAMP(expires_at), 0), seq
+			FROM messages
+			WHERE ((from_user_id = ? AND to_user_id = ?) OR (from_user_id = ? AND to_user_id = ?))
+				AND (expires_at IS NULL OR expires_at > NOW())`
+		args = append(args, userID, peerID, peerID, userID)
+	} else {
+		// 只返回用户入群之后的消息
+		query = `
+			SELECT gm.id, gm.from_user_id, '', gm.group_id, IFNULL(gm.content, ''), IFNULL(gm.msg_type, 'text'), IFNULL(gm.payload, ''),
+				IFNULL(gm.is_recalled, FALSE), UNIX_TIMESTAMP(gm.created_at), UNIX_TIMESTAMP(gm.edited_at), gm.mention_user_ids, IFNULL(gm.mention_all, FALSE),
+				IFNULL(UNIX_TIMESTAMP(gm.expires_at), 0), gm.seq
+			FROM group_messages gm
+			JOIN group_members m ON m.group_id = gm.group_id AND m.user_id = ? AND m.is_deleted = 0
+			WHERE gm.group_id = ? AND gm.created_at >= m.joined_at AND (gm.expires_at IS NULL OR gm.expires_at > NOW())`
+		args = append(args, userID, peerID)
+	}
+
+	switch {
+	case cursor.CreatedAt == 0:
+	case cursor.Seq > 0:
+		query += " AND (created_at < FROM_UNIXTIME(?) OR (created_at = FROM_UNIXTIME(?) AND seq < ?))"
+		args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.Seq)
+	default:
+		query += " AND created_at <= FROM_UNIXTIME(?)"
+		args = append(args, cursor.CreatedAt)
+	}
+
+	// 多取一条用于判断是否还有更早的消息
+	query += " ORDER BY created_at DESC, seq DESC LIMIT ?"
+	args = append(args, limit+int64(len(exclude))+1)
+
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Failed to query history from database", zap.String("user_id", userID), zap.Error(err))
+		return nil, false, last, status.Errorf(codes.Internal, "Failed to read history")
+	}
+	defer rows.Close()
+
+	var msgs []*pb.UnifiedMessage
+	hasMore := false
+	for rows.Next() {
+		var (
+			m              pb.UnifiedMessage
+			payloadJSON    string
+			editedAt       sql.NullInt64
+			mentionUserIDs sql.NullString
+			seq            int64
+		)
+		if err := rows.Scan(&m.Id, &m.FromUserId, &m.ToUserId, &m.GroupId, &m.Content, &m.MsgType, &payloadJSON,
+			&m.IsRecalled, &m.CreatedAt, &editedAt, &mentionUserIDs, &m.MentionAll, &m.ExpiresAt, &seq); err != nil {
+			logger.Error("Failed to scan history row", zap.Error(err))
+			return nil, false, last, status.Errorf(codes.Internal, "Failed to read history")
+		}
+		if exclude[m.Id] {
+			continue
+		}
+		if int64(len(msgs)) >= limit {
+			hasMore = true
+			break
+		}
+
+		m.Type = convType
+		m.IsRead = true
+		m.Payload, m.ReplyTo = decodeStoredPayload(payloadJSON)
+		if editedAt.Valid {
+			m.IsEdited = true
+			m.EditedAt = editedAt.Int64
+		}
+		if mentionUserIDs.Valid {
+			_ = json.Unmarshal([]byte(mentionUserIDs.String), &m.MentionUserIds)
+		}
+		msgs = append(msgs, &m)
+		last = historyCursor{CreatedAt: m.CreatedAt, Seq: seq}
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate history rows", zap.Error(err))
+		return nil, false, last, status.Errorf(codes.Internal, "Failed to read history")
+	}
+
+	return msgs, hasMore, last, nil
+}
+
+// messageSeq 查询消息的 seq，消息尚未落库时返回 0
+func (h *MessageHandler) messageSeq(ctx context.Context, convType, msgID string) (int64, error) {
+	var seq int64
+	err := h.db.QueryRowContext(ctx, fmt.Sprintf("SELECT seq FROM %s WHERE id = ?", persister.TableName(convType)), msgID).Scan(&seq)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error("Failed to query message seq", zap.String("msg_id", msgID), zap.Error(err))
+		return 0, status.Errorf(codes.Internal, "Failed to read history")
+	}
+	return seq, nil
+}
+
+// entryConversationID 返回 Stream 条目所属的会话ID（从 userID 的视角）
+func entryConversationID(entry redis.XMessage, userID string) string {
+	switch getString(entry.Values["type"]) {
+	case "private":
+		peerID := getString(entry.Values["from_user_id"])
+		if peerID == userID {
+			peerID = getString(entry.Values["to_user_id"])
+		}
+		return "private:" + peerID
+	case "group":
+		return "group:" + getString(entry.Values["group_id"])
+	}
+	return ""
+}
+
+// checkGroupMember 校验用户是群成员
+func (h *MessageHandler) checkGroupMember(ctx context.Context, groupID, userID string) error {
+	var exists int
+	err := h.db.QueryRowContext(ctx,
+		"SELECT 1 FROM group_members WHERE group_id = ? AND user_id = ? AND is_deleted = 0",
+		groupID, userID).Scan(&exists)
+	if err == sql.ErrNoRows {
+		return status.Errorf(codes.PermissionDenied, "not a member of this group")
+	}
+	if err != nil {
+		logger.Error("Failed to check group membership", zap.Error(err))
+		return status.Errorf(codes.Internal, "Failed to check group membership")
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseHistoryCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+		want   historyCursor
+		wantOK bool
+	}{
+		{name: "seq", cursor: "db:1700000000:42", want: historyCursor{CreatedAt: 1700000000, Seq: 42}, wantOK: true},
+		{name: "message id", cursor: "db:1700000000:6f1c2b9e-0000-4000-8000-000000000001", want: historyCursor{CreatedAt: 1700000000, MsgID: "6f1c2b9e-0000-4000-8000-000000000001"}, wantOK: true},
+		{name: "zero seq", cursor: "db:1700000000:0"},
+		{name: "negative seq", cursor: "db:1700000000:-3"},
+		{name: "missing position", cursor: "db:1700000000:"},
+		{name: "missing separator", cursor: "db:1700000000"},
+		{name: "bad time", cursor: "db:abc:42"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseHistoryCursor(tt.cursor)
+			if ok != tt.wantOK || got != tt.want {
+				t.Fatalf("parseHistoryCursor(%q) = %+v, %v, want %+v, %v", tt.cursor, got, ok, tt.want, tt.wantOK)
+			}
+			if ok && got.String() != tt.cursor {
+				t.Errorf("String() = %q, want %q", got.String(), tt.cursor)
+			}
+		})
+	}
+}
+
+func TestHistoryFromStreamScansUntilPageFills(t *testing.T) {
+	ctx := context.Background()
+	h, _ := newTestHandler(t, nil)
+
+	// 目标会话的两条消息之后是大量其他会话的消息，超过一次请求原先的扫描上限
+	pipe := h.rdb.Pipeline()
+	add := func(id, peer string) {
+		pipe.XAdd(ctx, &redis.XAddArgs{Stream: "stream:private:a", Values: map[string]interface{}{
+			"id": id, "type": "private", "from_user_id": "a", "to_user_id": peer, "content": id, "created_at": 1700000000,
+		}})
+	}
+	add("b1", "b")
+	add("b2", "b")
+	for i := 0; i < 2500; i++ {
+		add(fmt.Sprintf("c%d", i), "c")
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	msgs, hasMore, next, err := h.historyFromStream(ctx, "a", "private", "b", "", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(msgs) != 2 || msgs[0].Id != "b2" || msgs[1].Id != "b1" {
+		t.Fatalf("msgs = %v, want [b2 b1]", msgs)
+	}
+	if !hasMore || next != "db:1700000000:b1" {
+		t.Errorf("hasMore = %v, next = %q, want true, db:1700000000:b1", hasMore, next)
+	}
+}
+
+func TestHistoryFromDBCursor(t *testing.T) {
+	ctx := context.Background()
+	row := func(id string, createdAt, seq int64) []driver.Value {
+		return []driver.Value{id, "a", "b", "", id, "text", "", false, createdAt, nil, nil, false, int64(0), seq}
+	}
+	columns := []string{"id", "from_user_id", "to_user_id", "group_id", "content", "msg_type", "payload",
+		"is_recalled", "created_at", "edited_at", "mention_user_ids", "mention_all", "expires_at", "seq"}
+
+	tests := []struct {
+		name       string
+		cursor     historyCursor
+		seqOf      map[string]int64 // 按消息ID查询到的 seq
+		wantFilter string
+		wantArgs   []interface{}
+	}{
+		{
+			name:       "seq cursor",
+			cursor:     historyCursor{CreatedAt: 1700000000, Seq: 42},
+			wantFilter: "AND (created_at < FROM_UNIXTIME(?) OR (created_at = FROM_UNIXTIME(?) AND seq < ?))",
+			wantArgs:   []interface{}{int64(1700000000), int64(1700000000), int64(42)},
+		},
+		{
+			name:       "message id cursor resolved to seq",
+			cursor:     historyCursor{CreatedAt: 1700000000, MsgID: "m42"},
+			seqOf:      map[string]int64{"m42": 42},
+			wantFilter: "AND (created_at < FROM_UNIXTIME(?) OR (created_at = FROM_UNIXTIME(?) AND seq < ?))",
+			wantArgs:   []interface{}{int64(1700000000), int64(1700000000), int64(42)},
+		},
+		{
+			name:       "message not yet persisted",
+			cursor:     historyCursor{CreatedAt: 1700000000, MsgID: "pending"},
+			wantFilter: "AND created_at <= FROM_UNIXTIME(?)",
+			wantArgs:   []interface{}{int64(1700000000)},
+		},
+		{
+			name: "latest",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var historyQuery string
+			var historyArgs []driver.Value
+			h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				if strings.HasPrefix(query, "SELECT seq FROM") {
+					if seq, ok := tt.seqOf[args[0].(string)]; ok {
+						return []string{"seq"}, [][]driver.Value{{seq}}, nil
+					}
+					return []string{"seq"}, nil, nil
+				}
+				historyQuery, historyArgs = query, args
+				return columns, [][]driver.Value{row("m41", 1700000000, 41), row("m40", 1700000000, 40), row("m39", 1699999999, 39)}, nil
+			})
+
+			msgs, hasMore, last, err := h.historyFromDB(ctx, "a", "private", "b", tt.cursor, nil, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !strings.Contains(historyQuery, "ORDER BY created_at DESC, seq DESC") {
+				t.Errorf("query is not ordered by seq: %s", historyQuery)
+			}
+			if tt.wantFilter != "" && !strings.Contains(historyQuery, tt.wantFilter) {
+				t.Errorf("query missing %q: %s", tt.wantFilter, historyQuery)
+			}
+			// 前 4 个参数为会话双方，最后一个为 LIMIT
+			if got := fmt.Sprint(historyArgs[4 : len(historyArgs)-1]); got != fmt.Sprint(tt.wantArgs) {
+				t.Errorf("cursor args = %v, want %v", got, tt.wantArgs)
+			}
+			if len(msgs) != 2 || !hasMore {
+				t.Fatalf("got %d messages, hasMore %v, want 2, true", len(msgs), hasMore)
+			}
+			if want := (historyCursor{CreatedAt: 1700000000, Seq: 40}); last != want {
+				t.Errorf("last = %+v, want %+v", last, want)
+			}
+		})
+	}
+}
+
+func TestHistoryFromDBGroupJoinTime(t *testing.T) {
+	ctx := context.Background()
+	columns := []string{"id", "from_user_id", "to_user_id", "group_id", "content", "msg_type", "payload",
+		"is_recalled", "created_at", "edited_at", "mention_user_ids", "mention_all", "expires_at", "seq"}
+	// 群消息（新->旧）及成员的入群时间
+	messages := []struct {
+		id        string
+		createdAt int64
+	}{{"g4", 1700000400}, {"g3", 1700000300}, {"g2", 1700000200}, {"g1", 1700000100}}
+	joinedAt := map[string]int64{"founder": 1700000000, "late": 1700000250}
+
+	h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "JOIN group_members m ON m.group_id = gm.group_id AND m.user_id = ? AND m.is_deleted = 0") ||
+			!strings.Contains(query, "gm.created_at >= m.joined_at") {
+			t.Fatalf("group history is not limited to the member's join time: %s", query)
+		}
+		var rows [][]driver.Value
+		for i, m := range messages {
+			if m.createdAt >= joinedAt[args[0].(string)] {
+				rows = append(rows, []driver.Value{m.id, "founder", "", "g", m.id, "text", "", false, m.createdAt, nil, nil, false, int64(0), int64(len(messages) - i)})
+			}
+		}
+		return columns, rows, nil
+	})
+
+	tests := []struct {
+		name    string
+		userID  string
+		wantIDs string
+	}{
+		{name: "member since creation", userID: "founder", wantIDs: "g4,g3,g2,g1"},
+		{name: "member joined after the first messages", userID: "late", wantIDs: "g4,g3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs, hasMore, _, err := h.historyFromDB(ctx, tt.userID, "group", "g", historyCursor{}, nil, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, m := range msgs {
+				ids = append(ids, m.Id)
+			}
+			if got := strings.Join(ids, ","); got != tt.wantIDs || hasMore {
+				t.Errorf("messages = %q, hasMore %v, want %q, false", got, hasMore, tt.wantIDs)
+			}
+		})
+	}
+}
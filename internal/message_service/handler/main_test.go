@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc/metadata"
+
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.InitDefaultLogger()
+	os.Exit(m.Run())
+}
+
+// newTestHandler 创建连接到 miniredis 的 MessageHandler，数据库查询由 query 返回结果（nil 表示没有数据）
+func newTestHandler(t *testing.T, query stubQuery) (*MessageHandler, *miniredis.Miniredis) {
+	t.Helper()
+	return newTestHandlerWithExec(t, query, nil)
+}
+
+// newTestHandlerWithExec 与 newTestHandler 相同，数据库写入影响的行数由 exec 返回（nil 表示不影响任何行）
+func newTestHandlerWithExec(t *testing.T, query stubQuery, exec stubExec) (*MessageHandler, *miniredis.Miniredis) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	db := sql.OpenDB(stubConnector{query: query, exec: exec})
+	t.Cleanup(func() {
+		rdb.Close()
+		db.Close()
+	})
+
+	return &MessageHandler{
+		db:                   db,
+		rdb:                  rdb,
+		streamOp:             stream.NewStreamOperator(rdb),
+		recallWindow:         2 * time.Minute,
+		dedupWindow:          time.Hour,
+		groupFanoutThreshold: 500,
+	}, mr
+}
+
+// userContext 返回携带 userID 身份令牌的 gRPC 请求上下文
+func userContext(t *testing.T, userID string) context.Context {
+	t.Helper()
+	token, err := auth.GenerateToken(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+}
+
+// stubQuery 返回查询结果的列名和行
+type stubQuery func(query string, args []driver.Value) (columns []string, rows [][]driver.Value, err error)
+
+// stubExec 返回写入语句影响的行数
+type stubExec func(query string, args []driver.Value) (rowsAffected int64, err error)
+
+// stubConnector 不连接真实数据库的 database/sql 驱动，查询结果由 stubQuery 提供，写入结果由 stubExec 提供
+type stubConnector struct {
+	query stubQuery
+	exec  stubExec
+}
+
+func (c stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn(c), nil }
+func (c stubConnector) Driver() driver.Driver                        { return stubDriver{} }
+
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use sql.OpenDB") }
+
+type stubConn stubConnector
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) {
+	return stubStmt{query: query, conn: c}, nil
+}
+func (c stubConn) Close() error              { return nil }
+func (c stubConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+type stubStmt struct {
+	query string
+	conn  stubConn
+}
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if s.conn.exec == nil {
+		return driver.RowsAffected(0), nil
+	}
+	n, err := s.conn.exec(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(n), nil
+}
+
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if s.conn.query == nil {
+		return &stubRows{}, nil
+	}
+	columns, rows, err := s.conn.query(s.query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &stubRows{columns: columns, rows: rows}, nil
+}
+
+type stubRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *stubRows) Columns() []string { return r.columns }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
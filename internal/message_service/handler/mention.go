@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ChatIM/pkg/logger"
+)
+
+// groupMentions 校验后的群消息 @ 信息
+type groupMentions struct {
+	UserIDs []string // 被 @ 的成员（已去重，不含发送者本人）
+	All     bool     // @所有人
+}
+
+// resolveMentions 校验 @ 的成员均在群内，@所有人仅群主/管理员可用
+// 群主创建群时即为管理员，因此只需检查 role = 'admin'
+func (h *MessageHandler) resolveMentions(ctx context.Context, groupID, fromUserID string, userIDs []string, all bool, memberIDs []string) (*groupMentions, error) {
+	mentions := &groupMentions{All: all}
+
+	if all {
+		var role string
+		err := h.db.QueryRowContext(ctx,
+			"SELECT role FROM group_members WHERE group_id = ? AND user_id = ? AND is_deleted = 0",
+			groupID, fromUserID).Scan(&role)
+		if err != nil && err != sql.ErrNoRows {
+			logger.Error("Failed to check sender role for @all", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to check sender role")
+		}
+		if role != "admin" {
+			return nil, status.Errorf(codes.PermissionDenied, "only group owner or admins can mention all members")
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return mentions, nil
+	}
+
+	members := make(map[string]bool, len(memberIDs))
+	for _, id := range memberIDs {
+		members[id] = true
+	}
+
+	seen := make(map[string]bool, len(userIDs))
+	for _, id := range userIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || id == fromUserID || seen[id] {
+			continue
+		}
+		if !members[id] {
+			return nil, status.Errorf(codes.InvalidArgument, "mentioned user %s is not a group member", id)
+		}
+		seen[id] = true
+		mentions.UserIDs = append(mentions.UserIDs, id)
+	}
+
+	return mentions, nil
+}
+
+// empty 是否没有任何 @
+func (m *groupMentions) empty() bool {
+	return !m.All && len(m.UserIDs) == 0
+}
+
+// streamFields 写入 Stream 条目的 @ 字段，没有 @ 时返回 nil
+func (m *groupMentions) streamFields() map[string]interface{} {
+	if m.empty() {
+		return nil
+	}
+	fields := map[string]interface{}{
+		"mention_user_ids": strings.Join(m.UserIDs, ","),
+		"mention_all":      "false",
+	}
+	if m.All {
+		fields["mention_all"] = "true"
+	}
+	return fields
+}
+
+// recipients 需要收到提及提醒的成员（@所有人时为除发送者外的全部成员）
+func (m *groupMentions) recipients(memberIDs []string, fromUserID string) []string {
+	if !m.All {
+		return m.UserIDs
+	}
+	recipients := make([]string, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if id != fromUserID {
+			recipients = append(recipients, id)
+		}
+	}
+	return recipients
+}
+
+// userIDsJSON 写入数据库的被 @ 成员列表，没有时为 NULL
+func (m *groupMentions) userIDsJSON() sql.NullString {
+	if len(m.UserIDs) == 0 {
+		return sql.NullString{}
+	}
+	data, _ := json.Marshal(m.UserIDs)
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// parseMentionUserIDs 解析 Stream 中以逗号分隔的被 @ 成员列表
+func parseMentionUserIDs(v string) []string {
+	if v == "" {
+		return nil
+	}
+	return strings.Split(v, ",")
+}
+
+// mentionsUser 判断消息是否 @ 了指定用户
+func mentionsUser(mentionAll bool, mentionUserIDs []string, userID string) bool {
+	if mentionAll {
+		return true
+	}
+	for _, id := range mentionUserIDs {
+		if id == userID {
+			return true
+		}
+	}
+	return false
+}
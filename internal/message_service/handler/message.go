@@ -12,11 +12,15 @@ import (
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	pb "ChatIM/api/proto/message"
+	"ChatIM/internal/message_service/persister"
 	"ChatIM/pkg/auth"
+	"ChatIM/pkg/config"
 	"ChatIM/pkg/logger"
+	"ChatIM/pkg/metrics"
 	"ChatIM/pkg/stream"
 
 	"github.com/google/uuid"
@@ -25,105 +29,190 @@ import (
 
 type MessageHandler struct {
 	pb.UnimplementedMessageServiceServer
-	db       *sql.DB
-	rdb      *redis.Client
-	streamOp *stream.StreamOperator
+	db           *sql.DB
+	rdb          *redis.Client
+	streamOp     *stream.StreamOperator
+	recallWindow time.Duration
+	dedupWindow  time.Duration
+
+	groupFanoutThreshold int // 群成员数超过该值时使用读扩散
 }
 
-func NewMessageHandler(db *sql.DB, rdb *redis.Client) *MessageHandler {
+func NewMessageHandler(db *sql.DB, rdb *redis.Client, msgCfg config.MessageConfig) *MessageHandler {
+	recallWindow := time.Duration(msgCfg.RecallWindowSeconds) * time.Second
+	if recallWindow <= 0 {
+		recallWindow = 2 * time.Minute
+	}
+	dedupWindow := time.Duration(msgCfg.DedupWindowSeconds) * time.Second
+	if dedupWindow <= 0 {
+		dedupWindow = time.Hour
+	}
+	groupFanoutThreshold := msgCfg.GroupFanoutThreshold
+	if groupFanoutThreshold <= 0 {
+		groupFanoutThreshold = 500
+	}
+
 	return &MessageHandler{
-		db:       db,
-		rdb:      rdb,
-		streamOp: stream.NewStreamOperator(rdb),
+		db:                   db,
+		rdb:                  rdb,
+		streamOp:             stream.NewStreamOperator(rdb),
+		recallWindow:         recallWindow,
+		dedupWindow:          dedupWindow,
+		groupFanoutThreshold: groupFanoutThreshold,
 	}
 }
 
 // SendMessage 实现发送消息的接口（使用 Redis Stream）
+// 指定了晚于当前时间的 send_at 时消息进入定时队列，到期后由定时消息分发器发送
 func (h *MessageHandler) SendMessage(ctx context.Context, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
 	fromUserID, err := auth.GetUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
+
+	if isScheduled(req.SendAt) {
+		scheduled, err := h.schedulePrivateMessage(ctx, fromUserID, req)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.SendMessageResponse{
+			Code:      0,
+			Message:   "定时消息已创建",
+			Scheduled: scheduled,
+		}, nil
+	}
+
+	return h.sendPrivateMessage(ctx, fromUserID, req)
+}
+
+// sendPrivateMessage 立即发送私聊消息（写入 Stream、推送通知、落库）
+func (h *MessageHandler) sendPrivateMessage(ctx context.Context, fromUserID string, req *pb.SendMessageRequest) (*pb.SendMessageResponse, error) {
 	logger.Info("Sending private message",
 		zap.String("from_user_id", fromUserID),
 		zap.String("to_user_id", req.ToUserId))
 
-	msgID := uuid.New().String()
-	createdAt := time.Now().Format("2006-01-02 15:04:05")
-
-	// 1. 立即写入 Redis Stream（快速响应）
-	_, err = h.streamOp.AddPrivateMessage(ctx, msgID, fromUserID, req.ToUserId, req.Content)
+	// 0. 校验消息类型与负载，以及引用的消息
+	body, err := h.prepareMessageBody(ctx, req.MsgType, req.Content, req.Payload)
 	if err != nil {
-		logger.Error("Failed to add private message to stream", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "Failed to save message")
+		return nil, err
+	}
+	if req.ReplyToMsgId != "" {
+		body.Reply, err = h.buildReplySnapshot(ctx, fromUserID, req.ReplyToMsgId, "private", req.ToUserId)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	// 2. 更新双方的会话列表
-	conversationID := fmt.Sprintf("private:%s", req.ToUserId)
-	h.streamOp.UpdateConversationTime(ctx, fromUserID, fmt.Sprintf("private:%s", fromUserID))
-	h.streamOp.UpdateConversationTime(ctx, req.ToUserId, conversationID)
-
-	// 3. 发布消息通知到 Redis（通知 WebSocket 推送，包括发送者自己用于多设备同步）
-	go func() {
-		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
+	return h.deliverPrivateMessage(ctx, fromUserID, req, body)
+}
 
-		// 给接收者发送通知
-		notification := map[string]interface{}{
-			"msg_id":       msgID,
-			"to_user_id":   req.ToUserId,
-			"from_user_id": fromUserID,
-			"type":         "private",
-			"content":      req.Content,
-			"created_at":   time.Now().Unix(),
-		}
+// deliverPrivateMessage 发送已校验的私聊消息（写入 Stream、推送通知、落库）
+func (h *MessageHandler) deliverPrivateMessage(ctx context.Context, fromUserID string, req *pb.SendMessageRequest, body *messageBody) (*pb.SendMessageResponse, error) {
+	start := time.Now()
+	payloadJSON, err := body.storedPayloadJSON()
+	if err != nil {
+		return nil, err
+	}
 
-		notificationJSON, err := json.Marshal(notification)
+	msgID := uuid.New().String()
+	createdAt := time.Now().Format("2006-01-02 15:04:05")
+	// 会话开启了定时销毁时，消息带过期时间
+	expiresAt := h.messageExpiresAt(ctx, stream.ConversationKey("private", fromUserID, req.ToUserId))
+
+	// 按 client_msg_id 去重：客户端超时重试时直接返回首次发送的消息
+	sentRecord := stream.SentMessageRecord{
+		MsgID:     msgID,
+		CreatedAt: time.Now().Unix(),
+		ToUserID:  req.ToUserId,
+		Content:   body.Content,
+		MsgType:   body.MsgType,
+		Payload:   payloadJSON,
+	}
+	if req.ClientMsgId != "" {
+		sent, err := h.reserveClientMsgID(ctx, "private", fromUserID, req.ClientMsgId, sentRecord)
 		if err != nil {
-			logger.Warn("Failed to marshal notification", zap.Error(err))
-			return
+			return nil, err
 		}
+		if sent != nil {
+			logger.Info("Duplicate private message ignored",
+				zap.String("client_msg_id", req.ClientMsgId),
+				zap.String("msg_id", sent.MsgID))
+			return &pb.SendMessageResponse{
+				Code:      0,
+				Message:   "消息已发送",
+				Msg:       duplicateMessage(fromUserID, sent),
+				StreamId:  sent.StreamID,
+				Duplicate: true,
+			}, nil
+		}
+	}
 
-		err = h.rdb.Publish(notificationCtx, "message_notifications", notificationJSON).Err()
-		if err != nil {
-			logger.Warn("Failed to publish notification", zap.Error(err))
-		} else {
-			logger.Debug("Notification published",
-				zap.String("msg_id", msgID),
-				zap.String("to_user_id", req.ToUserId))
-		}
-
-		// 也给发送者发送通知（用于多设备同步/消息回显）
-		// senderNotification := map[string]interface{}{
-		// 	"msg_id":       msgID,
-		// 	"to_user_id":   fromUserID,
-		// 	"from_user_id": fromUserID,
-		// 	"type":         "private",
-		// 	"content":      req.Content,
-		// 	"created_at":   time.Now().Unix(),
-		// 	"is_sender":    true, // 标记这是发送者自己的消息
-		// }
-
-		// senderNotificationJSON, err := json.Marshal(senderNotification)
-		// if err == nil {
-		// 	h.rdb.Publish(notificationCtx, "message_notifications", senderNotificationJSON).Err()
-		// }
-	}()
+	// 1. 构造通知（通知 WebSocket 推送）：发给接收者，同时回显到发送者的其他设备（多设备同步），
+	// 发送消息的设备已从响应中拿到消息，由网关按 from_device_id 跳过
+	notification := map[string]interface{}{
+		"msg_id":         msgID,
+		"from_user_id":   fromUserID,
+		"from_device_id": deviceIDFromContext(ctx),
+		"to_user_id":     req.ToUserId,
+		"type":           "private",
+		"content":        body.Content,
+		"msg_type":       body.MsgType,
+		"payload":        body.Payload,
+		"reply_to":       body.Reply,
+		"created_at":     time.Now().Unix(),
+	}
+	if expiresAt > 0 {
+		notification["expires_at"] = expiresAt
+	}
+	recipients := []string{req.ToUserId}
+	if req.ToUserId != fromUserID {
+		recipients = append(recipients, fromUserID)
+	}
+	notificationJSON, err := marshalNotification(recipients, notification)
+	if err != nil {
+		logger.Warn("Failed to marshal notification", zap.Error(err))
+	}
 
-	// 4. 异步写入数据库（不阻塞用户）
-	go func() {
-		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	// 2. 写入双方的 Redis Stream，并在同一个 pipeline 中更新双方的会话列表、发布通知
+	conversations := []stream.ConversationTouch{{UserID: fromUserID, ConversationID: "private:" + req.ToUserId}}
+	if req.ToUserId != fromUserID {
+		conversations = append(conversations, stream.ConversationTouch{UserID: req.ToUserId, ConversationID: "private:" + fromUserID})
+	}
+	streamID, err := h.streamOp.AddPrivateMessage(ctx, msgID, fromUserID, req.ToUserId, body.Content, body.MsgType, payloadJSON, expiresAt, &stream.Delivery{
+		Conversations: conversations,
+		Notification:  notificationJSON,
+	})
+	if err != nil {
+		logger.Error("Failed to add private message to stream", zap.Error(err))
+		h.releaseClientMsgID(fromUserID, req.ClientMsgId)
+		observeSend("private", start, false)
+		return nil, status.Errorf(codes.Internal, "Failed to save message")
+	}
 
-		query := `INSERT INTO messages (id, from_user_id, to_user_id, content, created_at) VALUES (?, ?, ?, ?, ?)`
-		_, err := h.db.ExecContext(dbCtx, query, msgID, fromUserID, req.ToUserId, req.Content, createdAt)
-		if err != nil {
-			logger.Warn("Failed to save message to database", zap.Error(err))
-		} else {
-			logger.Debug("Message saved to database", zap.String("msg_id", msgID))
-		}
-	}()
+	// 3. 写入落库队列，由 persister 异步批量写入数据库（不阻塞用户）；写入失败时发送失败，
+	// client_msg_id 不记录为已发送，客户端可以重试
+	err = h.persist(ctx, persister.Message{
+		Kind:         persister.KindPrivate,
+		ID:           msgID,
+		ClientMsgID:  req.ClientMsgId,
+		FromUserID:   fromUserID,
+		ToUserID:     req.ToUserId,
+		Content:      body.Content,
+		MsgType:      body.MsgType,
+		Payload:      payloadJSON,
+		ReplyToMsgID: req.ReplyToMsgId,
+		CreatedAt:    createdAt,
+		ExpiresAt:    formatExpiresAt(expiresAt),
+	})
+	if err != nil {
+		h.releaseClientMsgID(fromUserID, req.ClientMsgId)
+		observeSend("private", start, false)
+		return nil, status.Errorf(codes.Internal, "Failed to save message")
+	}
+	sentRecord.StreamID = streamID
+	h.completeClientMsgID(ctx, fromUserID, req.ClientMsgId, sentRecord)
 
+	observeSend("private", start, true)
 	logger.Info("Message sent successfully", zap.String("msg_id", msgID))
 
 	return &pb.SendMessageResponse{
@@ -133,13 +222,19 @@ func (h *MessageHandler) SendMessage(ctx context.Context, req *pb.SendMessageReq
 			Id:         msgID,
 			FromUserId: fromUserID,
 			ToUserId:   req.ToUserId,
-			Content:    req.Content,
+			Content:    body.Content,
 			CreatedAt:  time.Now().Unix(),
+			MsgType:    body.MsgType,
+			Payload:    body.Payload,
+			ReplyTo:    body.Reply,
+			ExpiresAt:  expiresAt,
 		},
+		StreamId: streamID,
 	}, nil
 }
 
 // SendGroupMessage 发送群聊消息（写入每个成员的 Stream 并异步持久化到数据库）
+// 指定了晚于当前时间的 send_at 时消息进入定时队列
 func (h *MessageHandler) SendGroupMessage(ctx context.Context, req *pb.SendGroupMessageRequest) (*pb.SendGroupMessageResponse, error) {
 	fromUserID, err := auth.GetUserID(ctx)
 	if err != nil {
@@ -149,10 +244,49 @@ func (h *MessageHandler) SendGroupMessage(ctx context.Context, req *pb.SendGroup
 		return nil, status.Errorf(codes.InvalidArgument, "group_id is required")
 	}
 
+	if isScheduled(req.SendAt) {
+		scheduled, err := h.scheduleGroupMessage(ctx, fromUserID, req)
+		if err != nil {
+			return nil, err
+		}
+		return &pb.SendGroupMessageResponse{
+			Code:      0,
+			Message:   "定时消息已创建",
+			Scheduled: scheduled,
+		}, nil
+	}
+
+	return h.sendGroupMessage(ctx, fromUserID, req)
+}
+
+// sendGroupMessage 立即发送群聊消息
+func (h *MessageHandler) sendGroupMessage(ctx context.Context, fromUserID string, req *pb.SendGroupMessageRequest) (*pb.SendGroupMessageResponse, error) {
 	logger.Info("Sending group message",
 		zap.String("from_user_id", fromUserID),
 		zap.String("group_id", req.GroupId))
 
+	body, err := h.prepareMessageBody(ctx, req.MsgType, req.Content, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReplyToMsgId != "" {
+		body.Reply, err = h.buildReplySnapshot(ctx, fromUserID, req.ReplyToMsgId, "group", req.GroupId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return h.deliverGroupMessage(ctx, fromUserID, req, body)
+}
+
+// deliverGroupMessage 发送已校验的群聊消息（写入所有成员的 Stream、推送通知、落库）
+func (h *MessageHandler) deliverGroupMessage(ctx context.Context, fromUserID string, req *pb.SendGroupMessageRequest, body *messageBody) (*pb.SendGroupMessageResponse, error) {
+	start := time.Now()
+	payloadJSON, err := body.storedPayloadJSON()
+	if err != nil {
+		return nil, err
+	}
+
 	msgID := uuid.New().String()
 	createdAt := time.Now().Format("2006-01-02 15:04:05")
 
@@ -167,93 +301,164 @@ func (h *MessageHandler) SendGroupMessage(ctx context.Context, req *pb.SendGroup
 		return nil, status.Errorf(codes.NotFound, "Group has no members")
 	}
 
-	// 2. 写入所有成员的 Redis Stream (统一使用 stream:private:{user_id})
-	err = h.streamOp.AddGroupMessageToMembers(ctx, msgID, req.GroupId, fromUserID, req.Content, "text", memberIDs)
+	mentions, err := h.resolveMentions(ctx, req.GroupId, fromUserID, req.MentionUserIds, req.MentionAll, memberIDs)
 	if err != nil {
-		logger.Error("Failed to add group message to members' streams", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "Failed to save group message")
+		return nil, err
 	}
 
-	// 3. 更新所有成员的会话列表
-	conversationID := fmt.Sprintf("group:%s", req.GroupId)
-	for _, memberID := range memberIDs {
-		h.streamOp.UpdateConversationTime(ctx, memberID, conversationID)
+	// 群开启了定时销毁时，消息带过期时间
+	expiresAt := h.messageExpiresAt(ctx, stream.ConversationKey("group", fromUserID, req.GroupId))
+
+	// 按 client_msg_id 去重：客户端超时重试时直接返回首次发送的消息，避免在每个成员 Stream 中重复写入
+	sentRecord := stream.SentMessageRecord{
+		MsgID:          msgID,
+		CreatedAt:      time.Now().Unix(),
+		GroupID:        req.GroupId,
+		Content:        body.Content,
+		MsgType:        body.MsgType,
+		Payload:        payloadJSON,
+		MentionUserIDs: mentions.UserIDs,
+		MentionAll:     mentions.All,
+	}
+	if req.ClientMsgId != "" {
+		sent, err := h.reserveClientMsgID(ctx, "group", fromUserID, req.ClientMsgId, sentRecord)
+		if err != nil {
+			return nil, err
+		}
+		if sent != nil {
+			logger.Info("Duplicate group message ignored",
+				zap.String("client_msg_id", req.ClientMsgId),
+				zap.String("msg_id", sent.MsgID))
+			return &pb.SendGroupMessageResponse{
+				Code:      0,
+				Message:   "群聊消息已发送",
+				Msg:       duplicateGroupMessage(fromUserID, sent),
+				StreamId:  sent.StreamID,
+				Duplicate: true,
+			}, nil
+		}
 	}
 
-	// 4. 发布群消息通知到 Redis（通知所有在线成员，包括发送者用于多设备同步）
-	go func() {
-		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-		defer cancel()
-
-		// 给每个成员发送通知
-		for _, memberID := range memberIDs {
-			if memberID == fromUserID {
-				continue
-			}
-			notification := map[string]interface{}{
-				"msg_id":       msgID,
-				"to_user_id":   memberID,
-				"from_user_id": fromUserID,
-				"group_id":     req.GroupId,
-				"type":         "group",
-				"content":      req.Content,
-				"created_at":   time.Now().Unix(),
-			}
+	// 2. 构造群消息通知（一条通知携带所有接收者，由各网关推送给连接在本实例上的成员）
+	// 接收者包含发送者自己，用于同步到发送者的其他设备，发送消息的设备由网关跳过
+	notification := map[string]interface{}{
+		"msg_id":         msgID,
+		"from_user_id":   fromUserID,
+		"from_device_id": deviceIDFromContext(ctx),
+		"group_id":       req.GroupId,
+		"type":           "group",
+		"content":        body.Content,
+		"msg_type":       body.MsgType,
+		"payload":        body.Payload,
+		"reply_to":       body.Reply,
+		"created_at":     time.Now().Unix(),
+	}
+	if !mentions.empty() {
+		notification["mention_user_ids"] = mentions.UserIDs
+		notification["mention_all"] = mentions.All
+	}
+	if expiresAt > 0 {
+		notification["expires_at"] = expiresAt
+	}
+	notificationJSON, err := marshalNotification(memberIDs, notification)
+	if err != nil {
+		logger.Warn("Failed to marshal group notification", zap.Error(err))
+	}
 
-			// // 标记发送者自己的消息
-			// if memberID == fromUserID {
-			// 	notification["is_sender"] = true
-			// }
-
-			notificationJSON, err := json.Marshal(notification)
-			if err != nil {
-				logger.Warn("Failed to marshal notification for member",
-					zap.String("member_id", memberID),
-					zap.Error(err))
-				continue
-			}
+	conversationID := fmt.Sprintf("group:%s", req.GroupId)
+	conversations := make([]stream.ConversationTouch, len(memberIDs))
+	for i, memberID := range memberIDs {
+		conversations[i] = stream.ConversationTouch{UserID: memberID, ConversationID: conversationID}
+	}
+	delivery := &stream.Delivery{Conversations: conversations, Notification: notificationJSON}
+
+	// 3. 写入 Redis Stream 并在同一个 pipeline 中更新所有成员的会话列表、发布通知：
+	// 普通群写入每个成员的 stream:private:{user_id}（写扩散），
+	// 成员数超过阈值的群只写入一次 stream:group:{group_id}，成员按群会话游标读取（读扩散）
+	readFanout := len(memberIDs) > h.groupFanoutThreshold
+	var streamID string
+	if readFanout {
+		streamID, err = h.streamOp.AddGroupMessage(ctx, msgID, req.GroupId, fromUserID, body.Content, body.MsgType, payloadJSON, mentions.streamFields(), expiresAt, delivery)
+	} else {
+		streamID, err = h.streamOp.AddGroupMessageToMembers(ctx, msgID, req.GroupId, fromUserID, body.Content, body.MsgType, payloadJSON, memberIDs, mentions.streamFields(), expiresAt, delivery)
+	}
+	if err != nil {
+		logger.Error("Failed to add group message to stream", zap.Bool("read_fanout", readFanout), zap.Error(err))
+		h.releaseClientMsgID(fromUserID, req.ClientMsgId)
+		observeSend("group", start, false)
+		return nil, status.Errorf(codes.Internal, "Failed to save group message")
+	}
 
-			err = h.rdb.Publish(notificationCtx, "message_notifications", notificationJSON).Err()
-			if err != nil {
-				logger.Warn("Failed to publish notification to member",
-					zap.String("member_id", memberID),
-					zap.Error(err))
-			}
+	// 4. 写入落库队列，写入失败时发送失败
+	err = h.persist(ctx, persister.Message{
+		Kind:           persister.KindGroup,
+		ID:             msgID,
+		ClientMsgID:    req.ClientMsgId,
+		FromUserID:     fromUserID,
+		GroupID:        req.GroupId,
+		Content:        body.Content,
+		MsgType:        body.MsgType,
+		Payload:        payloadJSON,
+		ReplyToMsgID:   req.ReplyToMsgId,
+		MentionUserIDs: mentions.userIDsJSON().String,
+		MentionAll:     mentions.All,
+		CreatedAt:      createdAt,
+		ExpiresAt:      formatExpiresAt(expiresAt),
+	})
+	if err != nil {
+		h.releaseClientMsgID(fromUserID, req.ClientMsgId)
+		observeSend("group", start, false)
+		return nil, status.Errorf(codes.Internal, "Failed to save group message")
+	}
+	sentRecord.StreamID = streamID
+	h.completeClientMsgID(ctx, fromUserID, req.ClientMsgId, sentRecord)
+
+	// 5. 被 @ 的成员累加提及计数（会话列表据此展示"有人@我"），并额外收到一条提及提醒
+	mentionRecipients := mentions.recipients(memberIDs, fromUserID)
+	if len(mentionRecipients) > 0 {
+		if err := h.streamOp.IncrMentionCount(ctx, req.GroupId, mentionRecipients); err != nil {
+			logger.Warn("Failed to increment mention count", zap.Error(err))
 		}
 
-		logger.Debug("Notifications published for group message",
-			zap.String("msg_id", msgID),
-			zap.Int("member_count", len(memberIDs)))
-	}()
-
-	// 5. 异步写入数据库
-	go func() {
-		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+		go func() {
+			notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
 
-		query := `INSERT INTO group_messages (id, group_id, from_user_id, content, created_at) VALUES (?, ?, ?, ?, ?)`
-		_, err := h.db.ExecContext(dbCtx, query, msgID, req.GroupId, fromUserID, req.Content, createdAt)
-		if err != nil {
-			logger.Warn("Failed to save group message to database", zap.Error(err))
-		} else {
-			logger.Debug("Group message saved to database", zap.String("msg_id", msgID))
-		}
-	}()
+			h.publishEvent(notificationCtx, mentionRecipients, map[string]interface{}{
+				"type":         "mention",
+				"msg_id":       msgID,
+				"group_id":     req.GroupId,
+				"from_user_id": fromUserID,
+				"content":      body.Content,
+				"mention_all":  mentions.All,
+				"created_at":   time.Now().Unix(),
+			})
+		}()
+	}
 
+	observeSend("group", start, true)
 	logger.Info("Group message sent",
 		zap.String("msg_id", msgID),
-		zap.Int("member_count", len(memberIDs)))
+		zap.Int("member_count", len(memberIDs)),
+		zap.Bool("read_fanout", readFanout))
 
 	return &pb.SendGroupMessageResponse{
 		Code:    0,
 		Message: "群聊消息发送成功",
 		Msg: &pb.GroupMessage{
-			Id:         msgID,
-			GroupId:    req.GroupId,
-			FromUserId: fromUserID,
-			Content:    req.Content,
-			CreatedAt:  time.Now().Unix(),
+			Id:             msgID,
+			GroupId:        req.GroupId,
+			FromUserId:     fromUserID,
+			Content:        body.Content,
+			CreatedAt:      time.Now().Unix(),
+			MsgType:        body.MsgType,
+			Payload:        body.Payload,
+			ReplyTo:        body.Reply,
+			MentionUserIds: mentions.UserIDs,
+			MentionAll:     mentions.All,
+			ExpiresAt:      expiresAt,
 		},
+		StreamId: streamID,
 	}, nil
 }
 
@@ -265,9 +470,21 @@ func (h *MessageHandler) getGroupMembers(ctx context.Context, groupID string) ([
 		return cachedMembers, nil
 	}
 
-	// 从数据库读取
+	joinedAt, err := h.loadGroupMembers(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	members := make([]string, 0, len(joinedAt))
+	for userID := range joinedAt {
+		members = append(members, userID)
+	}
+	return members, nil
+}
+
+// loadGroupMembers 从数据库读取群成员及入群时间（Unix 秒），并写入群成员列表和入群时间缓存
+func (h *MessageHandler) loadGroupMembers(ctx context.Context, groupID string) (map[string]int64, error) {
 	rows, err := h.db.QueryContext(ctx,
-		"SELECT user_id FROM group_members WHERE group_id = ? AND is_deleted = 0",
+		"SELECT user_id, IFNULL(UNIX_TIMESTAMP(joined_at), 0) FROM group_members WHERE group_id = ? AND is_deleted = 0",
 		groupID)
 	if err != nil {
 		logger.Error("Error querying group members", zap.Error(err))
@@ -276,21 +493,378 @@ func (h *MessageHandler) getGroupMembers(ctx context.Context, groupID string) ([
 	defer rows.Close()
 
 	var members []string
+	joinedAt := make(map[string]int64)
 	for rows.Next() {
 		var userID string
-		if err := rows.Scan(&userID); err != nil {
+		var joined int64
+		if err := rows.Scan(&userID, &joined); err != nil {
 			continue
 		}
 		members = append(members, userID)
+		joinedAt[userID] = joined
 	}
 
 	// 保存到缓存
 	h.streamOp.CacheGroupMembers(ctx, groupID, members)
+	h.streamOp.CacheGroupJoinTimes(ctx, groupID, joinedAt)
 
-	return members, nil
+	return joinedAt, nil
+}
+
+// messageRef 定位到的消息基本信息（用于撤回等需要校验消息归属的操作）
+type messageRef struct {
+	ID         string
+	Type       string // "private" 或 "group"
+	FromUserID string
+	ToUserID   string
+	GroupID    string
+	Content    string
+	MsgType    string
+	Payload    string // Stream / 数据库中保存的负载 JSON
+	CreatedAt  int64
+	ExpiresAt  int64 // 定时销毁的过期时间，未开启时为 0
+	Recalled   bool  // 数据库中的撤回标记（从数据库定位到消息时有效）
+}
+
+// locateMessage 查找当前用户可见的一条消息
+// 优先在用户自己的 Stream 中查找（私聊双方和写扩散群所有成员的 Stream 中都有该消息），
+// 其次按消息索引在读扩散群的消息流中查找（要求当前用户是群成员，且消息发送于入群之后），找不到时（例如已被修剪）回退到数据库
+func (h *MessageHandler) locateMessage(ctx context.Context, userID, msgID string) (*messageRef, error) {
+	entry, err := h.streamOp.FindMessageInStream(ctx, userID, msgID, 1000)
+	if err != nil {
+		logger.Warn("Failed to search message in stream, fallback to database", zap.Error(err))
+	}
+	if entry != nil {
+		return streamEntryToRef(msgID, entry), nil
+	}
+
+	entry, groupID, err := h.streamOp.FindMessageInGroupStream(ctx, msgID, 1000)
+	if err != nil {
+		logger.Warn("Failed to search message in group stream, fallback to database", zap.Error(err))
+	}
+	if entry != nil {
+		// 群消息流包含用户入群之前的消息，入群前的消息对用户不可见
+		var joinedAt int64
+		err := h.db.QueryRowContext(ctx,
+			"SELECT IFNULL(UNIX_TIMESTAMP(joined_at), 0) FROM group_members WHERE group_id = ? AND user_id = ? AND is_deleted = 0",
+			groupID, userID).Scan(&joinedAt)
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "message not found")
+		}
+		if err != nil {
+			logger.Error("Failed to check group membership", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to check group membership")
+		}
+		ref := streamEntryToRef(msgID, entry)
+		if ref.CreatedAt < joinedAt {
+			return nil, status.Errorf(codes.NotFound, "message not found")
+		}
+		return ref, nil
+	}
+
+	// 私聊消息
+	var ref messageRef
+	var createdAt time.Time
+	err = h.db.QueryRowContext(ctx,
+		"SELECT from_user_id, to_user_id, IFNULL(content, ''), IFNULL(msg_type, 'text'), IFNULL(payload, ''), created_at, IFNULL(UNIX_TIMESTAMP(expires_at), 0), IFNULL(is_recalled, FALSE) FROM messages WHERE id = ? AND (from_user_id = ? OR to_user_id = ?)",
+		msgID, userID, userID).Scan(&ref.FromUserID, &ref.ToUserID, &ref.Content, &ref.MsgType, &ref.Payload, &createdAt, &ref.ExpiresAt, &ref.Recalled)
+	if err == nil {
+		ref.ID = msgID
+		ref.Type = "private"
+		ref.CreatedAt = createdAt.Unix()
+		return &ref, nil
+	}
+	if err != sql.ErrNoRows {
+		logger.Error("Failed to query private message", zap.String("msg_id", msgID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to query message")
+	}
+
+	// 群聊消息（要求当前用户是群成员，且消息发送于入群之后）
+	err = h.db.QueryRowContext(ctx, `
+		SELECT gm.group_id, gm.from_user_id, gm.content, IFNULL(gm.msg_type, 'text'), IFNULL(gm.payload, ''), gm.created_at,
+			IFNULL(UNIX_TIMESTAMP(gm.expires_at), 0), IFNULL(gm.is_recalled, FALSE)
+		FROM group_messages gm
+		JOIN group_members m ON m.group_id = gm.group_id AND m.user_id = ? AND m.is_deleted = 0
+		WHERE gm.id = ? AND gm.created_at >= m.joined_at`,
+		userID, msgID).Scan(&ref.GroupID, &ref.FromUserID, &ref.Content, &ref.MsgType, &ref.Payload, &createdAt, &ref.ExpiresAt, &ref.Recalled)
+	if err == nil {
+		ref.ID = msgID
+		ref.Type = "group"
+		ref.CreatedAt = createdAt.Unix()
+		return &ref, nil
+	}
+	if err != sql.ErrNoRows {
+		logger.Error("Failed to query group message", zap.String("msg_id", msgID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to query message")
+	}
+
+	return nil, status.Errorf(codes.NotFound, "message not found")
+}
+
+// isRecalled 判断消息是否已撤回：Redis 中的撤回标记随消息状态过期，从数据库定位到的消息同时参考数据库中的标记
+func (h *MessageHandler) isRecalled(ctx context.Context, ref *messageRef) (bool, error) {
+	if ref.Recalled {
+		return true, nil
+	}
+	return h.streamOp.IsMessageRecalled(ctx, ref.ID)
+}
+
+// streamEntryToRef 将 Stream 条目转换为 messageRef
+func streamEntryToRef(msgID string, entry *redis.XMessage) *messageRef {
+	return &messageRef{
+		ID:         msgID,
+		Type:       getString(entry.Values["type"]),
+		FromUserID: getString(entry.Values["from_user_id"]),
+		ToUserID:   getString(entry.Values["to_user_id"]),
+		GroupID:    getString(entry.Values["group_id"]),
+		Content:    getString(entry.Values["content"]),
+		MsgType:    msgTypeOrText(getString(entry.Values["msg_type"])),
+		Payload:    getString(entry.Values["payload"]),
+		CreatedAt:  getInt64(entry.Values["created_at"]),
+		ExpiresAt:  getInt64(entry.Values["expires_at"]),
+	}
+}
+
+const (
+	// persistEnqueueAttempts 写入落库队列的最大尝试次数
+	persistEnqueueAttempts = 3
+	// persistEnqueueBackoff 首次重试前的等待时间，之后每次翻倍
+	persistEnqueueBackoff = 50 * time.Millisecond
+)
+
+// persist 将消息写入落库队列，失败时按指数退避重试 persistEnqueueAttempts 次，仍失败时返回错误
+func (h *MessageHandler) persist(ctx context.Context, msg persister.Message) error {
+	backoff := persistEnqueueBackoff
+	var err error
+	for attempt := 0; attempt < persistEnqueueAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+		if err = persister.Enqueue(ctx, h.rdb, msg); err == nil {
+			return nil
+		}
+		logger.Warn("Failed to enqueue message for persistence",
+			zap.String("msg_id", msg.ID),
+			zap.Int("attempt", attempt+1),
+			zap.Error(err))
+	}
+	logger.Error("Giving up enqueueing message for persistence", zap.String("msg_id", msg.ID), zap.Error(err))
+	return err
+}
+
+// streamEntryToUnified 将 Stream 条目转换为统一消息格式（is_read 由调用方按游标判断）
+func streamEntryToUnified(msg redis.XMessage) *pb.UnifiedMessage {
+	payload, replyTo := decodeStoredPayload(getString(msg.Values["payload"]))
+	unifiedMsg := &pb.UnifiedMessage{
+		Id:         getString(msg.Values["id"]),
+		Type:       getString(msg.Values["type"]),
+		FromUserId: getString(msg.Values["from_user_id"]),
+		ToUserId:   getString(msg.Values["to_user_id"]),
+		GroupId:    getString(msg.Values["group_id"]),
+		Content:    getString(msg.Values["content"]),
+		CreatedAt:  getInt64(msg.Values["created_at"]),
+		StreamId:   msg.ID,
+		MsgType:    msgTypeOrText(getString(msg.Values["msg_type"])),
+		Payload:    payload,
+		ReplyTo:    replyTo,
+		ExpiresAt:  getInt64(msg.Values["expires_at"]),
+	}
+	if unifiedMsg.Type == "group" {
+		unifiedMsg.MentionUserIds = parseMentionUserIDs(getString(msg.Values["mention_user_ids"]))
+		unifiedMsg.MentionAll = getString(msg.Values["mention_all"]) == "true"
+	}
+	return unifiedMsg
+}
+
+// applyMessageStates 应用消息的撤回/编辑状态（已撤回的消息不返回原始内容，编辑过的消息返回最新内容）及表情回应
+func (h *MessageHandler) applyMessageStates(ctx context.Context, userID string, msgs []*pb.UnifiedMessage) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	msgIDs := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		msgIDs = append(msgIDs, m.Id)
+	}
+	recalled, err := h.streamOp.GetRecalledMessages(ctx, msgIDs)
+	if err != nil {
+		logger.Warn("Failed to check recalled messages", zap.Error(err))
+	}
+	edits, err := h.streamOp.GetMessageEdits(ctx, msgIDs)
+	if err != nil {
+		logger.Warn("Failed to get message edits", zap.Error(err))
+	}
+	reactions, err := h.streamOp.GetMessageReactions(ctx, msgIDs, userID)
+	if err != nil {
+		logger.Warn("Failed to get message reactions", zap.Error(err))
+	}
+
+	// 超出 Redis 状态保留范围的消息，撤回 / 编辑状态和表情回应以数据库为准
+	var expired []*pb.UnifiedMessage
+	var expiredIDs []string
+	for _, m := range msgs {
+		if stream.MessageStateExpired(m.CreatedAt) {
+			expired = append(expired, m)
+			expiredIDs = append(expiredIDs, m.Id)
+		}
+	}
+	if len(expired) > 0 {
+		h.applyPersistedStates(ctx, expired)
+		persisted, err := h.reactionsFromDB(ctx, expiredIDs, userID)
+		if err != nil {
+			logger.Warn("Failed to get message reactions from database", zap.Error(err))
+		} else {
+			if reactions == nil {
+				reactions = make(map[string][]stream.ReactionSummary)
+			}
+			for _, id := range expiredIDs {
+				reactions[id] = persisted[id]
+			}
+		}
+	}
+
+	h.applyQuotedRecalls(ctx, msgs)
+	for _, m := range msgs {
+		if recalled[m.Id] || m.IsRecalled {
+			m.IsRecalled = true
+			m.Content = ""
+			m.Payload = nil
+			m.ReplyTo = nil
+			continue
+		}
+		m.Reactions = toPbReactions(reactions[m.Id])
+		if edit, ok := edits[m.Id]; ok {
+			m.IsEdited = true
+			m.EditedAt = edit.EditedAt
+			m.Content = edit.Content
+		}
+	}
+}
+
+// applyPersistedStates 按数据库中的记录应用消息的撤回 / 编辑状态
+func (h *MessageHandler) applyPersistedStates(ctx context.Context, msgs []*pb.UnifiedMessage) {
+	byID := make(map[string]*pb.UnifiedMessage, len(msgs))
+	idsByKind := make(map[string][]interface{})
+	for _, m := range msgs {
+		byID[m.Id] = m
+		idsByKind[m.Type] = append(idsByKind[m.Type], m.Id)
+	}
+
+	for kind, ids := range idsByKind {
+		query := fmt.Sprintf("SELECT id, IFNULL(is_recalled, FALSE), IFNULL(content, ''), UNIX_TIMESTAMP(edited_at) FROM %s WHERE id IN (%s)",
+			persister.TableName(kind), strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "))
+		rows, err := h.db.QueryContext(ctx, query, ids...)
+		if err != nil {
+			logger.Warn("Failed to get message states from database", zap.Error(err))
+			continue
+		}
+		for rows.Next() {
+			var (
+				id       string
+				recalled bool
+				content  string
+				editedAt sql.NullInt64
+			)
+			if err := rows.Scan(&id, &recalled, &content, &editedAt); err != nil {
+				logger.Warn("Failed to scan message state", zap.Error(err))
+				break
+			}
+			m := byID[id]
+			if recalled {
+				m.IsRecalled = true
+			}
+			if editedAt.Valid {
+				m.IsEdited = true
+				m.EditedAt = editedAt.Int64
+				m.Content = content
+			}
+		}
+		rows.Close()
+	}
+}
+
+// conversationMembers 返回消息所在会话的所有参与者ID
+func (h *MessageHandler) conversationMembers(ctx context.Context, ref *messageRef) ([]string, error) {
+	if ref.Type == "group" {
+		return h.getGroupMembers(ctx, ref.GroupID)
+	}
+	if ref.FromUserID == ref.ToUserID {
+		return []string{ref.FromUserID}, nil
+	}
+	return []string{ref.FromUserID, ref.ToUserID}, nil
+}
+
+// publishEvent 向指定用户发布事件通知（经 message_notifications 频道由 WebSocket 推送）
+// event 中无需包含接收者，由 marshalNotification 填充
+func (h *MessageHandler) publishEvent(ctx context.Context, toUserIDs []string, event map[string]interface{}) {
+	if len(toUserIDs) == 0 {
+		return
+	}
+
+	notificationJSON, err := marshalNotification(toUserIDs, event)
+	if err != nil {
+		logger.Warn("Failed to marshal event notification", zap.Error(err))
+		return
+	}
+
+	if err := h.rdb.Publish(ctx, stream.NotificationChannel, notificationJSON).Err(); err != nil {
+		logger.Warn("Failed to publish event notification",
+			zap.Int("recipient_count", len(toUserIDs)),
+			zap.Any("type", event["type"]),
+			zap.Error(err))
+	}
+}
+
+// marshalNotification 为事件填充接收者并序列化：只有一个接收者时填充 to_user_id，
+// 多个接收者时合并为一条携带 to_user_ids 的通知，由各网关实例只推送给连接在本实例上的用户；没有接收者时返回 nil
+func marshalNotification(toUserIDs []string, event map[string]interface{}) ([]byte, error) {
+	if len(toUserIDs) == 0 {
+		return nil, nil
+	}
+
+	notification := make(map[string]interface{}, len(event)+1)
+	for k, v := range event {
+		notification[k] = v
+	}
+	if len(toUserIDs) == 1 {
+		notification["to_user_id"] = toUserIDs[0]
+	} else {
+		notification["to_user_ids"] = toUserIDs
+	}
+	return json.Marshal(notification)
+}
+
+// deviceIDFromContext 返回网关透传的发起请求的设备ID（metadata x-device-id），未携带时为空
+func deviceIDFromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get("x-device-id"); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// observeSend 记录消息发送结果和耗时（chatim_messages_sent_total / chatim_message_send_duration_seconds）
+func observeSend(convType string, start time.Time, success bool) {
+	result := "success"
+	if !success {
+		result = "failed"
+	}
+	metrics.MessagesSentTotal.WithLabelValues(convType, result).Inc()
+	if success {
+		metrics.MessageSendDuration.WithLabelValues(convType).Observe(time.Since(start).Seconds())
+	}
 }
 
 // PullMessages 拉取按会话分组的消息（基于游标的增量拉取）
+// 每页最多读取 limit 条 Stream 条目，还有更新的消息时返回下一页的 from_stream_id
 func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesRequest) (*pb.PullMessagesResponse, error) {
 	// 1. 获取当前用户 ID
 	userID, err := auth.GetUserID(ctx)
@@ -311,31 +885,65 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 		limit = 100
 	}
 
-	// 2. 确定起始游标
+	// 2. 确定起始游标：未指定时从各会话已读游标中最小的位置开始
+	cursors, err := h.streamOp.GetConversationCursors(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get conversation cursors, fallback to beginning", zap.Error(err))
+	}
 	startCursor := req.FromStreamId
 	if startCursor == "" {
-		cursor, err := h.streamOp.GetUserCursor(ctx, userID)
-		if err != nil {
-			logger.Warn("Failed to get user cursor, fallback to beginning", zap.Error(err))
-			cursor = "0-0"
-		}
-		startCursor = cursor
+		startCursor = cursors.Min()
 	}
 
 	// 3. 从 Redis Stream 读取消息（增量拉取）
 	streamKey := fmt.Sprintf("stream:private:%s", userID)
 
-	// 使用 XRange 进行增量拉取：从 startCursor 之后开始
-	messages, err := h.rdb.XRange(ctx, streamKey, "("+startCursor, "+").Result()
+	// 使用 XRangeN 进行增量拉取：从 startCursor 之后开始，多读一条用于判断是否还有下一页
+	messages, err := h.rdb.XRangeN(ctx, streamKey, "("+startCursor, "+", limit+1).Result()
+	streamRead := err == nil
 	if err != nil {
 		logger.Warn("Failed to read from stream", zap.Error(err))
 		messages = []redis.XMessage{} // 容错处理
 	}
 
+	// 合并读扩散群的消息流，按 Stream ID（毫秒时间戳）排序后与个人流统一处理
+	groupEntries, groupsComplete := h.readFanoutGroupEntries(ctx, userID, req.FromStreamId, cursors, limit+1)
+	if len(groupEntries) > 0 {
+		messages = append(messages, groupEntries...)
+		sort.SliceStable(messages, func(i, j int) bool {
+			return stream.CompareStreamIDs(messages[i].ID, messages[j].ID) < 0
+		})
+	}
+
+	// 每页最多 limit 条，下一页从本页最后一条之后继续
+	hasMore := int64(len(messages)) > limit
+	nextFromStreamID := ""
+	if hasMore {
+		messages = messages[:limit]
+		nextFromStreamID = messages[limit-1].ID
+	}
+
+	now := time.Now().Unix()
+
+	// 从低水位开始拉取时，本页开头连续的已读消息之前所有会话都没有未读消息，推进低水位，
+	// 下次拉取不再重复读取这些条目
+	if req.FromStreamId == "" && streamRead && groupsComplete {
+		if lowWater := readPrefixEnd(messages, userID, cursors, now); lowWater != "" {
+			if err := h.streamOp.AdvanceReadLowWaterMark(ctx, userID, lowWater); err != nil {
+				logger.Warn("Failed to advance read low-water mark", zap.Error(err))
+			}
+		}
+	}
+
 	// 4. 按会话分组消息
 	conversationMap := make(map[string]*pb.ConversationMessages)
 
 	for _, msg := range messages {
+		// 已过期但尚未被清理的消息不再返回
+		if entryExpired(msg, now) {
+			continue
+		}
+
 		msgType, _ := msg.Values["type"].(string)
 
 		var conversationID string
@@ -368,6 +976,13 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 			continue
 		}
 
+		// 按会话的已读游标判断是否已读（自己发送的消息视为已读）
+		fromUserID := getString(msg.Values["from_user_id"])
+		isRead := fromUserID == userID || stream.CompareStreamIDs(msg.ID, cursors.Get(conversationID)) <= 0
+		if isRead && !req.IncludeRead && fromUserID != userID {
+			continue
+		}
+
 		// 初始化会话
 		if _, exists := conversationMap[conversationID]; !exists {
 			conversationMap[conversationID] = &pb.ConversationMessages{
@@ -379,25 +994,18 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 		}
 
 		conv := conversationMap[conversationID]
-
-		// 限制每个会话的消息数量
-		if int64(len(conv.Messages)) >= limit {
-			continue
+		if !isRead {
+			conv.UnreadCount++
 		}
 
 		// 添加消息
-		unifiedMsg := &pb.UnifiedMessage{
-			Id:         getString(msg.Values["msg_id"]),
-			Type:       msgType,
-			FromUserId: getString(msg.Values["from_user_id"]),
-			Content:    getString(msg.Values["content"]),
-			CreatedAt:  getInt64(msg.Values["created_at"]),
-			IsRead:     false, // 新拉取的消息默认未读
-			StreamId:   msg.ID,
+		unifiedMsg := streamEntryToUnified(msg)
+		unifiedMsg.IsRead = isRead
+		if convType == "group" && !isRead && mentionsUser(unifiedMsg.MentionAll, unifiedMsg.MentionUserIds, userID) {
+			conv.HasMention = true
 		}
 
 		conv.Messages = append(conv.Messages, unifiedMsg)
-		conv.UnreadCount++
 
 		// 更新最后消息时间
 		if unifiedMsg.CreatedAt > conv.LastMessageTime {
@@ -405,7 +1013,36 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 		}
 	}
 
-	// 5. 转换为数组并按最后消息时间排序
+	// 5. 应用消息的撤回/编辑状态（已撤回的消息不返回原始内容，编辑过的消息返回最新内容）及表情回应
+	var allMsgs []*pb.UnifiedMessage
+	for _, conv := range conversationMap {
+		allMsgs = append(allMsgs, conv.Messages...)
+	}
+	h.applyMessageStates(ctx, userID, allMsgs)
+
+	// 私聊会话附带对方的已读位置（用于展示我发送的消息是否已读）
+	receipts, err := h.streamOp.GetReadReceipts(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get read receipts", zap.Error(err))
+	}
+	// 以及对方设备的送达位置（用于展示我发送的消息是否已送达）
+	deliveries, err := h.streamOp.GetDeliveryReceipts(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get delivery receipts", zap.Error(err))
+	}
+	for _, conv := range conversationMap {
+		if conv.Type != "private" {
+			continue
+		}
+		if receipt, ok := receipts[conv.PeerId]; ok {
+			conv.ReadUpTo = toPbReadMarker(receipt)
+		}
+		if receipt, ok := deliveries[conv.PeerId]; ok {
+			conv.DeliveredUpTo = toPbDeliveryMarker(receipt)
+		}
+	}
+
+	// 6. 转换为数组并按最后消息时间排序
 	var conversations []*pb.ConversationMessages
 	var totalUnread int32
 
@@ -425,7 +1062,8 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 		zap.String("user_id", userID),
 		zap.Int("conversation_count", len(conversations)),
 		zap.Int32("total_unread", totalUnread),
-		zap.Int("total_messages", len(messages)))
+		zap.Int("total_messages", len(messages)),
+		zap.Bool("has_more", hasMore))
 
 	return &pb.PullMessagesResponse{
 		Code:              0,
@@ -433,9 +1071,28 @@ func (h *MessageHandler) PullMessages(ctx context.Context, req *pb.PullMessagesR
 		Conversations:     conversations,
 		TotalUnread:       totalUnread,
 		ConversationCount: int32(len(conversations)),
+		HasMore:           hasMore,
+		NextFromStreamId:  nextFromStreamID,
 	}, nil
 }
 
+// readPrefixEnd 返回 messages 开头连续的已读条目（自己发送、已过期或不属于任何会话的条目也视为已读）中最后一条的 Stream ID，
+// 第一条即为未读时返回空
+func readPrefixEnd(messages []redis.XMessage, userID string, cursors *stream.ConversationCursors, now int64) string {
+	last := ""
+	for _, msg := range messages {
+		conversationID := entryConversationID(msg, userID)
+		read := conversationID == "" || entryExpired(msg, now) ||
+			getString(msg.Values["from_user_id"]) == userID ||
+			stream.CompareStreamIDs(msg.ID, cursors.Get(conversationID)) <= 0
+		if !read {
+			break
+		}
+		last = msg.ID
+	}
+	return last
+}
+
 // isMessageRead 判断消息是否已读
 func (h *MessageHandler) isMessageRead(values map[string]interface{}) bool {
 	if isReadStr, ok := values["is_read"].(string); ok {
@@ -500,6 +1157,11 @@ func getString(v interface{}) string {
 	return ""
 }
 
+// nullableString 辅助函数：空字符串写入数据库时存为 NULL
+func nullableString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
 // getInt64 辅助函数：从 interface{} 提取 int64
 func getInt64(v interface{}) int64 {
 	switch val := v.(type) {
@@ -526,14 +1188,22 @@ func (h *MessageHandler) GetUnreadCount(ctx context.Context, req *pb.GetUnreadCo
 }
 
 // UpdateLastSeenCursor 更新会话的已读游标
+// 每个会话（private:{peer_id} / group:{group_id}）独立维护游标，只允许前进
+// 私聊游标前进时向对方推送 read_receipt 已读回执
 func (h *MessageHandler) UpdateLastSeenCursor(ctx context.Context, req *pb.UpdateLastSeenCursorRequest) (*pb.UpdateLastSeenCursorResponse, error) {
 	userID, err := auth.GetUserID(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if req.LastSeenStreamId == "" {
-		return nil, status.Errorf(codes.InvalidArgument, "last_seen_stream_id is required")
+	if !stream.ValidStreamID(req.LastSeenStreamId) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid last_seen_stream_id")
+	}
+	if req.ConversationType != "private" && req.ConversationType != "group" {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_type: %s", req.ConversationType)
+	}
+	if req.PeerId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "peer_id is required")
 	}
 
 	logger.Info("Updating last seen cursor",
@@ -542,42 +1212,48 @@ func (h *MessageHandler) UpdateLastSeenCursor(ctx context.Context, req *pb.Updat
 		zap.String("peer_id", req.PeerId),
 		zap.String("cursor", req.LastSeenStreamId))
 
-	if err := h.streamOp.SetUserCursor(ctx, userID, req.LastSeenStreamId); err != nil {
-		logger.Error("Failed to set user cursor", zap.Error(err))
-		return nil, status.Errorf(codes.Internal, "Failed to update cursor")
+	conversationID := fmt.Sprintf("%s:%s", req.ConversationType, req.PeerId)
+	cursors, err := h.streamOp.GetConversationCursors(ctx, userID)
+	if err != nil {
+		logger.Warn("Failed to get conversation cursors", zap.Error(err))
 	}
+	oldCursor := cursors.Get(conversationID)
 
-	// 兼容群聊的数据库已读状态同步
-	switch req.ConversationType {
-	case "group":
-		if req.PeerId == "" {
-			return nil, status.Errorf(codes.InvalidArgument, "peer_id (group_id) is required for group conversation")
-		}
+	cursor, err := h.streamOp.SetConversationCursor(ctx, userID, conversationID, req.LastSeenStreamId)
+	if err != nil {
+		logger.Error("Failed to set conversation cursor", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to update cursor")
+	}
 
+	// 私聊：通知对方其发送的消息已被读取
+	if req.ConversationType == "private" {
 		go func() {
-			dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			receiptCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 			defer cancel()
 
-			h.db.ExecContext(dbCtx, `
-				INSERT INTO group_read_states (group_id, user_id, last_read_msg_id, last_read_at)
-				VALUES (?, ?, ?, NOW())
-				ON DUPLICATE KEY UPDATE
-					last_read_msg_id = VALUES(last_read_msg_id),
-					last_read_at = NOW()
-			`, req.PeerId, userID, req.LastSeenStreamId)
+			h.advancePrivateReadReceipt(receiptCtx, userID, req.PeerId, cursor)
 		}()
-
-	case "", "private":
-		// 私聊或未指定类型无需额外处理
-	default:
-		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_type: %s", req.ConversationType)
 	}
 
-	cursor, _ := h.streamOp.GetUserCursor(ctx, userID)
+	// 群聊：更新成员的已读位置（同步到 group_read_states），并向消息发送者推送最新的已读人数
+	if req.ConversationType == "group" {
+		if err := h.streamOp.ClearMentionCount(ctx, userID, req.PeerId); err != nil {
+			logger.Warn("Failed to clear mention count", zap.Error(err))
+		}
+
+		if cursor != oldCursor {
+			go func() {
+				readCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+
+				h.advanceGroupReadPosition(readCtx, userID, req.PeerId, oldCursor, cursor)
+			}()
+		}
+	}
 
 	logger.Info("Cursor updated successfully",
 		zap.String("user_id", userID),
-		zap.String("type", req.ConversationType),
+		zap.String("conversation_id", conversationID),
 		zap.String("cursor", cursor))
 
 	return &pb.UpdateLastSeenCursorResponse{
@@ -618,7 +1294,7 @@ func convertDBRowToMessage(id, fromUserID, toUserID, content string, isRead bool
 	return msg, nil
 }
 
-// MarkPrivateMessageAsRead 标记私聊消息为已读（仅更新数据库）
+// MarkPrivateMessageAsRead 标记私聊消息为已读（更新数据库并向发送者推送已读回执）
 // 注意：此方法不更新游标，游标应通过 UpdateLastSeenCursor 方法统一管理
 func (h *MessageHandler) MarkPrivateMessageAsRead(ctx context.Context, req *pb.MarkPrivateMessageAsReadRequest) (*pb.MarkPrivateMessageAsReadResponse, error) {
 	userID, err := auth.GetUserID(ctx)
@@ -645,6 +1321,28 @@ func (h *MessageHandler) MarkPrivateMessageAsRead(ctx context.Context, req *pb.M
 			msgID, userID)
 	}()
 
+	// 通知发送者（只处理对方发给当前用户、仍在当前用户 Stream 中的消息）
+	go func() {
+		receiptCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		entry, err := h.streamOp.FindMessageInStream(receiptCtx, userID, msgID, 1000)
+		if err != nil || entry == nil {
+			return
+		}
+		fromUserID := getString(entry.Values["from_user_id"])
+		if getString(entry.Values["type"]) != "private" || getString(entry.Values["to_user_id"]) != userID || fromUserID == userID {
+			return
+		}
+
+		h.publishReadReceipt(receiptCtx, userID, fromUserID, stream.ReadReceipt{
+			StreamID:  entry.ID,
+			MsgID:     msgID,
+			CreatedAt: getInt64(entry.Values["created_at"]),
+			ReadAt:    time.Now().Unix(),
+		})
+	}()
+
 	logger.Debug("Private message marked as read",
 		zap.String("msg_id", msgID),
 		zap.String("user_id", userID))
@@ -655,7 +1353,7 @@ func (h *MessageHandler) MarkPrivateMessageAsRead(ctx context.Context, req *pb.M
 	}, nil
 }
 
-// MarkGroupMessageAsRead 标记群聊消息为已读（仅更新数据库）
+// MarkGroupMessageAsRead 标记群聊消息为已读（更新成员的已读位置并备份到数据库）
 // 注意：此方法不更新游标，游标应通过 UpdateLastSeenCursor 方法统一管理
 func (h *MessageHandler) MarkGroupMessageAsRead(ctx context.Context, req *pb.MarkGroupMessageAsReadRequest) (*pb.MarkGroupMessageAsReadResponse, error) {
 	userID, err := auth.GetUserID(ctx)
@@ -675,19 +1373,29 @@ func (h *MessageHandler) MarkGroupMessageAsRead(ctx context.Context, req *pb.Mar
 		zap.String("last_msg_id", lastReadMsgID),
 		zap.String("user_id", userID))
 
-	// 异步更新数据库
-	go func() {
-		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	// 更新已读位置（只前进，同步到 group_read_states），并向发送者推送最新的已读人数
+	ref, err := h.locateMessage(ctx, userID, lastReadMsgID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type != "group" || ref.GroupID != groupID {
+		return nil, status.Errorf(codes.InvalidArgument, "message does not belong to this group")
+	}
 
-		h.db.ExecContext(dbCtx, `
-			INSERT INTO group_read_states (group_id, user_id, last_read_msg_id, last_read_at)
-			VALUES (?, ?, ?, NOW())
-			ON DUPLICATE KEY UPDATE
-				last_read_msg_id = VALUES(last_read_msg_id),
-				last_read_at = NOW()
-		`, groupID, userID, lastReadMsgID)
-	}()
+	// 消息校验通过后才清除提及计数，无效请求不影响会话列表的"有人@我"
+	if err := h.streamOp.ClearMentionCount(ctx, userID, groupID); err != nil {
+		logger.Warn("Failed to clear mention count", zap.Error(err))
+	}
+
+	msg := groupReadMessage{ID: ref.ID, FromUserID: ref.FromUserID, CreatedAt: ref.CreatedAt}
+	if _, advanced := h.saveGroupReadPosition(ctx, groupID, userID, msg); advanced && msg.FromUserID != userID {
+		go func() {
+			notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+
+			h.publishGroupReadCounts(notificationCtx, groupID, userID, []groupReadMessage{msg})
+		}()
+	}
 
 	logger.Debug("Group messages marked as read",
 		zap.String("group_id", groupID),
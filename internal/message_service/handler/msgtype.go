@@ -0,0 +1,207 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/logger"
+)
+
+// 支持的消息类型
+const (
+	MsgTypeText     = "text"
+	MsgTypeImage    = "image"
+	MsgTypeFile     = "file"
+	MsgTypeVoice    = "voice"
+	MsgTypeLocation = "location"
+	MsgTypeContact  = "contact"
+	// MsgTypeChatRecord 合并转发的聊天记录，只能由 ForwardMessages 生成
+	MsgTypeChatRecord = "chat_record"
+)
+
+const (
+	ossKeyPrefix     = "chatim/"
+	maxFileSize      = 50 * 1024 * 1024 // 与上传签名的文件大小限制保持一致
+	maxVoiceDuration = 300              // 语音最长 5 分钟
+)
+
+// messageBody 校验并规范化后的消息主体
+type messageBody struct {
+	MsgType string
+	Content string // 文本内容；非文本消息未填写时为服务端生成的摘要
+	Payload *pb.MessagePayload
+	Reply   *pb.ReplySnapshot // 引用回复快照，与 Payload 一起保存
+}
+
+// storedPayload 写入 Stream / 数据库的负载：消息类型负载 + 引用回复快照
+type storedPayload struct {
+	*pb.MessagePayload
+	Reply *pb.ReplySnapshot `json:"reply,omitempty"`
+}
+
+// storedPayloadJSON 返回写入 Stream / 数据库的负载 JSON，既无负载也无引用时为空
+func (b *messageBody) storedPayloadJSON() (string, error) {
+	if b.Payload == nil && b.Reply == nil {
+		return "", nil
+	}
+	data, err := json.Marshal(storedPayload{MessagePayload: b.Payload, Reply: b.Reply})
+	if err != nil {
+		return "", status.Errorf(codes.Internal, "Failed to encode payload")
+	}
+	return string(data), nil
+}
+
+// prepareMessageBody 按消息类型校验负载，并为非文本消息生成会话列表等场景使用的摘要
+func (h *MessageHandler) prepareMessageBody(ctx context.Context, msgType, content string, payload *pb.MessagePayload) (*messageBody, error) {
+	if msgType == "" {
+		msgType = MsgTypeText
+	}
+
+	body := &messageBody{MsgType: msgType, Content: content}
+
+	var summary string
+	switch msgType {
+	case MsgTypeText:
+		if strings.TrimSpace(content) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "content is required")
+		}
+		if payload != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "payload is not allowed for text message")
+		}
+		return body, nil
+
+	case MsgTypeImage:
+		img := payload.GetImage()
+		if img == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "payload.image is required")
+		}
+		if err := validateOSSKey(img.OssKey); err != nil {
+			return nil, err
+		}
+		if img.Width <= 0 || img.Height <= 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "image width and height must be positive")
+		}
+		summary = "[图片]"
+		body.Payload = &pb.MessagePayload{Image: img}
+
+	case MsgTypeFile:
+		file := payload.GetFile()
+		if file == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "payload.file is required")
+		}
+		if err := validateOSSKey(file.OssKey); err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(file.FileName) == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "file_name is required")
+		}
+		if file.Size <= 0 || file.Size > maxFileSize {
+			return nil, status.Errorf(codes.InvalidArgument, "file size must be between 1 and %d bytes", maxFileSize)
+		}
+		if file.MimeType == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "mime_type is required")
+		}
+		summary = "[文件] " + file.FileName
+		body.Payload = &pb.MessagePayload{File: file}
+
+	case MsgTypeVoice:
+		voice := payload.GetVoice()
+		if voice == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "payload.voice is required")
+		}
+		if err := validateOSSKey(voice.OssKey); err != nil {
+			return nil, err
+		}
+		if voice.Duration <= 0 || voice.Duration > maxVoiceDuration {
+			return nil, status.Errorf(codes.InvalidArgument, "voice duration must be between 1 and %d seconds", maxVoiceDuration)
+		}
+		summary = "[语音]"
+		body.Payload = &pb.MessagePayload{Voice: voice}
+
+	case MsgTypeLocation:
+		loc := payload.GetLocation()
+		if loc == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "payload.location is required")
+		}
+		if loc.Latitude < -90 || loc.Latitude > 90 || loc.Longitude < -180 || loc.Longitude > 180 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid latitude or longitude")
+		}
+		summary = strings.TrimSpace("[位置] " + loc.Name)
+		body.Payload = &pb.MessagePayload{Location: loc}
+
+	case MsgTypeContact:
+		card := payload.GetContact()
+		if card == nil || card.UserId == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "payload.contact.user_id is required")
+		}
+		// 昵称和头像以服务端数据为准，避免伪造名片
+		var username string
+		var nickname, avatar sql.NullString
+		err := h.db.QueryRowContext(ctx,
+			"SELECT username, nickname, avatar FROM users WHERE id = ?",
+			card.UserId).Scan(&username, &nickname, &avatar)
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "contact user not found")
+		}
+		if err != nil {
+			logger.Error("Failed to query contact card user", zap.String("user_id", card.UserId), zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to query contact user")
+		}
+		card.Nickname = username
+		if nickname.Valid && nickname.String != "" {
+			card.Nickname = nickname.String
+		}
+		card.Avatar = avatar.String
+		summary = "[名片] " + card.Nickname
+		body.Payload = &pb.MessagePayload{Contact: card}
+
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported msg_type: %s", msgType)
+	}
+
+	if strings.TrimSpace(body.Content) == "" {
+		body.Content = summary
+	}
+
+	return body, nil
+}
+
+// validateOSSKey 校验 OSS 对象 key 必须是通过上传签名生成的路径
+func validateOSSKey(key string) error {
+	if key == "" {
+		return status.Errorf(codes.InvalidArgument, "oss_key is required")
+	}
+	if !strings.HasPrefix(key, ossKeyPrefix) || strings.Contains(key, "..") {
+		return status.Errorf(codes.InvalidArgument, "invalid oss_key")
+	}
+	return nil
+}
+
+// decodeStoredPayload 解析 Stream / 数据库中保存的负载 JSON，拆分为消息类型负载和引用快照
+// 空值或格式错误时返回 nil
+func decodeStoredPayload(raw string) (*pb.MessagePayload, *pb.ReplySnapshot) {
+	if raw == "" {
+		return nil, nil
+	}
+	var stored storedPayload
+	if err := json.Unmarshal([]byte(raw), &stored); err != nil {
+		logger.Warn("Failed to decode message payload", zap.Error(err))
+		return nil, nil
+	}
+	return stored.MessagePayload, stored.Reply
+}
+
+// msgTypeOrText Stream 中旧消息可能没有 msg_type 字段，默认按文本处理
+func msgTypeOrText(msgType string) string {
+	if msgType == "" {
+		return MsgTypeText
+	}
+	return msgType
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+)
+
+func TestPrepareMessageBody(t *testing.T) {
+	ctx := context.Background()
+	h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		columns := []string{"username", "nickname", "avatar"}
+		switch args[0] {
+		case "u1":
+			return columns, [][]driver.Value{{"alice", "Alice", "https://cdn/a.png"}}, nil
+		case "u2":
+			return columns, [][]driver.Value{{"bob", nil, nil}}, nil
+		}
+		return columns, nil, nil
+	})
+
+	image := &pb.ImagePayload{OssKey: "chatim/img/1.png", Width: 100, Height: 80}
+	file := &pb.FilePayload{OssKey: "chatim/file/1.pdf", FileName: "a.pdf", Size: 1024, MimeType: "application/pdf"}
+
+	tests := []struct {
+		name        string
+		msgType     string
+		content     string
+		payload     *pb.MessagePayload
+		wantCode    codes.Code
+		wantType    string
+		wantContent string
+	}{
+		{name: "default text", content: "hi", wantType: MsgTypeText, wantContent: "hi"},
+		{name: "empty text", msgType: MsgTypeText, content: "  ", wantCode: codes.InvalidArgument},
+		{name: "text with payload", msgType: MsgTypeText, content: "hi", payload: &pb.MessagePayload{Image: image}, wantCode: codes.InvalidArgument},
+		{name: "unsupported type", msgType: "video", content: "hi", wantCode: codes.InvalidArgument},
+		{name: "chat record from client", msgType: MsgTypeChatRecord, payload: &pb.MessagePayload{ChatRecord: &pb.ChatRecordPayload{}}, wantCode: codes.InvalidArgument},
+
+		{name: "image", msgType: MsgTypeImage, payload: &pb.MessagePayload{Image: image}, wantType: MsgTypeImage, wantContent: "[图片]"},
+		{name: "image keeps caption", msgType: MsgTypeImage, content: "看这个", payload: &pb.MessagePayload{Image: image}, wantType: MsgTypeImage, wantContent: "看这个"},
+		{name: "image missing payload", msgType: MsgTypeImage, wantCode: codes.InvalidArgument},
+		{name: "image wrong payload", msgType: MsgTypeImage, payload: &pb.MessagePayload{File: file}, wantCode: codes.InvalidArgument},
+		{name: "image missing oss key", msgType: MsgTypeImage, payload: &pb.MessagePayload{Image: &pb.ImagePayload{Width: 1, Height: 1}}, wantCode: codes.InvalidArgument},
+		{name: "image foreign oss key", msgType: MsgTypeImage, payload: &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "other/1.png", Width: 1, Height: 1}}, wantCode: codes.InvalidArgument},
+		{name: "image path traversal", msgType: MsgTypeImage, payload: &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/../secret", Width: 1, Height: 1}}, wantCode: codes.InvalidArgument},
+		{name: "image zero size", msgType: MsgTypeImage, payload: &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/1.png", Width: 0, Height: 1}}, wantCode: codes.InvalidArgument},
+
+		{name: "file", msgType: MsgTypeFile, payload: &pb.MessagePayload{File: file}, wantType: MsgTypeFile, wantContent: "[文件] a.pdf"},
+		{name: "file missing name", msgType: MsgTypeFile, payload: &pb.MessagePayload{File: &pb.FilePayload{OssKey: "chatim/1", Size: 1, MimeType: "text/plain"}}, wantCode: codes.InvalidArgument},
+		{name: "file empty", msgType: MsgTypeFile, payload: &pb.MessagePayload{File: &pb.FilePayload{OssKey: "chatim/1", FileName: "a", MimeType: "text/plain"}}, wantCode: codes.InvalidArgument},
+		{name: "file too large", msgType: MsgTypeFile, payload: &pb.MessagePayload{File: &pb.FilePayload{OssKey: "chatim/1", FileName: "a", Size: maxFileSize + 1, MimeType: "text/plain"}}, wantCode: codes.InvalidArgument},
+		{name: "file missing mime type", msgType: MsgTypeFile, payload: &pb.MessagePayload{File: &pb.FilePayload{OssKey: "chatim/1", FileName: "a", Size: 1}}, wantCode: codes.InvalidArgument},
+
+		{name: "voice", msgType: MsgTypeVoice, payload: &pb.MessagePayload{Voice: &pb.VoicePayload{OssKey: "chatim/v.amr", Duration: maxVoiceDuration}}, wantType: MsgTypeVoice, wantContent: "[语音]"},
+		{name: "voice zero duration", msgType: MsgTypeVoice, payload: &pb.MessagePayload{Voice: &pb.VoicePayload{OssKey: "chatim/v.amr"}}, wantCode: codes.InvalidArgument},
+		{name: "voice too long", msgType: MsgTypeVoice, payload: &pb.MessagePayload{Voice: &pb.VoicePayload{OssKey: "chatim/v.amr", Duration: maxVoiceDuration + 1}}, wantCode: codes.InvalidArgument},
+
+		{name: "location", msgType: MsgTypeLocation, payload: &pb.MessagePayload{Location: &pb.LocationPayload{Latitude: 31.2, Longitude: 121.5, Name: "外滩"}}, wantType: MsgTypeLocation, wantContent: "[位置] 外滩"},
+		{name: "location without name", msgType: MsgTypeLocation, payload: &pb.MessagePayload{Location: &pb.LocationPayload{Latitude: -90, Longitude: 180}}, wantType: MsgTypeLocation, wantContent: "[位置]"},
+		{name: "location bad latitude", msgType: MsgTypeLocation, payload: &pb.MessagePayload{Location: &pb.LocationPayload{Latitude: 90.1}}, wantCode: codes.InvalidArgument},
+		{name: "location bad longitude", msgType: MsgTypeLocation, payload: &pb.MessagePayload{Location: &pb.LocationPayload{Longitude: -180.1}}, wantCode: codes.InvalidArgument},
+
+		{name: "contact uses server profile", msgType: MsgTypeContact, payload: &pb.MessagePayload{Contact: &pb.ContactCardPayload{UserId: "u1", Nickname: "forged"}}, wantType: MsgTypeContact, wantContent: "[名片] Alice"},
+		{name: "contact falls back to username", msgType: MsgTypeContact, payload: &pb.MessagePayload{Contact: &pb.ContactCardPayload{UserId: "u2"}}, wantType: MsgTypeContact, wantContent: "[名片] bob"},
+		{name: "contact missing user", msgType: MsgTypeContact, payload: &pb.MessagePayload{Contact: &pb.ContactCardPayload{}}, wantCode: codes.InvalidArgument},
+		{name: "contact unknown user", msgType: MsgTypeContact, payload: &pb.MessagePayload{Contact: &pb.ContactCardPayload{UserId: "nobody"}}, wantCode: codes.NotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := h.prepareMessageBody(ctx, tt.msgType, tt.content, tt.payload)
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %v", err, tt.wantCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if body.MsgType != tt.wantType || body.Content != tt.wantContent {
+				t.Errorf("body = %q %q, want %q %q", body.MsgType, body.Content, tt.wantType, tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestStoredPayloadRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		body messageBody
+	}{
+		{name: "empty", body: messageBody{}},
+		{name: "payload", body: messageBody{Payload: &pb.MessagePayload{Voice: &pb.VoicePayload{OssKey: "chatim/v", Duration: 3}}}},
+		{name: "reply", body: messageBody{Reply: &pb.ReplySnapshot{MsgId: "m1", Preview: "hi"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := tt.body.storedPayloadJSON()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (raw == "") != (tt.body.Payload == nil && tt.body.Reply == nil) {
+				t.Fatalf("storedPayloadJSON() = %q", raw)
+			}
+			payload, reply := decodeStoredPayload(raw)
+			if payload.GetVoice().GetDuration() != tt.body.Payload.GetVoice().GetDuration() ||
+				reply.GetMsgId() != tt.body.Reply.GetMsgId() {
+				t.Errorf("decodeStoredPayload(%q) = %v, %v", raw, payload, reply)
+			}
+		})
+	}
+
+	if payload, reply := decodeStoredPayload("{broken"); payload != nil || reply != nil {
+		t.Errorf("decodeStoredPayload of malformed JSON = %v, %v, want nil", payload, reply)
+	}
+}
@@ -0,0 +1,312 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// maxPinnedMessages 每个会话最多置顶的消息数
+const maxPinnedMessages = 20
+
+// PinMessage 在消息所在的会话内置顶该消息
+// 私聊双方均可置顶，群聊仅群主/管理员可置顶；已撤回和开启了定时销毁的消息不能置顶
+func (h *MessageHandler) PinMessage(ctx context.Context, req *pb.PinMessageRequest) (*pb.PinMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.MessageId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+
+	// 1. 查找消息（只能置顶自己所在会话中的消息）
+	ref, err := h.locateMessage(ctx, userID, req.MessageId)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type == "group" {
+		if err := h.checkPinPermission(ctx, ref.GroupID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	recalled, err := h.isRecalled(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	if recalled {
+		return nil, status.Errorf(codes.FailedPrecondition, "message has been recalled")
+	}
+	if ref.ExpiresAt > 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "disappearing messages cannot be pinned")
+	}
+
+	content := ref.Content
+	edits, err := h.streamOp.GetMessageEdits(ctx, []string{ref.ID})
+	if err != nil {
+		logger.Warn("Failed to get pinned message edits", zap.Error(err))
+	}
+	if edit, ok := edits[ref.ID]; ok {
+		content = edit.Content
+	}
+
+	// 会话对当前用户而言的 peerID
+	peerID := refPeerID(ref)
+	if ref.Type == "private" && ref.ToUserID == userID {
+		peerID = ref.FromUserID
+	}
+	key := stream.ConversationKey(ref.Type, userID, peerID)
+
+	// 2. 检查置顶数量上限
+	var count int
+	if err := h.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM pinned_messages WHERE conversation_key = ?", key).Scan(&count); err != nil {
+		logger.Error("Failed to count pinned messages", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to pin message")
+	}
+	if count >= maxPinnedMessages {
+		return nil, status.Errorf(codes.FailedPrecondition, "at most %d messages can be pinned in a conversation", maxPinnedMessages)
+	}
+
+	// 3. 保存置顶记录（重复置顶时保持原记录）
+	pinnedAt := time.Now().Unix()
+	result, err := h.db.ExecContext(ctx, `
+		INSERT IGNORE INTO pinned_messages
+			(msg_id, conversation_key, conversation_type, from_user_id, msg_type, content, msg_created_at, pinned_by, pinned_at)
+		VALUES (?, ?, ?, ?, ?, ?, FROM_UNIXTIME(?), ?, FROM_UNIXTIME(?))`,
+		ref.ID, key, ref.Type, ref.FromUserID, ref.MsgType, content, ref.CreatedAt, userID, pinnedAt)
+	if err != nil {
+		logger.Error("Failed to save pinned message", zap.String("msg_id", ref.ID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to pin message")
+	}
+
+	pinned := &pb.PinnedMessage{
+		MsgId:          ref.ID,
+		ConversationId: ref.Type + ":" + peerID,
+		FromUserId:     ref.FromUserID,
+		MsgType:        ref.MsgType,
+		Content:        content,
+		CreatedAt:      ref.CreatedAt,
+		PinnedBy:       userID,
+		PinnedAt:       pinnedAt,
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &pb.PinMessageResponse{Code: 0, Message: "消息已置顶", Pinned: pinned}, nil
+	}
+
+	// 4. 通知会话成员
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		h.publishPinEvent(notificationCtx, ref, userID, map[string]interface{}{
+			"action":       "pin",
+			"from_user_id": pinned.FromUserId,
+			"msg_type":     pinned.MsgType,
+			"content":      pinned.Content,
+			"created_at":   pinned.CreatedAt,
+			"pinned_at":    pinned.PinnedAt,
+		})
+	}()
+
+	logger.Info("Message pinned",
+		zap.String("msg_id", ref.ID),
+		zap.String("conversation", key),
+		zap.String("user_id", userID))
+
+	return &pb.PinMessageResponse{Code: 0, Message: "置顶成功", Pinned: pinned}, nil
+}
+
+// UnpinMessage 取消置顶消息，权限与置顶相同
+func (h *MessageHandler) UnpinMessage(ctx context.Context, req *pb.UnpinMessageRequest) (*pb.UnpinMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.MessageId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+
+	// 按置顶记录校验权限，消息本身可能已不在 Stream 中
+	var key string
+	err = h.db.QueryRowContext(ctx, "SELECT conversation_key FROM pinned_messages WHERE msg_id = ?", req.MessageId).Scan(&key)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "pinned message not found")
+	}
+	if err != nil {
+		logger.Error("Failed to query pinned message", zap.String("msg_id", req.MessageId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to unpin message")
+	}
+
+	ref, err := pinnedMessageRef(req.MessageId, key, userID)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Type == "group" {
+		if err := h.checkPinPermission(ctx, ref.GroupID, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := h.db.ExecContext(ctx, "DELETE FROM pinned_messages WHERE msg_id = ?", req.MessageId)
+	if err != nil {
+		logger.Error("Failed to delete pinned message", zap.String("msg_id", req.MessageId), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to unpin message")
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return &pb.UnpinMessageResponse{Code: 0, Message: "消息已取消置顶"}, nil
+	}
+
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		h.publishPinEvent(notificationCtx, ref, userID, map[string]interface{}{
+			"action": "unpin",
+		})
+	}()
+
+	logger.Info("Message unpinned",
+		zap.String("msg_id", req.MessageId),
+		zap.String("conversation", key),
+		zap.String("user_id", userID))
+
+	return &pb.UnpinMessageResponse{Code: 0, Message: "已取消置顶"}, nil
+}
+
+// ListPinnedMessages 查询会话的置顶消息（按置顶时间倒序），已撤回的消息不返回
+func (h *MessageHandler) ListPinnedMessages(ctx context.Context, req *pb.ListPinnedMessagesRequest) (*pb.ListPinnedMessagesResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	convType, peerID, ok := strings.Cut(req.ConversationId, ":")
+	if !ok || peerID == "" || (convType != "private" && convType != "group") {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+	}
+	if convType == "group" {
+		if err := h.checkGroupMember(ctx, peerID, userID); err != nil {
+			return nil, err
+		}
+	}
+	key := stream.ConversationKey(convType, userID, peerID)
+
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT msg_id, from_user_id, msg_type, IFNULL(content, ''),
+			IFNULL(UNIX_TIMESTAMP(msg_created_at), 0), pinned_by, UNIX_TIMESTAMP(pinned_at)
+		FROM pinned_messages
+		WHERE conversation_key = ?
+		ORDER BY pinned_at DESC`, key)
+	if err != nil {
+		logger.Error("Failed to query pinned messages", zap.String("conversation", key), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to get pinned messages")
+	}
+	defer rows.Close()
+
+	var pins []*pb.PinnedMessage
+	var ids []string
+	for rows.Next() {
+		pin := &pb.PinnedMessage{ConversationId: req.ConversationId}
+		if err := rows.Scan(&pin.MsgId, &pin.FromUserId, &pin.MsgType, &pin.Content, &pin.CreatedAt, &pin.PinnedBy, &pin.PinnedAt); err != nil {
+			logger.Warn("Failed to scan pinned message", zap.Error(err))
+			continue
+		}
+		pins = append(pins, pin)
+		ids = append(ids, pin.MsgId)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to get pinned messages")
+	}
+
+	res := &pb.ListPinnedMessagesResponse{Code: 0, Message: "查询成功", Pins: []*pb.PinnedMessage{}}
+	if len(pins) == 0 {
+		return res, nil
+	}
+
+	// 以最新的编辑内容为准，过滤已撤回的消息
+	recalled, err := h.streamOp.GetRecalledMessages(ctx, ids)
+	if err != nil {
+		logger.Warn("Failed to get recalled pinned messages", zap.Error(err))
+	}
+	edits, err := h.streamOp.GetMessageEdits(ctx, ids)
+	if err != nil {
+		logger.Warn("Failed to get pinned message edits", zap.Error(err))
+	}
+	for _, pin := range pins {
+		if recalled[pin.MsgId] {
+			continue
+		}
+		if edit, ok := edits[pin.MsgId]; ok {
+			pin.Content = edit.Content
+		}
+		res.Pins = append(res.Pins, pin)
+	}
+
+	return res, nil
+}
+
+// checkPinPermission 群聊中只有群主/管理员可以置顶或取消置顶
+func (h *MessageHandler) checkPinPermission(ctx context.Context, groupID, userID string) error {
+	role, err := h.groupMemberRole(ctx, groupID, userID)
+	if err != nil {
+		return err
+	}
+	if role != "admin" {
+		return status.Errorf(codes.PermissionDenied, "only group owner or admins can pin messages")
+	}
+	return nil
+}
+
+// pinnedMessageRef 由置顶记录的会话标识还原消息所在的会话，私聊时要求当前用户是会话一方
+func pinnedMessageRef(msgID, key, userID string) (*messageRef, error) {
+	parts := strings.Split(key, ":")
+	if len(parts) == 2 && parts[0] == "group" {
+		return &messageRef{ID: msgID, Type: "group", GroupID: parts[1]}, nil
+	}
+	if len(parts) == 3 && parts[0] == "private" && (parts[1] == userID || parts[2] == userID) {
+		return &messageRef{ID: msgID, Type: "private", FromUserID: parts[1], ToUserID: parts[2]}, nil
+	}
+	return nil, status.Errorf(codes.NotFound, "pinned message not found")
+}
+
+// publishPinEvent 向会话成员推送 message_pin 事件（私聊时为每一方填充对方ID）
+func (h *MessageHandler) publishPinEvent(ctx context.Context, ref *messageRef, userID string, fields map[string]interface{}) {
+	event := map[string]interface{}{
+		"type":              "message_pin",
+		"msg_id":            ref.ID,
+		"conversation_type": ref.Type,
+		"pinned_by":         userID,
+	}
+	for k, v := range fields {
+		event[k] = v
+	}
+
+	if ref.Type == "group" {
+		members, err := h.getGroupMembers(ctx, ref.GroupID)
+		if err != nil {
+			logger.Warn("Failed to get group members for pin notification", zap.Error(err))
+			return
+		}
+		event["group_id"] = ref.GroupID
+		h.publishEvent(ctx, members, event)
+		return
+	}
+
+	event["peer_id"] = ref.ToUserID
+	h.publishEvent(ctx, []string{ref.FromUserID}, event)
+	if ref.ToUserID != ref.FromUserID {
+		event["peer_id"] = ref.FromUserID
+		h.publishEvent(ctx, []string{ref.ToUserID}, event)
+	}
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	pb "ChatIM/api/proto/message"
+)
+
+func TestPullMessagesPages(t *testing.T) {
+	h, _ := newTestHandler(t, nil)
+	ctx := userContext(t, "a")
+
+	var streamIDs []string
+	for i := 1; i <= 5; i++ {
+		id, err := h.streamOp.AddPrivateMessage(context.Background(), fmt.Sprintf("m%d", i), "b", "a", "hi", "text", "", 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamIDs = append(streamIDs, id)
+	}
+
+	tests := []struct {
+		name     string
+		from     string
+		wantMsgs []string
+		wantNext string
+	}{
+		{name: "first page", wantMsgs: []string{"m1", "m2"}, wantNext: streamIDs[1]},
+		{name: "second page", from: streamIDs[1], wantMsgs: []string{"m3", "m4"}, wantNext: streamIDs[3]},
+		{name: "last page", from: streamIDs[3], wantMsgs: []string{"m5"}},
+		{name: "nothing new", from: streamIDs[4]},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := h.PullMessages(ctx, &pb.PullMessagesRequest{Limit: 2, FromStreamId: tt.from})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, conv := range res.Conversations {
+				for _, m := range conv.Messages {
+					got = append(got, m.Id)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.wantMsgs) {
+				t.Errorf("messages = %v, want %v", got, tt.wantMsgs)
+			}
+			if res.HasMore != (tt.wantNext != "") || res.NextFromStreamId != tt.wantNext {
+				t.Errorf("has_more = %v, next = %q, want %q", res.HasMore, res.NextFromStreamId, tt.wantNext)
+			}
+		})
+	}
+}
+
+func TestPullMessagesAdvancesReadLowWaterMark(t *testing.T) {
+	h, _ := newTestHandler(t, nil)
+	ctx := userContext(t, "a")
+	bg := context.Background()
+
+	add := func(msgID, from, to string) string {
+		id, err := h.streamOp.AddPrivateMessage(bg, msgID, from, to, "hi", "text", "", 0, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	readID := add("m1", "b", "a")
+	ownID := add("m2", "a", "b")
+	add("m3", "c", "a") // c 的会话没有已读游标
+	add("m4", "b", "a")
+	if _, err := h.streamOp.SetConversationCursor(bg, "a", "private:b", readID); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		from string
+	}{
+		// 带 from_stream_id 的拉取不知道更早的位置是否已读，不推进低水位
+		{name: "explicit from", from: "0-0"},
+		// m1 已读、m2 为自己发送，m3 未读
+		{name: "from low-water mark"},
+		{name: "repeated pull"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := h.PullMessages(ctx, &pb.PullMessagesRequest{Limit: 10, FromStreamId: tt.from})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.TotalUnread != 2 {
+				t.Errorf("total_unread = %d, want 2", res.TotalUnread)
+			}
+			cursors, err := h.streamOp.GetConversationCursors(bg, "a")
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := ownID
+			if tt.from != "" {
+				want = "0-0"
+			}
+			if got := cursors.Min(); got != want {
+				t.Errorf("low-water mark = %q, want %q", got, want)
+			}
+		})
+	}
+}
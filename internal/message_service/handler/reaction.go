@@ -0,0 +1,279 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// maxEmojiBytes 单个表情的最大长度（组合表情可能由多个码点组成）
+const maxEmojiBytes = 32
+
+// AddReaction 对一条可见的消息添加表情回应
+func (h *MessageHandler) AddReaction(ctx context.Context, req *pb.AddReactionRequest) (*pb.AddReactionResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, emoji, err := h.checkReactionTarget(ctx, userID, req.MessageId, req.Emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	// 超出 Redis 状态保留范围的消息直接读写数据库
+	expired := stream.MessageStateExpired(ref.CreatedAt)
+	var added bool
+	if expired {
+		added, err = h.insertReaction(ctx, ref, userID, emoji)
+	} else {
+		added, err = h.streamOp.AddReaction(ctx, ref.ID, emoji, userID, ref.CreatedAt)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to add reaction")
+	}
+
+	reactions := h.reactionsOf(ctx, ref, userID)
+
+	// Redis 中新增的回应异步备份到数据库
+	if added && !expired {
+		go func() {
+			dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if _, err := h.insertReaction(dbCtx, ref, userID, emoji); err != nil {
+				logger.Warn("Failed to save reaction to database", zap.Error(err))
+			}
+		}()
+	}
+	// 重复回应不产生新事件
+	if added {
+		h.publishReactionEvent(ref, userID, emoji, "add", reactions)
+	}
+
+	return &pb.AddReactionResponse{
+		Code:      0,
+		Message:   "回应成功",
+		Reactions: reactions,
+	}, nil
+}
+
+// RemoveReaction 取消自己对消息的表情回应
+func (h *MessageHandler) RemoveReaction(ctx context.Context, req *pb.RemoveReactionRequest) (*pb.RemoveReactionResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ref, emoji, err := h.checkReactionTarget(ctx, userID, req.MessageId, req.Emoji)
+	if err != nil {
+		return nil, err
+	}
+
+	expired := stream.MessageStateExpired(ref.CreatedAt)
+	var removed bool
+	if expired {
+		removed, err = h.deleteReaction(ctx, ref.ID, userID, emoji)
+	} else {
+		removed, err = h.streamOp.RemoveReaction(ctx, ref.ID, emoji, userID)
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to remove reaction")
+	}
+
+	reactions := h.reactionsOf(ctx, ref, userID)
+
+	if removed && !expired {
+		go func() {
+			dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			if _, err := h.deleteReaction(dbCtx, ref.ID, userID, emoji); err != nil {
+				logger.Warn("Failed to delete reaction from database", zap.Error(err))
+			}
+		}()
+	}
+	if removed {
+		h.publishReactionEvent(ref, userID, emoji, "remove", reactions)
+	}
+
+	return &pb.RemoveReactionResponse{
+		Code:      0,
+		Message:   "已取消回应",
+		Reactions: reactions,
+	}, nil
+}
+
+// checkReactionTarget 校验表情参数，并确认消息对当前用户可见且未被撤回
+func (h *MessageHandler) checkReactionTarget(ctx context.Context, userID, messageID, emoji string) (*messageRef, string, error) {
+	if messageID == "" {
+		return nil, "", status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+
+	emoji = strings.TrimSpace(emoji)
+	if emoji == "" {
+		return nil, "", status.Errorf(codes.InvalidArgument, "emoji is required")
+	}
+	if len(emoji) > maxEmojiBytes || !utf8.ValidString(emoji) || strings.ContainsAny(emoji, " \t\r\n:") {
+		return nil, "", status.Errorf(codes.InvalidArgument, "invalid emoji")
+	}
+
+	ref, err := h.locateMessage(ctx, userID, messageID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	recalled, err := h.isRecalled(ctx, ref)
+	if err != nil {
+		return nil, "", status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	if recalled {
+		return nil, "", status.Errorf(codes.FailedPrecondition, "message has been recalled")
+	}
+
+	return ref, emoji, nil
+}
+
+// reactionsOf 获取单条消息的回应汇总，失败时返回空列表（不影响回应本身）
+func (h *MessageHandler) reactionsOf(ctx context.Context, ref *messageRef, userID string) []*pb.Reaction {
+	var (
+		all map[string][]stream.ReactionSummary
+		err error
+	)
+	if stream.MessageStateExpired(ref.CreatedAt) {
+		all, err = h.reactionsFromDB(ctx, []string{ref.ID}, userID)
+	} else {
+		all, err = h.streamOp.GetMessageReactions(ctx, []string{ref.ID}, userID)
+	}
+	if err != nil {
+		logger.Warn("Failed to get message reactions", zap.Error(err))
+		return nil
+	}
+	return toPbReactions(all[ref.ID])
+}
+
+// insertReaction 将回应写入数据库，返回是否为新增
+func (h *MessageHandler) insertReaction(ctx context.Context, ref *messageRef, userID, emoji string) (bool, error) {
+	res, err := h.db.ExecContext(ctx, `
+		INSERT IGNORE INTO message_reactions (message_id, conversation_type, user_id, emoji, created_at)
+		VALUES (?, ?, ?, ?, NOW())
+	`, ref.ID, ref.Type, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// deleteReaction 从数据库删除回应，返回是否确实删除了回应
+func (h *MessageHandler) deleteReaction(ctx context.Context, messageID, userID, emoji string) (bool, error) {
+	res, err := h.db.ExecContext(ctx,
+		"DELETE FROM message_reactions WHERE message_id = ? AND user_id = ? AND emoji = ?",
+		messageID, userID, emoji)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// reactionsFromDB 从数据库批量读取消息的表情回应汇总（按回应人数降序），用于超出 Redis 状态保留范围的消息
+func (h *MessageHandler) reactionsFromDB(ctx context.Context, messageIDs []string, userID string) (map[string][]stream.ReactionSummary, error) {
+	reactions := make(map[string][]stream.ReactionSummary)
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	args := make([]interface{}, 0, len(messageIDs)+1)
+	args = append(args, userID)
+	for _, id := range messageIDs {
+		args = append(args, id)
+	}
+	rows, err := h.db.QueryContext(ctx, `
+		SELECT message_id, emoji, COUNT(*), IFNULL(MAX(user_id = ?), 0)
+		FROM message_reactions
+		WHERE message_id IN (`+strings.TrimSuffix(strings.Repeat("?, ", len(messageIDs)), ", ")+`)
+		GROUP BY message_id, emoji
+		ORDER BY COUNT(*) DESC, emoji`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			messageID string
+			r         stream.ReactionSummary
+		)
+		if err := rows.Scan(&messageID, &r.Emoji, &r.Count, &r.ReactedByMe); err != nil {
+			return nil, err
+		}
+		reactions[messageID] = append(reactions[messageID], r)
+	}
+	return reactions, rows.Err()
+}
+
+// publishReactionEvent 向会话中的其他成员推送轻量的回应变更事件
+func (h *MessageHandler) publishReactionEvent(ref *messageRef, userID, emoji, action string, reactions []*pb.Reaction) {
+	var count int32
+	for _, r := range reactions {
+		if r.Emoji == emoji {
+			count = r.Count
+		}
+	}
+
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		members, err := h.conversationMembers(notificationCtx, ref)
+		if err != nil {
+			logger.Warn("Failed to get conversation members for reaction notification", zap.Error(err))
+			return
+		}
+
+		var recipients []string
+		for _, memberID := range members {
+			if memberID != userID {
+				recipients = append(recipients, memberID)
+			}
+		}
+
+		h.publishEvent(notificationCtx, recipients, map[string]interface{}{
+			"type":              "reaction",
+			"msg_id":            ref.ID,
+			"conversation_type": ref.Type,
+			"group_id":          ref.GroupID,
+			"user_id":           userID,
+			"emoji":             emoji,
+			"action":            action,
+			"count":             count,
+		})
+	}()
+}
+
+// toPbReactions 将回应汇总转换为 protobuf 结构
+func toPbReactions(list []stream.ReactionSummary) []*pb.Reaction {
+	if len(list) == 0 {
+		return nil
+	}
+	reactions := make([]*pb.Reaction, 0, len(list))
+	for _, r := range list {
+		reactions = append(reactions, &pb.Reaction{
+			Emoji:       r.Emoji,
+			Count:       int32(r.Count),
+			ReactedByMe: r.ReactedByMe,
+		})
+	}
+	return reactions
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+)
+
+// RecallMessage 撤回一条自己发送的消息（私聊或群聊）
+// 1. 在所有成员 Stream 共用的撤回标记中记录该消息
+// 2. 异步更新 messages / group_messages 表（消息尚未落库时由 persister 落库后补上）
+// 3. 通过 message_notifications 推送 recall 事件，在线客户端据此替换消息气泡
+func (h *MessageHandler) RecallMessage(ctx context.Context, req *pb.RecallMessageRequest) (*pb.RecallMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.MessageId == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "message_id is required")
+	}
+
+	logger.Info("Recalling message",
+		zap.String("msg_id", req.MessageId),
+		zap.String("user_id", userID))
+
+	// 1. 查找消息并校验归属
+	ref, err := h.locateMessage(ctx, userID, req.MessageId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ref.FromUserID != userID {
+		return nil, status.Errorf(codes.PermissionDenied, "only the sender can recall this message")
+	}
+
+	// 2. 已撤回则直接返回成功（幂等）
+	recalled, err := h.isRecalled(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	if recalled {
+		return &pb.RecallMessageResponse{
+			Code:    0,
+			Message: "消息已撤回",
+		}, nil
+	}
+
+	// 3. 校验撤回时限
+	if time.Since(time.Unix(ref.CreatedAt, 0)) > h.recallWindow {
+		return nil, status.Errorf(codes.FailedPrecondition, "message can only be recalled within %s", h.recallWindow)
+	}
+
+	// 4. 写入撤回标记
+	recalledAt := time.Now().Unix()
+	if err := h.streamOp.MarkMessageRecalled(ctx, ref.ID, userID, recalledAt, ref.CreatedAt); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to recall message")
+	}
+
+	// 5. 异步更新数据库
+	go func() {
+		dbCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		// 消息可能仍在落库队列中，此时不影响任何行，由 persister 落库时按撤回标记补上
+		updated, err := persister.MarkRecalled(dbCtx, h.db, ref.Type, ref.ID, recalledAt)
+		if err != nil {
+			logger.Warn("Failed to mark message as recalled in database", zap.Error(err))
+		} else if !updated {
+			logger.Debug("Recalled message not persisted yet, persister will apply the recall", zap.String("msg_id", ref.ID))
+		}
+		// 已撤回的消息同时取消置顶
+		if _, err := h.db.ExecContext(dbCtx, "DELETE FROM pinned_messages WHERE msg_id = ?", ref.ID); err != nil {
+			logger.Warn("Failed to unpin recalled message", zap.Error(err))
+		}
+	}()
+
+	// 6. 通知会话中的其他成员
+	go func() {
+		notificationCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		defer cancel()
+
+		members, err := h.conversationMembers(notificationCtx, ref)
+		if err != nil {
+			logger.Warn("Failed to get conversation members for recall notification", zap.Error(err))
+			return
+		}
+
+		var recipients []string
+		for _, memberID := range members {
+			if memberID != userID {
+				recipients = append(recipients, memberID)
+			}
+		}
+
+		h.publishEvent(notificationCtx, recipients, map[string]interface{}{
+			"type":              "recall",
+			"msg_id":            ref.ID,
+			"conversation_type": ref.Type,
+			"from_user_id":      ref.FromUserID,
+			"group_id":          ref.GroupID,
+			"recalled_at":       recalledAt,
+		})
+	}()
+
+	logger.Info("Message recalled",
+		zap.String("msg_id", ref.ID),
+		zap.String("type", ref.Type))
+
+	return &pb.RecallMessageResponse{
+		Code:       0,
+		Message:    "消息已撤回",
+		RecalledAt: recalledAt,
+	}, nil
+}
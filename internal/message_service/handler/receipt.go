@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// receiptScanLimit 在读者 Stream 中向前查找对方最后一条消息的最大条目数
+const receiptScanLimit = 200
+
+// advancePrivateReadReceipt 读者在私聊中的已读位置前进到 upToStreamID（读者 Stream 中的ID）时，
+// 找到该位置及之前对方发来的最后一条消息，记录已读回执并通过 read_receipt 事件通知对方
+func (h *MessageHandler) advancePrivateReadReceipt(ctx context.Context, readerID, peerID, upToStreamID string) {
+	if readerID == peerID {
+		return
+	}
+
+	streamKey := fmt.Sprintf("stream:private:%s", readerID)
+	entries, err := h.rdb.XRevRangeN(ctx, streamKey, upToStreamID, "-", receiptScanLimit).Result()
+	if err != nil {
+		logger.Warn("Failed to read stream for read receipt", zap.String("user_id", readerID), zap.Error(err))
+		return
+	}
+
+	for _, entry := range entries {
+		if getString(entry.Values["type"]) != "private" ||
+			getString(entry.Values["from_user_id"]) != peerID ||
+			getString(entry.Values["to_user_id"]) != readerID {
+			continue
+		}
+
+		receipt := stream.ReadReceipt{
+			StreamID:  entry.ID,
+			MsgID:     getString(entry.Values["id"]),
+			CreatedAt: getInt64(entry.Values["created_at"]),
+			ReadAt:    time.Now().Unix(),
+		}
+		h.publishReadReceipt(ctx, readerID, peerID, receipt)
+		return
+	}
+}
+
+// publishReadReceipt 保存已读回执，已读位置确实前进时通知消息发送者
+func (h *MessageHandler) publishReadReceipt(ctx context.Context, readerID, senderID string, receipt stream.ReadReceipt) {
+	advanced, err := h.streamOp.AdvanceReadReceipt(ctx, senderID, readerID, receipt)
+	if err != nil || !advanced {
+		return
+	}
+
+	h.publishEvent(ctx, []string{senderID}, map[string]interface{}{
+		"type":       "read_receipt",
+		"peer_id":    readerID,
+		"msg_id":     receipt.MsgID,
+		"created_at": receipt.CreatedAt,
+		"read_at":    receipt.ReadAt,
+	})
+
+	logger.Debug("Read receipt published",
+		zap.String("reader_id", readerID),
+		zap.String("sender_id", senderID),
+		zap.String("msg_id", receipt.MsgID))
+}
+
+// toPbReadMarker 将已读回执转换为 PullMessages 返回的已读位置
+func toPbReadMarker(receipt stream.ReadReceipt) *pb.ReadMarker {
+	return &pb.ReadMarker{
+		MsgId:     receipt.MsgID,
+		CreatedAt: receipt.CreatedAt,
+		ReadAt:    receipt.ReadAt,
+	}
+}
+
+// toPbDeliveryMarker 将送达回执转换为 PullMessages 返回的送达位置
+func toPbDeliveryMarker(receipt stream.DeliveryReceipt) *pb.DeliveryMarker {
+	return &pb.DeliveryMarker{
+		MsgId:       receipt.MsgID,
+		CreatedAt:   receipt.CreatedAt,
+		DeliveredAt: receipt.DeliveredAt,
+	}
+}
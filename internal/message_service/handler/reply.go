@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/logger"
+)
+
+// replyPreviewMaxRunes 引用快照中内容预览的最大长度（按字符计）
+const replyPreviewMaxRunes = 50
+
+// buildReplySnapshot 校验被引用的消息属于当前会话，并生成引用快照
+// convType 为 "private" 时 peerID 为对方用户ID，为 "group" 时 peerID 为群组ID
+func (h *MessageHandler) buildReplySnapshot(ctx context.Context, userID, replyToMsgID, convType, peerID string) (*pb.ReplySnapshot, error) {
+	ref, err := h.locateMessage(ctx, userID, replyToMsgID)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return nil, status.Errorf(codes.NotFound, "quoted message not found")
+		}
+		return nil, err
+	}
+
+	if !sameConversation(ref, userID, convType, peerID) {
+		return nil, status.Errorf(codes.InvalidArgument, "quoted message does not belong to this conversation")
+	}
+
+	recalled, err := h.isRecalled(ctx, ref)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to check recall status")
+	}
+	if recalled {
+		return nil, status.Errorf(codes.FailedPrecondition, "quoted message has been recalled")
+	}
+
+	// 引用的是编辑后的最新内容
+	content := ref.Content
+	edits, err := h.streamOp.GetMessageEdits(ctx, []string{ref.ID})
+	if err != nil {
+		logger.Warn("Failed to get quoted message edits", zap.Error(err))
+	}
+	if edit, ok := edits[ref.ID]; ok {
+		content = edit.Content
+	}
+
+	snapshot := &pb.ReplySnapshot{
+		MsgId:      ref.ID,
+		FromUserId: ref.FromUserID,
+		MsgType:    ref.MsgType,
+		Preview:    truncateRunes(content, replyPreviewMaxRunes),
+		CreatedAt:  ref.CreatedAt,
+	}
+
+	var senderName string
+	if err := h.db.QueryRowContext(ctx, `SELECT username FROM users WHERE id = ?`, ref.FromUserID).Scan(&senderName); err == nil {
+		snapshot.FromUserName = senderName
+	}
+
+	return snapshot, nil
+}
+
+// sameConversation 判断消息是否属于指定会话
+func sameConversation(ref *messageRef, userID, convType, peerID string) bool {
+	switch convType {
+	case "private":
+		if ref.Type != "private" {
+			return false
+		}
+		return (ref.FromUserID == userID && ref.ToUserID == peerID) ||
+			(ref.FromUserID == peerID && ref.ToUserID == userID)
+	case "group":
+		return ref.Type == "group" && ref.GroupID == peerID
+	}
+	return false
+}
+
+// truncateRunes 按字符截断字符串，超出部分以省略号表示
+func truncateRunes(s string, max int) string {
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return string(runes[:max]) + "…"
+}
+
+// applyQuotedRecalls 被引用的消息撤回后，引用快照中不再展示其内容
+func (h *MessageHandler) applyQuotedRecalls(ctx context.Context, msgs []*pb.UnifiedMessage) {
+	var quotedIDs []string
+	for _, m := range msgs {
+		if m.ReplyTo != nil {
+			quotedIDs = append(quotedIDs, m.ReplyTo.MsgId)
+		}
+	}
+	if len(quotedIDs) == 0 {
+		return
+	}
+
+	recalled, err := h.streamOp.GetRecalledMessages(ctx, quotedIDs)
+	if err != nil {
+		logger.Warn("Failed to check recalled quoted messages", zap.Error(err))
+		return
+	}
+	for _, m := range msgs {
+		if m.ReplyTo != nil && recalled[m.ReplyTo.MsgId] {
+			m.ReplyTo.IsRecalled = true
+			m.ReplyTo.Preview = ""
+		}
+	}
+}
@@ -0,0 +1,616 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+)
+
+const (
+	// scheduledMaxAhead 定时消息最远可设置的发送时间
+	scheduledMaxAhead = 365 * 24 * time.Hour
+	// scheduledPollInterval 分发器检查到期消息的间隔
+	scheduledPollInterval = time.Second
+	// scheduledBatchSize 每次最多分发的到期消息数
+	scheduledBatchSize = 100
+	// scheduledRecoverInterval 恢复中断的分发、重建到期索引的间隔
+	scheduledRecoverInterval = time.Minute
+	// scheduledSendingTimeout 处于 sending 状态超过该时间视为分发器中断，重新入队
+	scheduledSendingTimeout = 5 * time.Minute
+	// scheduledRetryDelay 发送遇到临时错误时的重试间隔
+	scheduledRetryDelay = 30 * time.Second
+	// scheduledRetryWindow 超过计划发送时间该时长后不再重试，标记为失败
+	scheduledRetryWindow = time.Hour
+	// scheduledListLimit 查询定时消息的最大条数
+	scheduledListLimit = 200
+)
+
+// scheduledColumns 与 scanScheduledMessage 的字段顺序对应
+const scheduledColumns = `id, IFNULL(client_msg_id, ''), from_user_id, conversation_type, IFNULL(to_user_id, ''), IFNULL(group_id, ''),
+	IFNULL(content, ''), msg_type, IFNULL(payload, ''), IFNULL(reply_to_msg_id, ''), mention_user_ids, mention_all,
+	UNIX_TIMESTAMP(send_at), status, IFNULL(msg_id, ''), IFNULL(error, ''), UNIX_TIMESTAMP(created_at), UNIX_TIMESTAMP(updated_at)`
+
+// scheduledRow 定时消息记录
+type scheduledRow struct {
+	*pb.ScheduledMessage
+	ClientMsgID string
+	FromUserID  string
+}
+
+// isScheduled send_at 晚于当前时间时按定时消息处理
+func isScheduled(sendAt int64) bool {
+	return sendAt > time.Now().Unix()
+}
+
+// checkSendAt 校验定时发送时间
+func checkSendAt(sendAt int64) error {
+	if !isScheduled(sendAt) {
+		return status.Errorf(codes.InvalidArgument, "send_at must be in the future")
+	}
+	if time.Unix(sendAt, 0).After(time.Now().Add(scheduledMaxAhead)) {
+		return status.Errorf(codes.InvalidArgument, "send_at must be within %d days", int(scheduledMaxAhead.Hours()/24))
+	}
+	return nil
+}
+
+// schedulePrivateMessage 校验私聊消息并保存为定时消息
+func (h *MessageHandler) schedulePrivateMessage(ctx context.Context, fromUserID string, req *pb.SendMessageRequest) (*pb.ScheduledMessage, error) {
+	if err := checkSendAt(req.SendAt); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ToUserId) == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "to_user_id is required")
+	}
+
+	body, err := h.prepareMessageBody(ctx, req.MsgType, req.Content, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReplyToMsgId != "" {
+		if _, err := h.buildReplySnapshot(ctx, fromUserID, req.ReplyToMsgId, "private", req.ToUserId); err != nil {
+			return nil, err
+		}
+	}
+
+	return h.saveScheduledMessage(ctx, &scheduledRow{
+		ScheduledMessage: &pb.ScheduledMessage{
+			Id:               uuid.New().String(),
+			ConversationType: "private",
+			ToUserId:         req.ToUserId,
+			Content:          body.Content,
+			MsgType:          body.MsgType,
+			Payload:          body.Payload,
+			ReplyToMsgId:     req.ReplyToMsgId,
+			SendAt:           req.SendAt,
+		},
+		ClientMsgID: req.ClientMsgId,
+		FromUserID:  fromUserID,
+	})
+}
+
+// scheduleGroupMessage 校验群聊消息并保存为定时消息（@ 成员与群成员身份在发送时会再次校验）
+func (h *MessageHandler) scheduleGroupMessage(ctx context.Context, fromUserID string, req *pb.SendGroupMessageRequest) (*pb.ScheduledMessage, error) {
+	if err := checkSendAt(req.SendAt); err != nil {
+		return nil, err
+	}
+	if err := h.checkGroupMember(ctx, req.GroupId, fromUserID); err != nil {
+		return nil, err
+	}
+
+	body, err := h.prepareMessageBody(ctx, req.MsgType, req.Content, req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	if req.ReplyToMsgId != "" {
+		if _, err := h.buildReplySnapshot(ctx, fromUserID, req.ReplyToMsgId, "group", req.GroupId); err != nil {
+			return nil, err
+		}
+	}
+
+	memberIDs, err := h.getGroupMembers(ctx, req.GroupId)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to get group members")
+	}
+	mentions, err := h.resolveMentions(ctx, req.GroupId, fromUserID, req.MentionUserIds, req.MentionAll, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return h.saveScheduledMessage(ctx, &scheduledRow{
+		ScheduledMessage: &pb.ScheduledMessage{
+			Id:               uuid.New().String(),
+			ConversationType: "group",
+			GroupId:          req.GroupId,
+			Content:          body.Content,
+			MsgType:          body.MsgType,
+			Payload:          body.Payload,
+			ReplyToMsgId:     req.ReplyToMsgId,
+			MentionUserIds:   mentions.UserIDs,
+			MentionAll:       mentions.All,
+			SendAt:           req.SendAt,
+		},
+		ClientMsgID: req.ClientMsgId,
+		FromUserID:  fromUserID,
+	})
+}
+
+// saveScheduledMessage 写入定时消息表并加入到期索引
+// 同一 client_msg_id 重复创建时返回已存在的定时消息
+func (h *MessageHandler) saveScheduledMessage(ctx context.Context, row *scheduledRow) (*pb.ScheduledMessage, error) {
+	if len(row.ClientMsgID) > maxClientMsgIDLength {
+		return nil, status.Errorf(codes.InvalidArgument, "client_msg_id must be at most %d characters", maxClientMsgIDLength)
+	}
+
+	payloadJSON, err := (&messageBody{Payload: row.Payload}).storedPayloadJSON()
+	if err != nil {
+		return nil, err
+	}
+	mentionsJSON := (&groupMentions{UserIDs: row.MentionUserIds}).userIDsJSON()
+
+	_, err = h.db.ExecContext(ctx, `
+		INSERT INTO scheduled_messages (id, client_msg_id, from_user_id, conversation_type, to_user_id, group_id,
+			content, msg_type, payload, reply_to_msg_id, mention_user_ids, mention_all, send_at, status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, FROM_UNIXTIME(?), 'pending')`,
+		row.Id, nullableString(row.ClientMsgID), row.FromUserID, row.ConversationType, nullableString(row.ToUserId), nullableString(row.GroupId),
+		row.Content, row.MsgType, nullableString(payloadJSON), nullableString(row.ReplyToMsgId), mentionsJSON, row.MentionAll, row.SendAt)
+	if err != nil {
+		var mysqlErr *mysql.MySQLError
+		if row.ClientMsgID != "" && errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
+			existing, err := h.getScheduledByClientMsgID(ctx, row.FromUserID, row.ClientMsgID)
+			if err != nil {
+				return nil, err
+			}
+			logger.Info("Duplicate scheduled message ignored",
+				zap.String("client_msg_id", row.ClientMsgID),
+				zap.String("scheduled_id", existing.Id))
+			return existing.ScheduledMessage, nil
+		}
+		logger.Error("Failed to save scheduled message", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to save scheduled message")
+	}
+
+	// 到期索引写入失败不影响创建：分发器定期从数据库重建索引
+	if err := h.streamOp.ScheduleMessage(ctx, row.Id, row.SendAt); err != nil {
+		logger.Warn("Failed to add scheduled message to queue", zap.String("scheduled_id", row.Id), zap.Error(err))
+	}
+
+	logger.Info("Scheduled message created",
+		zap.String("scheduled_id", row.Id),
+		zap.String("from_user_id", row.FromUserID),
+		zap.Int64("send_at", row.SendAt))
+
+	row.Status = "pending"
+	row.CreatedAt = time.Now().Unix()
+	row.UpdatedAt = row.CreatedAt
+	return row.ScheduledMessage, nil
+}
+
+// ListScheduledMessages 查询当前用户的定时消息
+func (h *MessageHandler) ListScheduledMessages(ctx context.Context, req *pb.ListScheduledMessagesRequest) (*pb.ListScheduledMessagesResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statusFilter := req.Status
+	if statusFilter == "" {
+		statusFilter = "pending"
+	}
+	switch statusFilter {
+	case "pending", "sending", "sent", "canceled", "failed":
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid status")
+	}
+
+	query := "SELECT " + scheduledColumns + " FROM scheduled_messages WHERE from_user_id = ? AND status = ?"
+	args := []interface{}{userID, statusFilter}
+	if req.ConversationId != "" {
+		convType, peerID, ok := strings.Cut(req.ConversationId, ":")
+		switch {
+		case ok && convType == "private" && peerID != "":
+			query += " AND conversation_type = 'private' AND to_user_id = ?"
+		case ok && convType == "group" && peerID != "":
+			query += " AND conversation_type = 'group' AND group_id = ?"
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+		}
+		args = append(args, peerID)
+	}
+	query += " ORDER BY send_at ASC LIMIT ?"
+	args = append(args, scheduledListLimit)
+
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Error("Failed to list scheduled messages", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to list scheduled messages")
+	}
+	defer rows.Close()
+
+	var messages []*pb.ScheduledMessage
+	for rows.Next() {
+		row, err := scanScheduledMessage(rows)
+		if err != nil {
+			logger.Error("Failed to scan scheduled message", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to list scheduled messages")
+		}
+		messages = append(messages, row.ScheduledMessage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, status.Errorf(codes.Internal, "Failed to list scheduled messages")
+	}
+
+	return &pb.ListScheduledMessagesResponse{
+		Code:     0,
+		Message:  "查询成功",
+		Messages: messages,
+	}, nil
+}
+
+// UpdateScheduledMessage 修改尚未发送的定时消息的内容或发送时间
+func (h *MessageHandler) UpdateScheduledMessage(ctx context.Context, req *pb.UpdateScheduledMessageRequest) (*pb.UpdateScheduledMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	row, err := h.getOwnScheduledMessage(ctx, userID, req.ScheduledId)
+	if err != nil {
+		return nil, err
+	}
+	if row.Status != "pending" {
+		return nil, status.Errorf(codes.FailedPrecondition, "scheduled message is %s", row.Status)
+	}
+
+	if req.Content != "" || req.Payload != nil {
+		// 未指定的部分沿用原值：只修改负载时保留原有文字
+		content := req.Content
+		if content == "" {
+			content = row.Content
+		}
+		payload := req.Payload
+		if payload == nil {
+			payload = row.Payload
+		}
+		body, err := h.prepareMessageBody(ctx, row.MsgType, content, payload)
+		if err != nil {
+			return nil, err
+		}
+		row.Content = body.Content
+		row.Payload = body.Payload
+	}
+	if req.SendAt != 0 {
+		if err := checkSendAt(req.SendAt); err != nil {
+			return nil, err
+		}
+		row.SendAt = req.SendAt
+	}
+
+	payloadJSON, err := (&messageBody{Payload: row.Payload}).storedPayloadJSON()
+	if err != nil {
+		return nil, err
+	}
+
+	// 只更新仍处于 pending 的消息，避免与分发器并发
+	result, err := h.db.ExecContext(ctx, `
+		UPDATE scheduled_messages SET content = ?, payload = ?, send_at = FROM_UNIXTIME(?)
+		WHERE id = ? AND from_user_id = ? AND status = 'pending'`,
+		row.Content, nullableString(payloadJSON), row.SendAt, row.Id, userID)
+	if err != nil {
+		logger.Error("Failed to update scheduled message", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to update scheduled message")
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		return nil, status.Errorf(codes.FailedPrecondition, "scheduled message is no longer pending")
+	}
+
+	if err := h.streamOp.ScheduleMessage(ctx, row.Id, row.SendAt); err != nil {
+		logger.Warn("Failed to reschedule message", zap.String("scheduled_id", row.Id), zap.Error(err))
+	}
+
+	row.UpdatedAt = time.Now().Unix()
+	return &pb.UpdateScheduledMessageResponse{
+		Code:      0,
+		Message:   "定时消息已修改",
+		Scheduled: row.ScheduledMessage,
+	}, nil
+}
+
+// CancelScheduledMessage 取消尚未发送的定时消息
+func (h *MessageHandler) CancelScheduledMessage(ctx context.Context, req *pb.CancelScheduledMessageRequest) (*pb.CancelScheduledMessageResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.db.ExecContext(ctx,
+		"UPDATE scheduled_messages SET status = 'canceled' WHERE id = ? AND from_user_id = ? AND status = 'pending'",
+		req.ScheduledId, userID)
+	if err != nil {
+		logger.Error("Failed to cancel scheduled message", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to cancel scheduled message")
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		row, err := h.getOwnScheduledMessage(ctx, userID, req.ScheduledId)
+		if err != nil {
+			return nil, err
+		}
+		return nil, status.Errorf(codes.FailedPrecondition, "scheduled message is %s", row.Status)
+	}
+
+	if _, err := h.streamOp.UnscheduleMessage(ctx, req.ScheduledId); err != nil {
+		logger.Warn("Failed to remove scheduled message from queue", zap.String("scheduled_id", req.ScheduledId), zap.Error(err))
+	}
+
+	logger.Info("Scheduled message canceled", zap.String("scheduled_id", req.ScheduledId))
+
+	return &pb.CancelScheduledMessageResponse{
+		Code:    0,
+		Message: "定时消息已取消",
+	}, nil
+}
+
+// RunScheduledDispatcher 定时消息分发器：到期后按正常的发送流程（Stream、通知、落库）发送
+// 多个实例可同时运行，通过数据库状态的条件更新保证每条消息只被一个实例发送
+func (h *MessageHandler) RunScheduledDispatcher(ctx context.Context) {
+	logger.Info("Scheduled message dispatcher started")
+
+	h.recoverScheduledMessages(ctx)
+	lastRecover := time.Now()
+
+	ticker := time.NewTicker(scheduledPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if time.Since(lastRecover) >= scheduledRecoverInterval {
+			h.recoverScheduledMessages(ctx)
+			lastRecover = time.Now()
+		}
+
+		ids, err := h.streamOp.DueScheduledMessages(ctx, time.Now().Unix(), scheduledBatchSize)
+		if err != nil {
+			logger.Warn("Failed to get due scheduled messages", zap.Error(err))
+			continue
+		}
+		for _, id := range ids {
+			h.dispatchScheduledMessage(ctx, id)
+		}
+	}
+}
+
+// recoverScheduledMessages 将中断在 sending 状态的消息重新入队，并从数据库重建到期索引（Redis 数据丢失时）
+func (h *MessageHandler) recoverScheduledMessages(ctx context.Context) {
+	_, err := h.db.ExecContext(ctx,
+		"UPDATE scheduled_messages SET status = 'pending' WHERE status = 'sending' AND updated_at < FROM_UNIXTIME(?)",
+		time.Now().Add(-scheduledSendingTimeout).Unix())
+	if err != nil {
+		logger.Warn("Failed to recover interrupted scheduled messages", zap.Error(err))
+	}
+
+	rows, err := h.db.QueryContext(ctx, "SELECT id, UNIX_TIMESTAMP(send_at) FROM scheduled_messages WHERE status = 'pending'")
+	if err != nil {
+		logger.Warn("Failed to load pending scheduled messages", zap.Error(err))
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id     string
+			sendAt int64
+		)
+		if err := rows.Scan(&id, &sendAt); err != nil {
+			logger.Warn("Failed to scan pending scheduled message", zap.Error(err))
+			return
+		}
+		if err := h.streamOp.RestoreScheduledMessage(ctx, id, sendAt); err != nil {
+			logger.Warn("Failed to requeue scheduled message", zap.String("scheduled_id", id), zap.Error(err))
+			return
+		}
+	}
+}
+
+// dispatchScheduledMessage 认领并发送一条到期的定时消息
+func (h *MessageHandler) dispatchScheduledMessage(ctx context.Context, id string) {
+	now := time.Now().Unix()
+
+	// 认领：只有把状态从 pending 改为 sending 的实例负责发送
+	result, err := h.db.ExecContext(ctx,
+		"UPDATE scheduled_messages SET status = 'sending' WHERE id = ? AND status = 'pending' AND send_at <= FROM_UNIXTIME(?)",
+		id, now)
+	if err != nil {
+		logger.Warn("Failed to claim scheduled message", zap.String("scheduled_id", id), zap.Error(err))
+		return
+	}
+	if affected, _ := result.RowsAffected(); affected == 0 {
+		// 已被其他实例发送、已取消，或发送时间已被修改
+		var (
+			rowStatus string
+			sendAt    int64
+		)
+		err := h.db.QueryRowContext(ctx, "SELECT status, UNIX_TIMESTAMP(send_at) FROM scheduled_messages WHERE id = ?", id).Scan(&rowStatus, &sendAt)
+		if err == nil && rowStatus == "pending" {
+			_ = h.streamOp.ScheduleMessage(ctx, id, sendAt)
+			return
+		}
+		if err == nil && rowStatus == "sending" {
+			return
+		}
+		_, _ = h.streamOp.UnscheduleMessage(ctx, id)
+		return
+	}
+	_, _ = h.streamOp.UnscheduleMessage(ctx, id)
+
+	row, err := h.getScheduledMessage(ctx, "SELECT "+scheduledColumns+" FROM scheduled_messages WHERE id = ?", id)
+	if err != nil {
+		logger.Error("Failed to load claimed scheduled message", zap.String("scheduled_id", id), zap.Error(err))
+		return
+	}
+
+	// 分发器重试时以 client_msg_id 去重，避免重复发送
+	clientMsgID := row.ClientMsgID
+	if clientMsgID == "" {
+		clientMsgID = "scheduled:" + row.Id
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var msgID string
+	if row.ConversationType == "group" {
+		var res *pb.SendGroupMessageResponse
+		res, err = h.sendGroupMessage(sendCtx, row.FromUserID, &pb.SendGroupMessageRequest{
+			GroupId:        row.GroupId,
+			Content:        row.Content,
+			MsgType:        row.MsgType,
+			Payload:        row.Payload,
+			ReplyToMsgId:   row.ReplyToMsgId,
+			MentionUserIds: row.MentionUserIds,
+			MentionAll:     row.MentionAll,
+			ClientMsgId:    clientMsgID,
+		})
+		if err == nil {
+			msgID = res.Msg.Id
+		}
+	} else {
+		var res *pb.SendMessageResponse
+		res, err = h.sendPrivateMessage(sendCtx, row.FromUserID, &pb.SendMessageRequest{
+			ToUserId:     row.ToUserId,
+			Content:      row.Content,
+			MsgType:      row.MsgType,
+			Payload:      row.Payload,
+			ReplyToMsgId: row.ReplyToMsgId,
+			ClientMsgId:  clientMsgID,
+		})
+		if err == nil {
+			msgID = res.Msg.Id
+		}
+	}
+
+	if err != nil {
+		code := status.Code(err)
+		retryable := code == codes.Internal || code == codes.Unavailable || code == codes.DeadlineExceeded
+		if retryable && time.Since(time.Unix(row.SendAt, 0)) < scheduledRetryWindow {
+			logger.Warn("Scheduled message send failed, will retry",
+				zap.String("scheduled_id", row.Id),
+				zap.Error(err))
+			_, _ = h.db.ExecContext(ctx, "UPDATE scheduled_messages SET status = 'pending' WHERE id = ? AND status = 'sending'", row.Id)
+			_ = h.streamOp.ScheduleMessage(ctx, row.Id, time.Now().Add(scheduledRetryDelay).Unix())
+			return
+		}
+
+		reason := truncateRunes(status.Convert(err).Message(), 200)
+		logger.Warn("Scheduled message send failed",
+			zap.String("scheduled_id", row.Id),
+			zap.Error(err))
+		_, dbErr := h.db.ExecContext(ctx, "UPDATE scheduled_messages SET status = 'failed', error = ? WHERE id = ?", reason, row.Id)
+		if dbErr != nil {
+			logger.Error("Failed to mark scheduled message as failed", zap.String("scheduled_id", row.Id), zap.Error(dbErr))
+		}
+		h.publishScheduledEvent(ctx, row, "failed", "", reason)
+		return
+	}
+
+	_, err = h.db.ExecContext(ctx, "UPDATE scheduled_messages SET status = 'sent', msg_id = ? WHERE id = ?", msgID, row.Id)
+	if err != nil {
+		logger.Error("Failed to mark scheduled message as sent", zap.String("scheduled_id", row.Id), zap.Error(err))
+	}
+
+	logger.Info("Scheduled message sent",
+		zap.String("scheduled_id", row.Id),
+		zap.String("msg_id", msgID))
+	h.publishScheduledEvent(ctx, row, "sent", msgID, "")
+}
+
+// publishScheduledEvent 通知发送者定时消息的发送结果
+func (h *MessageHandler) publishScheduledEvent(ctx context.Context, row *scheduledRow, result, msgID, reason string) {
+	peerID := row.ToUserId
+	if row.ConversationType == "group" {
+		peerID = row.GroupId
+	}
+	h.publishEvent(ctx, []string{row.FromUserID}, map[string]interface{}{
+		"type":              "scheduled",
+		"scheduled_id":      row.Id,
+		"status":            result,
+		"msg_id":            msgID,
+		"error":             reason,
+		"conversation_type": row.ConversationType,
+		"peer_id":           peerID,
+	})
+}
+
+// getOwnScheduledMessage 查询当前用户的一条定时消息
+func (h *MessageHandler) getOwnScheduledMessage(ctx context.Context, userID, scheduledID string) (*scheduledRow, error) {
+	if scheduledID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "scheduled_id is required")
+	}
+	row, err := h.getScheduledMessage(ctx,
+		"SELECT "+scheduledColumns+" FROM scheduled_messages WHERE id = ? AND from_user_id = ?", scheduledID, userID)
+	if err == sql.ErrNoRows {
+		return nil, status.Errorf(codes.NotFound, "scheduled message not found")
+	}
+	if err != nil {
+		logger.Error("Failed to query scheduled message", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to query scheduled message")
+	}
+	return row, nil
+}
+
+// getScheduledByClientMsgID 按 client_msg_id 查询定时消息
+func (h *MessageHandler) getScheduledByClientMsgID(ctx context.Context, userID, clientMsgID string) (*scheduledRow, error) {
+	row, err := h.getScheduledMessage(ctx,
+		"SELECT "+scheduledColumns+" FROM scheduled_messages WHERE from_user_id = ? AND client_msg_id = ?", userID, clientMsgID)
+	if err != nil {
+		logger.Error("Failed to query scheduled message by client_msg_id", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to query scheduled message")
+	}
+	return row, nil
+}
+
+// getScheduledMessage 执行查询并返回单条定时消息
+func (h *MessageHandler) getScheduledMessage(ctx context.Context, query string, args ...interface{}) (*scheduledRow, error) {
+	return scanScheduledMessage(h.db.QueryRowContext(ctx, query, args...))
+}
+
+// rowScanner *sql.Row 与 *sql.Rows 的公共部分
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanScheduledMessage 按 scheduledColumns 的顺序读取一行定时消息
+func scanScheduledMessage(scanner rowScanner) (*scheduledRow, error) {
+	row := &scheduledRow{ScheduledMessage: &pb.ScheduledMessage{}}
+	var (
+		payloadJSON    string
+		mentionUserIDs sql.NullString
+	)
+	err := scanner.Scan(&row.Id, &row.ClientMsgID, &row.FromUserID, &row.ConversationType, &row.ToUserId, &row.GroupId,
+		&row.Content, &row.MsgType, &payloadJSON, &row.ReplyToMsgId, &mentionUserIDs, &row.MentionAll,
+		&row.SendAt, &row.Status, &row.MsgId, &row.Error, &row.CreatedAt, &row.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	row.Payload, _ = decodeStoredPayload(payloadJSON)
+	if mentionUserIDs.Valid {
+		_ = json.Unmarshal([]byte(mentionUserIDs.String), &row.MentionUserIds)
+	}
+	return row, nil
+}
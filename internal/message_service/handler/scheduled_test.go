@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+)
+
+// scheduledColumnNames 与 scheduledColumns 的字段顺序对应
+var scheduledColumnNames = []string{"id", "client_msg_id", "from_user_id", "conversation_type", "to_user_id", "group_id",
+	"content", "msg_type", "payload", "reply_to_msg_id", "mention_user_ids", "mention_all",
+	"send_at", "status", "msg_id", "error", "created_at", "updated_at"}
+
+// scheduledDBRow 返回发给 b 的一条定时私聊消息的数据库记录
+func scheduledDBRow(t *testing.T, content, msgType string, payload *pb.MessagePayload, sendAt int64) []driver.Value {
+	t.Helper()
+	payloadJSON, err := (&messageBody{Payload: payload}).storedPayloadJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	return []driver.Value{"s1", "", "a", "private", "b", "", content, msgType, payloadJSON, "", nil, false,
+		sendAt, "pending", "", "", now, now}
+}
+
+// execRecorder 记录数据库写入，匹配 affect 中任一片段的语句影响一行
+type execRecorder struct {
+	mu     sync.Mutex
+	affect []string
+	execs  []string
+	args   [][]driver.Value
+}
+
+func (r *execRecorder) exec(query string, args []driver.Value) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execs = append(r.execs, query)
+	r.args = append(r.args, args)
+	for _, fragment := range r.affect {
+		if strings.Contains(query, fragment) {
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+// find 返回第一条包含 fragment 的写入语句的参数
+func (r *execRecorder) find(fragment string) ([]driver.Value, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, query := range r.execs {
+		if strings.Contains(query, fragment) {
+			return r.args[i], true
+		}
+	}
+	return nil, false
+}
+
+func TestScheduleMessageStoresBodyContent(t *testing.T) {
+	image := &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/img/1.png", Width: 100, Height: 80}}
+	sendAt := time.Now().Add(time.Hour).Unix()
+
+	tests := []struct {
+		name        string
+		group       bool
+		msgType     string
+		content     string
+		payload     *pb.MessagePayload
+		wantContent string
+	}{
+		{name: "private text", content: "hi", wantContent: "hi"},
+		{name: "private image without caption", msgType: MsgTypeImage, payload: image, wantContent: "[图片]"},
+		{name: "private image with caption", msgType: MsgTypeImage, content: "看这个", payload: image, wantContent: "看这个"},
+		{name: "group image without caption", group: true, msgType: MsgTypeImage, payload: image, wantContent: "[图片]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &execRecorder{}
+			h, _ := newTestHandlerWithExec(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				switch {
+				case strings.Contains(query, "SELECT 1 FROM group_members"):
+					return []string{"1"}, [][]driver.Value{{int64(1)}}, nil
+				case strings.Contains(query, "FROM group_members WHERE group_id = ?"):
+					return []string{"user_id", "joined_at"}, [][]driver.Value{{"a", int64(0)}, {"b", int64(0)}}, nil
+				}
+				return nil, nil, nil
+			}, rec.exec)
+			ctx := userContext(t, "a")
+
+			var scheduled *pb.ScheduledMessage
+			if tt.group {
+				res, err := h.SendGroupMessage(ctx, &pb.SendGroupMessageRequest{GroupId: "g", MsgType: tt.msgType, Content: tt.content, Payload: tt.payload, SendAt: sendAt})
+				if err != nil {
+					t.Fatal(err)
+				}
+				scheduled = res.Scheduled
+			} else {
+				res, err := h.SendMessage(ctx, &pb.SendMessageRequest{ToUserId: "b", MsgType: tt.msgType, Content: tt.content, Payload: tt.payload, SendAt: sendAt})
+				if err != nil {
+					t.Fatal(err)
+				}
+				scheduled = res.Scheduled
+			}
+
+			if scheduled.GetContent() != tt.wantContent {
+				t.Errorf("scheduled content = %q, want %q", scheduled.GetContent(), tt.wantContent)
+			}
+			args, ok := rec.find("INSERT INTO scheduled_messages")
+			if !ok {
+				t.Fatal("scheduled message was not saved")
+			}
+			if args[6] != tt.wantContent {
+				t.Errorf("stored content = %v, want %q", args[6], tt.wantContent)
+			}
+
+			due, err := h.streamOp.DueScheduledMessages(context.Background(), sendAt, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(due) != 1 || due[0] != scheduled.GetId() {
+				t.Errorf("due = %v, want [%s]", due, scheduled.GetId())
+			}
+		})
+	}
+}
+
+func TestUpdateScheduledMessage(t *testing.T) {
+	oldImage := &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/img/1.png", Width: 100, Height: 80}}
+	newImage := &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/img/2.png", Width: 100, Height: 80}}
+	sendAt := time.Now().Add(time.Hour).Unix()
+	later := time.Now().Add(2 * time.Hour).Unix()
+
+	tests := []struct {
+		name        string
+		content     string // 记录中的文字
+		msgType     string
+		payload     *pb.MessagePayload
+		req         *pb.UpdateScheduledMessageRequest
+		wantCode    codes.Code
+		wantContent string
+		wantOssKey  string
+		wantSendAt  int64
+	}{
+		{
+			name: "text content", content: "old", msgType: MsgTypeText,
+			req:         &pb.UpdateScheduledMessageRequest{Content: "new"},
+			wantContent: "new", wantSendAt: sendAt,
+		},
+		{
+			name: "image caption", content: "[图片]", msgType: MsgTypeImage, payload: oldImage,
+			req:         &pb.UpdateScheduledMessageRequest{Content: "看这个"},
+			wantContent: "看这个", wantOssKey: "chatim/img/1.png", wantSendAt: sendAt,
+		},
+		{
+			name: "image payload keeps caption", content: "看这个", msgType: MsgTypeImage, payload: oldImage,
+			req:         &pb.UpdateScheduledMessageRequest{Payload: newImage},
+			wantContent: "看这个", wantOssKey: "chatim/img/2.png", wantSendAt: sendAt,
+		},
+		{
+			name: "send time only", content: "[图片]", msgType: MsgTypeImage, payload: oldImage,
+			req:         &pb.UpdateScheduledMessageRequest{SendAt: later},
+			wantContent: "[图片]", wantOssKey: "chatim/img/1.png", wantSendAt: later,
+		},
+		{
+			name: "payload on text message", content: "old", msgType: MsgTypeText,
+			req:      &pb.UpdateScheduledMessageRequest{Payload: newImage},
+			wantCode: codes.InvalidArgument,
+		},
+		{
+			name: "send time in the past", content: "old", msgType: MsgTypeText,
+			req:      &pb.UpdateScheduledMessageRequest{SendAt: time.Now().Add(-time.Minute).Unix()},
+			wantCode: codes.InvalidArgument,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &execRecorder{affect: []string{"UPDATE scheduled_messages SET content"}}
+			h, _ := newTestHandlerWithExec(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				return scheduledColumnNames, [][]driver.Value{scheduledDBRow(t, tt.content, tt.msgType, tt.payload, sendAt)}, nil
+			}, rec.exec)
+
+			tt.req.ScheduledId = "s1"
+			res, err := h.UpdateScheduledMessage(userContext(t, "a"), tt.req)
+			if tt.wantCode != codes.OK {
+				if status.Code(err) != tt.wantCode {
+					t.Fatalf("err = %v, want code %v", err, tt.wantCode)
+				}
+				if _, ok := rec.find("UPDATE scheduled_messages"); ok {
+					t.Error("rejected update was written to the database")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			args, ok := rec.find("UPDATE scheduled_messages SET content")
+			if !ok {
+				t.Fatal("scheduled message was not updated")
+			}
+			payload, _ := decodeStoredPayload(stringValue(args[1]))
+			if args[0] != tt.wantContent || payload.GetImage().GetOssKey() != tt.wantOssKey || args[2] != tt.wantSendAt {
+				t.Errorf("stored = %v %q %v, want %q %q %d", args[0], payload.GetImage().GetOssKey(), args[2], tt.wantContent, tt.wantOssKey, tt.wantSendAt)
+			}
+			if res.Scheduled.Content != tt.wantContent || res.Scheduled.SendAt != tt.wantSendAt {
+				t.Errorf("response = %q %d, want %q %d", res.Scheduled.Content, res.Scheduled.SendAt, tt.wantContent, tt.wantSendAt)
+			}
+		})
+	}
+}
+
+func TestDispatchScheduledMessage(t *testing.T) {
+	image := &pb.MessagePayload{Image: &pb.ImagePayload{OssKey: "chatim/img/1.png", Width: 100, Height: 80}}
+	sendAt := time.Now().Add(-time.Second).Unix()
+
+	tests := []struct {
+		name        string
+		content     string
+		msgType     string
+		payload     *pb.MessagePayload
+		claimed     bool
+		wantStatus  string // 分发后写入的状态，空表示未发送
+		wantContent string
+	}{
+		{name: "text", content: "hi", msgType: MsgTypeText, claimed: true, wantStatus: "sent", wantContent: "hi"},
+		{name: "image", content: "[图片]", msgType: MsgTypeImage, payload: image, claimed: true, wantStatus: "sent", wantContent: "[图片]"},
+		{name: "invalid message", content: "", msgType: MsgTypeText, claimed: true, wantStatus: "failed"},
+		{name: "claimed by another instance", content: "hi", msgType: MsgTypeText},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := &execRecorder{}
+			if tt.claimed {
+				rec.affect = []string{"SET status = 'sending'"}
+			}
+			rowStatus := "pending"
+			if !tt.claimed {
+				rowStatus = "sending"
+			}
+			h, _ := newTestHandlerWithExec(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+				if strings.HasPrefix(query, "SELECT status, UNIX_TIMESTAMP(send_at)") {
+					return []string{"status", "send_at"}, [][]driver.Value{{rowStatus, sendAt}}, nil
+				}
+				if strings.Contains(query, "FROM scheduled_messages WHERE id = ?") {
+					return scheduledColumnNames, [][]driver.Value{scheduledDBRow(t, tt.content, tt.msgType, tt.payload, sendAt)}, nil
+				}
+				return nil, nil, nil
+			}, rec.exec)
+			ctx := context.Background()
+			if err := h.streamOp.ScheduleMessage(ctx, "s1", sendAt); err != nil {
+				t.Fatal(err)
+			}
+
+			h.dispatchScheduledMessage(ctx, "s1")
+
+			entries, err := h.rdb.XRange(ctx, "stream:private:b", "-", "+").Result()
+			if err != nil {
+				t.Fatal(err)
+			}
+			args, sent := rec.find("SET status = 'sent'")
+			switch tt.wantStatus {
+			case "sent":
+				if len(entries) != 1 || entries[0].Values["content"] != tt.wantContent || entries[0].Values["msg_type"] != tt.msgType {
+					t.Fatalf("stream entries = %v, want one %s message %q", entries, tt.msgType, tt.wantContent)
+				}
+				if !sent || args[0] != entries[0].Values["id"] {
+					t.Errorf("sent status = %v, want msg_id %v", args, entries[0].Values["id"])
+				}
+			case "failed":
+				if _, ok := rec.find("SET status = 'failed'"); !ok || len(entries) != 0 || sent {
+					t.Errorf("invalid message was not marked as failed (entries %d, sent %v)", len(entries), sent)
+				}
+			default:
+				if len(entries) != 0 || sent {
+					t.Errorf("message claimed by another instance was sent")
+				}
+			}
+
+			// 认领后从到期索引中移除；被其他实例认领的消息由该实例移除
+			due, err := h.streamOp.DueScheduledMessages(ctx, time.Now().Unix(), 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.claimed && len(due) != 0 {
+				t.Errorf("due after dispatch = %v, want empty", due)
+			}
+		})
+	}
+}
+
+// stringValue 将数据库参数转换为字符串（NULL 为空字符串）
+func stringValue(v driver.Value) string {
+	s, _ := v.(string)
+	return s
+}
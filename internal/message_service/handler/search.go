@@ -0,0 +1,356 @@
+package handler
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	pb "ChatIM/api/proto/message"
+	"ChatIM/pkg/auth"
+	"ChatIM/pkg/logger"
+)
+
+const (
+	// searchMinQueryRunes ngram 分词的最小长度（ngram_token_size 默认 2），更短的关键词无法命中索引
+	searchMinQueryRunes = 2
+	// searchMaxQueryRunes 关键词最大长度
+	searchMaxQueryRunes = 100
+	// snippetContextRunes 片段中关键词前后保留的字符数
+	snippetContextRunes = 20
+)
+
+// SearchMessages 全文搜索当前用户参与的私聊和所在群的历史消息
+// 基于 messages / group_messages 的 ngram FULLTEXT 索引，已撤回的消息不参与搜索
+func (h *MessageHandler) SearchMessages(ctx context.Context, req *pb.SearchMessagesRequest) (*pb.SearchMessagesResponse, error) {
+	userID, err := auth.GetUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(req.Query)
+	if n := utf8.RuneCountInString(query); n < searchMinQueryRunes || n > searchMaxQueryRunes {
+		return nil, status.Errorf(codes.InvalidArgument, "query must be %d-%d characters", searchMinQueryRunes, searchMaxQueryRunes)
+	}
+
+	var convType, peerID string
+	if req.ConversationId != "" {
+		var ok bool
+		convType, peerID, ok = strings.Cut(req.ConversationId, ":")
+		if !ok || peerID == "" || (convType != "private" && convType != "group") {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid conversation_id")
+		}
+	}
+
+	var cursor historyCursor
+	if req.Before != "" {
+		var ok bool
+		if cursor, ok = parseHistoryCursor(req.Before); !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid before")
+		}
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	// 以短语方式匹配，关键词中的双引号会破坏 BOOLEAN MODE 语法
+	against := `"` + strings.ReplaceAll(query, `"`, " ") + `"`
+
+	var (
+		branches []string
+		args     []interface{}
+	)
+
+	if convType != "group" {
+		branch := `
+			SELECT pm.id, 'private' AS type, pm.from_user_id, IFNULL(u.username, ''), pm.to_user_id, '' AS group_id,
+				IFNULL(pm.content, ''), IFNULL(pm.msg_type, 'text'), IFNULL(pm.payload, ''),
+				UNIX_TIMESTAMP(pm.created_at) AS created_ts, UNIX_TIMESTAMP(pm.edited_at), NULL, FALSE
+			FROM messages pm
+			LEFT JOIN users u ON u.id = pm.from_user_id
+			WHERE MATCH(pm.content) AGAINST(? IN BOOLEAN MODE)
+				AND IFNULL(pm.is_recalled, FALSE) = FALSE
+				AND (pm.expires_at IS NULL OR pm.expires_at > NOW())
+				AND (pm.from_user_id = ? OR pm.to_user_id = ?)`
+		args = append(args, against, userID, userID)
+		if convType == "private" {
+			branch += " AND ((pm.from_user_id = ? AND pm.to_user_id = ?) OR (pm.from_user_id = ? AND pm.to_user_id = ?))"
+			args = append(args, userID, peerID, peerID, userID)
+		}
+		if req.FromUserId != "" {
+			branch += " AND pm.from_user_id = ?"
+			args = append(args, req.FromUserId)
+		}
+		if cursor.CreatedAt > 0 {
+			branch += " AND (pm.created_at < FROM_UNIXTIME(?) OR (pm.created_at = FROM_UNIXTIME(?) AND pm.id < ?))"
+			args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.MsgID)
+		}
+		branches = append(branches, branch)
+	}
+
+	if convType != "private" {
+		branch := `
+			SELECT gm.id, 'group' AS type, gm.from_user_id, IFNULL(u.username, ''), '' AS to_user_id, gm.group_id,
+				IFNULL(gm.content, ''), IFNULL(gm.msg_type, 'text'), IFNULL(gm.payload, ''),
+				UNIX_TIMESTAMP(gm.created_at) AS created_ts, UNIX_TIMESTAMP(gm.edited_at), gm.mention_user_ids, IFNULL(gm.mention_all, FALSE)
+			FROM group_messages gm
+			JOIN group_members m ON m.group_id = gm.group_id AND m.user_id = ? AND m.is_deleted = 0
+			LEFT JOIN users u ON u.id = gm.from_user_id
+			WHERE MATCH(gm.content) AGAINST(? IN BOOLEAN MODE)
+				AND IFNULL(gm.is_recalled, FALSE) = FALSE
+				AND (gm.expires_at IS NULL OR gm.expires_at > NOW())
+				AND gm.created_at >= m.joined_at`
+		args = append(args, userID, against)
+		if convType == "group" {
+			branch += " AND gm.group_id = ?"
+			args = append(args, peerID)
+		}
+		if req.FromUserId != "" {
+			branch += " AND gm.from_user_id = ?"
+			args = append(args, req.FromUserId)
+		}
+		if cursor.CreatedAt > 0 {
+			branch += " AND (gm.created_at < FROM_UNIXTIME(?) OR (gm.created_at = FROM_UNIXTIME(?) AND gm.id < ?))"
+			args = append(args, cursor.CreatedAt, cursor.CreatedAt, cursor.MsgID)
+		}
+		branches = append(branches, branch)
+	}
+
+	// 多取一条用于判断是否还有更多结果
+	sqlQuery := strings.Join(branches, "\nUNION ALL\n") + "\nORDER BY created_ts DESC, id DESC LIMIT ?"
+	args = append(args, limit+1)
+
+	rows, err := h.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		logger.Error("Failed to search messages", zap.String("user_id", userID), zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to search messages")
+	}
+	defer rows.Close()
+
+	var (
+		msgs    []*pb.UnifiedMessage
+		hasMore bool
+	)
+	for rows.Next() {
+		if int64(len(msgs)) >= limit {
+			hasMore = true
+			break
+		}
+
+		var (
+			m              pb.UnifiedMessage
+			payloadJSON    string
+			editedAt       sql.NullInt64
+			mentionUserIDs sql.NullString
+		)
+		if err := rows.Scan(&m.Id, &m.Type, &m.FromUserId, &m.FromUserName, &m.ToUserId, &m.GroupId,
+			&m.Content, &m.MsgType, &payloadJSON, &m.CreatedAt, &editedAt, &mentionUserIDs, &m.MentionAll); err != nil {
+			logger.Error("Failed to scan search result", zap.Error(err))
+			return nil, status.Errorf(codes.Internal, "Failed to search messages")
+		}
+
+		m.IsRead = true
+		m.Payload, m.ReplyTo = decodeStoredPayload(payloadJSON)
+		if editedAt.Valid {
+			m.IsEdited = true
+			m.EditedAt = editedAt.Int64
+		}
+		if mentionUserIDs.Valid {
+			_ = json.Unmarshal([]byte(mentionUserIDs.String), &m.MentionUserIds)
+		}
+		msgs = append(msgs, &m)
+	}
+	if err := rows.Err(); err != nil {
+		logger.Error("Failed to iterate search results", zap.Error(err))
+		return nil, status.Errorf(codes.Internal, "Failed to search messages")
+	}
+
+	nextBefore := ""
+	if hasMore && len(msgs) > 0 {
+		last := msgs[len(msgs)-1]
+		nextBefore = historyCursor{CreatedAt: last.CreatedAt, MsgID: last.Id}.String()
+	}
+
+	// 数据库写入撤回状态之前 Redis 中已标记撤回的消息同样不返回
+	h.applyMessageStates(ctx, userID, msgs)
+
+	hits := make([]*pb.UnifiedMessage, 0, len(msgs))
+	for _, m := range msgs {
+		if !m.IsRecalled {
+			hits = append(hits, m)
+		}
+	}
+	prevIDs, nextIDs := h.contextMessageIDs(ctx, userID, hits)
+
+	results := make([]*pb.MessageSearchResult, 0, len(hits))
+	for _, m := range hits {
+		result := &pb.MessageSearchResult{
+			Message: m,
+		}
+		if m.Type == "group" {
+			result.ConversationId = "group:" + m.GroupId
+		} else {
+			peer := m.ToUserId
+			if peer == userID {
+				peer = m.FromUserId
+			}
+			result.ConversationId = "private:" + peer
+		}
+		result.Snippet, result.Highlights = buildSnippet(m.Content, query)
+		result.PrevMsgId, result.NextMsgId = prevIDs[m.Id], nextIDs[m.Id]
+
+		results = append(results, result)
+	}
+
+	logger.Info("Messages searched",
+		zap.String("user_id", userID),
+		zap.String("conversation_id", req.ConversationId),
+		zap.Int("count", len(results)),
+		zap.Bool("has_more", hasMore))
+
+	return &pb.SearchMessagesResponse{
+		Code:       0,
+		Message:    "搜索成功",
+		Results:    results,
+		NextBefore: nextBefore,
+		HasMore:    hasMore,
+	}, nil
+}
+
+// contextMessageIDs 批量查询同一会话中紧邻每条消息的上一条和下一条消息ID（按消息ID索引），用于跳转到上下文
+// 所有消息的前后消息在一次查询中返回，与搜索相同，已撤回、已过期和入群之前的消息不作为上下文
+func (h *MessageHandler) contextMessageIDs(ctx context.Context, userID string, msgs []*pb.UnifiedMessage) (prev, next map[string]string) {
+	prev = make(map[string]string, len(msgs))
+	next = make(map[string]string, len(msgs))
+	if len(msgs) == 0 {
+		return prev, next
+	}
+
+	var (
+		parts []string
+		args  []interface{}
+	)
+	for _, m := range msgs {
+		var (
+			from      string
+			where     string
+			whereArgs []interface{}
+		)
+		if m.Type == "group" {
+			from = "group_messages c JOIN group_members m ON m.group_id = c.group_id AND m.user_id = ? AND m.is_deleted = 0"
+			where = "c.group_id = ? AND c.created_at >= m.joined_at"
+			whereArgs = []interface{}{userID, m.GroupId}
+		} else {
+			from = "messages c"
+			where = "((c.from_user_id = ? AND c.to_user_id = ?) OR (c.from_user_id = ? AND c.to_user_id = ?))"
+			whereArgs = []interface{}{m.FromUserId, m.ToUserId, m.ToUserId, m.FromUserId}
+		}
+
+		for _, dir := range []struct{ name, cmp, order string }{{"prev", "<", "DESC"}, {"next", ">", "ASC"}} {
+			parts = append(parts, "(SELECT ? AS hit_id, ? AS dir, c.id FROM "+from+" WHERE "+where+
+				" AND IFNULL(c.is_recalled, FALSE) = FALSE AND (c.expires_at IS NULL OR c.expires_at > NOW())"+
+				" AND (c.created_at "+dir.cmp+" FROM_UNIXTIME(?) OR (c.created_at = FROM_UNIXTIME(?) AND c.id "+dir.cmp+" ?))"+
+				" ORDER BY c.created_at "+dir.order+", c.id "+dir.order+" LIMIT 1)")
+			args = append(args, m.Id, dir.name)
+			args = append(args, whereArgs...)
+			args = append(args, m.CreatedAt, m.CreatedAt, m.Id)
+		}
+	}
+
+	rows, err := h.db.QueryContext(ctx, strings.Join(parts, "\nUNION ALL\n"), args...)
+	if err != nil {
+		logger.Warn("Failed to query context messages", zap.String("user_id", userID), zap.Error(err))
+		return prev, next
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var hitID, dir, id string
+		if err := rows.Scan(&hitID, &dir, &id); err != nil {
+			logger.Warn("Failed to scan context message", zap.Error(err))
+			return prev, next
+		}
+		if dir == "prev" {
+			prev[hitID] = id
+		} else {
+			next[hitID] = id
+		}
+	}
+	if err := rows.Err(); err != nil {
+		logger.Warn("Failed to iterate context messages", zap.Error(err))
+	}
+	return prev, next
+}
+
+// buildSnippet 截取关键词附近的内容片段，并返回片段中每处关键词的位置（不区分大小写）
+func buildSnippet(content, query string) (string, []*pb.HighlightRange) {
+	runes := []rune(content)
+	lowerRunes := []rune(strings.ToLower(content))
+	needle := []rune(strings.ToLower(query))
+
+	// 大小写转换改变了字符数时无法按位置对应，退化为不高亮
+	if len(lowerRunes) != len(runes) {
+		return truncateRunes(content, snippetContextRunes*2), nil
+	}
+
+	first := indexRunes(lowerRunes, needle, 0)
+	if first < 0 {
+		return truncateRunes(content, snippetContextRunes*2), nil
+	}
+
+	start := first - snippetContextRunes
+	if start < 0 {
+		start = 0
+	}
+	end := first + len(needle) + snippetContextRunes
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	prefix := ""
+	if start > 0 {
+		prefix = "…"
+	}
+	suffix := ""
+	if end < len(runes) {
+		suffix = "…"
+	}
+	offset := utf8.RuneCountInString(prefix) - start
+
+	var highlights []*pb.HighlightRange
+	for i := first; i >= 0 && i+len(needle) <= end; i = indexRunes(lowerRunes, needle, i+len(needle)) {
+		highlights = append(highlights, &pb.HighlightRange{
+			Start:  int32(i + offset),
+			Length: int32(len(needle)),
+		})
+	}
+
+	return prefix + string(runes[start:end]) + suffix, highlights
+}
+
+// indexRunes 在 s[from:] 中查找 sub，返回其在 s 中的位置，未找到返回 -1
+func indexRunes(s, sub []rune, from int) int {
+	for i := from; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
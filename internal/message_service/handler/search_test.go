@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"database/sql/driver"
+	"strings"
+	"testing"
+
+	pb "ChatIM/api/proto/message"
+)
+
+func TestSearchMessagesBatchesContextLookup(t *testing.T) {
+	var (
+		searchQuery   string
+		contextCalls  int
+		contextParts  int
+		searchColumns = []string{"id", "type", "from_user_id", "username", "to_user_id", "group_id",
+			"content", "msg_type", "payload", "created_ts", "edited_at", "mention_user_ids", "mention_all"}
+	)
+	h, _ := newTestHandler(t, func(query string, args []driver.Value) ([]string, [][]driver.Value, error) {
+		switch {
+		case strings.Contains(query, "MATCH("):
+			searchQuery = query
+			return searchColumns, [][]driver.Value{
+				{"m-2", "group", "b", "bob", "", "g", "hello group", "text", "", int64(1700000002), nil, nil, false},
+				{"m-1", "private", "a", "alice", "b", "", "hello private", "text", "", int64(1700000001), nil, nil, false},
+			}, nil
+		case strings.Contains(query, "AS hit_id"):
+			contextCalls++
+			contextParts = strings.Count(query, "SELECT ")
+			return []string{"hit_id", "dir", "id"}, [][]driver.Value{
+				{"m-2", "prev", "g-1"},
+				{"m-2", "next", "g-3"},
+				{"m-1", "next", "p-2"},
+			}, nil
+		}
+		return nil, nil, nil
+	})
+
+	res, err := h.SearchMessages(userContext(t, "a"), &pb.SearchMessagesRequest{Query: "hello"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(searchQuery, "gm.created_at >= m.joined_at") {
+		t.Error("group search does not exclude messages sent before the user joined")
+	}
+	if contextCalls != 1 || contextParts != 4 {
+		t.Fatalf("context lookup ran %d queries with %d selects, want 1 query with 4", contextCalls, contextParts)
+	}
+
+	want := map[string][2]string{"m-2": {"g-1", "g-3"}, "m-1": {"", "p-2"}}
+	if len(res.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(res.Results), len(want))
+	}
+	for _, r := range res.Results {
+		w := want[r.Message.Id]
+		if r.PrevMsgId != w[0] || r.NextMsgId != w[1] {
+			t.Errorf("%s: context = (%q, %q), want (%q, %q)", r.Message.Id, r.PrevMsgId, r.NextMsgId, w[0], w[1])
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package persister
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"ChatIM/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.InitDefaultLogger()
+	os.Exit(m.Run())
+}
+
+// stubDB 模拟 messages / group_messages 表的 database/sql 驱动（通过 sql.OpenDB 使用）
+//   - SELECT id ... WHERE id IN：返回 existing 中的ID
+//   - SELECT id ... WHERE from_user_id = ? AND client_msg_id = ?：返回 clientMsgOwner
+//   - INSERT：记录写入的消息ID，结果由 insertErr 决定，成功写入的ID加入 existing
+//   - UPDATE：消息已存在时记录表名和消息ID，影响 1 行
+type stubDB struct {
+	mu             sync.Mutex
+	existing       []string
+	clientMsgOwner string
+	insertErr      func(ids []string) error
+	inserted       [][]string
+	updates        []string
+}
+
+func (db *stubDB) Connect(context.Context) (driver.Conn, error) { return stubConn{db}, nil }
+func (db *stubDB) Driver() driver.Driver                        { return stubDriver{} }
+
+type stubDriver struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return nil, errors.New("use sql.OpenDB") }
+
+type stubConn struct{ db *stubDB }
+
+func (c stubConn) Prepare(query string) (driver.Stmt, error) {
+	return stubStmt{query: query, db: c.db}, nil
+}
+func (c stubConn) Close() error              { return nil }
+func (c stubConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+type stubStmt struct {
+	query string
+	db    *stubDB
+}
+
+func (s stubStmt) Close() error  { return nil }
+func (s stubStmt) NumInput() int { return -1 }
+
+func (s stubStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if strings.HasPrefix(s.query, "UPDATE") {
+		for _, arg := range args {
+			for _, id := range db.existing {
+				if arg == id {
+					db.updates = append(db.updates, strings.Fields(s.query)[1]+" "+id)
+					return driver.RowsAffected(1), nil
+				}
+			}
+		}
+		return driver.RowsAffected(0), nil
+	}
+
+	stride := 10
+	if strings.HasPrefix(s.query, "INSERT INTO group_messages") {
+		stride = 12
+	}
+	var ids []string
+	for i := 0; i < len(args); i += stride {
+		ids = append(ids, args[i].(string))
+	}
+	db.inserted = append(db.inserted, ids)
+	if db.insertErr != nil {
+		if err := db.insertErr(ids); err != nil {
+			return nil, err
+		}
+	}
+	db.existing = append(db.existing, ids...)
+	return driver.RowsAffected(len(ids)), nil
+}
+
+func (s stubStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	rows := &stubRows{}
+	if strings.Contains(s.query, "client_msg_id = ?") {
+		if db.clientMsgOwner != "" {
+			rows.values = append(rows.values, db.clientMsgOwner)
+		}
+		return rows, nil
+	}
+	for _, arg := range args {
+		for _, id := range db.existing {
+			if arg == id {
+				rows.values = append(rows.values, id)
+			}
+		}
+	}
+	return rows, nil
+}
+
+// stubRows 只有一列 id 的查询结果
+type stubRows struct {
+	values []string
+}
+
+func (r *stubRows) Columns() []string { return []string{"id"} }
+func (r *stubRows) Close() error      { return nil }
+
+func (r *stubRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	dest[0] = r.values[0]
+	r.values = r.values[1:]
+	return nil
+}
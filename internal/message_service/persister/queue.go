@@ -0,0 +1,89 @@
+// Package persister 消息落库的 write-behind 队列
+// 发送路径只把待落库的消息写入 Redis Stream，由 Worker 通过消费者组批量写入 MySQL，
+// MySQL 短暂不可用时消息保留在队列中等待重试，不会丢失
+package persister
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// QueueStream 待落库消息队列
+	QueueStream = "stream:persist"
+	// DeadLetterStream 无法写入数据库的消息（数据本身有问题），需人工排查
+	DeadLetterStream = "stream:persist:dead"
+	// ConsumerGroup 落库消费者组
+	ConsumerGroup = "persisters"
+
+	// deadLetterMaxLen 死信队列保留的最大条目数（近似）
+	deadLetterMaxLen = 100000
+)
+
+// 消息种类
+const (
+	KindPrivate = "private"
+	KindGroup   = "group"
+)
+
+// Message 待落库的一条私聊或群聊消息
+type Message struct {
+	Kind           string `json:"kind"` // private / group
+	ID             string `json:"id"`
+	ClientMsgID    string `json:"client_msg_id,omitempty"`
+	FromUserID     string `json:"from_user_id"`
+	ToUserID       string `json:"to_user_id,omitempty"` // 私聊
+	GroupID        string `json:"group_id,omitempty"`   // 群聊
+	Content        string `json:"content"`
+	MsgType        string `json:"msg_type"`
+	Payload        string `json:"payload,omitempty"`          // JSON
+	ReplyToMsgID   string `json:"reply_to_msg_id,omitempty"`  // 引用的消息ID
+	MentionUserIDs string `json:"mention_user_ids,omitempty"` // 被 @ 的成员（JSON 数组，仅群聊）
+	MentionAll     bool   `json:"mention_all,omitempty"`      // 是否 @所有人（仅群聊）
+	CreatedAt      string `json:"created_at"`                 // 格式 2006-01-02 15:04:05
+	ExpiresAt      string `json:"expires_at,omitempty"`       // 过期时间（会话开启定时销毁时），格式同 CreatedAt
+}
+
+// Enqueue 将消息写入待落库队列
+func Enqueue(ctx context.Context, rdb *redis.Client, msg Message) error {
+	if msg.Kind != KindPrivate && msg.Kind != KindGroup {
+		return fmt.Errorf("unknown message kind %q", msg.Kind)
+	}
+
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: QueueStream,
+		Values: map[string]interface{}{
+			"msg_id": msg.ID,
+			"data":   string(data),
+		},
+	}).Err()
+}
+
+// Watermark 返回落库水位：早于该时间写入的消息均已落库（或已移入死信队列）
+// 已确认的条目会从队列删除，因此队列中最早的条目即为最早的未落库消息；队列为空时水位为当前时间
+func Watermark(ctx context.Context, rdb *redis.Client) (time.Time, error) {
+	entries, err := rdb.XRangeN(ctx, QueueStream, "-", "+", 1).Result()
+	if err != nil {
+		return time.Time{}, err
+	}
+	if len(entries) == 0 {
+		return time.Now(), nil
+	}
+
+	ms, err := strconv.ParseInt(strings.SplitN(entries[0].ID, "-", 2)[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid queue entry id %q", entries[0].ID)
+	}
+	return time.UnixMilli(ms), nil
+}
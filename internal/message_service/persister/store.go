@@ -0,0 +1,174 @@
+package persister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// InsertMessages 批量写入同一种类的消息
+// 主键已存在的消息（同一条消息被重复投递）跳过，保证幂等；其他唯一键冲突（如 uk_from_client_msg）作为错误返回
+func InsertMessages(ctx context.Context, db *sql.DB, kind string, msgs []Message) error {
+	// 另一个消费者可能在查询和写入之间写入了同一批消息（认领超时的条目），此时重新过滤一次
+	for attempt := 0; ; attempt++ {
+		existing, err := existingMessageIDs(ctx, db, kind, msgs)
+		if err != nil {
+			return err
+		}
+
+		pending := make([]Message, 0, len(msgs))
+		for _, m := range msgs {
+			if !existing[m.ID] {
+				pending = append(pending, m)
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		err = insertRows(ctx, db, kind, pending)
+		if err == nil || attempt > 0 || !isDuplicateKey(err, "PRIMARY") {
+			return err
+		}
+	}
+}
+
+// insertRows 一条 INSERT 写入多行
+func insertRows(ctx context.Context, db *sql.DB, kind string, msgs []Message) error {
+	var (
+		query        string
+		placeholders string
+		args         []interface{}
+	)
+
+	if kind == KindGroup {
+		query = "INSERT INTO group_messages (id, client_msg_id, group_id, from_user_id, content, msg_type, payload, reply_to_msg_id, mention_user_ids, mention_all, created_at, expires_at) VALUES "
+		placeholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		for _, m := range msgs {
+			args = append(args, m.ID, nullable(m.ClientMsgID), m.GroupID, m.FromUserID, m.Content, m.MsgType,
+				nullable(m.Payload), nullable(m.ReplyToMsgID), nullable(m.MentionUserIDs), m.MentionAll, m.CreatedAt, nullable(m.ExpiresAt))
+		}
+	} else {
+		query = "INSERT INTO messages (id, client_msg_id, from_user_id, to_user_id, content, msg_type, payload, reply_to_msg_id, created_at, expires_at) VALUES "
+		placeholders = "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)"
+		for _, m := range msgs {
+			args = append(args, m.ID, nullable(m.ClientMsgID), m.FromUserID, m.ToUserID, m.Content, m.MsgType,
+				nullable(m.Payload), nullable(m.ReplyToMsgID), m.CreatedAt, nullable(m.ExpiresAt))
+		}
+	}
+
+	query += strings.TrimSuffix(strings.Repeat(placeholders+", ", len(msgs)), ", ")
+
+	_, err := db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// existingMessageIDs 查询已落库的消息ID
+func existingMessageIDs(ctx context.Context, db *sql.DB, kind string, msgs []Message) (map[string]bool, error) {
+	ids := make([]interface{}, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+	query := fmt.Sprintf("SELECT id FROM %s WHERE id IN (%s)", TableName(kind), strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "))
+
+	rows, err := db.QueryContext(ctx, query, ids...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
+
+// FindByClientMsgID 查询发送者以 client_msg_id 已落库的消息ID，不存在时返回空字符串
+func FindByClientMsgID(ctx context.Context, db *sql.DB, kind, fromUserID, clientMsgID string) (string, error) {
+	var id string
+	query := fmt.Sprintf("SELECT id FROM %s WHERE from_user_id = ? AND client_msg_id = ?", TableName(kind))
+	err := db.QueryRowContext(ctx, query, fromUserID, clientMsgID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return id, err
+}
+
+// MarkRecalled 将消息标记为已撤回，消息不存在或已撤回时返回 false
+func MarkRecalled(ctx context.Context, db *sql.DB, kind, msgID string, recalledAt int64) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET is_recalled = TRUE, recalled_at = FROM_UNIXTIME(?) WHERE id = ? AND NOT IFNULL(is_recalled, FALSE)", TableName(kind))
+	res, err := db.ExecContext(ctx, query, recalledAt, msgID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ApplyEdit 写入消息的编辑内容，消息不存在或已有更新的编辑时返回 false
+func ApplyEdit(ctx context.Context, db *sql.DB, kind, msgID, content string, editedAt int64) (bool, error) {
+	query := fmt.Sprintf("UPDATE %s SET content = ?, edited_at = FROM_UNIXTIME(?) WHERE id = ? AND (edited_at IS NULL OR edited_at <= FROM_UNIXTIME(?))", TableName(kind))
+	res, err := db.ExecContext(ctx, query, content, editedAt, msgID, editedAt)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// TableName 返回消息种类对应的表名
+func TableName(kind string) string {
+	if kind == KindGroup {
+		return "group_messages"
+	}
+	return "messages"
+}
+
+// isPoison 判断写入失败是否由数据本身导致（重试也不会成功），例如外键约束失败、字段超长
+// 只有已知的数据错误视为问题消息，连接错误、死锁、超时等其他错误均按临时错误重试
+func isPoison(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return false
+	}
+	switch mysqlErr.Number {
+	case 1048, // 字段不能为 NULL
+		1062, // 唯一键冲突（主键冲突已在写入前过滤）
+		1264, // 数值超出范围
+		1265, // 数据被截断
+		1292, // 日期时间格式错误
+		1366, // 字符串编码错误
+		1406, // 字段超长
+		1452: // 外键约束失败
+		return true
+	}
+	return false
+}
+
+// isDuplicateKey 判断错误是否为指定唯一键的冲突
+// MySQL 8 的错误信息为 for key 'table.key'，5.7 为 for key 'key'
+func isDuplicateKey(err error, key string) bool {
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+		return false
+	}
+	return strings.HasSuffix(mysqlErr.Message, "'"+key+"'") || strings.HasSuffix(mysqlErr.Message, "."+key+"'")
+}
+
+// isClientMsgIDConflict 判断错误是否为同一发送者的 client_msg_id 重复
+func isClientMsgIDConflict(err error) bool {
+	return isDuplicateKey(err, "uk_from_client_msg")
+}
+
+func nullable(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
@@ -0,0 +1,138 @@
+package persister
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestIsPoison(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"data too long", &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'content' at row 1"}, true},
+		{"incorrect string value", &mysql.MySQLError{Number: 1366, Message: "Incorrect string value"}, true},
+		{"foreign key", &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, true},
+		{"column cannot be null", &mysql.MySQLError{Number: 1048, Message: "Column 'group_id' cannot be null"}, true},
+		{"incorrect datetime", &mysql.MySQLError{Number: 1292, Message: "Incorrect datetime value"}, true},
+		{"client msg id conflict", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-c1' for key 'messages.uk_from_client_msg'"}, true},
+		{"wrapped data error", fmt.Errorf("insert: %w", &mysql.MySQLError{Number: 1406}), true},
+		{"deadlock", &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, false},
+		{"lock wait timeout", &mysql.MySQLError{Number: 1205}, false},
+		{"read only", &mysql.MySQLError{Number: 1290}, false},
+		{"unknown server error", &mysql.MySQLError{Number: 1105, Message: "Unknown error"}, false},
+		{"table missing", &mysql.MySQLError{Number: 1146, Message: "Table 'chatim.messages' doesn't exist"}, false},
+		{"connection error", mysql.ErrInvalidConn, false},
+		{"context deadline", context.DeadlineExceeded, false},
+		{"bad connection", errors.New("driver: bad connection"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isPoison(tt.err); got != tt.want {
+				t.Errorf("isPoison(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDuplicateKey(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantPrimary   bool
+		wantClientMsg bool
+	}{
+		{"mysql 8 primary", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'm1' for key 'messages.PRIMARY'"}, true, false},
+		{"mysql 5.7 primary", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'm1' for key 'PRIMARY'"}, true, false},
+		{"mysql 8 client msg id", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-c1' for key 'group_messages.uk_from_client_msg'"}, false, true},
+		{"mysql 5.7 client msg id", &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-c1' for key 'uk_from_client_msg'"}, false, true},
+		{"other error", &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'PRIMARY'"}, false, false},
+		{"not mysql", errors.New("Duplicate entry for key 'PRIMARY'"), false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDuplicateKey(tt.err, "PRIMARY"); got != tt.wantPrimary {
+				t.Errorf("isDuplicateKey(PRIMARY) = %v, want %v", got, tt.wantPrimary)
+			}
+			if got := isClientMsgIDConflict(tt.err); got != tt.wantClientMsg {
+				t.Errorf("isClientMsgIDConflict = %v, want %v", got, tt.wantClientMsg)
+			}
+		})
+	}
+}
+
+func TestInsertMessagesSkipsExistingIDs(t *testing.T) {
+	ctx := context.Background()
+	msgs := []Message{
+		{Kind: KindPrivate, ID: "m1", FromUserID: "a", ToUserID: "b", Content: "1", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+		{Kind: KindPrivate, ID: "m2", FromUserID: "a", ToUserID: "b", Content: "2", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+		{Kind: KindPrivate, ID: "m3", FromUserID: "a", ToUserID: "b", Content: "3", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+	}
+
+	tests := []struct {
+		name       string
+		existing   []string
+		insertErrs []error  // 依次作为每次 INSERT 的结果
+		concurrent []string // 第一次 INSERT 失败时已被其他消费者写入的消息
+		wantErr    bool
+		wantRows   [][]string // 每次 INSERT 写入的消息ID
+	}{
+		{
+			name:     "none existing",
+			wantRows: [][]string{{"m1", "m2", "m3"}},
+		},
+		{
+			name:     "redelivered message skipped",
+			existing: []string{"m2"},
+			wantRows: [][]string{{"m1", "m3"}},
+		},
+		{
+			name:     "all existing",
+			existing: []string{"m1", "m2", "m3"},
+		},
+		{
+			name:       "concurrent primary key conflict refilters once",
+			insertErrs: []error{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'm1' for key 'messages.PRIMARY'"}},
+			concurrent: []string{"m1"},
+			wantRows:   [][]string{{"m1", "m2", "m3"}, {"m2", "m3"}},
+		},
+		{
+			name:       "client msg id conflict returned",
+			insertErrs: []error{&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-c1' for key 'messages.uk_from_client_msg'"}},
+			wantErr:    true,
+			wantRows:   [][]string{{"m1", "m2", "m3"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := &stubDB{existing: tt.existing}
+			errs := tt.insertErrs
+			db.insertErr = func([]string) error {
+				if len(errs) == 0 {
+					return nil
+				}
+				err := errs[0]
+				errs = errs[1:]
+				db.existing = append(db.existing, tt.concurrent...)
+				return err
+			}
+
+			err := InsertMessages(ctx, sql.OpenDB(db), KindPrivate, msgs)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if fmt.Sprint(db.inserted) != fmt.Sprint(tt.wantRows) {
+				t.Errorf("inserted = %v, want %v", db.inserted, tt.wantRows)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+package persister
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"ChatIM/pkg/config"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// Worker 从待落库队列消费消息并批量写入 MySQL
+//   - 通过消费者组 XREADGROUP 读取，写入成功后 XACK 并删除条目
+//   - 整批写入失败时按指数退避重试；数据错误导致失败时逐条写入以隔离问题消息
+//   - 数据本身有问题的消息移入死信队列；临时错误的消息保持未确认，超时后被重新认领
+//   - 写入后补上消息落库前发生的撤回和编辑
+type Worker struct {
+	db         *sql.DB
+	rdb        *redis.Client
+	streamOp   *stream.StreamOperator
+	consumer   string
+	batchSize  int64
+	block      time.Duration
+	maxRetries int
+	claimIdle  time.Duration
+}
+
+// NewWorker 创建落库 Worker
+func NewWorker(db *sql.DB, rdb *redis.Client, cfg config.PersisterConfig) *Worker {
+	w := &Worker{
+		db:         db,
+		rdb:        rdb,
+		streamOp:   stream.NewStreamOperator(rdb),
+		consumer:   cfg.Consumer,
+		batchSize:  int64(cfg.BatchSize),
+		block:      time.Duration(cfg.BlockMs) * time.Millisecond,
+		maxRetries: cfg.MaxRetries,
+		claimIdle:  time.Duration(cfg.ClaimIdleSeconds) * time.Second,
+	}
+	if w.consumer == "" {
+		hostname, _ := os.Hostname()
+		w.consumer = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+	if w.batchSize <= 0 {
+		w.batchSize = 100
+	}
+	if w.block <= 0 {
+		w.block = 2 * time.Second
+	}
+	if w.maxRetries <= 0 {
+		w.maxRetries = 3
+	}
+	if w.claimIdle <= 0 {
+		w.claimIdle = 30 * time.Second
+	}
+	return w
+}
+
+// Run 持续消费队列直到 ctx 结束
+func (w *Worker) Run(ctx context.Context) {
+	logger.Info("Persister worker started", zap.String("consumer", w.consumer))
+
+	for ctx.Err() == nil {
+		if err := w.ensureGroup(ctx); err != nil {
+			logger.Error("Failed to create persister consumer group", zap.Error(err))
+			w.sleep(ctx, time.Second)
+			continue
+		}
+		break
+	}
+
+	lastClaim := time.Time{}
+	for ctx.Err() == nil {
+		// 定期认领超时未确认的消息（包括本消费者上次写入失败的，以及已退出的消费者遗留的）
+		if time.Since(lastClaim) >= w.claimIdle {
+			w.claimStale(ctx)
+			lastClaim = time.Now()
+		}
+
+		streams, err := w.rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    ConsumerGroup,
+			Consumer: w.consumer,
+			Streams:  []string{QueueStream, ">"},
+			Count:    w.batchSize,
+			Block:    w.block,
+		}).Result()
+		if err == redis.Nil {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			// 消费者组可能因队列被删除而丢失
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				_ = w.ensureGroup(ctx)
+				continue
+			}
+			logger.Error("Failed to read persist queue", zap.Error(err))
+			w.sleep(ctx, time.Second)
+			continue
+		}
+
+		for _, s := range streams {
+			w.process(ctx, s.Messages)
+		}
+	}
+
+	logger.Info("Persister worker stopped", zap.String("consumer", w.consumer))
+}
+
+// ensureGroup 创建消费者组（已存在时忽略）
+func (w *Worker) ensureGroup(ctx context.Context) error {
+	err := w.rdb.XGroupCreateMkStream(ctx, QueueStream, ConsumerGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+// claimStale 认领空闲超过 claimIdle 的未确认消息并重新处理
+func (w *Worker) claimStale(ctx context.Context) {
+	start := "0-0"
+	for ctx.Err() == nil {
+		entries, next, err := w.rdb.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   QueueStream,
+			Group:    ConsumerGroup,
+			Consumer: w.consumer,
+			MinIdle:  w.claimIdle,
+			Start:    start,
+			Count:    w.batchSize,
+		}).Result()
+		if err != nil {
+			logger.Warn("Failed to claim pending persist messages", zap.Error(err))
+			return
+		}
+
+		if len(entries) > 0 {
+			logger.Info("Claimed pending persist messages", zap.Int("count", len(entries)))
+			w.process(ctx, entries)
+		}
+
+		if next == "0-0" || next == "" {
+			return
+		}
+		start = next
+	}
+}
+
+// process 写入一批消息：成功的确认，问题消息移入死信队列，临时失败的保持未确认
+func (w *Worker) process(ctx context.Context, entries []redis.XMessage) {
+	batches := map[string][]Message{}
+	entryIDs := map[string][]string{}
+
+	for _, entry := range entries {
+		data, _ := entry.Values["data"].(string)
+		var msg Message
+		if err := json.Unmarshal([]byte(data), &msg); err != nil || (msg.Kind != KindPrivate && msg.Kind != KindGroup) {
+			if err == nil {
+				err = fmt.Errorf("unknown message kind %q", msg.Kind)
+			}
+			w.deadLetter(ctx, entry, err)
+			continue
+		}
+		batches[msg.Kind] = append(batches[msg.Kind], msg)
+		entryIDs[msg.Kind] = append(entryIDs[msg.Kind], entry.ID)
+	}
+
+	for kind, msgs := range batches {
+		ids := entryIDs[kind]
+
+		err := w.insertWithRetry(ctx, kind, msgs)
+		if err == nil {
+			w.applyRedisState(ctx, kind, msgs)
+			w.ack(ctx, ids...)
+			logger.Debug("Messages persisted", zap.String("kind", kind), zap.Int("count", len(msgs)))
+			continue
+		}
+		if !isPoison(err) {
+			// 临时错误（数据库不可用等）逐条写入也不会成功，保持未确认，超时后重新认领
+			logger.Error("Failed to save messages to database, will retry later",
+				zap.String("kind", kind),
+				zap.Int("count", len(msgs)),
+				zap.Error(err))
+			continue
+		}
+
+		logger.Warn("Batch persist failed, falling back to one by one",
+			zap.String("kind", kind),
+			zap.Int("count", len(msgs)),
+			zap.Error(err))
+
+		// 逐条写入，隔离导致整批失败的消息；遇到临时错误时停止，剩余消息保持未确认
+		for i, msg := range msgs {
+			err := w.insertWithRetry(ctx, kind, []Message{msg})
+			switch {
+			case err == nil:
+				w.applyRedisState(ctx, kind, []Message{msg})
+				w.ack(ctx, ids[i])
+			case isClientMsgIDConflict(err):
+				w.dropDuplicate(ctx, kind, msg, ids[i])
+			case isPoison(err):
+				w.deadLetter(ctx, findEntry(entries, ids[i]), err)
+			default:
+				logger.Error("Failed to save message to database, will retry later",
+					zap.String("msg_id", msg.ID),
+					zap.Int("remaining", len(msgs)-i),
+					zap.Error(err))
+			}
+			if err != nil && !isPoison(err) {
+				break
+			}
+		}
+	}
+}
+
+// applyRedisState 将 Redis 中的撤回标记和最新编辑内容写入刚落库的消息
+// 撤回 / 编辑时先写 Redis 再更新数据库，消息尚未落库时数据库更新不影响任何行，由这里补上；
+// 写入后才读取 Redis，因此晚于本次读取的撤回 / 编辑一定能在数据库中找到消息
+func (w *Worker) applyRedisState(ctx context.Context, kind string, msgs []Message) {
+	ids := make([]string, len(msgs))
+	for i, m := range msgs {
+		ids[i] = m.ID
+	}
+
+	recalls, err := w.streamOp.GetRecallTimes(ctx, ids)
+	if err != nil {
+		logger.Warn("Failed to get recall state for persisted messages", zap.Error(err))
+	}
+	for id, recalledAt := range recalls {
+		if _, err := MarkRecalled(ctx, w.db, kind, id, recalledAt); err != nil {
+			logger.Warn("Failed to apply recall to persisted message", zap.String("msg_id", id), zap.Error(err))
+		}
+	}
+
+	edits, err := w.streamOp.GetMessageEdits(ctx, ids)
+	if err != nil {
+		logger.Warn("Failed to get edits for persisted messages", zap.Error(err))
+	}
+	for id, edit := range edits {
+		if _, err := ApplyEdit(ctx, w.db, kind, id, edit.Content, edit.EditedAt); err != nil {
+			logger.Warn("Failed to apply edit to persisted message", zap.String("msg_id", id), zap.Error(err))
+		}
+	}
+}
+
+// dropDuplicate 同一发送者的 client_msg_id 已有落库的消息（重复发送），不再写入并确认条目
+func (w *Worker) dropDuplicate(ctx context.Context, kind string, msg Message, entryID string) {
+	existingID, err := FindByClientMsgID(ctx, w.db, kind, msg.FromUserID, msg.ClientMsgID)
+	if err != nil {
+		// 查询失败时保持未确认，等待重新认领
+		logger.Warn("Failed to look up duplicate message", zap.String("msg_id", msg.ID), zap.Error(err))
+		return
+	}
+
+	logger.Warn("Duplicate client_msg_id, message not persisted",
+		zap.String("msg_id", msg.ID),
+		zap.String("existing_msg_id", existingID),
+		zap.String("from_user_id", msg.FromUserID),
+		zap.String("client_msg_id", msg.ClientMsgID))
+	w.ack(ctx, entryID)
+}
+
+// insertWithRetry 写入消息，临时错误时按指数退避重试
+func (w *Worker) insertWithRetry(ctx context.Context, kind string, msgs []Message) error {
+	backoff := 100 * time.Millisecond
+	var err error
+	for attempt := 0; attempt <= w.maxRetries; attempt++ {
+		if attempt > 0 {
+			if !w.sleep(ctx, backoff) {
+				return ctx.Err()
+			}
+			backoff *= 2
+		}
+
+		dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		err = InsertMessages(dbCtx, w.db, kind, msgs)
+		cancel()
+		if err == nil || isPoison(err) {
+			return err
+		}
+	}
+	return err
+}
+
+// ack 确认并删除已处理的条目，避免队列无限增长
+func (w *Worker) ack(ctx context.Context, ids ...string) {
+	pipe := w.rdb.TxPipeline()
+	pipe.XAck(ctx, QueueStream, ConsumerGroup, ids...)
+	pipe.XDel(ctx, QueueStream, ids...)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Warn("Failed to ack persisted messages", zap.Strings("ids", ids), zap.Error(err))
+	}
+}
+
+// deadLetter 将无法写入的消息移入死信队列并确认原条目
+func (w *Worker) deadLetter(ctx context.Context, entry redis.XMessage, cause error) {
+	values := map[string]interface{}{
+		"source_id": entry.ID,
+		"error":     cause.Error(),
+		"failed_at": time.Now().Unix(),
+	}
+	for k, v := range entry.Values {
+		values[k] = v
+	}
+
+	err := w.rdb.XAdd(ctx, &redis.XAddArgs{
+		Stream: DeadLetterStream,
+		MaxLen: deadLetterMaxLen,
+		Approx: true,
+		Values: values,
+	}).Err()
+	if err != nil {
+		// 写入死信队列失败时不确认，等待下次重新认领
+		logger.Error("Failed to move message to dead letter stream", zap.String("id", entry.ID), zap.Error(err))
+		return
+	}
+
+	logger.Error("Message moved to dead letter stream",
+		zap.String("id", entry.ID),
+		zap.Any("msg_id", entry.Values["msg_id"]),
+		zap.Error(cause))
+	w.ack(ctx, entry.ID)
+}
+
+// sleep 等待 d，ctx 结束时提前返回 false
+func (w *Worker) sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func findEntry(entries []redis.XMessage, id string) redis.XMessage {
+	for _, e := range entries {
+		if e.ID == id {
+			return e
+		}
+	}
+	return redis.XMessage{ID: id}
+}
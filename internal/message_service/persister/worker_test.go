@@ -0,0 +1,187 @@
+package persister
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-sql-driver/mysql"
+	"github.com/redis/go-redis/v9"
+
+	"ChatIM/pkg/stream"
+)
+
+// newTestWorker 创建连接到 miniredis 和 stubDB 的 Worker，并把 msgs 写入队列后读出（未确认）
+func newTestWorker(t *testing.T, db *stubDB, msgs []Message) (*Worker, *redis.Client, []redis.XMessage) {
+	t.Helper()
+	ctx := context.Background()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	w := &Worker{
+		db:         sql.OpenDB(db),
+		rdb:        rdb,
+		streamOp:   stream.NewStreamOperator(rdb),
+		consumer:   "test",
+		batchSize:  100,
+		block:      time.Millisecond,
+		maxRetries: 1,
+		claimIdle:  time.Minute,
+	}
+	if err := w.ensureGroup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, m := range msgs {
+		if err := Enqueue(ctx, rdb, m); err != nil {
+			t.Fatal(err)
+		}
+	}
+	streams, err := rdb.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    ConsumerGroup,
+		Consumer: w.consumer,
+		Streams:  []string{QueueStream, ">"},
+		Count:    100,
+	}).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return w, rdb, streams[0].Messages
+}
+
+func TestWorkerProcess(t *testing.T) {
+	privateMsg := func(id string) Message {
+		return Message{Kind: KindPrivate, ID: id, ClientMsgID: "c-" + id, FromUserID: "a", ToUserID: "b",
+			Content: id, MsgType: "text", CreatedAt: "2024-01-01 00:00:00"}
+	}
+	groupMsg := func(id string) Message {
+		return Message{Kind: KindGroup, ID: id, FromUserID: "a", GroupID: "g",
+			Content: id, MsgType: "text", CreatedAt: "2024-01-01 00:00:00"}
+	}
+	failIDs := func(err error, ids ...string) func([]string) error {
+		return func(inserted []string) error {
+			for _, id := range inserted {
+				for _, bad := range ids {
+					if id == bad {
+						return err
+					}
+				}
+			}
+			return nil
+		}
+	}
+	tooLong := &mysql.MySQLError{Number: 1406, Message: "Data too long for column 'content' at row 1"}
+	deadlock := &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}
+	clientMsgConflict := &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'a-c-m2' for key 'messages.uk_from_client_msg'"}
+
+	tests := []struct {
+		name           string
+		msgs           []Message
+		insertErr      func([]string) error
+		wantInserts    []string // 各次 INSERT 写入的消息ID
+		wantPending    int      // 仍未确认的条目数
+		wantDeadLetter int
+	}{
+		{
+			name:        "batched by kind",
+			msgs:        []Message{privateMsg("m1"), groupMsg("g1"), privateMsg("m2")},
+			wantInserts: []string{"[g1]", "[m1 m2]"},
+		},
+		{
+			name:           "poison row isolated",
+			msgs:           []Message{privateMsg("m1"), privateMsg("m2"), privateMsg("m3")},
+			insertErr:      failIDs(tooLong, "m2"),
+			wantInserts:    []string{"[m1 m2 m3]", "[m1]", "[m2]", "[m3]"},
+			wantDeadLetter: 1,
+		},
+		{
+			name:        "client msg id conflict dropped",
+			msgs:        []Message{privateMsg("m1"), privateMsg("m2")},
+			insertErr:   failIDs(clientMsgConflict, "m2"),
+			wantInserts: []string{"[m1 m2]", "[m1]", "[m2]"},
+		},
+		{
+			name:        "transient batch error does not fall back",
+			msgs:        []Message{privateMsg("m1"), privateMsg("m2")},
+			insertErr:   failIDs(deadlock, "m1"),
+			wantInserts: []string{"[m1 m2]", "[m1 m2]"},
+			wantPending: 2,
+		},
+		{
+			name: "fallback stops at transient error",
+			msgs: []Message{privateMsg("m1"), privateMsg("m2"), privateMsg("m3")},
+			insertErr: func(ids []string) error {
+				if len(ids) > 1 {
+					return tooLong
+				}
+				if ids[0] == "m2" {
+					return deadlock
+				}
+				return nil
+			},
+			wantInserts: []string{"[m1 m2 m3]", "[m1]", "[m2]", "[m2]"},
+			wantPending: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			db := &stubDB{insertErr: tt.insertErr}
+			w, rdb, entries := newTestWorker(t, db, tt.msgs)
+
+			w.process(ctx, entries)
+
+			var inserts []string
+			for _, ids := range db.inserted {
+				inserts = append(inserts, fmt.Sprint(ids))
+			}
+			// 不同种类的批次写入顺序不固定，按写入内容比较
+			sort.Strings(inserts)
+			sort.Strings(tt.wantInserts)
+			if fmt.Sprint(inserts) != fmt.Sprint(tt.wantInserts) {
+				t.Errorf("inserts = %v, want %v", inserts, tt.wantInserts)
+			}
+			if n := rdb.XLen(ctx, QueueStream).Val(); n != int64(tt.wantPending) {
+				t.Errorf("queue length = %d, want %d", n, tt.wantPending)
+			}
+			pending := rdb.XPending(ctx, QueueStream, ConsumerGroup).Val()
+			if pending.Count != int64(tt.wantPending) {
+				t.Errorf("pending = %d, want %d", pending.Count, tt.wantPending)
+			}
+			if n := rdb.XLen(ctx, DeadLetterStream).Val(); n != int64(tt.wantDeadLetter) {
+				t.Errorf("dead letters = %d, want %d", n, tt.wantDeadLetter)
+			}
+		})
+	}
+}
+
+func TestWorkerAppliesRecallAndEditAfterInsert(t *testing.T) {
+	ctx := context.Background()
+	db := &stubDB{}
+	msgs := []Message{
+		{Kind: KindPrivate, ID: "m1", FromUserID: "a", ToUserID: "b", Content: "1", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+		{Kind: KindPrivate, ID: "m2", FromUserID: "a", ToUserID: "b", Content: "2", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+		{Kind: KindPrivate, ID: "m3", FromUserID: "a", ToUserID: "b", Content: "3", MsgType: "text", CreatedAt: "2024-01-01 00:00:00"},
+	}
+	w, _, entries := newTestWorker(t, db, msgs)
+
+	// 消息落库前已被撤回 / 编辑（数据库更新未影响任何行）
+	if err := w.streamOp.MarkMessageRecalled(ctx, "m1", "a", 1700000000, time.Now().Unix()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.streamOp.SaveMessageEdit(ctx, "m3", stream.MessageEdit{Content: "3 edited", EditedBy: "a", EditedAt: 1700000001}, time.Now().Unix()); err != nil {
+		t.Fatal(err)
+	}
+
+	w.process(ctx, entries)
+
+	want := []string{"messages m1", "messages m3"}
+	if fmt.Sprint(db.updates) != fmt.Sprint(want) {
+		t.Errorf("updates = %v, want %v", db.updates, want)
+	}
+}
@@ -0,0 +1,258 @@
+// Package retention 定期裁剪用户消息流 stream:private:{user_id} 和读扩散群消息流 stream:group:{group_id}
+// 按条数和时长两条规则计算每个流的裁剪位置，并且（可配置）不越过落库水位，
+// 保证被裁剪的消息都已写入数据库，历史消息可从数据库读取
+package retention
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"ChatIM/internal/message_service/persister"
+	"ChatIM/pkg/config"
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/metrics"
+	"ChatIM/pkg/stream"
+)
+
+const (
+	// userStreamPattern 需要裁剪的用户消息流
+	userStreamPattern = "stream:private:*"
+	// groupStreamPattern 需要裁剪的读扩散群消息流
+	groupStreamPattern = "stream:group:*"
+	// lockKey 多个实例同时运行时，同一周期只允许一个实例执行裁剪
+	lockKey = "lock:stream:retention"
+)
+
+// Job 用户消息流和读扩散群消息流的定期裁剪任务
+type Job struct {
+	rdb                *redis.Client
+	streamOp           *stream.StreamOperator
+	interval           time.Duration
+	maxEntries         int64
+	groupMaxEntries    int64
+	maxAge             time.Duration
+	keepUntilPersisted bool
+	safetyMargin       time.Duration
+	scanCount          int64
+}
+
+// NewJob 创建裁剪任务
+func NewJob(rdb *redis.Client, cfg config.RetentionConfig) *Job {
+	j := &Job{
+		rdb:                rdb,
+		streamOp:           stream.NewStreamOperator(rdb),
+		interval:           time.Duration(cfg.IntervalSeconds) * time.Second,
+		maxEntries:         cfg.MaxEntries,
+		groupMaxEntries:    cfg.GroupMaxEntries,
+		maxAge:             time.Duration(cfg.MaxAgeHours) * time.Hour,
+		keepUntilPersisted: cfg.KeepUntilPersisted,
+		safetyMargin:       time.Duration(cfg.SafetyMarginSeconds) * time.Second,
+		scanCount:          cfg.ScanCount,
+	}
+	if j.interval <= 0 {
+		j.interval = 5 * time.Minute
+	}
+	if j.safetyMargin <= 0 {
+		j.safetyMargin = time.Minute
+	}
+	if j.scanCount <= 0 {
+		j.scanCount = 500
+	}
+	if j.groupMaxEntries <= 0 {
+		j.groupMaxEntries = 5000
+	}
+	// Stream 条目不能比 Redis 中的撤回 / 编辑 / 表情回应状态保留得更久
+	if j.maxAge <= 0 || j.maxAge > stream.MaxStreamAge {
+		j.maxAge = stream.MaxStreamAge
+	}
+	return j
+}
+
+// Run 按周期执行裁剪直到 ctx 结束
+func (j *Job) Run(ctx context.Context) {
+	logger.Info("Stream retention job started",
+		zap.Duration("interval", j.interval),
+		zap.Int64("max_entries", j.maxEntries),
+		zap.Int64("group_max_entries", j.groupMaxEntries),
+		zap.Duration("max_age", j.maxAge),
+		zap.Bool("keep_until_persisted", j.keepUntilPersisted))
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			j.runOnce(ctx)
+		}
+	}
+}
+
+// runOnce 执行一轮裁剪
+func (j *Job) runOnce(ctx context.Context) {
+	// 锁的有效期略短于周期，实例崩溃时下一周期可由其他实例接手
+	acquired, err := j.rdb.SetNX(ctx, lockKey, "1", j.interval*9/10).Result()
+	if err != nil {
+		logger.Warn("Failed to acquire stream retention lock", zap.Error(err))
+		metrics.StreamRetentionRunsTotal.WithLabelValues("failed").Inc()
+		return
+	}
+	if !acquired {
+		metrics.StreamRetentionRunsTotal.WithLabelValues("skipped").Inc()
+		return
+	}
+
+	start := time.Now()
+	scanned, trimmed, err := j.trimAll(ctx)
+	metrics.StreamRetentionRunDuration.Observe(time.Since(start).Seconds())
+	metrics.StreamRetentionStreamsScanned.Set(float64(scanned))
+	if err != nil {
+		logger.Error("Stream retention run failed",
+			zap.Int("streams_scanned", scanned),
+			zap.Int64("entries_trimmed", trimmed),
+			zap.Error(err))
+		metrics.StreamRetentionRunsTotal.WithLabelValues("failed").Inc()
+		return
+	}
+
+	metrics.StreamRetentionRunsTotal.WithLabelValues("success").Inc()
+	logger.Info("Stream retention run finished",
+		zap.Int("streams_scanned", scanned),
+		zap.Int64("entries_trimmed", trimmed),
+		zap.Duration("duration", time.Since(start)))
+}
+
+// trimAll 遍历所有用户流和读扩散群消息流并裁剪，返回扫描的流数量与删除的条目数
+func (j *Job) trimAll(ctx context.Context) (int, int64, error) {
+	now := time.Now()
+
+	// 本轮不允许裁剪到 limit 及之后的条目
+	limit := ""
+	if j.keepUntilPersisted {
+		watermark, err := persister.Watermark(ctx, j.rdb)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to get persistence watermark: %w", err)
+		}
+		watermark = watermark.Add(-j.safetyMargin)
+		metrics.PersistWatermarkLagSeconds.Set(now.Sub(watermark).Seconds())
+		limit = fmt.Sprintf("%d-0", watermark.UnixMilli())
+	}
+
+	ageMinID := ""
+	if j.maxAge > 0 {
+		ageMinID = fmt.Sprintf("%d-0", now.Add(-j.maxAge).UnixMilli())
+	}
+
+	var (
+		scanned int
+		trimmed int64
+	)
+	for _, target := range []struct {
+		pattern    string
+		maxEntries int64
+	}{
+		{userStreamPattern, j.maxEntries},
+		{groupStreamPattern, j.groupMaxEntries},
+	} {
+		n, t, err := j.trimPattern(ctx, target.pattern, target.maxEntries, ageMinID, limit)
+		scanned += n
+		trimmed += t
+		if err != nil {
+			return scanned, trimmed, err
+		}
+	}
+	return scanned, trimmed, nil
+}
+
+// trimPattern 遍历匹配 pattern 的流并裁剪，每个流最多保留 maxEntries 条
+func (j *Job) trimPattern(ctx context.Context, pattern string, maxEntries int64, ageMinID, limit string) (int, int64, error) {
+	var (
+		scanned int
+		trimmed int64
+		cursor  uint64
+	)
+	for {
+		keys, next, err := j.rdb.ScanType(ctx, cursor, pattern, j.scanCount, "stream").Result()
+		if err != nil {
+			return scanned, trimmed, err
+		}
+
+		for _, key := range keys {
+			if ctx.Err() != nil {
+				return scanned, trimmed, ctx.Err()
+			}
+			scanned++
+
+			n, err := j.trimStream(ctx, key, maxEntries, ageMinID, limit)
+			if err != nil {
+				// 单个流失败不影响其他流
+				logger.Warn("Failed to trim stream", zap.String("stream_key", key), zap.Error(err))
+				continue
+			}
+			trimmed += n
+			metrics.StreamEntriesTrimmedTotal.Add(float64(n))
+		}
+
+		cursor = next
+		if cursor == 0 {
+			return scanned, trimmed, nil
+		}
+	}
+}
+
+// trimStream 计算单个流的裁剪位置并裁剪：取条数与时长规则中较新的位置，但不超过 limit
+func (j *Job) trimStream(ctx context.Context, key string, maxEntries int64, ageMinID, limit string) (int64, error) {
+	minID := ageMinID
+
+	if maxEntries > 0 {
+		countMinID, err := j.countMinID(ctx, key, maxEntries)
+		if err != nil {
+			return 0, err
+		}
+		if countMinID != "" && (minID == "" || stream.CompareStreamIDs(countMinID, minID) > 0) {
+			minID = countMinID
+		}
+	}
+
+	if minID == "" {
+		return 0, nil
+	}
+	if limit != "" && stream.CompareStreamIDs(minID, limit) > 0 {
+		minID = limit
+	}
+
+	return j.streamOp.TrimStreamByMinID(ctx, key, minID)
+}
+
+// countMinID 返回按条数规则需要保留的最早条目ID，未超出时返回空
+func (j *Job) countMinID(ctx context.Context, key string, maxEntries int64) (string, error) {
+	length, err := j.rdb.XLen(ctx, key).Result()
+	if err != nil {
+		return "", err
+	}
+	excess := length - maxEntries
+	if excess <= 0 {
+		return "", nil
+	}
+
+	// 从较短的一端读取，找到第 excess+1 条（最早需要保留的条目）
+	if excess < maxEntries {
+		entries, err := j.rdb.XRangeN(ctx, key, "-", "+", excess+1).Result()
+		if err != nil || len(entries) == 0 {
+			return "", err
+		}
+		return entries[len(entries)-1].ID, nil
+	}
+
+	entries, err := j.rdb.XRevRangeN(ctx, key, "+", "-", maxEntries).Result()
+	if err != nil || len(entries) == 0 {
+		return "", err
+	}
+	return entries[len(entries)-1].ID, nil
+}
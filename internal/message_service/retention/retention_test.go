@@ -0,0 +1,157 @@
+package retention
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"ChatIM/pkg/config"
+	"ChatIM/pkg/logger"
+)
+
+func TestMain(m *testing.M) {
+	_ = logger.InitDefaultLogger()
+	os.Exit(m.Run())
+}
+
+// newTestJob 创建连接到 miniredis 的裁剪任务
+func newTestJob(t *testing.T, cfg config.RetentionConfig) (*Job, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewJob(rdb, cfg), rdb
+}
+
+// addEntries 向 key 写入 ID 为 1000-0、2000-0 ... n*1000-0 的条目
+func addEntries(t *testing.T, rdb *redis.Client, key string, n int) {
+	t.Helper()
+	for i := 1; i <= n; i++ {
+		addEntry(t, rdb, key, fmt.Sprintf("%d-0", i*1000))
+	}
+}
+
+func addEntry(t *testing.T, rdb *redis.Client, key, id string) {
+	t.Helper()
+	err := rdb.XAdd(context.Background(), &redis.XAddArgs{Stream: key, ID: id, Values: []string{"msg_id", id}}).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// firstID 返回流中最早的条目ID，流为空时返回空
+func firstID(t *testing.T, rdb *redis.Client, key string) string {
+	t.Helper()
+	entries, err := rdb.XRangeN(context.Background(), key, "-", "+", 1).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[0].ID
+}
+
+func TestCountMinID(t *testing.T) {
+	const key = "stream:private:u"
+
+	tests := []struct {
+		name       string
+		maxEntries int64
+		want       string
+	}{
+		{name: "within limit", maxEntries: 10, want: ""},
+		{name: "few excess reads from the start", maxEntries: 8, want: "3000-0"},
+		{name: "many excess reads from the end", maxEntries: 3, want: "8000-0"},
+		{name: "excess equal to kept", maxEntries: 5, want: "6000-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, rdb := newTestJob(t, config.RetentionConfig{})
+			addEntries(t, rdb, key, 10)
+
+			got, err := j.countMinID(context.Background(), key, tt.maxEntries)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("countMinID(%d) = %q, want %q", tt.maxEntries, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTrimStream(t *testing.T) {
+	const key = "stream:private:u"
+
+	tests := []struct {
+		name        string
+		maxEntries  int64
+		ageMinID    string
+		limit       string
+		wantTrimmed int64
+		wantFirst   string
+	}{
+		{name: "no rule applies", maxEntries: 20, wantTrimmed: 0, wantFirst: "1000-0"},
+		{name: "count only", maxEntries: 3, wantTrimmed: 7, wantFirst: "8000-0"},
+		{name: "age only", ageMinID: "4000-0", wantTrimmed: 3, wantFirst: "4000-0"},
+		{name: "age newer than count", maxEntries: 8, ageMinID: "5000-0", wantTrimmed: 4, wantFirst: "5000-0"},
+		{name: "count newer than age", maxEntries: 3, ageMinID: "2000-0", wantTrimmed: 7, wantFirst: "8000-0"},
+		{name: "watermark clamps count", maxEntries: 3, limit: "6000-0", wantTrimmed: 5, wantFirst: "6000-0"},
+		{name: "watermark clamps age", ageMinID: "9000-0", limit: "4500-0", wantTrimmed: 4, wantFirst: "5000-0"},
+		{name: "watermark after trim position", maxEntries: 8, limit: "6000-0", wantTrimmed: 2, wantFirst: "3000-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			j, rdb := newTestJob(t, config.RetentionConfig{})
+			addEntries(t, rdb, key, 10)
+
+			trimmed, err := j.trimStream(context.Background(), key, tt.maxEntries, tt.ageMinID, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if trimmed != tt.wantTrimmed {
+				t.Errorf("trimmed %d entries, want %d", trimmed, tt.wantTrimmed)
+			}
+			if got := firstID(t, rdb, key); got != tt.wantFirst {
+				t.Errorf("first entry = %q, want %q", got, tt.wantFirst)
+			}
+		})
+	}
+}
+
+func TestTrimAllEmptyQueueKeepsSafetyMargin(t *testing.T) {
+	const key = "stream:private:u"
+	j, rdb := newTestJob(t, config.RetentionConfig{
+		MaxEntries:          1,
+		KeepUntilPersisted:  true,
+		SafetyMarginSeconds: 60,
+	})
+
+	// 落库队列为空时水位为当前时间减去安全余量：之前的条目可以裁剪，之后的即使超出条数也保留
+	now := time.Now()
+	old := fmt.Sprintf("%d-0", now.Add(-2*time.Minute).UnixMilli())
+	recent := fmt.Sprintf("%d-0", now.Add(-30*time.Second).UnixMilli())
+	latest := fmt.Sprintf("%d-0", now.Add(-10*time.Second).UnixMilli())
+	for _, id := range []string{old, recent, latest} {
+		addEntry(t, rdb, key, id)
+	}
+
+	scanned, trimmed, err := j.trimAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scanned != 1 || trimmed != 1 {
+		t.Fatalf("scanned %d streams and trimmed %d entries, want 1 and 1", scanned, trimmed)
+	}
+	if got := firstID(t, rdb, key); got != recent {
+		t.Errorf("first entry = %q, want %q", got, recent)
+	}
+}
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"ChatIM/pkg/stream"
+)
+
+// RunPresenceSync 订阅网关发布的上线/下线事件，同步到 users.status 和 users.last_seen_at，直到 ctx 结束
+// 在线状态以 Redis 为准，这里只是为数据库中的用户资料保留一份副本
+func (h *UserHandler) RunPresenceSync(ctx context.Context) {
+	pubsub := h.redis.Subscribe(ctx, stream.PresenceChannel)
+	defer pubsub.Close()
+
+	log.Printf("✅ Subscribed to Redis channel '%s'", stream.PresenceChannel)
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+
+			var event stream.PresenceEvent
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				log.Printf("Failed to unmarshal presence event: %v", err)
+				continue
+			}
+			if event.UserID == "" || (event.Status != "online" && event.Status != "offline") {
+				continue
+			}
+			if err := h.setUserStatus(ctx, event.UserID, event.Status, event.LastSeenAt); err != nil {
+				log.Printf("Failed to sync status for user %s: %v", event.UserID, err)
+			}
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	pb "ChatIM/api/proto/user"
 	"ChatIM/pkg/auth"
+	"ChatIM/pkg/stream"
 
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
@@ -19,14 +20,16 @@ import (
 
 type UserHandler struct {
 	pb.UnimplementedUserServiceServer
-	db    *sql.DB
-	redis *redis.Client
+	db       *sql.DB
+	redis    *redis.Client
+	streamOp *stream.StreamOperator
 }
 
 func NewUserHandler(db *sql.DB, redis *redis.Client) *UserHandler {
 	return &UserHandler{
-		db:    db,
-		redis: redis,
+		db:       db,
+		redis:    redis,
+		streamOp: stream.NewStreamOperator(redis),
 	}
 }
 
@@ -129,12 +132,7 @@ func (h *UserHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 		log.Printf("Failed to generate token for user %s: %v", req.Username, err)
 		return nil, fmt.Errorf("failed to generate token")
 	}
-	// 4. 将用户状态写入 Redis (在线状态)
-	err = h.redis.Set(ctx, "online_status:"+userID, "1", 24*time.Hour).Err()
-	if err != nil {
-		// Redis 写入失败不应该影响登录，但应该记录日志
-		log.Printf("Warning: failed to set user online status in Redis for user %s: %v", userID, err)
-	}
+	// 4. 在线状态由 WebSocket 连接的心跳维护，登录时不再写入
 	// 👇 5. 新增：将 username -> user_id 的映射写入 Redis
 	// 这个缓存可以设置得更久，比如 7 天
 	usernameKey := "user_id_by_username:" + req.Username
@@ -152,16 +150,20 @@ func (h *UserHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 }
 func (h *UserHandler) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
 	log.Printf("Received logout request for user_id: %s", req.Username)
-	// 删除 Redis 中的在线状态
-	onlineKey := "online_status:" + req.Username
-	err := h.redis.Del(ctx, onlineKey).Err()
-	if err != nil {
+	// 删除 Redis 中所有设备的在线状态，并记录最后在线时间
+	if err := h.streamOp.ClearPresence(ctx, req.Username); err != nil {
 		log.Printf("Error deleting online status from Redis for user %s: %v", req.Username, err)
 		return &pb.LogoutResponse{
 			Code:    -1,
 			Message: "服务内部错误",
 		}, nil
 	}
+	if err := h.streamOp.RecordUserOnlineTime(ctx, req.Username); err != nil {
+		log.Printf("Warning: failed to record last seen time for user %s: %v", req.Username, err)
+	}
+	if err := h.setUserStatus(ctx, req.Username, "offline", time.Now().Unix()); err != nil {
+		log.Printf("Warning: failed to update status for user %s: %v", req.Username, err)
+	}
 
 	return &pb.LogoutResponse{
 		Code:    0,
@@ -243,9 +245,9 @@ func (h *UserHandler) CheckUserOnline(ctx context.Context, req *pb.CheckUserOnli
 	}
 
 	// 现在 targetUserID 已经是我们要查询的 UUID 了
+	// 在线状态由 WebSocket 心跳刷新，最后在线时间在连接断开时记录
 	log.Printf("Checking online status for user_id: %s", targetUserID)
-	onlineKey := "online_status:" + targetUserID
-	result, err := h.redis.Exists(ctx, onlineKey).Result()
+	isOnline, err := h.streamOp.IsUserOnline(ctx, targetUserID)
 	if err != nil {
 		log.Printf("Error checking user online status in Redis: %v", err)
 		return &pb.CheckUserOnlineResponse{
@@ -255,16 +257,52 @@ func (h *UserHandler) CheckUserOnline(ctx context.Context, req *pb.CheckUserOnli
 		}, nil
 	}
 
-	isOnline := result == 1
+	// 在线用户不返回最后在线时间（为 0）
+	var lastSeenAt int64
+	if !isOnline {
+		lastSeenAt = h.lastSeenAt(ctx, targetUserID)
+	}
 	log.Printf("User %s is online: %t", targetUserID, isOnline)
 
 	return &pb.CheckUserOnlineResponse{
-		Code:     0,
-		Message:  "查询成功",
-		IsOnline: isOnline,
+		Code:       0,
+		Message:    "查询成功",
+		IsOnline:   isOnline,
+		LastSeenAt: lastSeenAt,
 	}, nil
 }
 
+// lastSeenAt 返回离线用户的最后在线时间：优先使用 Redis 中的记录，没有记录时读取 users.last_seen_at
+func (h *UserHandler) lastSeenAt(ctx context.Context, userID string) int64 {
+	lastSeenAt, err := h.streamOp.GetUserLastSeen(ctx, userID)
+	if err == nil && lastSeenAt > 0 {
+		return lastSeenAt
+	}
+
+	var dbLastSeen sql.NullInt64
+	err = h.db.QueryRowContext(ctx,
+		"SELECT UNIX_TIMESTAMP(last_seen_at) FROM users WHERE id = ?", userID).Scan(&dbLastSeen)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			log.Printf("Error querying last_seen_at for user %s: %v", userID, err)
+		}
+		return 0
+	}
+	return dbLastSeen.Int64
+}
+
+// setUserStatus 同步 users.status 和 users.last_seen_at，lastSeenAt 为 0 时只更新状态
+func (h *UserHandler) setUserStatus(ctx context.Context, userID, status string, lastSeenAt int64) error {
+	if lastSeenAt <= 0 {
+		_, err := h.db.ExecContext(ctx, "UPDATE users SET status = ? WHERE id = ?", status, userID)
+		return err
+	}
+	_, err := h.db.ExecContext(ctx,
+		"UPDATE users SET status = ?, last_seen_at = FROM_UNIXTIME(?) WHERE id = ?",
+		status, lastSeenAt, userID)
+	return err
+}
+
 // SearchUsers 搜索用户
 func (h *UserHandler) SearchUsers(ctx context.Context, req *pb.SearchUsersRequest) (*pb.SearchUsersResponse, error) {
 	log.Printf("Searching users with keyword: %s", req.Keyword)
@@ -0,0 +1,394 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+
+	"ChatIM/pkg/stream"
+)
+
+// 送达确认
+//
+// 私聊和群聊消息的推送帧携带 stream_id（消息在接收者 Stream 中的ID），客户端收到后回复
+//   {"v": 1, "op": "delivered", "data": {"stream_ids": ["..."]}}
+// 网关对每个连接跟踪已推送但未确认的消息，ackTimeout 内未确认的重发，发送 maxDeliveryAttempts 次仍未确认的放弃重发，
+// 推送 {"type": "resync", "stream_id": "..."}（最早一条未送达的消息），客户端收到后通过 sync 补拉。
+// 连接断开时仍有未确认的消息则在 Redis 中记录补拉标记，同一设备重新连接时推送 resync；
+// 未传 device_id 的连接每次使用新的设备ID，补拉标记按用户记录，由该用户下一个未传 device_id 的连接取出。
+// 私聊消息被对方设备确认后记录送达回执，并以 delivered 事件通知发送者。
+
+const (
+	opDelivered = "delivered" // 确认收到推送（deliveredRequest）
+
+	// ackTimeout 推送后等待客户端确认的时间，超时未确认时重发
+	ackTimeout = 10 * time.Second
+	// retransmitInterval 检查未确认推送的间隔
+	retransmitInterval = 5 * time.Second
+	// maxDeliveryAttempts 单条推送的最大发送次数（含首次）
+	maxDeliveryAttempts = 3
+	// maxPendingDeliveries 单个连接最多跟踪的未确认推送数，超出时放弃最早的推送
+	maxPendingDeliveries = 512
+	// resyncTTL 补拉标记的有效期
+	resyncTTL = 7 * 24 * time.Hour
+	// deliveryTimeout 记录送达回执和补拉标记时访问 Redis 的超时时间
+	deliveryTimeout = 2 * time.Second
+	// anonymousResyncDevice 未传 device_id 的连接记录补拉标记使用的设备ID（不是合法的 device_id，不会与客户端设备冲突）
+	anonymousResyncDevice = "*"
+)
+
+// deliveredRequest delivered 请求帧的 data
+type deliveredRequest struct {
+	StreamIDs []string `json:"stream_ids"`
+}
+
+// pendingDelivery 已推送但未确认的一条消息
+type pendingDelivery struct {
+	streamID   string
+	message    []byte
+	msgID      string
+	fromUserID string
+	createdAt  int64
+	private    bool // 私聊消息，确认后记录送达回执
+	sentAt     time.Time
+	attempts   int
+}
+
+// deliveryTracker 单个连接已推送但未确认的消息（按推送顺序），由 Hub 和连接的 readPump 并发访问
+type deliveryTracker struct {
+	mu      sync.Mutex
+	pending []*pendingDelivery
+	missed  string // 已放弃重发的最早一条消息的 Stream ID，客户端补拉后清除
+}
+
+func newDeliveryTracker() *deliveryTracker {
+	return &deliveryTracker{}
+}
+
+// track 记录一条已推送的消息，超过上限时放弃最早的一条
+func (t *deliveryTracker) track(d *pendingDelivery) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pending = append(t.pending, d)
+	if len(t.pending) > maxPendingDeliveries {
+		t.markMissedLocked(t.pending[0].streamID)
+		t.pending[0] = nil
+		t.pending = t.pending[1:]
+	}
+}
+
+// ack 移除已确认的消息并返回
+func (t *deliveryTracker) ack(streamIDs []string) []*pendingDelivery {
+	acked := make(map[string]bool, len(streamIDs))
+	for _, id := range streamIDs {
+		acked[id] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var removed []*pendingDelivery
+	remaining := t.pending[:0]
+	for _, d := range t.pending {
+		if acked[d.streamID] {
+			removed = append(removed, d)
+			continue
+		}
+		remaining = append(remaining, d)
+	}
+	for i := len(remaining); i < len(t.pending); i++ {
+		t.pending[i] = nil
+	}
+	t.pending = remaining
+	return removed
+}
+
+// due 返回需要重发的消息，发送次数已达上限的消息不再重发；返回的 missed 非空表示本次有消息被放弃
+func (t *deliveryTracker) due(now time.Time) (resend [][]byte, missed string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	remaining := t.pending[:0]
+	for _, d := range t.pending {
+		if now.Sub(d.sentAt) < ackTimeout {
+			remaining = append(remaining, d)
+			continue
+		}
+		if d.attempts >= maxDeliveryAttempts {
+			if missed == "" {
+				missed = d.streamID
+			}
+			t.markMissedLocked(d.streamID)
+			continue
+		}
+		d.attempts++
+		d.sentAt = now
+		resend = append(resend, d.message)
+		remaining = append(remaining, d)
+	}
+	for i := len(remaining); i < len(t.pending); i++ {
+		t.pending[i] = nil
+	}
+	t.pending = remaining
+	return resend, missed
+}
+
+// oldest 返回最早一条未送达消息的 Stream ID（已放弃的优先），全部已确认时返回空字符串
+func (t *deliveryTracker) oldest() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.missed != "" {
+		return t.missed
+	}
+	if len(t.pending) > 0 {
+		return t.pending[0].streamID
+	}
+	return ""
+}
+
+// resynced 客户端已补拉，清除放弃重发的记录
+func (t *deliveryTracker) resynced() {
+	t.mu.Lock()
+	t.missed = ""
+	t.mu.Unlock()
+}
+
+func (t *deliveryTracker) markMissedLocked(streamID string) {
+	if t.missed == "" {
+		t.missed = streamID
+	}
+}
+
+// resyncFrame 通知客户端有消息未能送达，需要通过 sync 补拉
+func resyncFrame(streamID string) []byte {
+	message, _ := json.Marshal(map[string]interface{}{
+		"type":      "resync",
+		"stream_id": streamID,
+	})
+	return message
+}
+
+// SendTrackedToUsers 推送私聊/群聊消息并跟踪送达：每个接收者的推送帧携带消息在其 Stream 中的ID，
+// streamIDs 的 key 为用户ID（读扩散群为 stream.StreamIDsAll），没有 Stream ID 的接收者按普通推送处理
+// 返回推送成功的用户数
+func (h *Hub) SendTrackedToUsers(userIDs []string, push map[string]interface{}, streamIDs map[string]string, except Device) int {
+	msgID, _ := push["id"].(string)
+	fromUserID, _ := push["from_user_id"].(string)
+	msgType, _ := push["type"].(string)
+	createdAt, _ := push["created_at"].(float64)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for _, userID := range userIDs {
+		if _, ok := h.clients[userID]; !ok {
+			continue
+		}
+
+		streamID := streamIDs[userID]
+		if streamID == "" {
+			streamID = streamIDs[stream.StreamIDsAll]
+		}
+		if streamID == "" {
+			delete(push, "stream_id")
+		} else {
+			push["stream_id"] = streamID
+		}
+		message, err := json.Marshal(push)
+		if err != nil {
+			log.Printf("Failed to marshal push message for user %s: %v", userID, err)
+			continue
+		}
+		if streamID == "" {
+			if h.sendToUserLocked(userID, message, except) > 0 {
+				delivered++
+			}
+			continue
+		}
+
+		sent := 0
+		for deviceID, client := range h.clients[userID] {
+			if userID == except.UserID && deviceID == except.DeviceID {
+				continue
+			}
+			client.delivery.track(&pendingDelivery{
+				streamID:   streamID,
+				message:    message,
+				msgID:      msgID,
+				fromUserID: fromUserID,
+				createdAt:  int64(createdAt),
+				private:    msgType == "private",
+				sentAt:     time.Now(),
+				attempts:   1,
+			})
+			select {
+			case client.Send <- message:
+				sent++
+			default:
+				// 通道已满或已关闭，认为客户端已断开，未确认的消息在断开时标记补拉
+				log.Printf("Failed to send message to user %s device %s, channel blocked", userID, deviceID)
+				h.removeClientLocked(client)
+			}
+		}
+		if sent > 0 {
+			delivered++
+		}
+	}
+	return delivered
+}
+
+// retransmit 重发超时未确认的推送，发送次数已达上限的推送放弃重发并通知客户端补拉
+func (h *Hub) retransmit() {
+	now := time.Now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, devices := range h.clients {
+		for _, client := range devices {
+			resend, missed := client.delivery.due(now)
+			if missed != "" {
+				resend = append(resend, resyncFrame(missed))
+				log.Printf("User %s device %s did not acknowledge pushes, asking client to resync from %s", client.UserID, client.DeviceID, missed)
+			}
+			for _, message := range resend {
+				select {
+				case client.Send <- message:
+				default:
+					log.Printf("Failed to retransmit to user %s device %s, channel blocked", client.UserID, client.DeviceID)
+					h.removeClientLocked(client)
+				}
+				if h.clients[client.UserID][client.DeviceID] != client {
+					break
+				}
+			}
+		}
+	}
+}
+
+// handleDelivered 处理客户端的送达确认：停止重发，私聊消息记录送达回执并通知发送者
+func (h *Hub) handleDelivered(c *Client, req deliveredRequest) {
+	acked := c.delivery.ack(req.StreamIDs)
+
+	// 同一发送者只需记录最后一条（推送顺序即接收者 Stream 中的顺序）
+	latest := make(map[string]*pendingDelivery)
+	for _, d := range acked {
+		if !d.private || d.fromUserID == "" || d.fromUserID == c.UserID {
+			continue
+		}
+		latest[d.fromUserID] = d
+	}
+	if len(latest) == 0 {
+		return
+	}
+
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	for senderID, d := range latest {
+		receipt := stream.DeliveryReceipt{
+			StreamID:    d.streamID,
+			MsgID:       d.msgID,
+			CreatedAt:   d.createdAt,
+			DeliveredAt: time.Now().Unix(),
+		}
+		advanced, err := so.AdvanceDeliveryReceipt(ctx, senderID, c.UserID, receipt)
+		if err != nil || !advanced {
+			continue
+		}
+
+		payload, err := json.Marshal(map[string]interface{}{
+			"type":         "delivered",
+			"to_user_id":   senderID,
+			"peer_id":      c.UserID,
+			"msg_id":       receipt.MsgID,
+			"created_at":   receipt.CreatedAt,
+			"delivered_at": receipt.DeliveredAt,
+		})
+		if err != nil {
+			continue
+		}
+		if err := h.rdb.Load().Publish(ctx, stream.NotificationChannel, payload).Err(); err != nil {
+			log.Printf("Failed to publish delivery receipt from user %s: %v", c.UserID, err)
+		}
+	}
+}
+
+// resumeDelivery 连接建立时取出设备的补拉标记，存在时通知客户端补拉
+func (h *Hub) resumeDelivery(c *Client) {
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	streamID, err := so.TakeResync(ctx, c.UserID, c.resyncDeviceID())
+	if err != nil {
+		log.Printf("Failed to get resync marker for user %s device %s: %v", c.UserID, c.DeviceID, err)
+		return
+	}
+	if streamID != "" {
+		h.sendToClient(c, resyncFrame(streamID))
+	}
+}
+
+// suspendDelivery 连接断开时仍有未送达的消息，记录补拉标记；同一设备已重新连接时直接通知新连接补拉
+func (h *Hub) suspendDelivery(c *Client) {
+	streamID := c.delivery.oldest()
+	if streamID == "" {
+		return
+	}
+
+	h.mu.RLock()
+	current, ok := h.clients[c.UserID][c.DeviceID]
+	h.mu.RUnlock()
+	if ok && current != c {
+		h.sendToClient(current, resyncFrame(streamID))
+		return
+	}
+
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+
+	if err := so.MarkResync(ctx, c.UserID, c.resyncDeviceID(), streamID, resyncTTL); err != nil {
+		log.Printf("Failed to mark resync for user %s device %s: %v", c.UserID, c.DeviceID, err)
+	}
+}
+
+// resyncDeviceID 返回记录补拉标记使用的设备ID：服务端生成的设备ID不会再次出现，按用户记录
+func (c *Client) resyncDeviceID() string {
+	if c.anonymous {
+		return anonymousResyncDevice
+	}
+	return c.DeviceID
+}
+
+// sendToClient 向仍处于注册状态的连接发送一帧，通道已满时丢弃
+func (h *Hub) sendToClient(c *Client, message []byte) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.clients[c.UserID][c.DeviceID] != c {
+		return
+	}
+	select {
+	case c.Send <- message:
+	default:
+	}
+}
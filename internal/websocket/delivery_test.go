@@ -0,0 +1,341 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"ChatIM/pkg/logger"
+	"ChatIM/pkg/stream"
+)
+
+// newTestHub 创建连接到 miniredis 的 Hub
+func newTestHub(t *testing.T) (*Hub, *redis.Client) {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	h := NewHub()
+	h.rdb.Store(rdb)
+	return h, rdb
+}
+
+// addTestClient 注册一个不带网络连接的客户端
+func addTestClient(h *Hub, userID, deviceID string) *Client {
+	c := &Client{
+		UserID:      userID,
+		DeviceID:    deviceID,
+		Send:        make(chan []byte, 16),
+		connectedAt: time.Now(),
+		delivery:    newDeliveryTracker(),
+		requests:    make(chan requestFrame, maxQueuedRequests),
+	}
+	h.mu.Lock()
+	h.addClientLocked(c)
+	h.mu.Unlock()
+	return c
+}
+
+// pendingIDs 返回按推送顺序排列的未确认 Stream ID
+func pendingIDs(t *deliveryTracker) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ids := make([]string, 0, len(t.pending))
+	for _, d := range t.pending {
+		ids = append(ids, d.streamID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestDeliveryTrackerAck(t *testing.T) {
+	tests := []struct {
+		name        string
+		ack         []string
+		wantAcked   string
+		wantPending string
+	}{
+		{name: "nothing", wantPending: "1-0,2-0,3-0"},
+		{name: "unknown id", ack: []string{"9-0"}, wantPending: "1-0,2-0,3-0"},
+		{name: "middle", ack: []string{"2-0"}, wantAcked: "2-0", wantPending: "1-0,3-0"},
+		{name: "out of order", ack: []string{"3-0", "1-0"}, wantAcked: "1-0,3-0", wantPending: "2-0"},
+		{name: "all", ack: []string{"1-0", "2-0", "3-0"}, wantAcked: "1-0,2-0,3-0"},
+		{name: "duplicate ids", ack: []string{"1-0", "1-0"}, wantAcked: "1-0", wantPending: "2-0,3-0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newDeliveryTracker()
+			for _, id := range []string{"1-0", "2-0", "3-0"} {
+				tracker.track(&pendingDelivery{streamID: id, sentAt: time.Now(), attempts: 1})
+			}
+
+			var acked []string
+			for _, d := range tracker.ack(tt.ack) {
+				acked = append(acked, d.streamID)
+			}
+			if got := strings.Join(acked, ","); got != tt.wantAcked {
+				t.Errorf("acked = %q, want %q", got, tt.wantAcked)
+			}
+			if got := pendingIDs(tracker); got != tt.wantPending {
+				t.Errorf("pending = %q, want %q", got, tt.wantPending)
+			}
+		})
+	}
+}
+
+func TestDeliveryTrackerDue(t *testing.T) {
+	start := time.Now()
+
+	tests := []struct {
+		name        string
+		attempts    int
+		elapsed     time.Duration
+		wantResend  bool
+		wantMissed  bool
+		wantPending bool
+	}{
+		{name: "not yet due", attempts: 1, elapsed: ackTimeout - time.Second, wantPending: true},
+		{name: "first retransmit", attempts: 1, elapsed: ackTimeout, wantResend: true, wantPending: true},
+		{name: "last retransmit", attempts: maxDeliveryAttempts - 1, elapsed: ackTimeout, wantResend: true, wantPending: true},
+		{name: "gives up", attempts: maxDeliveryAttempts, elapsed: ackTimeout, wantMissed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := newDeliveryTracker()
+			tracker.track(&pendingDelivery{streamID: "1-0", message: []byte("m1"), sentAt: start, attempts: tt.attempts})
+
+			resend, missed := tracker.due(start.Add(tt.elapsed))
+			if (len(resend) == 1) != tt.wantResend {
+				t.Errorf("resend = %q, want resend %v", resend, tt.wantResend)
+			}
+			if (missed == "1-0") != tt.wantMissed {
+				t.Errorf("missed = %q, want missed %v", missed, tt.wantMissed)
+			}
+			if (pendingIDs(tracker) == "1-0") != tt.wantPending {
+				t.Errorf("pending = %q, want pending %v", pendingIDs(tracker), tt.wantPending)
+			}
+
+			// 放弃重发的消息作为补拉起点，客户端补拉后清除
+			wantOldest := ""
+			if tt.wantPending || tt.wantMissed {
+				wantOldest = "1-0"
+			}
+			if got := tracker.oldest(); got != wantOldest {
+				t.Errorf("oldest = %q, want %q", got, wantOldest)
+			}
+			tracker.resynced()
+			if tt.wantMissed && tracker.oldest() != "" {
+				t.Errorf("oldest after resync = %q, want empty", tracker.oldest())
+			}
+		})
+	}
+
+	// 重发后重新计时，直到达到发送次数上限
+	tracker := newDeliveryTracker()
+	tracker.track(&pendingDelivery{streamID: "1-0", message: []byte("m1"), sentAt: start, attempts: 1})
+	now := start
+	for attempt := 2; attempt <= maxDeliveryAttempts; attempt++ {
+		if resend, _ := tracker.due(now.Add(ackTimeout / 2)); len(resend) != 0 {
+			t.Fatalf("attempt %d: resent before ack timeout", attempt)
+		}
+		now = now.Add(ackTimeout)
+		if resend, _ := tracker.due(now); len(resend) != 1 {
+			t.Fatalf("attempt %d: resend = %q, want one message", attempt, resend)
+		}
+	}
+	if _, missed := tracker.due(now.Add(ackTimeout)); missed != "1-0" {
+		t.Errorf("missed after %d attempts = %q, want 1-0", maxDeliveryAttempts, missed)
+	}
+}
+
+func TestDeliveryTrackerOverflow(t *testing.T) {
+	tracker := newDeliveryTracker()
+	for i := 1; i <= maxPendingDeliveries+2; i++ {
+		tracker.track(&pendingDelivery{streamID: fmt.Sprintf("%d-0", i), sentAt: time.Now(), attempts: 1})
+	}
+
+	tracker.mu.Lock()
+	pending, first := len(tracker.pending), tracker.pending[0].streamID
+	tracker.mu.Unlock()
+	if pending != maxPendingDeliveries {
+		t.Errorf("pending = %d, want %d", pending, maxPendingDeliveries)
+	}
+	if first != "3-0" {
+		t.Errorf("first pending = %q, want 3-0", first)
+	}
+	// 被挤出的最早一条作为补拉起点
+	if got := tracker.oldest(); got != "1-0" {
+		t.Errorf("oldest = %q, want 1-0", got)
+	}
+}
+
+func TestSendTrackedToUsers(t *testing.T) {
+	h, _ := newTestHub(t)
+	phone := addTestClient(h, "b", "phone")
+	web := addTestClient(h, "b", "web")
+	sender := addTestClient(h, "a", "phone")
+
+	push := map[string]interface{}{"type": "private", "id": "m1", "from_user_id": "a", "created_at": float64(1700000000)}
+	streamIDs := map[string]string{"a": "10-0", "b": "20-0"}
+	if n := h.SendTrackedToUsers([]string{"a", "b", "offline"}, push, streamIDs, Device{UserID: "a", DeviceID: "phone"}); n != 1 {
+		t.Errorf("delivered = %d, want 1", n)
+	}
+
+	for _, c := range []*Client{phone, web} {
+		select {
+		case message := <-c.Send:
+			var frame map[string]interface{}
+			if err := json.Unmarshal(message, &frame); err != nil {
+				t.Fatal(err)
+			}
+			if frame["stream_id"] != "20-0" {
+				t.Errorf("device %s stream_id = %v, want 20-0", c.DeviceID, frame["stream_id"])
+			}
+		default:
+			t.Errorf("device %s received nothing", c.DeviceID)
+		}
+		if got := pendingIDs(c.delivery); got != "20-0" {
+			t.Errorf("device %s pending = %q, want 20-0", c.DeviceID, got)
+		}
+	}
+	// 发送设备不推送也不跟踪
+	if len(sender.Send) != 0 || pendingIDs(sender.delivery) != "" {
+		t.Errorf("sending device received the push")
+	}
+}
+
+func TestHandleDelivered(t *testing.T) {
+	ctx := context.Background()
+	h, rdb := newTestHub(t)
+	receiver := addTestClient(h, "b", "phone")
+
+	sub := rdb.Subscribe(ctx, stream.NotificationChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	track := func(streamID, msgID, from string, private bool) {
+		receiver.delivery.track(&pendingDelivery{
+			streamID: streamID, msgID: msgID, fromUserID: from, private: private,
+			createdAt: 1700000000, sentAt: time.Now(), attempts: 1,
+		})
+	}
+	track("1-0", "m1", "a", true)
+	track("2-0", "m2", "a", true)
+	track("3-0", "g1", "c", false) // 群消息不记录送达回执
+	track("4-0", "m4", "b", true)  // 自己发送的消息
+
+	h.handleDelivered(receiver, deliveredRequest{StreamIDs: []string{"1-0", "2-0", "3-0", "4-0"}})
+
+	if got := pendingIDs(receiver.delivery); got != "" {
+		t.Errorf("pending = %q, want empty", got)
+	}
+
+	receipts, err := h.streamOperator().GetDeliveryReceipts(ctx, "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := receipts["b"]; r.StreamID != "2-0" || r.MsgID != "m2" {
+		t.Errorf("receipt = %+v, want latest message m2", r)
+	}
+	for _, sender := range []string{"b", "c"} {
+		if receipts, _ := h.streamOperator().GetDeliveryReceipts(ctx, sender); len(receipts) != 0 {
+			t.Errorf("unexpected receipts for sender %s: %v", sender, receipts)
+		}
+	}
+
+	select {
+	case msg := <-sub.Channel():
+		var event map[string]interface{}
+		if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+			t.Fatal(err)
+		}
+		if event["type"] != "delivered" || event["to_user_id"] != "a" || event["msg_id"] != "m2" {
+			t.Errorf("event = %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no delivered event published")
+	}
+
+	// 再次确认更早的消息不会让回执后退，也不再通知
+	track("1-0", "m1", "a", true)
+	h.handleDelivered(receiver, deliveredRequest{StreamIDs: []string{"1-0"}})
+	select {
+	case msg := <-sub.Channel():
+		t.Errorf("unexpected event %s", msg.Payload)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSuspendAndResumeDelivery(t *testing.T) {
+	h, _ := newTestHub(t)
+	c := addTestClient(h, "b", "phone")
+	c.delivery.track(&pendingDelivery{streamID: "5-0", sentAt: time.Now(), attempts: 1})
+
+	// 连接断开：记录补拉标记
+	h.mu.Lock()
+	h.removeClientLocked(c)
+	h.mu.Unlock()
+	h.suspendDelivery(c)
+
+	// 同一设备重新连接：推送 resync 并清除标记
+	reconnected := addTestClient(h, "b", "phone")
+	h.resumeDelivery(reconnected)
+	select {
+	case message := <-reconnected.Send:
+		if string(message) != string(resyncFrame("5-0")) {
+			t.Errorf("frame = %s, want resync from 5-0", message)
+		}
+	default:
+		t.Fatal("no resync frame after reconnect")
+	}
+
+	h.resumeDelivery(reconnected)
+	if len(reconnected.Send) != 0 {
+		t.Errorf("resync marker was not cleared")
+	}
+}
+
+func TestResumeDeliveryWithGeneratedDeviceID(t *testing.T) {
+	h, _ := newTestHub(t)
+	c := addTestClient(h, "b", "generated-1")
+	c.anonymous = true
+	c.delivery.track(&pendingDelivery{streamID: "5-0", sentAt: time.Now(), attempts: 1})
+
+	h.mu.Lock()
+	h.removeClientLocked(c)
+	h.mu.Unlock()
+	h.suspendDelivery(c)
+
+	// 传了 device_id 的设备不会取走按用户记录的标记
+	phone := addTestClient(h, "b", "phone")
+	h.resumeDelivery(phone)
+	if len(phone.Send) != 0 {
+		t.Fatal("named device received the resync marker of a generated device")
+	}
+
+	// 未传 device_id 重新连接时生成了新的设备ID，仍然收到 resync
+	reconnected := addTestClient(h, "b", "generated-2")
+	reconnected.anonymous = true
+	h.resumeDelivery(reconnected)
+	select {
+	case message := <-reconnected.Send:
+		if string(message) != string(resyncFrame("5-0")) {
+			t.Errorf("frame = %s, want resync from 5-0", message)
+		}
+	default:
+		t.Fatal("no resync frame after reconnect with a generated device id")
+	}
+}
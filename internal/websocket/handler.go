@@ -3,11 +3,21 @@ package websocket
 import (
 	"log"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
 )
 
+const (
+	// maxFrameSize 客户端发来的单帧最大字节数（请求帧可能携带完整的消息内容和负载）
+	maxFrameSize = 64 * 1024
+	// maxDeviceFieldLen 设备ID和平台名称的最大长度
+	maxDeviceFieldLen = 64
+)
+
 // HandleWebSocket 处理 WebSocket 连接请求
 func (h *Hub) HandleWebSocket(c *gin.Context) {
 	// 1. 从查询参数中获取 token
@@ -20,6 +30,22 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 	}
 	userID := userIDInterface.(string)
 
+	// 2. 设备标识：客户端通过查询参数 device_id / platform 传入，未传设备ID时视为一次性设备，
+	// 一次性设备的补拉标记按用户记录（见 resyncDeviceID）
+	deviceID := strings.TrimSpace(c.Query("device_id"))
+	anonymous := deviceID == ""
+	if anonymous {
+		deviceID = uuid.New().String()
+	}
+	platform := strings.ToLower(strings.TrimSpace(c.Query("platform")))
+	if platform == "" {
+		platform = "unknown"
+	}
+	if !validDeviceField(deviceID) || !validDeviceField(platform) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid device_id or platform"})
+		return
+	}
+
 	// 3. 升级 HTTP 连接为 WebSocket 连接
 	conn, err := Upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
@@ -27,11 +53,19 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 		return
 	}
 
-	// 4. 创建客户端并注册到 Hub
+	// 4. 创建客户端并注册到 Hub，同一用户的多个设备各自保持连接
 	client := &Client{
-		Conn:   conn,
-		UserID: userID,
-		Send:   make(chan []byte, 256), // 带缓冲的通道
+		Conn:          conn,
+		UserID:        userID,
+		DeviceID:      deviceID,
+		authorization: bearerToken(c),
+		Platform:      platform,
+		Send:          make(chan []byte, 256), // 带缓冲的通道
+		connectedAt:   time.Now(),
+		typing:        newTypingTracker(),
+		delivery:      newDeliveryTracker(),
+		requests:      make(chan requestFrame, maxQueuedRequests),
+		anonymous:     anonymous,
 	}
 
 	h.register <- client
@@ -41,36 +75,82 @@ func (h *Hub) HandleWebSocket(c *gin.Context) {
 	go client.readPump(h) // 负责读取消息
 }
 
-// readPump 持续从 WebSocket 连接读取消息
+// bearerToken 返回连接使用的 Token（Authorization 请求头或查询参数 token），格式为 "Bearer <token>"
+func bearerToken(c *gin.Context) string {
+	if authHeader := c.GetHeader("Authorization"); authHeader != "" {
+		return authHeader
+	}
+	return "Bearer " + c.Query("token")
+}
+
+// validDeviceField 校验客户端传入的设备ID或平台名称：长度不超过 maxDeviceFieldLen，只能包含字母、数字和 - _ . :
+// 设备ID会写入 Redis key 和 gRPC metadata，不允许空白和控制字符
+func validDeviceField(value string) bool {
+	if value == "" || len(value) > maxDeviceFieldLen {
+		return false
+	}
+	for _, r := range value {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// readPump 持续从 WebSocket 连接读取消息，调用 MessageService 的请求帧交给 requestWorker 执行
 func (c *Client) readPump(h *Hub) {
+	go c.requestWorker(h)
+
 	defer func() {
+		close(c.requests)
+		h.stopTyping(c)
 		h.unregister <- c
+		h.leavePresence(c)
+		h.suspendDelivery(c)
 		c.Conn.Close()
 	}()
 
-	// 设置读取超时和最大消息大小
-	c.Conn.SetReadLimit(512)
-	// ... (可以设置 pong handler 等)
+	// 设置读取超时和最大消息大小，每次收到 pong 时延长读取超时并刷新在线状态
+	c.Conn.SetReadLimit(maxFrameSize)
+	c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.Conn.SetPongHandler(func(string) error {
+		c.Conn.SetReadDeadline(time.Now().Add(pongWait))
+		h.refreshPresence(c)
+		return nil
+	})
+	h.refreshPresence(c)
+	h.resumeDelivery(c)
 
 	for {
-		// 我们暂时不处理客户端发来的消息，只负责推送
-		_, _, err := c.Conn.ReadMessage()
+		messageType, data, err := c.Conn.ReadMessage()
 		if err != nil {
 			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error: %v", err)
 			}
 			break
 		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+		c.handleFrame(h, data)
 	}
 }
 
-// writePump 持续向 WebSocket 连接写入消息
+// writePump 持续向 WebSocket 连接写入消息，并按 pingPeriod 发送心跳
 func (c *Client) writePump() {
-	defer c.Conn.Close()
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.Conn.Close()
+	}()
 
 	for {
 		select {
 		case message, ok := <-c.Send:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
 			if !ok {
 				// 通道被关闭
 				c.Conn.WriteMessage(websocket.CloseMessage, []byte{})
@@ -82,6 +162,12 @@ func (c *Client) writePump() {
 				log.Printf("Failed to write message: %v", err)
 				return
 			}
+
+		case <-ticker.C:
+			c.Conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.Conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
 		}
 	}
 }
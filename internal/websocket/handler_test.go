@@ -0,0 +1,34 @@
+package websocket
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidDeviceField(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  bool
+	}{
+		{name: "uuid", value: "6f1c2b9e-0000-4000-8000-000000000001", want: true},
+		{name: "platform", value: "ios", want: true},
+		{name: "allowed punctuation", value: "web_1.0:tab-2", want: true},
+		{name: "max length", value: strings.Repeat("a", maxDeviceFieldLen), want: true},
+		{name: "empty"},
+		{name: "too long", value: strings.Repeat("a", maxDeviceFieldLen+1)},
+		{name: "space", value: "my phone"},
+		{name: "newline", value: "phone\n"},
+		{name: "glob character", value: "phone*"},
+		{name: "slash", value: "a/b"},
+		{name: "non ascii", value: "手机"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validDeviceField(tt.value); got != tt.want {
+				t.Errorf("validDeviceField(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,8 +4,14 @@ import (
 	"log"
 	"net/http"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	groupPb "ChatIM/api/proto/group"
+	msgPb "ChatIM/api/proto/message"
 
 	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
 )
 
 // Upgrader 用于将 HTTP 连接升级为 WebSocket 连接
@@ -17,17 +23,35 @@ var Upgrader = websocket.Upgrader{
 	},
 }
 
-// Client 代表一个 WebSocket 客户端
+// maxDevicesPerUser 单个用户在同一实例上同时保持的连接数上限，超出时断开最早建立的连接
+const maxDevicesPerUser = 10
+
+// Client 代表一个 WebSocket 客户端（用户的一个设备连接）
 type Client struct {
-	Conn   *websocket.Conn
-	UserID string
-	Send   chan []byte // 发送消息的通道
+	Conn     *websocket.Conn
+	UserID   string
+	DeviceID string      // 设备ID，同一设备重连时替换旧连接
+	Platform string      // 设备平台，如 web、ios、android、desktop
+	Send     chan []byte // 发送消息的通道
+
+	authorization string // 连接建立时的 Authorization（Bearer Token），用于代替用户调用 MessageService
+	connectedAt   time.Time
+	typing        *typingTracker    // 输入状态，仅由 readPump 访问
+	delivery      *deliveryTracker  // 已推送但未确认送达的消息
+	requests      chan requestFrame // 等待 requestWorker 执行的请求帧，由 readPump 写入并在退出时关闭
+	anonymous     bool              // 客户端未传 device_id，DeviceID 由服务端生成
+}
+
+// Device 标识用户的某个设备，用于推送时跳过发起操作的设备
+type Device struct {
+	UserID   string
+	DeviceID string
 }
 
 // Hub 管理所有的客户端连接
 type Hub struct {
-	// 注册的客户端，key 是 UserID
-	clients map[string]*Client
+	// 注册的客户端，key 是 UserID，value 是该用户各设备的连接（key 是 DeviceID）
+	clients map[string]map[string]*Client
 
 	// 从客户端接收的消息
 	broadcast chan []byte
@@ -40,12 +64,21 @@ type Hub struct {
 
 	// 读写锁，保护 clients map
 	mu sync.RWMutex
+
+	// 用于转发输入状态和维护在线状态的 Redis 客户端，由 StartSubscriber 设置
+	rdb atomic.Pointer[redis.Client]
+
+	// 处理 /ws 请求帧的 MessageService 客户端，由 DialMessageService 设置
+	messageClient msgPb.MessageServiceClient
+
+	// 群成员缓存未命中时加载群成员的 GroupService 客户端，由 DialGroupService 设置
+	groupClient groupPb.GroupServiceClient
 }
 
 // NewHub 创建一个新的 Hub
 func NewHub() *Hub {
 	return &Hub{
-		clients:    make(map[string]*Client),
+		clients:    make(map[string]map[string]*Client),
 		broadcast:  make(chan []byte),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
@@ -54,20 +87,21 @@ func NewHub() *Hub {
 
 // Run 启动 Hub 的主循环
 func (h *Hub) Run() {
+	ticker := time.NewTicker(retransmitInterval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case client := <-h.register:
 			h.mu.Lock()
-			h.clients[client.UserID] = client
+			h.addClientLocked(client)
 			h.mu.Unlock()
-			log.Printf("Client %s connected", client.UserID)
+			log.Printf("Client %s connected (device %s, platform %s)", client.UserID, client.DeviceID, client.Platform)
 
 		case client := <-h.unregister:
 			h.mu.Lock()
-			if _, ok := h.clients[client.UserID]; ok {
-				delete(h.clients, client.UserID)
-				close(client.Send)
-				log.Printf("Client %s disconnected", client.UserID)
+			if h.removeClientLocked(client) {
+				log.Printf("Client %s disconnected (device %s)", client.UserID, client.DeviceID)
 			}
 			h.mu.Unlock()
 
@@ -75,45 +109,117 @@ func (h *Hub) Run() {
 			// 这个 broadcast 通道我们暂时用不到，先留在这里
 			// 后续如果需要群发，可以用它
 			log.Printf("Broadcasting message: %s", string(message))
+
+		case <-ticker.C:
+			// 重发超时未确认的推送
+			h.retransmit()
 		}
 	}
 }
 
-// SendMessageToUser 向指定用户发送消息
-func (h *Hub) SendMessageToUser(userID string, message []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// addClientLocked 注册连接：同一设备重连时替换旧连接，连接数超过上限时断开最早建立的连接，调用方需持有写锁
+func (h *Hub) addClientLocked(client *Client) {
+	devices, ok := h.clients[client.UserID]
+	if !ok {
+		devices = make(map[string]*Client)
+		h.clients[client.UserID] = devices
+	}
+
+	if old, ok := devices[client.DeviceID]; ok {
+		log.Printf("Client %s reconnected from device %s, closing previous connection", client.UserID, client.DeviceID)
+		h.removeClientLocked(old)
+	}
+
+	if len(devices) >= maxDevicesPerUser {
+		var oldest *Client
+		for _, c := range devices {
+			if oldest == nil || c.connectedAt.Before(oldest.connectedAt) {
+				oldest = c
+			}
+		}
+		log.Printf("User %s exceeds %d connections, closing device %s", client.UserID, maxDevicesPerUser, oldest.DeviceID)
+		h.removeClientLocked(oldest)
+	}
+
+	// removeClientLocked 可能在用户没有其他连接时删除了整个设备表
+	if _, ok := h.clients[client.UserID]; !ok {
+		h.clients[client.UserID] = devices
+	}
+	devices[client.DeviceID] = client
+}
 
-	if client, ok := h.clients[userID]; ok {
+// removeClientLocked 注销连接并关闭其发送通道，连接已被替换或移除时返回 false，调用方需持有写锁
+func (h *Hub) removeClientLocked(client *Client) bool {
+	devices, ok := h.clients[client.UserID]
+	if !ok || devices[client.DeviceID] != client {
+		return false
+	}
+	delete(devices, client.DeviceID)
+	if len(devices) == 0 {
+		delete(h.clients, client.UserID)
+	}
+	close(client.Send)
+	return true
+}
+
+// sendToUserLocked 将消息推送给用户的所有设备（except 对应的设备除外），返回推送成功的连接数
+// 发送通道已满的连接视为已断开并移除，调用方需持有写锁
+func (h *Hub) sendToUserLocked(userID string, message []byte, except Device) int {
+	delivered := 0
+	for deviceID, client := range h.clients[userID] {
+		if userID == except.UserID && deviceID == except.DeviceID {
+			continue
+		}
 		select {
 		case client.Send <- message:
-			log.Printf("Message sent to user %s", userID)
+			delivered++
 		default:
 			// 通道已满或已关闭，认为客户端已断开
-			log.Printf("Failed to send message to user %s, channel blocked", userID)
-			close(client.Send)
-			delete(h.clients, userID)
+			log.Printf("Failed to send message to user %s device %s, channel blocked", userID, deviceID)
+			h.removeClientLocked(client)
 		}
-	} else {
+	}
+	return delivered
+}
+
+// SendMessageToUser 向指定用户的所有设备发送消息
+func (h *Hub) SendMessageToUser(userID string, message []byte) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.clients[userID]; !ok {
 		log.Printf("User %s is not connected", userID)
+		return
 	}
+	delivered := h.sendToUserLocked(userID, message, Device{})
+	log.Printf("Message sent to user %s (%d devices)", userID, delivered)
+}
+
+// SendMessageToUsers 向多个用户发送同一条消息，只推送给连接在本实例上的用户，返回推送成功的用户数
+// 用于群消息等一条通知携带多个接收者的场景，未连接的用户直接跳过
+// except 为发起操作的设备（如发送消息的设备），该设备已有结果，不再推送；不需要跳过时传零值
+func (h *Hub) SendMessageToUsers(userIDs []string, message []byte, except Device) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for _, userID := range userIDs {
+		if h.sendToUserLocked(userID, message, except) > 0 {
+			delivered++
+		}
+	}
+	return delivered
 }
 
 func (h *Hub) NotifyUser(userID string, message []byte) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	h.mu.Lock()
+	defer h.mu.Unlock()
 
-	if client, ok := h.clients[userID]; ok {
-		select {
-		case client.Send <- message:
-			log.Printf("Message successfully queued for user %s", userID)
-		default:
-			// 通道已满或已关闭，认为客户端已断开
-			log.Printf("User %s is connected but send channel is blocked, disconnecting.", userID)
-			close(client.Send)
-			delete(h.clients, userID)
-		}
-	} else {
+	if _, ok := h.clients[userID]; !ok {
 		log.Printf("User %s is not online, skipping WebSocket push.", userID)
+		return
+	}
+	if h.sendToUserLocked(userID, message, Device{}) > 0 {
+		log.Printf("Message successfully queued for user %s", userID)
 	}
 }
@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"ChatIM/pkg/stream"
+)
+
+const (
+	// writeWait 单次写入（消息或心跳）的超时时间
+	writeWait = 10 * time.Second
+	// pongWait 等待客户端 pong 的时间，超时未收到视为连接已断开
+	pongWait = 60 * time.Second
+	// pingPeriod 服务端发送 ping 的间隔，必须小于 pongWait
+	pingPeriod = (pongWait * 9) / 10
+	// presenceTTL 设备在线状态的有效期，每次收到 pong 时刷新；网关异常退出时在线状态在该时间后自动失效
+	presenceTTL = pongWait + 30*time.Second
+	// presenceTimeout 更新在线状态时访问 Redis 的超时时间
+	presenceTimeout = 2 * time.Second
+)
+
+// streamOperator 返回用于维护在线状态的 StreamOperator，Redis 未设置时返回 nil
+func (h *Hub) streamOperator() *stream.StreamOperator {
+	rdb := h.rdb.Load()
+	if rdb == nil {
+		return nil
+	}
+	return stream.NewStreamOperator(rdb)
+}
+
+// refreshPresence 连接建立和每次收到 pong 时刷新设备的在线状态，用户由离线变为在线时发布上线事件
+func (h *Hub) refreshPresence(c *Client) {
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	cameOnline, err := so.RefreshPresence(ctx, c.UserID, c.DeviceID, presenceTTL)
+	if err != nil || !cameOnline {
+		return
+	}
+	event := stream.PresenceEvent{UserID: c.UserID, Status: "online", LastSeenAt: time.Now().Unix()}
+	if err := so.PublishPresence(ctx, event); err != nil {
+		log.Printf("Failed to publish online event for user %s: %v", c.UserID, err)
+	}
+}
+
+// leavePresence 连接断开时移除设备的在线状态并记录最后在线时间，用户没有其他在线设备时发布下线事件
+// 同一设备已重新连接（连接被替换）时保留在线状态
+func (h *Hub) leavePresence(c *Client) {
+	h.mu.RLock()
+	current, ok := h.clients[c.UserID][c.DeviceID]
+	h.mu.RUnlock()
+	if ok && current != c {
+		return
+	}
+
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), presenceTimeout)
+	defer cancel()
+
+	if err := so.RecordUserOnlineTime(ctx, c.UserID); err != nil {
+		log.Printf("Failed to record last seen time for user %s: %v", c.UserID, err)
+	}
+	wentOffline, err := so.RemovePresence(ctx, c.UserID, c.DeviceID)
+	if err != nil || !wentOffline {
+		return
+	}
+	event := stream.PresenceEvent{UserID: c.UserID, Status: "offline", LastSeenAt: time.Now().Unix()}
+	if err := so.PublishPresence(ctx, event); err != nil {
+		log.Printf("Failed to publish offline event for user %s: %v", c.UserID, err)
+	}
+}
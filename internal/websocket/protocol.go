@@ -0,0 +1,304 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	msgPb "ChatIM/api/proto/message"
+	"ChatIM/pkg/clients"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// /ws 双向协议（v1）
+//
+// 客户端发送的请求帧：{"v": 1, "op": "send.private", "seq": 12, "data": {...}}
+//   - v:    协议版本，省略时为 1
+//   - op:   操作名，见下方 op* 常量
+//   - seq:  客户端自增序号，服务端在 ack 中原样带回；为 0 时不回 ack
+//   - data: 操作参数，字段与对应 HTTP 接口的请求体一致
+//
+// 服务端对每个请求帧回复一个 ack 帧：
+//   {"v": 1, "op": "ack", "seq": 12, "ok": true, "data": {...}}
+//   {"v": 1, "op": "ack", "seq": 12, "ok": false, "error": {"code": "InvalidArgument", "message": "..."}}
+// data 为对应 RPC 的响应（发送消息时包含服务端生成的消息ID和 Stream ID），error.code 为 gRPC 状态码名称。
+//
+// 调用 MessageService 的请求由连接的 requestWorker 按接收顺序逐个执行，readPump 只负责入队；
+// 队列已满时直接回复 ResourceExhausted，客户端稍后重试。输入状态和送达确认不经过队列，在 readPump 中处理。
+//
+// 服务端主动推送的事件（消息、撤回、输入状态等）保持原有格式，以 "type" 字段区分，不带 op。
+// 消息推送携带 stream_id，客户端需要以 delivered 请求确认收到，见 delivery.go。
+// 不带 op 的 typing_start / typing_stop 帧按旧格式继续处理。
+
+const (
+	protocolVersion = 1
+
+	opSend      = "send.private" // 发送私聊消息（SendMessageRequest）
+	opSendGroup = "send.group"   // 发送群聊消息（SendGroupMessageRequest）
+	opRead      = "read.private" // 标记私聊消息已读（MarkPrivateMessageAsReadRequest）
+	opReadGroup = "read.group"   // 标记群聊消息已读（MarkGroupMessageAsReadRequest）
+	opCursor    = "read.cursor"  // 更新已读游标（UpdateLastSeenCursorRequest）
+	opSync      = "sync"         // 增量拉取消息（PullMessagesRequest）
+	opHistory   = "history"      // 分页拉取会话历史（PullHistoryRequest）
+	opTyping    = "typing.start" // 正在输入
+	opTypingEnd = "typing.stop"  // 停止输入（另有 delivered，见 delivery.go）
+	opAck       = "ack"          // 服务端回复
+
+	// rpcTimeout 代替用户调用 MessageService 的超时时间
+	rpcTimeout = 10 * time.Second
+	// maxQueuedRequests 单个连接排队等待执行的请求帧上限
+	maxQueuedRequests = 32
+)
+
+// requestFrame 客户端发来的请求帧
+type requestFrame struct {
+	V    int             `json:"v,omitempty"`
+	Op   string          `json:"op"`
+	Seq  int64           `json:"seq"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// ackFrame 服务端对请求帧的回复
+type ackFrame struct {
+	V     int         `json:"v"`
+	Op    string      `json:"op"`
+	Seq   int64       `json:"seq"`
+	OK    bool        `json:"ok"`
+	Data  interface{} `json:"data,omitempty"`
+	Error *ackError   `json:"error,omitempty"`
+}
+
+type ackError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// rpcResponse MessageService 响应的公共字段，code 不为 0 时视为失败
+type rpcResponse interface {
+	GetCode() int32
+	GetMessage() string
+}
+
+// DialMessageService 连接 MessageService，连接后客户端可以通过 /ws 发送消息、标记已读和拉取消息
+func (h *Hub) DialMessageService(addr string) error {
+	client, err := clients.NewMessageClient(addr)
+	if err != nil {
+		return err
+	}
+	h.messageClient = client
+	log.Printf("WebSocket hub connected to Message Service at: %s", addr)
+	return nil
+}
+
+// handleFrame 处理客户端发来的一帧：带 op 的为请求帧，否则按旧格式的输入状态帧处理
+func (c *Client) handleFrame(h *Hub, data []byte) {
+	var frame requestFrame
+	if err := json.Unmarshal(data, &frame); err != nil {
+		log.Printf("Invalid frame from user %s: %v", c.UserID, err)
+		return
+	}
+
+	if frame.Op == "" {
+		var legacy inboundFrame
+		if err := json.Unmarshal(data, &legacy); err != nil {
+			return
+		}
+		switch legacy.Type {
+		case "typing_start", "typing_stop":
+			h.handleTyping(c, legacy)
+		default:
+			log.Printf("Unsupported frame type %q from user %s", legacy.Type, c.UserID)
+		}
+		return
+	}
+
+	// 输入状态只由 readPump 访问，送达确认需要尽快停止重发，两者都不经过请求队列
+	if frame.Op == opTyping || frame.Op == opTypingEnd || frame.Op == opDelivered {
+		h.serveRequest(c, frame)
+		return
+	}
+
+	select {
+	case c.requests <- frame:
+	default:
+		log.Printf("Request queue of user %s device %s is full, rejecting op %s", c.UserID, c.DeviceID, frame.Op)
+		if frame.Seq != 0 {
+			h.reply(c, &ackFrame{V: protocolVersion, Op: opAck, Seq: frame.Seq, Error: &ackError{
+				Code:    codes.ResourceExhausted.String(),
+				Message: "too many pending requests",
+			}})
+		}
+	}
+}
+
+// requestWorker 按接收顺序逐个执行连接的请求帧，保证同一连接发送的消息按顺序落库；readPump 退出关闭队列后结束
+func (c *Client) requestWorker(h *Hub) {
+	for frame := range c.requests {
+		h.serveRequest(c, frame)
+	}
+}
+
+// serveRequest 执行请求帧并回复 ack（seq 为 0 时不回复）
+func (h *Hub) serveRequest(c *Client, frame requestFrame) {
+	ack := &ackFrame{V: protocolVersion, Op: opAck, Seq: frame.Seq}
+	res, err := h.handleRequest(c, frame)
+	if err != nil {
+		st := status.Convert(err)
+		ack.Error = &ackError{Code: st.Code().String(), Message: st.Message()}
+	} else {
+		ack.OK = true
+		ack.Data = res
+	}
+
+	if frame.Seq == 0 {
+		return
+	}
+	h.reply(c, ack)
+}
+
+// handleRequest 执行请求帧对应的操作，返回 ack 中的 data
+func (h *Hub) handleRequest(c *Client, frame requestFrame) (interface{}, error) {
+	if frame.V != 0 && frame.V != protocolVersion {
+		return nil, status.Errorf(codes.Unimplemented, "unsupported protocol version %d", frame.V)
+	}
+
+	switch frame.Op {
+	case opTyping, opTypingEnd:
+		var typing inboundFrame
+		if err := decodeData(frame.Data, &typing); err != nil {
+			return nil, err
+		}
+		typing.Type = "typing_start"
+		if frame.Op == opTypingEnd {
+			typing.Type = "typing_stop"
+		}
+		h.handleTyping(c, typing)
+		return nil, nil
+	case opDelivered:
+		var delivered deliveredRequest
+		if err := decodeData(frame.Data, &delivered); err != nil {
+			return nil, err
+		}
+		h.handleDelivered(c, delivered)
+		return nil, nil
+	}
+
+	if h.messageClient == nil {
+		return nil, status.Errorf(codes.Unavailable, "message service is not available")
+	}
+
+	ctx, cancel := context.WithTimeout(c.rpcContext(), rpcTimeout)
+	defer cancel()
+
+	var (
+		res rpcResponse
+		err error
+	)
+	switch frame.Op {
+	case opSend:
+		req := &msgPb.SendMessageRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.SendMessage(ctx, req)
+	case opSendGroup:
+		req := &msgPb.SendGroupMessageRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.SendGroupMessage(ctx, req)
+	case opRead:
+		req := &msgPb.MarkPrivateMessageAsReadRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.MarkPrivateMessageAsRead(ctx, req)
+	case opReadGroup:
+		req := &msgPb.MarkGroupMessageAsReadRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.MarkGroupMessageAsRead(ctx, req)
+	case opCursor:
+		req := &msgPb.UpdateLastSeenCursorRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.UpdateLastSeenCursor(ctx, req)
+	case opSync:
+		req := &msgPb.PullMessagesRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		var pulled *msgPb.PullMessagesResponse
+		pulled, err = h.messageClient.PullMessages(ctx, req)
+		res = pulled
+		// 补拉到最后一页后才清除补拉起点
+		if err == nil && pulled.GetCode() == 0 && !pulled.GetHasMore() {
+			c.delivery.resynced()
+		}
+	case opHistory:
+		req := &msgPb.PullHistoryRequest{}
+		if err := decodeData(frame.Data, req); err != nil {
+			return nil, err
+		}
+		res, err = h.messageClient.PullHistory(ctx, req)
+	default:
+		return nil, status.Errorf(codes.Unimplemented, "unknown op %q", frame.Op)
+	}
+
+	if err != nil {
+		log.Printf("WebSocket op %s from user %s failed: %v", frame.Op, c.UserID, err)
+		return nil, err
+	}
+	if res.GetCode() != 0 {
+		return nil, status.Error(codes.FailedPrecondition, res.GetMessage())
+	}
+	return res, nil
+}
+
+// rpcContext 以连接建立时的 Token 和设备ID代替用户调用 MessageService（与 HTTP 网关透传的 metadata 一致）
+// Token 过期后调用返回 Unauthenticated，客户端需要使用新 Token 重新连接
+func (c *Client) rpcContext() context.Context {
+	md := metadata.New(map[string]string{
+		"authorization": c.authorization,
+		"x-device-id":   c.DeviceID,
+	})
+	return metadata.NewOutgoingContext(context.Background(), md)
+}
+
+// decodeData 解析请求帧的 data，字段与 HTTP 接口的 JSON 请求体一致
+func decodeData(data json.RawMessage, v interface{}) error {
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid data: %v", err)
+	}
+	return nil
+}
+
+// reply 将 ack 写入连接的发送通道
+// 连接已被注销（发送通道已关闭）或通道已满时丢弃，客户端按请求超时处理并重试
+func (h *Hub) reply(c *Client, ack *ackFrame) {
+	message, err := json.Marshal(ack)
+	if err != nil {
+		log.Printf("Failed to marshal ack for user %s: %v", c.UserID, err)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.clients[c.UserID][c.DeviceID] != c {
+		return
+	}
+	select {
+	case c.Send <- message:
+	default:
+		log.Printf("Failed to send ack %d to user %s, channel blocked", ack.Seq, c.UserID)
+	}
+}
@@ -0,0 +1,219 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	msgPb "ChatIM/api/proto/message"
+)
+
+// fakeMessageService 只实现 SendMessage 的 MessageService 客户端，其余 RPC 未实现（调用时 panic）
+type fakeMessageService struct {
+	msgPb.MessageServiceClient
+	send func(ctx context.Context, req *msgPb.SendMessageRequest) (*msgPb.SendMessageResponse, error)
+}
+
+func (f *fakeMessageService) SendMessage(ctx context.Context, req *msgPb.SendMessageRequest, _ ...grpc.CallOption) (*msgPb.SendMessageResponse, error) {
+	return f.send(ctx, req)
+}
+
+// receiveAck 读取连接收到的下一个 ack 帧，没有时返回 nil
+func receiveAck(t *testing.T, c *Client) map[string]interface{} {
+	t.Helper()
+	select {
+	case message := <-c.Send:
+		var ack map[string]interface{}
+		if err := json.Unmarshal(message, &ack); err != nil {
+			t.Fatalf("ack is not valid JSON: %v: %s", err, message)
+		}
+		return ack
+	case <-time.After(time.Second):
+		return nil
+	}
+}
+
+func TestDecodeData(t *testing.T) {
+	tests := []struct {
+		name        string
+		data        string
+		wantErr     bool
+		wantToUser  string
+		wantMsgType string
+	}{
+		{name: "omitted"},
+		{name: "empty object", data: `{}`},
+		{name: "snake case fields", data: `{"to_user_id": "b", "msg_type": "text", "content": "hi"}`, wantToUser: "b", wantMsgType: "text"},
+		{name: "unknown fields ignored", data: `{"to_user_id": "b", "extra": 1}`, wantToUser: "b"},
+		{name: "wrong field type", data: `{"to_user_id": 1}`, wantErr: true},
+		{name: "not an object", data: `"hello"`, wantErr: true},
+		{name: "malformed", data: `{"to_user_id":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data json.RawMessage
+			if tt.data != "" {
+				data = json.RawMessage(tt.data)
+			}
+			req := &msgPb.SendMessageRequest{}
+			err := decodeData(data, req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("decodeData(%s) error = %v, wantErr %v", tt.data, err, tt.wantErr)
+			}
+			if err != nil {
+				if status.Code(err) != codes.InvalidArgument {
+					t.Errorf("code = %v, want InvalidArgument", status.Code(err))
+				}
+				return
+			}
+			if req.ToUserId != tt.wantToUser || req.MsgType != tt.wantMsgType {
+				t.Errorf("decoded = %q %q, want %q %q", req.ToUserId, req.MsgType, tt.wantToUser, tt.wantMsgType)
+			}
+		})
+	}
+}
+
+func TestServeRequest(t *testing.T) {
+	service := &fakeMessageService{send: func(ctx context.Context, req *msgPb.SendMessageRequest) (*msgPb.SendMessageResponse, error) {
+		// 以连接的 Token 和设备ID代替用户调用
+		md, _ := metadata.FromOutgoingContext(ctx)
+		if got := md.Get("authorization"); len(got) != 1 || got[0] != "Bearer token" {
+			return nil, status.Errorf(codes.Unauthenticated, "authorization = %v", got)
+		}
+		if got := md.Get("x-device-id"); len(got) != 1 || got[0] != "phone" {
+			return nil, status.Errorf(codes.Unauthenticated, "x-device-id = %v", got)
+		}
+
+		switch req.ToUserId {
+		case "blocked":
+			return &msgPb.SendMessageResponse{Code: 1, Message: "blocked by user"}, nil
+		case "missing":
+			return nil, status.Errorf(codes.NotFound, "user not found")
+		}
+		return &msgPb.SendMessageResponse{StreamId: "1-0", Msg: &msgPb.Message{Id: "m1"}}, nil
+	}}
+
+	tests := []struct {
+		name        string
+		frame       string
+		noService   bool
+		wantAck     bool
+		wantOK      bool
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "send", frame: `{"v":1,"op":"send.private","seq":1,"data":{"to_user_id":"b","content":"hi"}}`, wantAck: true, wantOK: true},
+		{name: "version omitted", frame: `{"op":"send.private","seq":2,"data":{"to_user_id":"b"}}`, wantAck: true, wantOK: true},
+		{name: "no ack without seq", frame: `{"v":1,"op":"send.private","data":{"to_user_id":"b"}}`},
+		{name: "unsupported version", frame: `{"v":2,"op":"send.private","seq":3}`, wantAck: true, wantCode: codes.Unimplemented},
+		{name: "unknown op", frame: `{"v":1,"op":"send.channel","seq":4}`, wantAck: true, wantCode: codes.Unimplemented},
+		{name: "invalid data", frame: `{"v":1,"op":"send.private","seq":5,"data":{"to_user_id":[]}}`, wantAck: true, wantCode: codes.InvalidArgument},
+		{name: "rpc error", frame: `{"v":1,"op":"send.private","seq":6,"data":{"to_user_id":"missing"}}`, wantAck: true, wantCode: codes.NotFound, wantMessage: "user not found"},
+		{name: "response code", frame: `{"v":1,"op":"send.private","seq":7,"data":{"to_user_id":"blocked"}}`, wantAck: true, wantCode: codes.FailedPrecondition, wantMessage: "blocked by user"},
+		{name: "service unavailable", frame: `{"v":1,"op":"send.private","seq":8}`, noService: true, wantAck: true, wantCode: codes.Unavailable},
+		{name: "delivered ack", frame: `{"v":1,"op":"delivered","seq":9,"data":{"stream_ids":["1-0"]}}`, noService: true, wantAck: true, wantOK: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub()
+			if !tt.noService {
+				h.messageClient = service
+			}
+			c := addTestClient(h, "a", "phone")
+			c.authorization = "Bearer token"
+			go c.requestWorker(h)
+			defer close(c.requests)
+
+			c.handleFrame(h, []byte(tt.frame))
+
+			ack := receiveAck(t, c)
+			if !tt.wantAck {
+				if ack != nil {
+					t.Errorf("unexpected ack %v", ack)
+				}
+				return
+			}
+			if ack == nil {
+				t.Fatal("no ack")
+			}
+			if ack["op"] != opAck || ack["v"] != float64(protocolVersion) {
+				t.Errorf("ack header = %v", ack)
+			}
+			if ok, _ := ack["ok"].(bool); ok != tt.wantOK {
+				t.Fatalf("ack ok = %v, want %v: %v", ok, tt.wantOK, ack)
+			}
+			if tt.wantOK {
+				return
+			}
+			ackErr, _ := ack["error"].(map[string]interface{})
+			if ackErr["code"] != tt.wantCode.String() {
+				t.Errorf("error code = %v, want %v", ackErr["code"], tt.wantCode)
+			}
+			if tt.wantMessage != "" && ackErr["message"] != tt.wantMessage {
+				t.Errorf("error message = %v, want %q", ackErr["message"], tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestRequestQueue(t *testing.T) {
+	release := make(chan struct{})
+	var order []string
+	h := NewHub()
+	h.messageClient = &fakeMessageService{send: func(ctx context.Context, req *msgPb.SendMessageRequest) (*msgPb.SendMessageResponse, error) {
+		<-release
+		order = append(order, req.Content)
+		return &msgPb.SendMessageResponse{}, nil
+	}}
+	c := addTestClient(h, "a", "phone")
+	c.Send = make(chan []byte, maxQueuedRequests+8)
+
+	// 队列未消费时，超出上限的请求立即被拒绝，readPump 不会阻塞
+	for i := 1; i <= maxQueuedRequests+1; i++ {
+		c.handleFrame(h, []byte(fmt.Sprintf(`{"v":1,"op":"send.private","seq":%d,"data":{"to_user_id":"b","content":"%d"}}`, i, i)))
+	}
+	ack := receiveAck(t, c)
+	if ack == nil || ack["seq"] != float64(maxQueuedRequests+1) {
+		t.Fatalf("ack = %v, want rejection of seq %d", ack, maxQueuedRequests+1)
+	}
+	if ackErr, _ := ack["error"].(map[string]interface{}); ackErr["code"] != codes.ResourceExhausted.String() {
+		t.Errorf("error = %v, want ResourceExhausted", ack["error"])
+	}
+
+	// 排队的请求按接收顺序执行
+	go c.requestWorker(h)
+	close(release)
+	for i := 1; i <= maxQueuedRequests; i++ {
+		ack := receiveAck(t, c)
+		if ack == nil || ack["seq"] != float64(i) || ack["ok"] != true {
+			t.Fatalf("ack %d = %v", i, ack)
+		}
+	}
+	close(c.requests)
+	for i, content := range order {
+		if content != fmt.Sprint(i+1) {
+			t.Fatalf("execution order = %v", order)
+		}
+	}
+}
+
+func TestHandleFrameIgnoresMalformedFrames(t *testing.T) {
+	h := NewHub()
+	c := addTestClient(h, "a", "phone")
+
+	for _, frame := range []string{`not json`, `{"type":"unknown"}`, `[]`} {
+		c.handleFrame(h, []byte(frame))
+	}
+	if len(c.Send) != 0 || len(c.requests) != 0 {
+		t.Errorf("malformed frames produced %d replies and %d queued requests", len(c.Send), len(c.requests))
+	}
+}
@@ -40,7 +40,7 @@ type GroupMessageNotification struct {
 }
 
 // StartSubscriber 启动 Redis 订阅者（统一使用 Stream 架构）
-// 私聊和群聊消息都写入用户的 stream:private:{user_id}，统一处理
+// 私聊和普通群聊消息写入用户的 stream:private:{user_id}，超大群消息写入 stream:group:{group_id}，通知统一处理
 func StartSubscriber(hub *Hub) {
 	// 加载配置以连接 Redis
 	cfg, err := config.LoadConfig()
@@ -54,6 +54,9 @@ func StartSubscriber(hub *Hub) {
 		DB:       cfg.Database.Redis.DB,
 	})
 
+	// 输入状态和在线状态使用同一 Redis 实例
+	hub.setRedis(rdb)
+
 	// 启动消息通知订阅（私聊和群聊统一通知）
 	go subscribePrivateMessages(hub, rdb)
 
@@ -61,7 +64,6 @@ func StartSubscriber(hub *Hub) {
 }
 
 // subscribePrivateMessages 订阅消息通知（私聊 + 群聊统一）
-// 现在私聊和群聊消息都写入用户的 stream:private:{user_id}
 // 通过 "type" 字段区分消息类型："private" 或 "group"
 func subscribePrivateMessages(hub *Hub, rdb *redis.Client) {
 	pubsub := rdb.Subscribe(context.Background(), "message_notifications")
@@ -79,8 +81,15 @@ func subscribePrivateMessages(hub *Hub, rdb *redis.Client) {
 			continue
 		}
 
-		toUserID, ok := notification["to_user_id"].(string)
-		if !ok {
+		// 输入状态：一条通知携带所有接收者，只推送给连接在本实例上的用户
+		if msgType, _ := notification["type"].(string); msgType == "typing_start" || msgType == "typing_stop" {
+			hub.deliverTyping(notification)
+			continue
+		}
+
+		// 单个接收者使用 to_user_id；群消息等多接收者事件合并为一条通知，携带 to_user_ids
+		recipients := notificationRecipients(notification)
+		if len(recipients) == 0 {
 			log.Printf("Invalid to_user_id in notification")
 			continue
 		}
@@ -90,7 +99,8 @@ func subscribePrivateMessages(hub *Hub, rdb *redis.Client) {
 		// 构建推送消息（直接使用通知中的数据，无需查询数据库）
 		var pushMessage map[string]interface{}
 
-		if msgType == "group" {
+		switch msgType {
+		case "group":
 			// 群聊消息
 			pushMessage = map[string]interface{}{
 				"type":         "group",
@@ -98,9 +108,139 @@ func subscribePrivateMessages(hub *Hub, rdb *redis.Client) {
 				"group_id":     notification["group_id"],
 				"from_user_id": notification["from_user_id"],
 				"content":      notification["content"],
+				"msg_type":     notification["msg_type"],
+				"payload":      notification["payload"],
+				"reply_to":     notification["reply_to"],
 				"created_at":   notification["created_at"],
 			}
-		} else {
+			if mentionUserIDs, ok := notification["mention_user_ids"]; ok {
+				pushMessage["mention_user_ids"] = mentionUserIDs
+				pushMessage["mention_all"] = notification["mention_all"]
+			}
+			if expiresAt, ok := notification["expires_at"]; ok {
+				pushMessage["expires_at"] = expiresAt
+			}
+		case "mention":
+			// @ 提醒：被 @ 的成员额外收到，用于强提醒和会话列表的"有人@我"标记
+			pushMessage = map[string]interface{}{
+				"type":         "mention",
+				"id":           notification["msg_id"],
+				"group_id":     notification["group_id"],
+				"from_user_id": notification["from_user_id"],
+				"content":      notification["content"],
+				"mention_all":  notification["mention_all"],
+				"created_at":   notification["created_at"],
+			}
+		case "recall":
+			// 消息撤回事件：客户端将对应气泡替换为"消息已撤回"
+			pushMessage = map[string]interface{}{
+				"type":              "recall",
+				"id":                notification["msg_id"],
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"from_user_id":      notification["from_user_id"],
+				"recalled_at":       notification["recalled_at"],
+			}
+		case "edit":
+			// 消息编辑事件：携带最新内容，客户端据此替换气泡内容并显示"已编辑"
+			pushMessage = map[string]interface{}{
+				"type":              "edit",
+				"id":                notification["msg_id"],
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"from_user_id":      notification["from_user_id"],
+				"content":           notification["content"],
+				"is_edited":         true,
+				"edited_at":         notification["edited_at"],
+			}
+		case "reaction":
+			// 表情回应变更事件：只携带变更的表情及其最新人数
+			pushMessage = map[string]interface{}{
+				"type":              "reaction",
+				"id":                notification["msg_id"],
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"user_id":           notification["user_id"],
+				"emoji":             notification["emoji"],
+				"action":            notification["action"],
+				"count":             notification["count"],
+			}
+		case "scheduled":
+			// 定时消息发送结果：通知发送者定时消息已发出或发送失败
+			pushMessage = map[string]interface{}{
+				"type":              "scheduled",
+				"scheduled_id":      notification["scheduled_id"],
+				"status":            notification["status"],
+				"msg_id":            notification["msg_id"],
+				"error":             notification["error"],
+				"conversation_type": notification["conversation_type"],
+				"peer_id":           notification["peer_id"],
+			}
+		case "read_receipt":
+			// 私聊已读回执：对方已读到我发送的 msg_id 及之前的消息
+			pushMessage = map[string]interface{}{
+				"type":       "read_receipt",
+				"peer_id":    notification["peer_id"],
+				"id":         notification["msg_id"],
+				"created_at": notification["created_at"],
+				"read_at":    notification["read_at"],
+			}
+		case "delivered":
+			// 私聊送达回执：对方设备已收到我发送的 msg_id 及之前的消息
+			pushMessage = map[string]interface{}{
+				"type":         "delivered",
+				"peer_id":      notification["peer_id"],
+				"id":           notification["msg_id"],
+				"created_at":   notification["created_at"],
+				"delivered_at": notification["delivered_at"],
+			}
+		case "group_read":
+			// 群消息已读人数变化：发送给消息发送者，用于更新"N 人已读"
+			pushMessage = map[string]interface{}{
+				"type":         "group_read",
+				"id":           notification["msg_id"],
+				"group_id":     notification["group_id"],
+				"reader_id":    notification["reader_id"],
+				"read_count":   notification["read_count"],
+				"unread_count": notification["unread_count"],
+			}
+		case "message_pin":
+			// 会话内的消息置顶/取消置顶（action 为 pin 或 unpin），置顶时携带消息内容
+			pushMessage = map[string]interface{}{
+				"type":              "message_pin",
+				"action":            notification["action"],
+				"id":                notification["msg_id"],
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"peer_id":           notification["peer_id"],
+				"pinned_by":         notification["pinned_by"],
+				"from_user_id":      notification["from_user_id"],
+				"msg_type":          notification["msg_type"],
+				"content":           notification["content"],
+				"created_at":        notification["created_at"],
+				"pinned_at":         notification["pinned_at"],
+			}
+		case "expire":
+			// 定时销毁：消息到期已被删除，客户端移除对应气泡并刷新会话预览
+			pushMessage = map[string]interface{}{
+				"type":              "expire",
+				"id":                notification["msg_id"],
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"peer_id":           notification["peer_id"],
+			}
+		case "conversation_ttl":
+			// 会话的定时销毁设置变更
+			pushMessage = map[string]interface{}{
+				"type":              "conversation_ttl",
+				"conversation_type": notification["conversation_type"],
+				"group_id":          notification["group_id"],
+				"peer_id":           notification["peer_id"],
+				"ttl_seconds":       notification["ttl_seconds"],
+				"updated_by":        notification["updated_by"],
+				"updated_at":        notification["updated_at"],
+			}
+		default:
 			// 私聊消息（默认）
 			pushMessage = map[string]interface{}{
 				"type":         "private",
@@ -108,20 +248,71 @@ func subscribePrivateMessages(hub *Hub, rdb *redis.Client) {
 				"from_user_id": notification["from_user_id"],
 				"to_user_id":   notification["to_user_id"],
 				"content":      notification["content"],
+				"msg_type":     notification["msg_type"],
+				"payload":      notification["payload"],
+				"reply_to":     notification["reply_to"],
 				"created_at":   notification["created_at"],
 			}
+			if expiresAt, ok := notification["expires_at"]; ok {
+				pushMessage["expires_at"] = expiresAt
+			}
 		}
 
-		messageJSON, err := json.Marshal(pushMessage)
-		if err != nil {
-			log.Printf("Failed to marshal push message: %v", err)
-			continue
+		// 推送给目标用户的所有设备；发送者自己的其他设备也在接收者中（多设备同步），跳过发送消息的设备
+		except := notificationOrigin(notification)
+		var delivered int
+		if pushMessage["type"] == "private" || pushMessage["type"] == "group" {
+			// 消息推送携带接收者 Stream 中的ID并跟踪送达，客户端未确认时重发
+			delivered = hub.SendTrackedToUsers(recipients, pushMessage, notificationStreamIDs(notification), except)
+		} else {
+			messageJSON, err := json.Marshal(pushMessage)
+			if err != nil {
+				log.Printf("Failed to marshal push message: %v", err)
+				continue
+			}
+			delivered = hub.SendMessageToUsers(recipients, messageJSON, except)
 		}
+		log.Printf("✅ Message pushed to %d/%d users via WebSocket", delivered, len(recipients))
+	}
+}
+
+// notificationRecipients 返回通知的接收者：to_user_ids 优先，否则为 to_user_id
+func notificationRecipients(notification map[string]interface{}) []string {
+	if ids, ok := notification["to_user_ids"].([]interface{}); ok {
+		recipients := make([]string, 0, len(ids))
+		for _, id := range ids {
+			if userID, ok := id.(string); ok && userID != "" {
+				recipients = append(recipients, userID)
+			}
+		}
+		return recipients
+	}
+	if userID, ok := notification["to_user_id"].(string); ok {
+		return []string{userID}
+	}
+	return nil
+}
+
+// notificationStreamIDs 返回消息在各接收者 Stream 中的ID（key 为用户ID，读扩散群为 stream.StreamIDsAll）
+func notificationStreamIDs(notification map[string]interface{}) map[string]string {
+	raw, _ := notification["stream_ids"].(map[string]interface{})
+	streamIDs := make(map[string]string, len(raw))
+	for key, v := range raw {
+		if id, ok := v.(string); ok {
+			streamIDs[key] = id
+		}
+	}
+	return streamIDs
+}
 
-		// 推送给目标用户
-		hub.SendMessageToUser(toUserID, messageJSON)
-		log.Printf("✅ Message pushed to user %s via WebSocket", toUserID)
+// notificationOrigin 返回发起操作的设备（from_user_id + from_device_id），通知未携带设备ID时为零值
+func notificationOrigin(notification map[string]interface{}) Device {
+	deviceID, _ := notification["from_device_id"].(string)
+	if deviceID == "" {
+		return Device{}
 	}
+	userID, _ := notification["from_user_id"].(string)
+	return Device{UserID: userID, DeviceID: deviceID}
 }
 
 // 已移除 fetchMessageFromDB 和 fetchGroupMessageFromDB 函数
@@ -0,0 +1,260 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	groupPb "ChatIM/api/proto/group"
+	"ChatIM/pkg/clients"
+	"ChatIM/pkg/stream"
+)
+
+const (
+	// typingThrottle 同一会话内 typing_start 的最小转发间隔，客户端每次按键都可以发送，由服务端限流
+	typingThrottle = 3 * time.Second
+	// typingExpire 正在输入状态的有效期，接收方超过该时间未收到新的 typing_start 即自动清除提示
+	typingExpire = 6 * time.Second
+	// typingMaxConversations 单个连接同时处于输入状态的会话数上限
+	typingMaxConversations = 20
+	// typingRelayTimeout 转发输入状态时访问 Redis 的超时时间
+	typingRelayTimeout = time.Second
+	// typingMembersTimeout 群成员缓存未命中时从 GroupService 加载群成员的超时时间
+	typingMembersTimeout = 3 * time.Second
+	// groupMembersPageSize 从 GroupService 分页加载群成员的每页数量（GetGroupMembers 的上限）
+	groupMembersPageSize = 100
+)
+
+// inboundFrame 输入状态帧（旧格式的 typing_start / typing_stop 帧，以及 typing.* 请求帧的 data）
+type inboundFrame struct {
+	Type             string `json:"type"`
+	ConversationType string `json:"conversation_type"`
+	PeerID           string `json:"peer_id"`
+	GroupID          string `json:"group_id"`
+}
+
+// typingState 连接在某个会话中的输入状态（只保存在连接的 readPump 中，不落地）
+type typingState struct {
+	conversationType string
+	targetID         string
+	startedAt        time.Time
+}
+
+// typingTracker 记录单个连接各会话的输入状态，用于限流和断开时补发 typing_stop
+// 只在连接自己的 readPump goroutine 中使用，无需加锁
+type typingTracker struct {
+	active map[string]*typingState
+}
+
+func newTypingTracker() *typingTracker {
+	return &typingTracker{active: make(map[string]*typingState)}
+}
+
+// handleTyping 按会话限流后转发输入状态
+// typing_start 在 typingThrottle 内只转发一次；typing_stop 只在对方仍显示输入提示时转发
+func (h *Hub) handleTyping(c *Client, frame inboundFrame) {
+	var targetID string
+	switch frame.ConversationType {
+	case "private":
+		targetID = frame.PeerID
+		if targetID == "" || targetID == c.UserID {
+			return
+		}
+	case "group":
+		targetID = frame.GroupID
+		if targetID == "" {
+			return
+		}
+	default:
+		return
+	}
+
+	key := frame.ConversationType + ":" + targetID
+	now := time.Now()
+	state, ok := c.typing.active[key]
+	if ok && now.Sub(state.startedAt) >= typingExpire {
+		// 接收方已自动清除提示，视为不在输入
+		delete(c.typing.active, key)
+		ok = false
+	}
+
+	if frame.Type == "typing_stop" {
+		if !ok {
+			return
+		}
+		delete(c.typing.active, key)
+		h.relayTyping(c, state.conversationType, state.targetID, "typing_stop")
+		return
+	}
+
+	if ok && now.Sub(state.startedAt) < typingThrottle {
+		return
+	}
+	if !ok {
+		c.typing.prune(now)
+		if len(c.typing.active) >= typingMaxConversations {
+			return
+		}
+		state = &typingState{conversationType: frame.ConversationType, targetID: targetID}
+		c.typing.active[key] = state
+	}
+	state.startedAt = now
+	h.relayTyping(c, state.conversationType, state.targetID, "typing_start")
+}
+
+// stopTyping 连接断开时，为仍处于输入状态的会话补发 typing_stop
+func (h *Hub) stopTyping(c *Client) {
+	now := time.Now()
+	for key, state := range c.typing.active {
+		if now.Sub(state.startedAt) < typingExpire {
+			h.relayTyping(c, state.conversationType, state.targetID, "typing_stop")
+		}
+		delete(c.typing.active, key)
+	}
+}
+
+// prune 清除已过期的输入状态
+func (t *typingTracker) prune(now time.Time) {
+	for key, state := range t.active {
+		if now.Sub(state.startedAt) >= typingExpire {
+			delete(t.active, key)
+		}
+	}
+}
+
+// DialGroupService 连接 GroupService，群成员缓存未命中时用于加载群成员
+func (h *Hub) DialGroupService(addr string) error {
+	client, err := clients.NewGroupClient(addr)
+	if err != nil {
+		return err
+	}
+	h.groupClient = client
+	log.Printf("WebSocket hub connected to Group Service at: %s", addr)
+	return nil
+}
+
+// relayTyping 将输入状态发布到 NotificationChannel，由各网关实例推送给在线的接收者
+// 群聊优先使用 Redis 中的群成员缓存，未命中时与发送群消息一样加载群成员并写入缓存，输入提示不要求可靠送达
+func (h *Hub) relayTyping(c *Client, conversationType, targetID, eventType string) {
+	so := h.streamOperator()
+	if so == nil {
+		return
+	}
+
+	notification := map[string]interface{}{
+		"type":              eventType,
+		"conversation_type": conversationType,
+		"from_user_id":      c.UserID,
+	}
+	if eventType == "typing_start" {
+		notification["expires_in"] = int64(typingExpire.Seconds())
+	}
+
+	if conversationType == "private" {
+		notification["peer_id"] = c.UserID
+		notification["to_user_ids"] = []string{targetID}
+	} else {
+		members, err := h.typingGroupMembers(so, c, targetID)
+		if err != nil {
+			log.Printf("Failed to get members of group %s for typing: %v", targetID, err)
+			return
+		}
+
+		recipients := make([]string, 0, len(members))
+		isMember := false
+		for _, m := range members {
+			if m == c.UserID {
+				isMember = true
+				continue
+			}
+			recipients = append(recipients, m)
+		}
+		if !isMember || len(recipients) == 0 {
+			return
+		}
+		notification["group_id"] = targetID
+		notification["to_user_ids"] = recipients
+	}
+
+	payload, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), typingRelayTimeout)
+	defer cancel()
+	if err := h.rdb.Load().Publish(ctx, stream.NotificationChannel, payload).Err(); err != nil {
+		log.Printf("Failed to publish typing event from user %s: %v", c.UserID, err)
+	}
+}
+
+// typingGroupMembers 获取群成员：先读缓存，未命中时以连接用户的身份从 GroupService 分页加载全部成员并写入缓存
+// 用户不是群成员时 GroupService 返回 PermissionDenied
+func (h *Hub) typingGroupMembers(so *stream.StreamOperator, c *Client, groupID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(c.rpcContext(), typingMembersTimeout)
+	defer cancel()
+
+	members, hit, err := so.GetCachedGroupMembers(ctx, groupID)
+	if err != nil || hit {
+		return members, err
+	}
+	if h.groupClient == nil {
+		return nil, nil
+	}
+
+	members = nil
+	for offset := int64(0); ; offset += groupMembersPageSize {
+		res, err := h.groupClient.GetGroupMembers(ctx, &groupPb.GetGroupMembersRequest{
+			GroupId: groupID,
+			Limit:   groupMembersPageSize,
+			Offset:  offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range res.Members {
+			members = append(members, m.UserId)
+		}
+		if len(res.Members) < groupMembersPageSize {
+			break
+		}
+	}
+
+	// 只缓存完整的成员列表，与消息服务共用同一份缓存
+	if err := so.CacheGroupMembers(ctx, groupID, members); err != nil {
+		log.Printf("Failed to cache members of group %s: %v", groupID, err)
+	}
+	return members, nil
+}
+
+// deliverTyping 将输入状态推送给连接在本实例上的接收者（接收者的所有设备）
+// 与消息推送不同，发送通道已满时直接丢弃，不断开连接
+func (h *Hub) deliverTyping(notification map[string]interface{}) {
+	recipients, _ := notification["to_user_ids"].([]interface{})
+	delete(notification, "to_user_ids")
+
+	message, err := json.Marshal(notification)
+	if err != nil {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for _, r := range recipients {
+		userID, _ := r.(string)
+		for _, client := range h.clients[userID] {
+			select {
+			case client.Send <- message:
+			default:
+			}
+		}
+	}
+}
+
+// setRedis 设置用于转发输入状态和维护在线状态的 Redis 客户端
+func (h *Hub) setRedis(rdb *redis.Client) {
+	h.rdb.Store(rdb)
+}
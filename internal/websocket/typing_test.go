@@ -0,0 +1,117 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	groupPb "ChatIM/api/proto/group"
+	"ChatIM/pkg/stream"
+)
+
+// fakeGroupService 只实现 GetGroupMembers 的 GroupService 客户端，成员按 members 分页返回
+type fakeGroupService struct {
+	groupPb.GroupServiceClient
+	members map[string][]string
+	calls   int
+}
+
+func (f *fakeGroupService) GetGroupMembers(_ context.Context, req *groupPb.GetGroupMembersRequest, _ ...grpc.CallOption) (*groupPb.GetGroupMembersResponse, error) {
+	f.calls++
+	members, ok := f.members[req.GroupId]
+	if !ok {
+		return nil, status.Errorf(codes.PermissionDenied, "您不是群成员")
+	}
+	res := &groupPb.GetGroupMembersResponse{Total: int32(len(members))}
+	for i := req.Offset; i < req.Offset+req.Limit && i < int64(len(members)); i++ {
+		res.Members = append(res.Members, &groupPb.GroupMember{UserId: members[i]})
+	}
+	return res, nil
+}
+
+func TestRelayTypingGroupMembers(t *testing.T) {
+	ctx := context.Background()
+	h, rdb := newTestHub(t)
+	so := stream.NewStreamOperator(rdb)
+
+	large := []string{"a"}
+	for i := 1; i < groupMembersPageSize+20; i++ {
+		large = append(large, fmt.Sprintf("u%d", i))
+	}
+	groups := &fakeGroupService{members: map[string][]string{
+		"small": {"a", "b"},
+		"large": large,
+	}}
+	h.groupClient = groups
+
+	if err := so.CacheGroupMembers(ctx, "cached", []string{"a", "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := so.CacheGroupMembers(ctx, "empty", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sub := rdb.Subscribe(ctx, stream.NotificationChannel)
+	defer sub.Close()
+	if _, err := sub.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name           string
+		groupID        string
+		wantRecipients int
+		wantCalls      int // 累计的 GetGroupMembers 调用次数
+	}{
+		{name: "cache hit", groupID: "cached", wantRecipients: 1},
+		{name: "empty group sentinel", groupID: "empty"},
+		{name: "cache miss loads members", groupID: "small", wantRecipients: 1, wantCalls: 1},
+		{name: "loaded members are cached", groupID: "small", wantRecipients: 1, wantCalls: 1},
+		{name: "cache miss loads every page", groupID: "large", wantRecipients: len(large) - 1, wantCalls: 3},
+		{name: "not a member", groupID: "other", wantCalls: 4},
+	}
+
+	c := addTestClient(h, "a", "phone")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h.relayTyping(c, "group", tt.groupID, "typing_start")
+
+			var recipients []interface{}
+			select {
+			case msg := <-sub.Channel():
+				var notification map[string]interface{}
+				if err := json.Unmarshal([]byte(msg.Payload), &notification); err != nil {
+					t.Fatal(err)
+				}
+				if notification["group_id"] != tt.groupID || notification["from_user_id"] != "a" {
+					t.Errorf("notification = %v", notification)
+				}
+				recipients, _ = notification["to_user_ids"].([]interface{})
+			case <-time.After(100 * time.Millisecond):
+			}
+
+			if len(recipients) != tt.wantRecipients {
+				t.Errorf("recipients = %v, want %d", recipients, tt.wantRecipients)
+			}
+			for _, r := range recipients {
+				if r == "a" {
+					t.Errorf("unexpected recipient %v", r)
+				}
+			}
+			if groups.calls != tt.wantCalls {
+				t.Errorf("GetGroupMembers calls = %d, want %d", groups.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	members, hit, err := so.GetCachedGroupMembers(ctx, "large")
+	if err != nil || !hit || len(members) != len(large) {
+		t.Errorf("cached members of large group = %d, %v, %v, want %d", len(members), hit, err, len(large))
+	}
+}
@@ -0,0 +1,27 @@
+-- migrations/005_message_recall.sql
+-- 消息撤回：为 messages / group_messages 表添加撤回标记
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'is_recalled'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `is_recalled` BOOLEAN DEFAULT FALSE COMMENT ''是否已撤回'', ADD COLUMN `recalled_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''撤回时间''',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'is_recalled'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `is_recalled` BOOLEAN DEFAULT FALSE COMMENT ''是否已撤回'', ADD COLUMN `recalled_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''撤回时间''',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('005_message_recall');
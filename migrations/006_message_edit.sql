@@ -0,0 +1,40 @@
+-- migrations/006_message_edit.sql
+-- 消息编辑：为 messages / group_messages 表添加编辑时间，并创建编辑历史表
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'edited_at'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `edited_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''最后编辑时间''',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'edited_at'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `edited_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''最后编辑时间''',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 消息编辑历史表（私聊和群聊共用）
+CREATE TABLE IF NOT EXISTS `message_edit_history` (
+  `id` BIGINT AUTO_INCREMENT PRIMARY KEY,
+  `message_id` VARCHAR(36) NOT NULL COMMENT '消息ID',
+  `conversation_type` ENUM('private', 'group') NOT NULL COMMENT '会话类型',
+  `editor_id` VARCHAR(36) NOT NULL COMMENT '编辑者ID',
+  `old_content` TEXT COMMENT '编辑前内容',
+  `new_content` TEXT COMMENT '编辑后内容',
+  `edited_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '编辑时间',
+  FOREIGN KEY (editor_id) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  INDEX idx_message_edited (message_id, edited_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息编辑历史表';
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('006_message_edit');
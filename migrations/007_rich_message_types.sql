@@ -0,0 +1,30 @@
+-- migrations/007_rich_message_types.sql
+-- 富消息类型：messages / group_messages 统一支持 msg_type + payload（图片、文件、语音、位置、名片）
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'msg_type'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `msg_type` VARCHAR(20) NOT NULL DEFAULT ''text'' COMMENT ''消息类型'' AFTER `content`, ADD COLUMN `payload` JSON NULL COMMENT ''非文本消息的结构化负载'' AFTER `msg_type`',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- group_messages.msg_type 原为 ENUM('text', 'image', 'file', 'notice')，改为 VARCHAR 以便扩展新的消息类型
+ALTER TABLE `group_messages` MODIFY COLUMN `msg_type` VARCHAR(20) NOT NULL DEFAULT 'text' COMMENT '消息类型';
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'payload'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `payload` JSON NULL COMMENT ''非文本消息的结构化负载'' AFTER `msg_type`',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('007_rich_message_types');
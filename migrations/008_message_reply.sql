@@ -0,0 +1,27 @@
+-- migrations/008_message_reply.sql
+-- 引用回复：记录被引用的消息ID（引用快照保存在 payload.reply 中）
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'reply_to_msg_id'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `reply_to_msg_id` VARCHAR(36) NULL DEFAULT NULL COMMENT ''引用的消息ID'', ADD INDEX `idx_reply_to` (`reply_to_msg_id`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'reply_to_msg_id'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `reply_to_msg_id` VARCHAR(36) NULL DEFAULT NULL COMMENT ''引用的消息ID'', ADD INDEX `idx_reply_to` (`reply_to_msg_id`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('008_message_reply');
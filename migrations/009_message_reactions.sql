@@ -0,0 +1,16 @@
+-- migrations/009_message_reactions.sql
+-- 表情回应：Redis 中按消息保存回应集合，此表作持久化备份
+
+CREATE TABLE IF NOT EXISTS `message_reactions` (
+  `message_id` VARCHAR(36) NOT NULL COMMENT '消息ID',
+  `conversation_type` ENUM('private', 'group') NOT NULL COMMENT '会话类型',
+  `user_id` VARCHAR(36) NOT NULL COMMENT '回应者ID',
+  `emoji` VARCHAR(32) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL COMMENT '表情（按二进制比较，避免不同表情被视为相同）',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '回应时间',
+  PRIMARY KEY (message_id, user_id, emoji),
+  FOREIGN KEY (user_id) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  INDEX idx_message (message_id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='消息表情回应表';
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('009_message_reactions');
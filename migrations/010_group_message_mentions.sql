@@ -0,0 +1,16 @@
+-- migrations/010_group_message_mentions.sql
+-- 群消息 @ 提及：记录被 @ 的成员以及是否 @所有人
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'mention_user_ids'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `mention_user_ids` JSON NULL COMMENT ''被 @ 的成员ID列表'', ADD COLUMN `mention_all` BOOLEAN DEFAULT FALSE COMMENT ''是否 @所有人''',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('010_group_message_mentions');
@@ -0,0 +1,27 @@
+-- migrations/011_client_msg_id.sql
+-- 发送去重：记录客户端生成的 client_msg_id，同一发送者的 client_msg_id 唯一
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'client_msg_id'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `client_msg_id` VARCHAR(64) NULL DEFAULT NULL COMMENT ''客户端消息ID（去重）'' AFTER `id`, ADD UNIQUE INDEX `uk_from_client_msg` (`from_user_id`, `client_msg_id`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'client_msg_id'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `client_msg_id` VARCHAR(64) NULL DEFAULT NULL COMMENT ''客户端消息ID（去重）'' AFTER `id`, ADD UNIQUE INDEX `uk_from_client_msg` (`from_user_id`, `client_msg_id`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('011_client_msg_id');
@@ -0,0 +1,16 @@
+-- migrations/012_message_history_index.sql
+-- 会话历史分页：Stream 被裁剪后按 (发送者, 接收者, 时间) 倒序查询私聊历史
+
+SET @idx_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND INDEX_NAME = 'idx_conversation_created'
+);
+SET @sql := IF(@idx_exists = 0,
+	'ALTER TABLE `messages` ADD INDEX `idx_conversation_created` (`from_user_id`, `to_user_id`, `created_at`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('012_message_history_index');
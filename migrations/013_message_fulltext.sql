@@ -0,0 +1,27 @@
+-- migrations/013_message_fulltext.sql
+-- 消息全文搜索：messages / group_messages 的 content 使用 ngram 分词的 FULLTEXT 索引（支持中文）
+
+SET @idx_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND INDEX_NAME = 'ft_content'
+);
+SET @sql := IF(@idx_exists = 0,
+	'ALTER TABLE `messages` ADD FULLTEXT INDEX `ft_content` (`content`) WITH PARSER ngram',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @idx_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.STATISTICS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND INDEX_NAME = 'ft_content'
+);
+SET @sql := IF(@idx_exists = 0,
+	'ALTER TABLE `group_messages` ADD FULLTEXT INDEX `ft_content` (`content`) WITH PARSER ngram',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('013_message_fulltext');
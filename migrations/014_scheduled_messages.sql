@@ -0,0 +1,30 @@
+-- migrations/014_scheduled_messages.sql
+-- 定时消息：到期前保存在此表（Redis 有序集合 scheduled:messages 作为到期索引），到期后按正常流程发送
+
+CREATE TABLE IF NOT EXISTS `scheduled_messages` (
+  `id` VARCHAR(36) PRIMARY KEY,
+  `client_msg_id` VARCHAR(64) NULL DEFAULT NULL COMMENT '客户端消息ID（去重）',
+  `from_user_id` VARCHAR(36) NOT NULL COMMENT '发送者ID',
+  `conversation_type` ENUM('private', 'group') NOT NULL COMMENT '会话类型',
+  `to_user_id` VARCHAR(36) NULL DEFAULT NULL COMMENT '接收者ID（私聊）',
+  `group_id` VARCHAR(36) NULL DEFAULT NULL COMMENT '群组ID（群聊）',
+  `content` TEXT COMMENT '消息内容',
+  `msg_type` VARCHAR(20) NOT NULL DEFAULT 'text' COMMENT '消息类型',
+  `payload` JSON NULL COMMENT '非文本消息的结构化负载',
+  `reply_to_msg_id` VARCHAR(36) NULL DEFAULT NULL COMMENT '引用的消息ID',
+  `mention_user_ids` JSON NULL COMMENT '被 @ 的成员ID列表',
+  `mention_all` BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否 @所有人',
+  `send_at` TIMESTAMP NOT NULL COMMENT '计划发送时间',
+  `status` ENUM('pending', 'sending', 'sent', 'canceled', 'failed') NOT NULL DEFAULT 'pending' COMMENT '状态',
+  `msg_id` VARCHAR(36) NULL DEFAULT NULL COMMENT '发送成功后的消息ID',
+  `error` VARCHAR(255) NULL DEFAULT NULL COMMENT '发送失败原因',
+  `created_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+  `updated_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  FOREIGN KEY (from_user_id) REFERENCES `users`(`id`) ON DELETE CASCADE,
+  UNIQUE INDEX uk_from_client_msg (from_user_id, client_msg_id),
+  INDEX idx_from_status_send_at (from_user_id, status, send_at),
+  INDEX idx_status_send_at (status, send_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='定时消息表';
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('014_scheduled_messages');
@@ -0,0 +1,27 @@
+-- migrations/015_disappearing_messages.sql
+-- 消息定时销毁：会话开启销毁时长后发送的消息记录过期时间，由后台清理任务到期删除
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'expires_at'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''过期时间（为空表示不过期）'', ADD INDEX `idx_expires_at` (`expires_at`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'expires_at'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `expires_at` TIMESTAMP NULL DEFAULT NULL COMMENT ''过期时间（为空表示不过期）'', ADD INDEX `idx_expires_at` (`expires_at`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('015_disappearing_messages');
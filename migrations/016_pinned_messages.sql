@@ -0,0 +1,19 @@
+-- migrations/016_pinned_messages.sql
+-- 会话内的置顶消息：私聊双方共享，群聊仅群主/管理员可置顶
+-- 与会话列表的置顶（Redis conversation:list 的 score）无关
+
+CREATE TABLE IF NOT EXISTS `pinned_messages` (
+  `msg_id` VARCHAR(36) PRIMARY KEY COMMENT '被置顶的消息ID',
+  `conversation_key` VARCHAR(100) NOT NULL COMMENT '会话标识：private:{较小的用户ID}:{较大的用户ID} 或 group:{group_id}',
+  `conversation_type` ENUM('private', 'group') NOT NULL COMMENT '会话类型',
+  `from_user_id` VARCHAR(36) NOT NULL COMMENT '消息发送者ID',
+  `msg_type` VARCHAR(20) NOT NULL DEFAULT 'text' COMMENT '消息类型',
+  `content` TEXT COMMENT '置顶时的消息内容（列表中以最新的编辑内容为准）',
+  `msg_created_at` TIMESTAMP NULL DEFAULT NULL COMMENT '消息发送时间',
+  `pinned_by` VARCHAR(36) NOT NULL COMMENT '置顶操作者ID',
+  `pinned_at` TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '置顶时间',
+  INDEX idx_conversation_pinned_at (conversation_key, pinned_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='置顶消息表';
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('016_pinned_messages');
@@ -0,0 +1,27 @@
+-- migrations/017_message_seq.sql
+-- 会话历史分页：created_at 只精确到秒，同一秒内的消息按自增的 seq（写入顺序）排序，翻页游标为 (created_at, seq)
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'messages' AND COLUMN_NAME = 'seq'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `messages` ADD COLUMN `seq` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT ''写入顺序（同一秒内的消息排序）'', ADD UNIQUE INDEX `uk_seq` (`seq`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+SET @col_exists := (
+	SELECT COUNT(1)
+	FROM INFORMATION_SCHEMA.COLUMNS
+	WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = 'group_messages' AND COLUMN_NAME = 'seq'
+);
+SET @sql := IF(@col_exists = 0,
+	'ALTER TABLE `group_messages` ADD COLUMN `seq` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT COMMENT ''写入顺序（同一秒内的消息排序）'', ADD UNIQUE INDEX `uk_seq` (`seq`)',
+	'SELECT 1'
+);
+PREPARE stmt FROM @sql; EXECUTE stmt; DEALLOCATE PREPARE stmt;
+
+-- 记录本次迁移
+INSERT IGNORE INTO `schema_migrations` (`version`) VALUES ('017_message_seq');
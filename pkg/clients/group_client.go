@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"log"
+
+	pb "ChatIM/api/proto/group"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// GroupClient 群组服务客户端，直接暴露 GroupServiceClient 的全部 RPC
+type GroupClient struct {
+	conn *grpc.ClientConn
+	pb.GroupServiceClient
+}
+
+// NewGroupClient 创建新的群组服务客户端
+func NewGroupClient(addr string) (*GroupClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("Failed to connect to group service: %v", err)
+		return nil, err
+	}
+
+	return &GroupClient{
+		conn:               conn,
+		GroupServiceClient: pb.NewGroupServiceClient(conn),
+	}, nil
+}
+
+// Close 关闭连接
+func (gc *GroupClient) Close() error {
+	return gc.conn.Close()
+}
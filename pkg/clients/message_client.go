@@ -0,0 +1,35 @@
+package clients
+
+import (
+	"log"
+
+	pb "ChatIM/api/proto/message"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// MessageClient 消息服务客户端，直接暴露 MessageServiceClient 的全部 RPC
+type MessageClient struct {
+	conn *grpc.ClientConn
+	pb.MessageServiceClient
+}
+
+// NewMessageClient 创建新的消息服务客户端
+func NewMessageClient(addr string) (*MessageClient, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		log.Printf("Failed to connect to message service: %v", err)
+		return nil, err
+	}
+
+	return &MessageClient{
+		conn:                 conn,
+		MessageServiceClient: pb.NewMessageServiceClient(conn),
+	}, nil
+}
+
+// Close 关闭连接
+func (mc *MessageClient) Close() error {
+	return mc.conn.Close()
+}
@@ -14,6 +14,7 @@ type Config struct {
 	JWT      JWTConfig      `mapstructure:"jwt"`
 	OSS      OSSConfig      `mapstructure:"oss"`
 	Log      LogConfig      `mapstructure:"log"`
+	Message  MessageConfig  `mapstructure:"message"`
 }
 
 type ServerConfig struct {
@@ -26,6 +27,8 @@ type ServerConfig struct {
 	MessageGRPCAddr    string `mapstructure:"message_grpc_addr"`    // 新增：Message Service 地址（用于 API Gateway 连接）
 	GroupGRPCAddr      string `mapstructure:"group_grpc_addr"`      // 新增：Group Service 地址（用于 API Gateway 连接）
 	FriendshipGRPCAddr string `mapstructure:"friendship_grpc_addr"` // 新增：Friendship Service 地址
+	APIMetricsPort     string `mapstructure:"api_metrics_port"`     // API Gateway 的 Prometheus 指标端口，默认 :9090
+	MessageMetricsPort string `mapstructure:"message_metrics_port"` // Message Service 的 Prometheus 指标端口，默认 :9092
 	CertFile           string `mapstructure:"cert_file"`            // SSL 证书文件路径
 	KeyFile            string `mapstructure:"key_file"`             // SSL 密钥文件路径
 }
@@ -62,6 +65,38 @@ type LogConfig struct {
 	DevMode    bool   `mapstructure:"dev_mode"`    // 开发模式
 }
 
+type MessageConfig struct {
+	RecallWindowSeconds int `mapstructure:"recall_window_seconds"` // 消息撤回时限（秒），0 表示使用默认值 120
+	DedupWindowSeconds  int `mapstructure:"dedup_window_seconds"`  // client_msg_id 去重窗口（秒），0 表示使用默认值 3600
+
+	GroupFanoutThreshold int `mapstructure:"group_fanout_threshold"` // 群成员数超过该值时改为读扩散（只写 stream:group:{group_id}），0 表示使用默认值 500
+
+	Persister PersisterConfig `mapstructure:"persister"` // 消息异步落库
+	Retention RetentionConfig `mapstructure:"retention"` // 用户消息流和读扩散群消息流保留策略
+}
+
+// PersisterConfig 消息落库 Worker 配置，0 / 空值表示使用默认值
+type PersisterConfig struct {
+	Consumer         string `mapstructure:"consumer"`           // 消费者名称，默认 主机名-进程号
+	BatchSize        int    `mapstructure:"batch_size"`         // 每批最多写入的消息数，默认 100
+	BlockMs          int    `mapstructure:"block_ms"`           // 队列为空时每次阻塞等待的时间（毫秒），默认 2000
+	MaxRetries       int    `mapstructure:"max_retries"`        // 单批写入失败的重试次数，默认 3
+	ClaimIdleSeconds int    `mapstructure:"claim_idle_seconds"` // 未确认的消息超过该时间后重新认领处理（秒），默认 30
+}
+
+// RetentionConfig stream:private:{user_id} 和 stream:group:{group_id} 的定期裁剪配置
+// 条数与 MaxAgeHours 同时配置时取裁剪得更多的一个；MaxAgeHours 为 0 或超过 168 时按 168 小时（消息状态在 Redis 中的保留时长）处理
+type RetentionConfig struct {
+	Enabled             bool  `mapstructure:"enabled"`               // 是否启用定期裁剪
+	IntervalSeconds     int   `mapstructure:"interval_seconds"`      // 裁剪周期（秒），默认 300
+	MaxEntries          int64 `mapstructure:"max_entries"`           // 每个用户流最多保留的条目数
+	GroupMaxEntries     int64 `mapstructure:"group_max_entries"`     // 每个读扩散群消息流最多保留的条目数，默认 5000
+	MaxAgeHours         int   `mapstructure:"max_age_hours"`         // 条目最长保留时间（小时），最大 168
+	KeepUntilPersisted  bool  `mapstructure:"keep_until_persisted"`  // 只裁剪已落库的条目（不越过落库水位）
+	SafetyMarginSeconds int   `mapstructure:"safety_margin_seconds"` // 落库水位的安全余量（秒），默认 60
+	ScanCount           int64 `mapstructure:"scan_count"`            // 每次 SCAN 的 COUNT，默认 500
+}
+
 // LoadConfig 加载配置文件
 func LoadConfig() (*Config, error) {
 	viper.SetConfigName("config")
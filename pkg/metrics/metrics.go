@@ -127,6 +127,51 @@ var (
 	)
 )
 
+// Stream 保留（裁剪）指标
+var (
+	// 裁剪删除的 Stream 条目总数
+	StreamEntriesTrimmedTotal = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "chatim_stream_entries_trimmed_total",
+			Help: "Total number of entries trimmed from user streams by the retention job",
+		},
+	)
+
+	// 裁剪任务执行次数
+	StreamRetentionRunsTotal = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "chatim_stream_retention_runs_total",
+			Help: "Total number of stream retention runs",
+		},
+		[]string{"status"}, // status: success/failed/skipped
+	)
+
+	// 裁剪任务耗时
+	StreamRetentionRunDuration = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "chatim_stream_retention_run_duration_seconds",
+			Help:    "Stream retention run duration in seconds",
+			Buckets: []float64{.01, .05, .1, .5, 1, 5, 10, 30, 60, 300},
+		},
+	)
+
+	// 本次裁剪扫描的用户流数量
+	StreamRetentionStreamsScanned = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "chatim_stream_retention_streams_scanned",
+			Help: "Number of user streams scanned by the last retention run",
+		},
+	)
+
+	// 落库水位落后当前时间的秒数（水位之前的条目才允许裁剪）
+	PersistWatermarkLagSeconds = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "chatim_persist_watermark_lag_seconds",
+			Help: "Seconds between now and the persistence watermark",
+		},
+	)
+)
+
 // WebSocket 指标
 var (
 	// WebSocket 活跃连接数
@@ -52,9 +52,12 @@ func (c *OSSClient) GenerateUploadSignature(fileType string, maxSize int64) (*Up
 
 	// 生成上传路径
 	var dir string
-	if fileType == "image" {
+	switch fileType {
+	case "image":
 		dir = fmt.Sprintf("chatim/images/%s/%s/", now.Format("2006"), now.Format("01"))
-	} else {
+	case "voice":
+		dir = fmt.Sprintf("chatim/voices/%s/%s/", now.Format("2006"), now.Format("01"))
+	default:
 		dir = fmt.Sprintf("chatim/files/%s/%s/", now.Format("2006"), now.Format("01"))
 	}
 
@@ -0,0 +1,170 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"ChatIM/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// 对比群消息的两种投递方式（均包含写入、会话列表更新和通知发布）：
+//   - write_fanout: 每个成员一次 XADD、一次 UpdateConversationTime、一次 PUBLISH
+//   - read_fanout:  一次 XADD 写入 stream:group:{group_id}，批量更新会话列表，一条携带所有接收者的 PUBLISH
+//
+// *_pipelined 为携带 Delivery 的实现：Stream 写入、会话列表更新和通知在一个 pipeline 中提交。
+// rtt=0 时 pipelined 反而更慢：CPU profile 中约 2/3 的时间在 miniredis 的 EVAL（每次执行脚本都新建 gopher-lua 解释器并注册模块），
+// 真实 Redis 缓存已编译的脚本，没有这部分开销；rtt=200µs 模拟跨主机部署，往返次数决定耗时。
+//
+// go test -run '^$' -bench BenchmarkGroupDelivery ./pkg/stream/
+
+func newBenchOperator(b *testing.B) (*StreamOperator, *redis.Client) {
+	return newBenchOperatorWithRTT(b, 0)
+}
+
+// newBenchOperatorWithRTT 连接到 miniredis，rtt 大于 0 时每次写入前等待 rtt，模拟与 Redis 之间的网络往返
+// pipeline 中的命令由一次写入发出，只等待一次
+func newBenchOperatorWithRTT(b *testing.B, rtt time.Duration) (*StreamOperator, *redis.Client) {
+	b.Helper()
+	logger.Logger = zap.NewNop()
+
+	mr := miniredis.RunT(b)
+	rdb := redis.NewClient(&redis.Options{
+		Addr: mr.Addr(),
+		Dialer: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := (&net.Dialer{}).DialContext(ctx, network, addr)
+			if err != nil || rtt <= 0 {
+				return conn, err
+			}
+			return &latencyConn{Conn: conn, rtt: rtt}, nil
+		},
+	})
+	b.Cleanup(func() { rdb.Close() })
+	return NewStreamOperator(rdb), rdb
+}
+
+type latencyConn struct {
+	net.Conn
+	rtt time.Duration
+}
+
+func (c *latencyConn) Write(p []byte) (int, error) {
+	time.Sleep(c.rtt)
+	return c.Conn.Write(p)
+}
+
+func benchMembers(n int) []string {
+	members := make([]string, n)
+	for i := range members {
+		members[i] = fmt.Sprintf("user-%d", i)
+	}
+	return members
+}
+
+// benchGroupDelivery 群消息附带的会话列表更新和一条携带所有接收者的通知
+func benchGroupDelivery(msgID, groupID string, members []string) *Delivery {
+	touches := make([]ConversationTouch, len(members))
+	for i, memberID := range members {
+		touches[i] = ConversationTouch{UserID: memberID, ConversationID: "group:" + groupID}
+	}
+	notification, _ := json.Marshal(map[string]interface{}{
+		"msg_id":      msgID,
+		"to_user_ids": members[1:],
+		"group_id":    groupID,
+		"type":        "group",
+		"content":     "hello",
+	})
+	return &Delivery{Conversations: touches, Notification: notification}
+}
+
+func BenchmarkGroupDelivery(b *testing.B) {
+	ctx := context.Background()
+
+	for _, rtt := range []time.Duration{0, 200 * time.Microsecond} {
+		for _, size := range []int{50, 500, 2000} {
+			members := benchMembers(size)
+			groupID := fmt.Sprintf("group-%d", size)
+			conversationID := "group:" + groupID
+
+			b.Run(fmt.Sprintf("write_fanout/members=%d/rtt=%s", size, rtt), func(b *testing.B) {
+				so, rdb := newBenchOperatorWithRTT(b, rtt)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					msgID := fmt.Sprintf("msg-%d", i)
+					for _, memberID := range members {
+						values := map[string]interface{}{"id": msgID, "group_id": groupID, "from_user_id": members[0], "content": "hello", "type": "group"}
+						if err := rdb.XAdd(ctx, &redis.XAddArgs{Stream: "stream:private:" + memberID, Values: values}).Err(); err != nil {
+							b.Fatal(err)
+						}
+					}
+					for _, memberID := range members {
+						so.UpdateConversationTime(ctx, memberID, conversationID)
+					}
+					for _, memberID := range members[1:] {
+						notification, _ := json.Marshal(map[string]interface{}{
+							"msg_id":     msgID,
+							"to_user_id": memberID,
+							"group_id":   groupID,
+							"type":       "group",
+							"content":    "hello",
+						})
+						rdb.Publish(ctx, NotificationChannel, notification)
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("read_fanout/members=%d/rtt=%s", size, rtt), func(b *testing.B) {
+				so, rdb := newBenchOperatorWithRTT(b, rtt)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					msgID := fmt.Sprintf("msg-%d", i)
+					if _, err := so.AddGroupMessage(ctx, msgID, groupID, members[0], "hello", "text", "", nil, 0, nil); err != nil {
+						b.Fatal(err)
+					}
+					if err := so.UpdateConversationTimes(ctx, members, conversationID); err != nil {
+						b.Fatal(err)
+					}
+					notification, _ := json.Marshal(map[string]interface{}{
+						"msg_id":      msgID,
+						"to_user_ids": members[1:],
+						"group_id":    groupID,
+						"type":        "group",
+						"content":     "hello",
+					})
+					rdb.Publish(ctx, NotificationChannel, notification)
+				}
+			})
+
+			b.Run(fmt.Sprintf("write_fanout_pipelined/members=%d/rtt=%s", size, rtt), func(b *testing.B) {
+				so, _ := newBenchOperatorWithRTT(b, rtt)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					msgID := fmt.Sprintf("msg-%d", i)
+					delivery := benchGroupDelivery(msgID, groupID, members)
+					if _, err := so.AddGroupMessageToMembers(ctx, msgID, groupID, members[0], "hello", "text", "", members, nil, 0, delivery); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+
+			b.Run(fmt.Sprintf("read_fanout_pipelined/members=%d/rtt=%s", size, rtt), func(b *testing.B) {
+				so, _ := newBenchOperatorWithRTT(b, rtt)
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					msgID := fmt.Sprintf("msg-%d", i)
+					delivery := benchGroupDelivery(msgID, groupID, members)
+					if _, err := so.AddGroupMessage(ctx, msgID, groupID, members[0], "hello", "text", "", nil, 0, delivery); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
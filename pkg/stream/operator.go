@@ -3,7 +3,11 @@ package stream
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"ChatIM/pkg/logger"
@@ -39,9 +43,140 @@ type MessagePayload struct {
 	ReadAt     int64  `json:"read_at"`
 }
 
+// NotificationChannel 消息通知的 Pub/Sub 频道，由 WebSocket 网关订阅后推送给在线用户
+const NotificationChannel = "message_notifications"
+
+// Delivery 一次发送中随消息写入一并提交的附带操作
+// Stream 写入、会话列表更新和通知发布放在同一个 pipeline 中，一次发送只需一次 Redis 往返
+type Delivery struct {
+	Conversations []ConversationTouch // 需要更新最新消息时间的会话列表项
+	Notification  []byte              // 发布到 NotificationChannel 的通知，为空时不发布
+}
+
+// streamWrite 本次发送写入的一个 Stream
+type streamWrite struct {
+	label  string                 // 通知中 stream_ids 的 key（用户ID，读扩散群消息流为 "*"）
+	key    string                 // Stream 键
+	values map[string]interface{} // 条目字段
+}
+
+// StreamIDsAll 读扩散群消息在通知 stream_ids 中的 key，所有成员共用同一个群消息流中的ID
+const StreamIDsAll = "*"
+
+// anyStream 表示至少一个 Stream 写入成功即发布通知（addAndPublish 的 required）
+const anyStream = -1
+
+// addAndPublishScript 写入本次发送的所有 Stream 并发布通知，通知中附带消息在各接收者 Stream 中的ID（stream_ids），供网关跟踪送达
+// 各 Stream 的写入互不影响（单个写入失败不会中断其他写入），stream_ids 直接使用 XADD 返回的ID；
+// 必须写入的 Stream 失败（或没有任何 Stream 写入成功）时不发布通知
+// KEYS 为写入的 Stream；ARGV[1] 为频道，ARGV[2] 为通知 JSON 对象（为空时不发布），
+// ARGV[3] 为必须写入成功的 Stream 序号（从 1 开始，0 表示至少一个），ARGV[3+i] 为 KEYS[i] 的 {"label": ..., "args": [XADD 参数]}
+// 返回 {各 Stream 的ID（失败为空）, 各 Stream 的错误（成功为空）, 是否已发布}
+var addAndPublishScript = redis.NewScript(`
+local ids, errors, streamIDs, written = {}, {}, {}, 0
+for i, key in ipairs(KEYS) do
+	local write = cjson.decode(ARGV[3 + i])
+	local res = redis.pcall('XADD', key, unpack(write.args))
+	if type(res) == 'table' and res.err then
+		ids[i], errors[i] = '', res.err
+	else
+		ids[i], errors[i] = res, ''
+		streamIDs[write.label] = res
+		written = written + 1
+	end
+end
+
+local required = tonumber(ARGV[3])
+local ok = written > 0 and (required == 0 or ids[required] ~= '')
+if ARGV[2] == '' or not ok then
+	return {ids, errors, 0}
+end
+-- 通知 JSON 原样拼接 stream_ids 字段，不经过 cjson 解码再编码（会改变数值和转义格式）
+local body = string.sub(ARGV[2], 1, -2)
+if body ~= '{' then
+	body = body .. ','
+end
+redis.call('PUBLISH', ARGV[1], body .. '"stream_ids":' .. cjson.encode(streamIDs) .. '}')
+return {ids, errors, 1}
+`)
+
+// streamWrites addAndPublishScript 的执行结果
+type streamWrites struct {
+	cmd *redis.Cmd
+}
+
+// addAndPublish 将 Stream 写入、会话列表更新和通知发布加入 pipeline
+// required 为必须写入成功才发布通知的 Stream 在 writes 中的下标，anyStream 表示至少一个写入成功即发布
+func (d *Delivery) addAndPublish(ctx context.Context, pipe redis.Pipeliner, writes []streamWrite, required int) (*streamWrites, []redis.Cmder) {
+	var deliveryCmds []redis.Cmder
+	var notification []byte
+	if d != nil {
+		if len(d.Conversations) > 0 {
+			keys, args := touchConversationsArgs(d.Conversations)
+			deliveryCmds = append(deliveryCmds, touchConversationsScript.Eval(ctx, pipe, keys, args...))
+		}
+		notification = d.Notification
+	}
+
+	keys := make([]string, len(writes))
+	args := make([]interface{}, 0, len(writes)+3)
+	args = append(args, NotificationChannel, string(notification), required+1)
+	for i, w := range writes {
+		keys[i] = w.key
+		// 参数统一转为字符串，避免数值经过 Lua 转换后格式变化
+		xaddArgs := make([]string, 0, 1+len(w.values)*2)
+		xaddArgs = append(xaddArgs, "*")
+		for field, value := range w.values {
+			xaddArgs = append(xaddArgs, field, fmt.Sprint(value))
+		}
+		data, _ := json.Marshal(map[string]interface{}{"label": w.label, "args": xaddArgs})
+		args = append(args, data)
+	}
+	return &streamWrites{cmd: addAndPublishScript.Eval(ctx, pipe, keys, args...)}, deliveryCmds
+}
+
+// result 返回第 i 个 Stream 写入的ID
+func (w *streamWrites) result(i int) (string, error) {
+	res, err := w.cmd.Slice()
+	if err != nil {
+		return "", err
+	}
+	if len(res) != 3 {
+		return "", fmt.Errorf("unexpected add message result: %v", res)
+	}
+	ids, _ := res[0].([]interface{})
+	errs, _ := res[1].([]interface{})
+	if i >= len(ids) || i >= len(errs) {
+		return "", fmt.Errorf("unexpected add message result: %v", res)
+	}
+	if msg, _ := errs[i].(string); msg != "" {
+		return "", errors.New(msg)
+	}
+	id, _ := ids[i].(string)
+	return id, nil
+}
+
+// logDeliveryErrors 附带操作失败不影响消息写入结果，只记录日志
+func logDeliveryErrors(msgID string, cmds []redis.Cmder) {
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			logger.Warn("Failed to apply message delivery command",
+				zap.String("msg_id", msgID),
+				zap.String("command", cmd.Name()),
+				zap.Error(err))
+		}
+	}
+}
+
 // AddPrivateMessage 添加私聊消息到 Stream（同时写入发送者和接收者的 stream）
-func (so *StreamOperator) AddPrivateMessage(ctx context.Context, msgID, fromUserID, toUserID, content string) (string, error) {
+// payload 为非文本消息的结构化负载（JSON），文本消息传空字符串
+// expiresAt 为消息过期时间（会话开启定时销毁时），大于 0 时写入的条目会登记到待销毁索引
+// delivery 为随消息一并提交的会话列表更新和通知，与 Stream 写入在同一个 pipeline 中执行，可为 nil
+// 返回消息在发送者 Stream 中的ID（发送者 Stream 写入失败时为空）
+func (so *StreamOperator) AddPrivateMessage(ctx context.Context, msgID, fromUserID, toUserID, content, msgType, payload string, expiresAt int64, delivery *Delivery) (string, error) {
 	now := time.Now()
+	entries := make(map[string]string, 2)
+	pipe := so.rdb.Pipeline()
 
 	// 1. 写入发送者的 Stream（用于消息回显和多设备同步）
 	// 发送者看到的消息标记为已读
@@ -51,18 +186,46 @@ func (so *StreamOperator) AddPrivateMessage(ctx context.Context, msgID, fromUser
 		"to_user_id":   toUserID,
 		"content":      content,
 		"created_at":   now.Unix(),
-		"msg_type":     "text",
+		"msg_type":     msgType,
+		"payload":      payload,
 		"is_read":      "true", // 发送者自己的消息默认已读
 		"read_at":      fmt.Sprintf("%d", now.Unix()),
 		"type":         "private",
 	}
+	if expiresAt > 0 {
+		senderPayload["expires_at"] = expiresAt
+	}
 
 	fromStreamKey := fmt.Sprintf("stream:private:%s", fromUserID)
-	senderStreamID, err := so.rdb.XAdd(ctx, &redis.XAddArgs{
-		Stream: fromStreamKey,
-		Values: senderPayload,
-	}).Result()
+	writes := []streamWrite{{label: fromUserID, key: fromStreamKey, values: senderPayload}}
+
+	// 2. 写入接收者的 Stream（给自己发消息时只写一条）
+	toStreamKey := fmt.Sprintf("stream:private:%s", toUserID)
+	if fromUserID != toUserID {
+		receiverPayload := map[string]interface{}{
+			"id":           msgID,
+			"from_user_id": fromUserID,
+			"to_user_id":   toUserID,
+			"content":      content,
+			"created_at":   now.Unix(),
+			"msg_type":     msgType,
+			"payload":      payload,
+			"is_read":      "false",
+			"read_at":      "0",
+			"type":         "private", // 标识这是私聊消息
+		}
+		if expiresAt > 0 {
+			receiverPayload["expires_at"] = expiresAt
+		}
+		writes = append(writes, streamWrite{label: toUserID, key: toStreamKey, values: receiverPayload})
+	}
 
+	// 3. 会话列表更新和通知与 Stream 写入一起提交，接收者的 Stream 写入失败时不发布通知
+	adds, deliveryCmds := delivery.addAndPublish(ctx, pipe, writes, len(writes)-1)
+	pipe.Exec(ctx)
+	logDeliveryErrors(msgID, deliveryCmds)
+
+	senderStreamID, err := adds.result(0)
 	if err != nil {
 		// 如果是给自己发消息，这就是唯一的写入，必须报错
 		if fromUserID == toUserID {
@@ -73,43 +236,36 @@ func (so *StreamOperator) AddPrivateMessage(ctx context.Context, msgID, fromUser
 		logger.Warn("Failed to add private message to sender stream", zap.Error(err), zap.String("msg_id", msgID))
 	}
 
-	// 如果是给自己发消息，只写一条，直接返回
+	if senderStreamID != "" {
+		entries[fromStreamKey] = senderStreamID
+	}
+
 	if fromUserID == toUserID {
+		so.trackExpiringMessage(ctx, msgID, ConversationKey("private", fromUserID, toUserID), expiresAt, entries)
 		logger.Debug("Private message added to self stream", zap.String("msg_id", msgID), zap.String("stream_id", senderStreamID))
 		return senderStreamID, nil
 	}
 
-	// 2. 写入接收者的 Stream
-	receiverPayload := map[string]interface{}{
-		"id":           msgID,
-		"from_user_id": fromUserID,
-		"to_user_id":   toUserID,
-		"content":      content,
-		"created_at":   now.Unix(),
-		"msg_type":     "text",
-		"is_read":      "false",
-		"read_at":      "0",
-		"type":         "private", // 标识这是私聊消息
-	}
-
-	toStreamKey := fmt.Sprintf("stream:private:%s", toUserID)
-	msgStreamID, err := so.rdb.XAdd(ctx, &redis.XAddArgs{
-		Stream: toStreamKey,
-		Values: receiverPayload,
-	}).Result()
-
+	msgStreamID, err := adds.result(1)
 	if err != nil {
 		logger.Error("Error adding private message to receiver stream", zap.Error(err), zap.String("msg_id", msgID))
+		so.trackExpiringMessage(ctx, msgID, ConversationKey("private", fromUserID, toUserID), expiresAt, entries)
 		return "", err
 	}
+	entries[toStreamKey] = msgStreamID
+	so.trackExpiringMessage(ctx, msgID, ConversationKey("private", fromUserID, toUserID), expiresAt, entries)
 
 	logger.Debug("Private message added to both streams", zap.String("msg_id", msgID), zap.String("stream_id", msgStreamID))
-	return msgStreamID, nil
+	return senderStreamID, nil
 }
 
 // AddGroupMessageToMembers 添加群聊消息到所有成员的个人 Stream
 // 统一使用 stream:private:{user_id} 格式，群聊消息也写入成员个人流
-func (so *StreamOperator) AddGroupMessageToMembers(ctx context.Context, msgID, groupID, fromUserID, content, msgType string, memberIDs []string) error {
+// extra 为附加字段（如 @ 提及信息），原样写入每个成员的 Stream 条目，可为 nil
+// expiresAt 为消息过期时间（群开启定时销毁时），大于 0 时写入的条目会登记到待销毁索引
+// 所有成员的写入与 delivery 在同一个 pipeline 中执行
+// 返回消息在发送者 Stream 中的ID（发送者不在成员列表中时为空）
+func (so *StreamOperator) AddGroupMessageToMembers(ctx context.Context, msgID, groupID, fromUserID, content, msgType, msgPayload string, memberIDs []string, extra map[string]interface{}, expiresAt int64, delivery *Delivery) (string, error) {
 	now := time.Now()
 
 	payload := map[string]interface{}{
@@ -119,53 +275,91 @@ func (so *StreamOperator) AddGroupMessageToMembers(ctx context.Context, msgID, g
 		"content":      content,
 		"created_at":   now.Unix(),
 		"msg_type":     msgType,
+		"payload":      msgPayload,
 		"is_read":      "false",
 		"read_at":      "0",
 		"type":         "group", // 标识这是群聊消息
 	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	if expiresAt > 0 {
+		payload["expires_at"] = expiresAt
+	}
 
-	// 遍历所有群成员，写入各自的 stream:private:{user_id}
-	successCount := 0
-	for _, memberID := range memberIDs {
-		streamKey := fmt.Sprintf("stream:private:%s", memberID)
-
-		// 为发送者标记消息为已读（用于消息回显）
-		memberPayload := make(map[string]interface{})
-		for k, v := range payload {
-			memberPayload[k] = v
-		}
+	// 为发送者标记消息为已读（用于消息回显）
+	senderPayload := make(map[string]interface{}, len(payload))
+	for k, v := range payload {
+		senderPayload[k] = v
+	}
+	senderPayload["is_read"] = "true"
+	senderPayload["read_at"] = fmt.Sprintf("%d", now.Unix())
+
+	// 所有成员的 stream:private:{user_id} 在同一个 pipeline 中写入
+	pipe := so.rdb.Pipeline()
+	writes := make([]streamWrite, len(memberIDs))
+	for i, memberID := range memberIDs {
+		memberPayload := payload
 		if memberID == fromUserID {
-			memberPayload["is_read"] = "true"
-			memberPayload["read_at"] = fmt.Sprintf("%d", now.Unix())
+			memberPayload = senderPayload
 		}
+		writes[i] = streamWrite{label: memberID, key: fmt.Sprintf("stream:private:%s", memberID), values: memberPayload}
+	}
+	adds, deliveryCmds := delivery.addAndPublish(ctx, pipe, writes, anyStream)
+	pipe.Exec(ctx)
+	logDeliveryErrors(msgID, deliveryCmds)
 
-		_, err := so.rdb.XAdd(ctx, &redis.XAddArgs{
-			Stream: streamKey,
-			Values: memberPayload,
-		}).Result()
-
+	successCount := 0
+	var senderStreamID string
+	entries := make(map[string]string, len(memberIDs))
+	for i, memberID := range memberIDs {
+		streamID, err := adds.result(i)
 		if err != nil {
 			logger.Warn("Failed to add group message to member stream", zap.String("member_id", memberID), zap.Error(err))
 			continue
 		}
 
+		if memberID == fromUserID {
+			senderStreamID = streamID
+		}
+		entries[fmt.Sprintf("stream:private:%s", memberID)] = streamID
 		successCount++
 	}
+	so.trackExpiringMessage(ctx, msgID, ConversationKey("group", fromUserID, groupID), expiresAt, entries)
 
 	logger.Debug("Group message added to members' streams", zap.String("msg_id", msgID), zap.Int("success_count", successCount), zap.Int("total_members", len(memberIDs)-1))
 
 	if successCount == 0 {
-		return fmt.Errorf("failed to add message to any member stream")
+		return "", fmt.Errorf("failed to add message to any member stream")
 	}
 
-	return nil
+	return senderStreamID, nil
+}
+
+// ==================== 读扩散群消息 ====================
+
+const (
+	// readFanoutGroupsKey 使用读扩散的群组集合：集合中的群消息只写入 stream:group:{group_id}，
+	// 群从写扩散切换为读扩散后保持不变，切换前的消息仍留在成员个人流中
+	readFanoutGroupsKey = "group:fanout:read"
+	// groupMessageIndexPrefix 读扩散群消息ID -> 群组ID 的索引，用于撤回、编辑等按消息ID定位消息的操作
+	groupMessageIndexPrefix = "group:msg:"
+	// groupMessageIndexTTL 消息索引的有效期，过期后按消息ID定位时回退到数据库
+	groupMessageIndexTTL = 7 * 24 * time.Hour
+)
+
+// GroupStreamKey 返回读扩散群的消息流 stream:group:{group_id}
+func GroupStreamKey(groupID string) string {
+	return fmt.Sprintf("stream:group:%s", groupID)
 }
 
-// AddGroupMessage 保留原方法以兼容旧代码（可选）
-func (so *StreamOperator) AddGroupMessage(ctx context.Context, msgID, groupID, fromUserID, content, msgType string) (string, error) {
-	// 这个方法现在废弃，建议使用 AddGroupMessageToMembers
-	// 保留是为了不破坏现有代码
-	streamKey := fmt.Sprintf("stream:group:%s", groupID)
+// AddGroupMessage 将群聊消息写入群组自己的 stream:group:{group_id}（读扩散，只写一次）
+// 成员通过各自的 group:{group_id} 会话游标读取，超大群使用该方式避免逐个成员写入
+// extra 为附加字段（如 @ 提及信息），可为 nil；群消息流由 retention 任务按落库水位裁剪，写入时不裁剪
+// expiresAt 大于 0 时写入的条目会登记到待销毁索引；delivery 与写入在同一个 pipeline 中执行，可为 nil
+// 返回消息在群消息流中的ID
+func (so *StreamOperator) AddGroupMessage(ctx context.Context, msgID, groupID, fromUserID, content, msgType, msgPayload string, extra map[string]interface{}, expiresAt int64, delivery *Delivery) (string, error) {
+	streamKey := GroupStreamKey(groupID)
 	now := time.Now()
 
 	payload := map[string]interface{}{
@@ -175,24 +369,85 @@ func (so *StreamOperator) AddGroupMessage(ctx context.Context, msgID, groupID, f
 		"content":      content,
 		"created_at":   now.Unix(),
 		"msg_type":     msgType,
-		"is_read":      "false",
-		"read_at":      "0",
+		"payload":      msgPayload,
+		"type":         "group",
+	}
+	for k, v := range extra {
+		payload[k] = v
+	}
+	if expiresAt > 0 {
+		payload["expires_at"] = expiresAt
 	}
 
-	msgStreamID, err := so.rdb.XAdd(ctx, &redis.XAddArgs{
-		Stream: streamKey,
-		Values: payload,
-	}).Result()
-
+	// 写入群消息流、登记读扩散群、消息索引以及附带的会话列表更新和通知在同一个 pipeline 中完成
+	write := streamWrite{label: StreamIDsAll, key: streamKey, values: payload}
+	pipe := so.rdb.Pipeline()
+	register := pipe.SAdd(ctx, readFanoutGroupsKey, groupID)
+	index := pipe.Set(ctx, groupMessageIndexPrefix+msgID, groupID, groupMessageIndexTTL)
+	adds, deliveryCmds := delivery.addAndPublish(ctx, pipe, []streamWrite{write}, 0)
+	pipe.Exec(ctx)
+	logDeliveryErrors(msgID, deliveryCmds)
+
+	msgStreamID, err := adds.result(0)
+	if err == nil {
+		err = register.Err()
+	}
+	if err == nil {
+		err = index.Err()
+	}
 	if err != nil {
 		logger.Error("Error adding group message to stream", zap.Error(err), zap.String("msg_id", msgID))
 		return "", err
 	}
+	so.trackExpiringMessage(ctx, msgID, ConversationKey("group", fromUserID, groupID), expiresAt, map[string]string{streamKey: msgStreamID})
 
 	logger.Debug("Group message added to stream", zap.String("msg_id", msgID), zap.String("stream_id", msgStreamID))
 	return msgStreamID, nil
 }
 
+// IsReadFanoutGroup 判断群是否使用读扩散
+func (so *StreamOperator) IsReadFanoutGroup(ctx context.Context, groupID string) (bool, error) {
+	return so.rdb.SIsMember(ctx, readFanoutGroupsKey, groupID).Result()
+}
+
+// ListReadFanoutGroups 返回所有使用读扩散的群
+func (so *StreamOperator) ListReadFanoutGroups(ctx context.Context) ([]string, error) {
+	groups, err := so.rdb.SMembers(ctx, readFanoutGroupsKey).Result()
+	if err != nil {
+		logger.Error("Error listing read fan-out groups", zap.Error(err))
+		return nil, err
+	}
+	return groups, nil
+}
+
+// FindMessageInGroupStream 通过消息索引在读扩散群的消息流中查找消息（从最新往前查找 scanLimit 条）
+// 返回消息所在的群组ID；索引不存在（非读扩散群消息或已过期）或未找到时返回 nil
+func (so *StreamOperator) FindMessageInGroupStream(ctx context.Context, messageID string, scanLimit int64) (*redis.XMessage, string, error) {
+	groupID, err := so.rdb.Get(ctx, groupMessageIndexPrefix+messageID).Result()
+	if err == redis.Nil {
+		return nil, "", nil
+	}
+	if err != nil {
+		logger.Error("Error getting group message index", zap.Error(err), zap.String("message_id", messageID))
+		return nil, "", err
+	}
+
+	streamKey := GroupStreamKey(groupID)
+	messages, err := so.rdb.XRevRangeN(ctx, streamKey, "+", "-", scanLimit).Result()
+	if err != nil {
+		logger.Error("Error reading stream", zap.Error(err), zap.String("stream_key", streamKey))
+		return nil, "", err
+	}
+
+	for i := range messages {
+		if messages[i].Values["id"] == messageID {
+			return &messages[i], groupID, nil
+		}
+	}
+
+	return nil, "", nil
+}
+
 // ReadMessages 从 Stream 读取消息
 func (so *StreamOperator) ReadMessages(ctx context.Context, streamKey string, startID string, count int64) ([]map[string]string, error) {
 	if count <= 0 {
@@ -290,16 +545,16 @@ func (so *StreamOperator) TrimStream(ctx context.Context, streamKey string, maxL
 	return nil
 }
 
-// TrimStreamByMinID 按最小 ID 修剪 Stream
-func (so *StreamOperator) TrimStreamByMinID(ctx context.Context, streamKey string, minID string) error {
+// TrimStreamByMinID 按最小 ID 修剪 Stream，返回删除的条目数
+func (so *StreamOperator) TrimStreamByMinID(ctx context.Context, streamKey string, minID string) (int64, error) {
 	// 删除所有小于 minID 的消息
-	err := so.rdb.XTrimMinID(ctx, streamKey, minID).Err()
+	trimmed, err := so.rdb.XTrimMinID(ctx, streamKey, minID).Result()
 	if err != nil {
 		logger.Error("Error trimming stream by minID", zap.Error(err), zap.String("stream_key", streamKey))
-		return err
+		return 0, err
 	}
 
-	return nil
+	return trimmed, nil
 }
 
 // GetStreamLength 获取 Stream 长度
@@ -400,6 +655,98 @@ func (so *StreamOperator) GetUserLastOnlineTime(ctx context.Context, userID stri
 	return lastOnlineTime, nil
 }
 
+// GetUserLastSeen 获取用户最后在线时间（RecordUserOnlineTime 记录的时间），未记录时返回 0
+func (so *StreamOperator) GetUserLastSeen(ctx context.Context, userID string) (int64, error) {
+	onlineKey := fmt.Sprintf("user:last_online:%s", userID)
+
+	lastSeen, err := so.rdb.Get(ctx, onlineKey).Int64()
+	if err == redis.Nil {
+		return 0, nil
+	}
+	if err != nil {
+		logger.Error("Error getting user last seen time", zap.Error(err), zap.String("user_id", userID))
+		return 0, err
+	}
+	return lastSeen, nil
+}
+
+// ==================== 在线状态 ====================
+
+const (
+	// presenceKeyPrefix 用户各设备的在线状态（ZSet: member 为设备ID，score 为过期时间），由 WebSocket 心跳刷新
+	presenceKeyPrefix = "presence:"
+	// PresenceChannel 用户上线/下线事件的 Pub/Sub 频道，由用户服务同步到 users 表
+	PresenceChannel = "presence_events"
+)
+
+// PresenceEvent 用户上线/下线事件
+type PresenceEvent struct {
+	UserID     string `json:"user_id"`
+	Status     string `json:"status"`       // online / offline
+	LastSeenAt int64  `json:"last_seen_at"` // 事件发生时间（Unix 秒）
+}
+
+func presenceKey(userID string) string {
+	return presenceKeyPrefix + userID
+}
+
+// RefreshPresence 刷新设备的在线状态，ttl 内没有再次刷新视为离线
+// 返回 true 表示刷新前用户没有其他在线设备（即用户本次上线）
+func (so *StreamOperator) RefreshPresence(ctx context.Context, userID, deviceID string, ttl time.Duration) (bool, error) {
+	key := presenceKey(userID)
+	now := time.Now()
+
+	pipe := so.rdb.TxPipeline()
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(now.Unix(), 10))
+	added := pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.Add(ttl).Unix()), Member: deviceID})
+	count := pipe.ZCard(ctx, key)
+	pipe.Expire(ctx, key, ttl)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error refreshing presence", zap.Error(err), zap.String("user_id", userID), zap.String("device_id", deviceID))
+		return false, err
+	}
+	return added.Val() == 1 && count.Val() == 1, nil
+}
+
+// RemovePresence 移除设备的在线状态，返回 true 表示用户已没有在线设备（即用户本次下线）
+func (so *StreamOperator) RemovePresence(ctx context.Context, userID, deviceID string) (bool, error) {
+	key := presenceKey(userID)
+
+	pipe := so.rdb.TxPipeline()
+	pipe.ZRem(ctx, key, deviceID)
+	pipe.ZRemRangeByScore(ctx, key, "-inf", strconv.FormatInt(time.Now().Unix(), 10))
+	count := pipe.ZCard(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error removing presence", zap.Error(err), zap.String("user_id", userID), zap.String("device_id", deviceID))
+		return false, err
+	}
+	return count.Val() == 0, nil
+}
+
+// ClearPresence 移除用户所有设备的在线状态（注销时使用）
+func (so *StreamOperator) ClearPresence(ctx context.Context, userID string) error {
+	return so.rdb.Del(ctx, presenceKey(userID)).Err()
+}
+
+// IsUserOnline 判断用户是否有未过期的在线设备
+func (so *StreamOperator) IsUserOnline(ctx context.Context, userID string) (bool, error) {
+	count, err := so.rdb.ZCount(ctx, presenceKey(userID), "("+strconv.FormatInt(time.Now().Unix(), 10), "+inf").Result()
+	if err != nil {
+		logger.Error("Error checking presence", zap.Error(err), zap.String("user_id", userID))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PublishPresence 发布用户上线/下线事件
+func (so *StreamOperator) PublishPresence(ctx context.Context, event PresenceEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return so.rdb.Publish(ctx, PresenceChannel, payload).Err()
+}
+
 // CacheUserGroups 缓存用户所在的群列表
 func (so *StreamOperator) CacheUserGroups(ctx context.Context, userID string, groups []string) error {
 	cacheKey := fmt.Sprintf("user:groups:%s", userID)
@@ -537,6 +884,70 @@ func (so *StreamOperator) InvalidateGroupMemberCache(ctx context.Context, groupI
 	return nil
 }
 
+// CacheGroupJoinTimes 缓存群成员的入群时间（Unix 秒），有效期与群成员列表缓存相同
+func (so *StreamOperator) CacheGroupJoinTimes(ctx context.Context, groupID string, joinedAt map[string]int64) error {
+	cacheKey := fmt.Sprintf("group:joined:%s", groupID)
+
+	// 空群写入空标记，区分“缓存未命中”和“用户不是群成员”
+	if len(joinedAt) == 0 {
+		pipe := so.rdb.Pipeline()
+		pipe.HSet(ctx, cacheKey, emptyGroupSentinel, 0)
+		pipe.Expire(ctx, cacheKey, 1*time.Minute)
+		if _, err := pipe.Exec(ctx); err != nil {
+			logger.Error("Error caching empty group join times", zap.Error(err), zap.String("group_id", groupID))
+			return err
+		}
+		return nil
+	}
+
+	fields := make(map[string]interface{}, len(joinedAt))
+	for userID, joined := range joinedAt {
+		fields[userID] = joined
+	}
+	pipe := so.rdb.Pipeline()
+	pipe.HSet(ctx, cacheKey, fields)
+	pipe.Expire(ctx, cacheKey, 5*time.Minute)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error caching group join times", zap.Error(err), zap.String("group_id", groupID))
+		return err
+	}
+	return nil
+}
+
+// GetCachedJoinTimes 查询用户在 groupIDs 各群的缓存入群时间
+// joined 为用户所在的群及入群时间（Unix 秒），missing 为未命中缓存、需要从数据库加载的群
+func (so *StreamOperator) GetCachedJoinTimes(ctx context.Context, userID string, groupIDs []string) (map[string]int64, []string, error) {
+	joined := make(map[string]int64)
+	if len(groupIDs) == 0 {
+		return joined, nil, nil
+	}
+
+	pipe := so.rdb.Pipeline()
+	gets := make([]*redis.StringCmd, len(groupIDs))
+	exists := make([]*redis.IntCmd, len(groupIDs))
+	for i, groupID := range groupIDs {
+		cacheKey := fmt.Sprintf("group:joined:%s", groupID)
+		gets[i] = pipe.HGet(ctx, cacheKey, userID)
+		exists[i] = pipe.Exists(ctx, cacheKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		logger.Error("Error getting cached group join times", zap.Error(err), zap.String("user_id", userID))
+		return nil, nil, err
+	}
+
+	var missing []string
+	for i, groupID := range groupIDs {
+		if exists[i].Val() == 0 {
+			missing = append(missing, groupID)
+			continue
+		}
+		if t, err := gets[i].Int64(); err == nil {
+			joined[groupID] = t
+		}
+	}
+	return joined, missing, nil
+}
+
 // UpdatePrivateMessageAsRead 标记私聊消息为已读（在 Stream 中更新）
 func (so *StreamOperator) UpdatePrivateMessageAsRead(ctx context.Context, toUserID, messageID string) error {
 	streamKey := fmt.Sprintf("stream:private:%s", toUserID)
@@ -604,118 +1015,781 @@ func (so *StreamOperator) GetMessageReadStatus(ctx context.Context, messageID st
 	return result == "true", nil
 }
 
-// ==================== 会话列表管理 ====================
+// ==================== 消息状态（撤回 / 编辑 / 表情回应）的保留时长 ====================
 
-// UpdateConversationTime 更新会话的最新消息时间（收到消息时调用）
-func (so *StreamOperator) UpdateConversationTime(ctx context.Context, userID, conversationID string) error {
-	key := fmt.Sprintf("conversation:list:%s", userID)
-	score := float64(time.Now().UnixMilli())
+const (
+	// MaxStreamAge Stream 条目的最长保留时间，retention 任务的 max_age_hours 不能超过该值；
+	// 发送时间早于该时长的消息只能从数据库读取撤回、编辑和表情回应状态
+	MaxStreamAge = 7 * 24 * time.Hour
+	// messageStateTTL msg:recall / msg:edit / msg:reaction(s) 键从消息发送时间起的有效期，
+	// 比 MaxStreamAge 多一天，保证 Stream 中的条目被裁剪之前状态不会过期
+	messageStateTTL = MaxStreamAge + 24*time.Hour
+)
 
-	// 检查是否已置顶
-	currentScore := so.rdb.ZScore(ctx, key, conversationID).Val()
-	if currentScore > 10000000000000 {
-		// 已置顶，保持置顶状态，更新置顶内的时间
-		score = 10000000000000 + score
+// MessageStateExpired 判断发送于 createdAt（Unix 秒）的消息是否已超出 Redis 状态的保留范围
+func MessageStateExpired(createdAt int64) bool {
+	return createdAt > 0 && time.Since(time.Unix(createdAt, 0)) >= MaxStreamAge
+}
+
+// messageStateExpireAt 返回消息状态键的过期时间，同一条消息的所有状态键同时过期
+func messageStateExpireAt(createdAt int64) time.Time {
+	if createdAt <= 0 {
+		return time.Now().Add(messageStateTTL)
 	}
+	return time.Unix(createdAt, 0).Add(messageStateTTL)
+}
 
-	err := so.rdb.ZAdd(ctx, key, redis.Z{
-		Score:  score,
-		Member: conversationID,
-	}).Err()
+// ==================== 消息撤回 ====================
+
+// FindMessageInStream 在用户的 Stream 中按消息ID查找消息（从最新往前查找 scanLimit 条）
+func (so *StreamOperator) FindMessageInStream(ctx context.Context, userID, messageID string, scanLimit int64) (*redis.XMessage, error) {
+	streamKey := fmt.Sprintf("stream:private:%s", userID)
 
+	messages, err := so.rdb.XRevRangeN(ctx, streamKey, "+", "-", scanLimit).Result()
 	if err != nil {
-		logger.Error("Error updating conversation time", zap.Error(err), zap.String("user_id", userID), zap.String("conversation_id", conversationID))
-		return err
+		logger.Error("Error reading stream", zap.Error(err), zap.String("stream_key", streamKey))
+		return nil, err
+	}
+
+	for i := range messages {
+		if messages[i].Values["id"] == messageID {
+			return &messages[i], nil
+		}
 	}
 
-	// 设置过期时间（30天）
-	so.rdb.Expire(ctx, key, 30*24*time.Hour)
+	return nil, nil
+}
+
+// MarkMessageRecalled 标记消息为已撤回
+// Stream 中的数据不可修改，与已读状态一样使用 Hash 存储撤回标记，
+// 私聊和群聊消息在所有成员 Stream 中共用同一个消息ID，因此一次写入即可覆盖所有受影响的 Stream
+// createdAt 为消息的发送时间，撤回标记随消息状态一同过期
+func (so *StreamOperator) MarkMessageRecalled(ctx context.Context, messageID, recalledBy string, recalledAt, createdAt int64) error {
+	hashKey := fmt.Sprintf("msg:recall:%s", messageID)
+
+	pipe := so.rdb.TxPipeline()
+	pipe.HSet(ctx, hashKey, map[string]interface{}{
+		"recalled_by": recalledBy,
+		"recalled_at": recalledAt,
+	})
+	pipe.ExpireAt(ctx, hashKey, messageStateExpireAt(createdAt))
+	_, err := pipe.Exec(ctx)
+	if err != nil {
+		logger.Error("Error marking message as recalled", zap.Error(err), zap.String("message_id", messageID))
+		return err
+	}
 
+	logger.Debug("Marked message as recalled", zap.String("message_id", messageID))
 	return nil
 }
 
-// PinConversation 置顶会话
-func (so *StreamOperator) PinConversation(ctx context.Context, userID, conversationID string) error {
-	key := fmt.Sprintf("conversation:list:%s", userID)
+// IsMessageRecalled 判断消息是否已撤回
+func (so *StreamOperator) IsMessageRecalled(ctx context.Context, messageID string) (bool, error) {
+	hashKey := fmt.Sprintf("msg:recall:%s", messageID)
 
-	// 获取当前 score
-	currentScore := so.rdb.ZScore(ctx, key, conversationID).Val()
-	if currentScore == 0 {
-		// 会话不存在，使用当前时间
-		currentScore = float64(time.Now().UnixMilli())
+	n, err := so.rdb.Exists(ctx, hashKey).Result()
+	if err != nil {
+		logger.Error("Error checking message recall status", zap.Error(err), zap.String("message_id", messageID))
+		return false, err
 	}
 
-	// 如果已经置顶，不做处理
-	if currentScore > 10000000000000 {
-		logger.Debug("Conversation already pinned", zap.String("conversation_id", conversationID))
-		return nil
-	}
+	return n > 0, nil
+}
 
-	// 置顶：10^13 + 当前时间戳
-	pinnedScore := 10000000000000 + currentScore
+// GetRecalledMessages 批量查询消息是否已撤回，返回已撤回的消息ID集合
+func (so *StreamOperator) GetRecalledMessages(ctx context.Context, messageIDs []string) (map[string]bool, error) {
+	recalled := make(map[string]bool)
+	if len(messageIDs) == 0 {
+		return recalled, nil
+	}
 
-	err := so.rdb.ZAdd(ctx, key, redis.Z{
-		Score:  pinnedScore,
-		Member: conversationID,
-	}).Err()
+	pipe := so.rdb.Pipeline()
+	cmds := make([]*redis.IntCmd, len(messageIDs))
+	for i, id := range messageIDs {
+		cmds[i] = pipe.Exists(ctx, fmt.Sprintf("msg:recall:%s", id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error checking recalled messages", zap.Error(err))
+		return recalled, err
+	}
 
-	if err != nil {
-		logger.Error("Error pinning conversation", zap.Error(err), zap.String("user_id", userID), zap.String("conversation_id", conversationID))
-		return err
+	for i, cmd := range cmds {
+		if cmd.Val() > 0 {
+			recalled[messageIDs[i]] = true
+		}
 	}
 
-	logger.Info("Pinned conversation", zap.String("conversation_id", conversationID), zap.String("user_id", userID))
-	return nil
+	return recalled, nil
 }
 
-// UnpinConversation 取消置顶会话
-func (so *StreamOperator) UnpinConversation(ctx context.Context, userID, conversationID string) error {
-	key := fmt.Sprintf("conversation:list:%s", userID)
+// GetRecallTimes 批量获取消息的撤回时间，未撤回的消息不会出现在结果中
+func (so *StreamOperator) GetRecallTimes(ctx context.Context, messageIDs []string) (map[string]int64, error) {
+	recalled := make(map[string]int64)
+	if len(messageIDs) == 0 {
+		return recalled, nil
+	}
 
-	// 获取当前 score
-	currentScore := so.rdb.ZScore(ctx, key, conversationID).Val()
-	if currentScore == 0 {
-		return fmt.Errorf("conversation not found")
+	pipe := so.rdb.Pipeline()
+	cmds := make([]*redis.StringCmd, len(messageIDs))
+	for i, id := range messageIDs {
+		cmds[i] = pipe.HGet(ctx, fmt.Sprintf("msg:recall:%s", id), "recalled_at")
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		logger.Error("Error getting message recall times", zap.Error(err))
+		return recalled, err
 	}
 
-	// 如果未置顶，不做处理
-	if currentScore < 10000000000000 {
-		logger.Debug("Conversation is not pinned", zap.String("conversation_id", conversationID))
-		return nil
+	for i, cmd := range cmds {
+		if recalledAt, err := cmd.Int64(); err == nil {
+			recalled[messageIDs[i]] = recalledAt
+		}
 	}
 
-	// 还原到原始时间戳
-	originalScore := currentScore - 10000000000000
+	return recalled, nil
+}
 
-	err := so.rdb.ZAdd(ctx, key, redis.Z{
-		Score:  originalScore,
-		Member: conversationID,
-	}).Err()
+// ==================== 消息编辑 ====================
+
+// MessageEdit 消息的最新编辑内容
+type MessageEdit struct {
+	Content  string `json:"content"`
+	EditedBy string `json:"edited_by"`
+	EditedAt int64  `json:"edited_at"`
+}
 
+// SaveMessageEdit 保存消息的最新编辑内容
+// 与撤回标记一样，所有成员 Stream 中的同一条消息共用一个 Hash，并随消息状态一同过期（createdAt 为消息的发送时间）
+func (so *StreamOperator) SaveMessageEdit(ctx context.Context, messageID string, edit MessageEdit, createdAt int64) error {
+	hashKey := fmt.Sprintf("msg:edit:%s", messageID)
+
+	pipe := so.rdb.TxPipeline()
+	pipe.HSet(ctx, hashKey, map[string]interface{}{
+		"content":   edit.Content,
+		"edited_by": edit.EditedBy,
+		"edited_at": edit.EditedAt,
+	})
+	pipe.ExpireAt(ctx, hashKey, messageStateExpireAt(createdAt))
+	_, err := pipe.Exec(ctx)
 	if err != nil {
-		logger.Error("Error unpinning conversation", zap.Error(err), zap.String("user_id", userID), zap.String("conversation_id", conversationID))
+		logger.Error("Error saving message edit", zap.Error(err), zap.String("message_id", messageID))
 		return err
 	}
 
-	logger.Info("Unpinned conversation", zap.String("conversation_id", conversationID), zap.String("user_id", userID))
+	logger.Debug("Saved message edit", zap.String("message_id", messageID))
 	return nil
 }
 
-// GetConversationList 获取会话列表（按时间降序，置顶在前）
-func (so *StreamOperator) GetConversationList(ctx context.Context, userID string, offset, limit int64) ([]ConversationItem, error) {
-	key := fmt.Sprintf("conversation:list:%s", userID)
-
-	// ZREVRANGE：按 score 降序（置顶和最新的在前）
-	results, err := so.rdb.ZRevRangeWithScores(ctx, key, offset, offset+limit-1).Result()
-	if err != nil {
-		logger.Error("Error getting conversation list", zap.Error(err), zap.String("user_id", userID))
-		return nil, err
+// GetMessageEdits 批量获取消息的最新编辑内容，未编辑过的消息不会出现在结果中
+func (so *StreamOperator) GetMessageEdits(ctx context.Context, messageIDs []string) (map[string]MessageEdit, error) {
+	edits := make(map[string]MessageEdit)
+	if len(messageIDs) == 0 {
+		return edits, nil
 	}
 
-	var conversations []ConversationItem
-	for _, z := range results {
-		conversationID := z.Member.(string)
-		score := z.Score
+	pipe := so.rdb.Pipeline()
+	cmds := make([]*redis.MapStringStringCmd, len(messageIDs))
+	for i, id := range messageIDs {
+		cmds[i] = pipe.HGetAll(ctx, fmt.Sprintf("msg:edit:%s", id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error getting message edits", zap.Error(err))
+		return edits, err
+	}
+
+	for i, cmd := range cmds {
+		values := cmd.Val()
+		if len(values) == 0 {
+			continue
+		}
+		var editedAt int64
+		fmt.Sscanf(values["edited_at"], "%d", &editedAt)
+		edits[messageIDs[i]] = MessageEdit{
+			Content:  values["content"],
+			EditedBy: values["edited_by"],
+			EditedAt: editedAt,
+		}
+	}
+
+	return edits, nil
+}
+
+// ==================== 表情回应 ====================
+
+// ReactionSummary 消息上某个表情的回应汇总
+type ReactionSummary struct {
+	Emoji       string
+	Count       int64
+	ReactedByMe bool
+}
+
+// AddReaction 记录用户对消息的表情回应，返回是否为新增（重复回应返回 false）
+// msg:reactions:{id} 记录消息上出现过的表情，msg:reaction:{id}:{emoji} 记录回应该表情的用户，
+// 两者都随消息状态一同过期（createdAt 为消息的发送时间）
+func (so *StreamOperator) AddReaction(ctx context.Context, messageID, emoji, userID string, createdAt int64) (bool, error) {
+	userSetKey := fmt.Sprintf("msg:reaction:%s:%s", messageID, emoji)
+	emojiSetKey := fmt.Sprintf("msg:reactions:%s", messageID)
+	expireAt := messageStateExpireAt(createdAt)
+
+	pipe := so.rdb.TxPipeline()
+	added := pipe.SAdd(ctx, userSetKey, userID)
+	pipe.SAdd(ctx, emojiSetKey, emoji)
+	pipe.ExpireAt(ctx, userSetKey, expireAt)
+	pipe.ExpireAt(ctx, emojiSetKey, expireAt)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error adding reaction", zap.Error(err), zap.String("message_id", messageID))
+		return false, err
+	}
+
+	return added.Val() > 0, nil
+}
+
+// RemoveReaction 取消用户对消息的表情回应，返回是否确实移除了回应
+func (so *StreamOperator) RemoveReaction(ctx context.Context, messageID, emoji, userID string) (bool, error) {
+	userSetKey := fmt.Sprintf("msg:reaction:%s:%s", messageID, emoji)
+
+	removed, err := so.rdb.SRem(ctx, userSetKey, userID).Result()
+	if err != nil {
+		logger.Error("Error removing reaction", zap.Error(err), zap.String("message_id", messageID))
+		return false, err
+	}
+
+	// 该表情已无人回应时从表情列表中移除
+	count, err := so.rdb.SCard(ctx, userSetKey).Result()
+	if err == nil && count == 0 {
+		so.rdb.SRem(ctx, fmt.Sprintf("msg:reactions:%s", messageID), emoji)
+	}
+
+	return removed > 0, nil
+}
+
+// GetMessageReactions 批量获取消息的表情回应汇总（按回应人数降序），没有回应的消息不会出现在结果中
+func (so *StreamOperator) GetMessageReactions(ctx context.Context, messageIDs []string, userID string) (map[string][]ReactionSummary, error) {
+	reactions := make(map[string][]ReactionSummary)
+	if len(messageIDs) == 0 {
+		return reactions, nil
+	}
+
+	// 1. 获取每条消息上的表情列表
+	pipe := so.rdb.Pipeline()
+	emojiCmds := make([]*redis.StringSliceCmd, len(messageIDs))
+	for i, id := range messageIDs {
+		emojiCmds[i] = pipe.SMembers(ctx, fmt.Sprintf("msg:reactions:%s", id))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error getting message reaction emojis", zap.Error(err))
+		return reactions, err
+	}
+
+	// 2. 获取每个表情的回应人数以及当前用户是否回应
+	type reactionCmd struct {
+		messageID string
+		emoji     string
+		count     *redis.IntCmd
+		mine      *redis.BoolCmd
+	}
+	var cmds []reactionCmd
+	pipe = so.rdb.Pipeline()
+	for i, cmd := range emojiCmds {
+		for _, emoji := range cmd.Val() {
+			key := fmt.Sprintf("msg:reaction:%s:%s", messageIDs[i], emoji)
+			cmds = append(cmds, reactionCmd{
+				messageID: messageIDs[i],
+				emoji:     emoji,
+				count:     pipe.SCard(ctx, key),
+				mine:      pipe.SIsMember(ctx, key, userID),
+			})
+		}
+	}
+	if len(cmds) == 0 {
+		return reactions, nil
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error getting message reactions", zap.Error(err))
+		return reactions, err
+	}
+
+	for _, cmd := range cmds {
+		if cmd.count.Val() == 0 {
+			continue
+		}
+		reactions[cmd.messageID] = append(reactions[cmd.messageID], ReactionSummary{
+			Emoji:       cmd.emoji,
+			Count:       cmd.count.Val(),
+			ReactedByMe: cmd.mine.Val(),
+		})
+	}
+
+	for _, list := range reactions {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Count != list[j].Count {
+				return list[i].Count > list[j].Count
+			}
+			return list[i].Emoji < list[j].Emoji
+		})
+	}
+
+	return reactions, nil
+}
+
+// ==================== @ 提及 ====================
+
+// IncrMentionCount 为被 @ 的用户累加在群内被提及的次数
+// mention:user:{user_id} 是一个 Hash，field 为群组ID，value 为未读的提及次数
+func (so *StreamOperator) IncrMentionCount(ctx context.Context, groupID string, userIDs []string) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	pipe := so.rdb.Pipeline()
+	for _, userID := range userIDs {
+		pipe.HIncrBy(ctx, fmt.Sprintf("mention:user:%s", userID), groupID, 1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Error incrementing mention count", zap.Error(err), zap.String("group_id", groupID))
+		return err
+	}
+
+	return nil
+}
+
+// ClearMentionCount 用户读过群消息后清除该群的提及计数
+func (so *StreamOperator) ClearMentionCount(ctx context.Context, userID, groupID string) error {
+	err := so.rdb.HDel(ctx, fmt.Sprintf("mention:user:%s", userID), groupID).Err()
+	if err != nil {
+		logger.Error("Error clearing mention count", zap.Error(err), zap.String("user_id", userID), zap.String("group_id", groupID))
+		return err
+	}
+	return nil
+}
+
+// GetMentionCounts 获取用户在各个群中未读的提及次数（群组ID -> 次数）
+func (so *StreamOperator) GetMentionCounts(ctx context.Context, userID string) (map[string]int64, error) {
+	counts := make(map[string]int64)
+
+	values, err := so.rdb.HGetAll(ctx, fmt.Sprintf("mention:user:%s", userID)).Result()
+	if err != nil {
+		logger.Error("Error getting mention counts", zap.Error(err), zap.String("user_id", userID))
+		return counts, err
+	}
+
+	for groupID, v := range values {
+		var n int64
+		fmt.Sscanf(v, "%d", &n)
+		if n > 0 {
+			counts[groupID] = n
+		}
+	}
+
+	return counts, nil
+}
+
+// ==================== 发送去重 ====================
+
+// SentMessageRecord 按 client_msg_id 记录的已发送消息，重复请求按该记录返回首次发送的消息
+type SentMessageRecord struct {
+	MsgID          string   `json:"msg_id"`
+	StreamID       string   `json:"stream_id"` // 为空表示首次发送仍在处理中
+	CreatedAt      int64    `json:"created_at"`
+	ToUserID       string   `json:"to_user_id,omitempty"` // 私聊
+	GroupID        string   `json:"group_id,omitempty"`   // 群聊
+	Content        string   `json:"content"`
+	MsgType        string   `json:"msg_type"`
+	Payload        string   `json:"payload,omitempty"` // 与 Stream 中保存的负载 JSON 相同
+	MentionUserIDs []string `json:"mention_user_ids,omitempty"`
+	MentionAll     bool     `json:"mention_all,omitempty"`
+}
+
+// ReserveClientMsgID 为发送者的 client_msg_id 占位（SET NX + TTL）
+// 占位成功返回 nil；该 client_msg_id 在去重窗口内已发送过时返回原消息记录
+func (so *StreamOperator) ReserveClientMsgID(ctx context.Context, fromUserID, clientMsgID string, record SentMessageRecord, ttl time.Duration) (*SentMessageRecord, error) {
+	key := fmt.Sprintf("msg:dedup:%s:%s", fromUserID, clientMsgID)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := so.rdb.SetNX(ctx, key, data, ttl).Result()
+	if err != nil {
+		logger.Error("Error reserving client msg id", zap.Error(err), zap.String("client_msg_id", clientMsgID))
+		return nil, err
+	}
+	if ok {
+		return nil, nil
+	}
+
+	existing, err := so.rdb.Get(ctx, key).Result()
+	if err == redis.Nil {
+		// 占位恰好过期，按新消息处理
+		return nil, nil
+	}
+	if err != nil {
+		logger.Error("Error getting sent message record", zap.Error(err), zap.String("client_msg_id", clientMsgID))
+		return nil, err
+	}
+
+	var sent SentMessageRecord
+	if err := json.Unmarshal([]byte(existing), &sent); err != nil {
+		return nil, err
+	}
+	return &sent, nil
+}
+
+// CompleteClientMsgID 消息写入成功后补充 Stream ID（保留原有的过期时间）
+func (so *StreamOperator) CompleteClientMsgID(ctx context.Context, fromUserID, clientMsgID string, record SentMessageRecord) error {
+	key := fmt.Sprintf("msg:dedup:%s:%s", fromUserID, clientMsgID)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if err := so.rdb.SetArgs(ctx, key, data, redis.SetArgs{KeepTTL: true, Mode: "XX"}).Err(); err != nil && err != redis.Nil {
+		logger.Error("Error completing client msg id", zap.Error(err), zap.String("client_msg_id", clientMsgID))
+		return err
+	}
+	return nil
+}
+
+// ReleaseClientMsgID 消息写入失败时释放占位，允许客户端重试
+func (so *StreamOperator) ReleaseClientMsgID(ctx context.Context, fromUserID, clientMsgID string) error {
+	return so.rdb.Del(ctx, fmt.Sprintf("msg:dedup:%s:%s", fromUserID, clientMsgID)).Err()
+}
+
+// ==================== 定时消息 ====================
+
+// scheduledQueueKey 定时消息到期索引（member 为定时消息ID，score 为计划发送时间）
+const scheduledQueueKey = "scheduled:messages"
+
+// ScheduleMessage 将定时消息加入（或更新）到期索引
+func (so *StreamOperator) ScheduleMessage(ctx context.Context, scheduledID string, sendAt int64) error {
+	return so.rdb.ZAdd(ctx, scheduledQueueKey, redis.Z{
+		Score:  float64(sendAt),
+		Member: scheduledID,
+	}).Err()
+}
+
+// RestoreScheduledMessage 定时消息不在到期索引中时重新加入（已存在时保留原有的到期时间，例如重试延迟）
+func (so *StreamOperator) RestoreScheduledMessage(ctx context.Context, scheduledID string, sendAt int64) error {
+	return so.rdb.ZAddNX(ctx, scheduledQueueKey, redis.Z{
+		Score:  float64(sendAt),
+		Member: scheduledID,
+	}).Err()
+}
+
+// UnscheduleMessage 从到期索引中移除定时消息，返回是否确实移除（多个分发器并发时只有一个返回 true）
+func (so *StreamOperator) UnscheduleMessage(ctx context.Context, scheduledID string) (bool, error) {
+	removed, err := so.rdb.ZRem(ctx, scheduledQueueKey, scheduledID).Result()
+	if err != nil {
+		return false, err
+	}
+	return removed > 0, nil
+}
+
+// DueScheduledMessages 返回计划发送时间不晚于 now 的定时消息ID
+func (so *StreamOperator) DueScheduledMessages(ctx context.Context, now int64, limit int64) ([]string, error) {
+	return so.rdb.ZRangeByScore(ctx, scheduledQueueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now, 10),
+		Count: limit,
+	}).Result()
+}
+
+// ==================== 消息定时销毁 ====================
+
+const (
+	// conversationTTLPrefix 会话的定时销毁设置（Hash: ttl / updated_by / updated_at）
+	conversationTTLPrefix = "conversation:ttl:"
+	// expiringQueueKey 待销毁消息索引（member 为消息ID，score 为过期时间）
+	expiringQueueKey = "expire:messages"
+	// expiringEntriesPrefix 消息写入的 Stream 条目（Hash: stream key -> stream ID，另有 conversation 字段记录所属会话）
+	expiringEntriesPrefix = "expire:entries:"
+	// expiringConversationField expire:entries 中记录所属会话的字段（与 stream key 不会冲突）
+	expiringConversationField = "conversation"
+)
+
+// ConversationTTL 会话的消息定时销毁设置
+type ConversationTTL struct {
+	TTLSeconds int64
+	UpdatedBy  string
+	UpdatedAt  int64
+}
+
+// ExpiredMessage 已清理的过期消息
+type ExpiredMessage struct {
+	MsgID           string
+	ConversationKey string   // 见 ConversationKey
+	UserIDs         []string // 消息所在 Stream 的用户
+	GroupStream     bool     // 消息写在读扩散群的 stream:group:{group_id} 中，UserIDs 为空，由调用方按群成员通知
+}
+
+// ConversationKey 返回会话在双方共享的数据（定时销毁设置、置顶消息）中的标识
+// userID 为当前用户，peerID 为对方用户ID或群组ID
+// 私聊按用户ID排序拼接为 private:{a}:{b}，双方得到同一个标识；群聊为 group:{group_id}
+func ConversationKey(convType, userID, peerID string) string {
+	if convType == "group" {
+		return "group:" + peerID
+	}
+	if peerID < userID {
+		userID, peerID = peerID, userID
+	}
+	return "private:" + userID + ":" + peerID
+}
+
+// SetConversationTTL 保存会话的定时销毁设置，TTLSeconds 为 0 时删除设置
+func (so *StreamOperator) SetConversationTTL(ctx context.Context, key string, setting ConversationTTL) error {
+	redisKey := conversationTTLPrefix + key
+	if setting.TTLSeconds <= 0 {
+		return so.rdb.Del(ctx, redisKey).Err()
+	}
+	return so.rdb.HSet(ctx, redisKey,
+		"ttl", setting.TTLSeconds,
+		"updated_by", setting.UpdatedBy,
+		"updated_at", setting.UpdatedAt,
+	).Err()
+}
+
+// GetConversationTTL 读取会话的定时销毁设置，未开启时返回 nil
+func (so *StreamOperator) GetConversationTTL(ctx context.Context, key string) (*ConversationTTL, error) {
+	values, err := so.rdb.HGetAll(ctx, conversationTTLPrefix+key).Result()
+	if err != nil {
+		return nil, err
+	}
+	ttl, _ := strconv.ParseInt(values["ttl"], 10, 64)
+	if ttl <= 0 {
+		return nil, nil
+	}
+	updatedAt, _ := strconv.ParseInt(values["updated_at"], 10, 64)
+	return &ConversationTTL{
+		TTLSeconds: ttl,
+		UpdatedBy:  values["updated_by"],
+		UpdatedAt:  updatedAt,
+	}, nil
+}
+
+// trackExpiringMessage 将带过期时间的消息及其写入的 Stream 条目登记到待销毁索引
+func (so *StreamOperator) trackExpiringMessage(ctx context.Context, msgID, conversationKey string, expiresAt int64, entries map[string]string) {
+	if expiresAt <= 0 || len(entries) == 0 {
+		return
+	}
+
+	values := make(map[string]interface{}, len(entries)+1)
+	for streamKey, streamID := range entries {
+		values[streamKey] = streamID
+	}
+	values[expiringConversationField] = conversationKey
+
+	entriesKey := expiringEntriesPrefix + msgID
+	pipe := so.rdb.TxPipeline()
+	pipe.HSet(ctx, entriesKey, values)
+	// 清理任务长时间未运行时条目记录也不会永久残留
+	pipe.ExpireAt(ctx, entriesKey, time.Unix(expiresAt, 0).Add(24*time.Hour))
+	pipe.ZAdd(ctx, expiringQueueKey, redis.Z{
+		Score:  float64(expiresAt),
+		Member: msgID,
+	})
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("Failed to track expiring message", zap.String("msg_id", msgID), zap.Error(err))
+	}
+}
+
+// DueExpiredMessages 返回过期时间不晚于 now 的消息ID
+func (so *StreamOperator) DueExpiredMessages(ctx context.Context, now int64, limit int64) ([]string, error) {
+	return so.rdb.ZRangeByScore(ctx, expiringQueueKey, &redis.ZRangeBy{
+		Min:   "-inf",
+		Max:   strconv.FormatInt(now, 10),
+		Count: limit,
+	}).Result()
+}
+
+// PurgeExpiredMessage 从所有写入过的 Stream 中删除过期消息并移出待销毁索引
+// 删除操作是幂等的；多个实例并发清理同一条消息时只有一个返回非 nil，由其负责后续通知
+func (so *StreamOperator) PurgeExpiredMessage(ctx context.Context, msgID string) (*ExpiredMessage, error) {
+	entriesKey := expiringEntriesPrefix + msgID
+	values, err := so.rdb.HGetAll(ctx, entriesKey).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	expired := &ExpiredMessage{
+		MsgID:           msgID,
+		ConversationKey: values[expiringConversationField],
+	}
+
+	pipe := so.rdb.Pipeline()
+	for streamKey, streamID := range values {
+		if streamKey == expiringConversationField {
+			continue
+		}
+		pipe.XDel(ctx, streamKey, streamID)
+		if strings.HasPrefix(streamKey, "stream:group:") {
+			expired.GroupStream = true
+			continue
+		}
+		expired.UserIDs = append(expired.UserIDs, strings.TrimPrefix(streamKey, "stream:private:"))
+	}
+	pipe.Del(ctx, entriesKey)
+	removed := pipe.ZRem(ctx, expiringQueueKey, msgID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, err
+	}
+
+	if removed.Val() == 0 {
+		return nil, nil
+	}
+	return expired, nil
+}
+
+// ==================== 会话列表管理 ====================
+
+// conversationListTTL 会话列表的过期时间
+const conversationListTTL = 30 * 24 * time.Hour
+
+// touchConversationsScript 更新多个会话列表项的最新消息时间，已置顶（score > 10^13）的会话保持置顶
+// KEYS 为 conversation:list:{user_id}，ARGV[1] 为当前毫秒时间戳，ARGV[2] 为列表过期秒数，ARGV[2+i] 为 KEYS[i] 中的会话ID
+var touchConversationsScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+for i, key in ipairs(KEYS) do
+	local member = ARGV[i + 2]
+	local score = now
+	local cur = redis.call('ZSCORE', key, member)
+	if cur and tonumber(cur) > 10000000000000 then
+		score = 10000000000000 + now
+	end
+	redis.call('ZADD', key, string.format('%.0f', score), member)
+	redis.call('EXPIRE', key, ARGV[2])
+end
+return #KEYS
+`)
+
+// ConversationTouch 需要更新最新消息时间的会话列表项
+type ConversationTouch struct {
+	UserID         string
+	ConversationID string
+}
+
+// touchConversationsArgs 生成 touchConversationsScript 的 KEYS 和 ARGV
+func touchConversationsArgs(touches []ConversationTouch) ([]string, []interface{}) {
+	keys := make([]string, len(touches))
+	args := make([]interface{}, 0, len(touches)+2)
+	args = append(args, time.Now().UnixMilli(), int64(conversationListTTL.Seconds()))
+	for i, t := range touches {
+		keys[i] = fmt.Sprintf("conversation:list:%s", t.UserID)
+		args = append(args, t.ConversationID)
+	}
+	return keys, args
+}
+
+// UpdateConversationTime 更新会话的最新消息时间（收到消息时调用）
+func (so *StreamOperator) UpdateConversationTime(ctx context.Context, userID, conversationID string) error {
+	return so.TouchConversations(ctx, []ConversationTouch{{UserID: userID, ConversationID: conversationID}})
+}
+
+// UpdateConversationTimes 批量更新多个用户同一会话的最新消息时间（群消息使用）
+func (so *StreamOperator) UpdateConversationTimes(ctx context.Context, userIDs []string, conversationID string) error {
+	touches := make([]ConversationTouch, len(userIDs))
+	for i, userID := range userIDs {
+		touches[i] = ConversationTouch{UserID: userID, ConversationID: conversationID}
+	}
+	return so.TouchConversations(ctx, touches)
+}
+
+// TouchConversations 在一次脚本调用中更新多个会话列表项的最新消息时间（保持置顶状态）
+func (so *StreamOperator) TouchConversations(ctx context.Context, touches []ConversationTouch) error {
+	if len(touches) == 0 {
+		return nil
+	}
+
+	keys, args := touchConversationsArgs(touches)
+	if err := touchConversationsScript.Run(ctx, so.rdb, keys, args...).Err(); err != nil {
+		logger.Error("Error updating conversation time", zap.Error(err), zap.Int("count", len(touches)))
+		return err
+	}
+	return nil
+}
+
+// PinConversation 置顶会话
+func (so *StreamOperator) PinConversation(ctx context.Context, userID, conversationID string) error {
+	key := fmt.Sprintf("conversation:list:%s", userID)
+
+	// 获取当前 score
+	currentScore := so.rdb.ZScore(ctx, key, conversationID).Val()
+	if currentScore == 0 {
+		// 会话不存在，使用当前时间
+		currentScore = float64(time.Now().UnixMilli())
+	}
+
+	// 如果已经置顶，不做处理
+	if currentScore > 10000000000000 {
+		logger.Debug("Conversation already pinned", zap.String("conversation_id", conversationID))
+		return nil
+	}
+
+	// 置顶：10^13 + 当前时间戳
+	pinnedScore := 10000000000000 + currentScore
+
+	err := so.rdb.ZAdd(ctx, key, redis.Z{
+		Score:  pinnedScore,
+		Member: conversationID,
+	}).Err()
+
+	if err != nil {
+		logger.Error("Error pinning conversation", zap.Error(err), zap.String("user_id", userID), zap.String("conversation_id", conversationID))
+		return err
+	}
+
+	logger.Info("Pinned conversation", zap.String("conversation_id", conversationID), zap.String("user_id", userID))
+	return nil
+}
+
+// UnpinConversation 取消置顶会话
+func (so *StreamOperator) UnpinConversation(ctx context.Context, userID, conversationID string) error {
+	key := fmt.Sprintf("conversation:list:%s", userID)
+
+	// 获取当前 score
+	currentScore := so.rdb.ZScore(ctx, key, conversationID).Val()
+	if currentScore == 0 {
+		return fmt.Errorf("conversation not found")
+	}
+
+	// 如果未置顶，不做处理
+	if currentScore < 10000000000000 {
+		logger.Debug("Conversation is not pinned", zap.String("conversation_id", conversationID))
+		return nil
+	}
+
+	// 还原到原始时间戳
+	originalScore := currentScore - 10000000000000
+
+	err := so.rdb.ZAdd(ctx, key, redis.Z{
+		Score:  originalScore,
+		Member: conversationID,
+	}).Err()
+
+	if err != nil {
+		logger.Error("Error unpinning conversation", zap.Error(err), zap.String("user_id", userID), zap.String("conversation_id", conversationID))
+		return err
+	}
+
+	logger.Info("Unpinned conversation", zap.String("conversation_id", conversationID), zap.String("user_id", userID))
+	return nil
+}
+
+// GetConversationList 获取会话列表（按时间降序，置顶在前）
+func (so *StreamOperator) GetConversationList(ctx context.Context, userID string, offset, limit int64) ([]ConversationItem, error) {
+	key := fmt.Sprintf("conversation:list:%s", userID)
+
+	// ZREVRANGE：按 score 降序（置顶和最新的在前）
+	results, err := so.rdb.ZRevRangeWithScores(ctx, key, offset, offset+limit-1).Result()
+	if err != nil {
+		logger.Error("Error getting conversation list", zap.Error(err), zap.String("user_id", userID))
+		return nil, err
+	}
+
+	var conversations []ConversationItem
+	for _, z := range results {
+		conversationID := z.Member.(string)
+		score := z.Score
 
 		isPinned := score > 10000000000000
 		lastMessageTime := int64(score)
@@ -771,54 +1845,381 @@ func (so *StreamOperator) CreateConversation(ctx context.Context, userID, conver
 
 // ==================== 已读游标管理 ====================
 
-// GetUserCursor 获取用户级已读游标（私聊+群聊共用）
-func (so *StreamOperator) GetUserCursor(ctx context.Context, userID string) (string, error) {
-	key := fmt.Sprintf("cursor:user:%s", userID)
-	cursor, err := so.rdb.Get(ctx, key).Result()
-	if err == redis.Nil {
-		return "0-0", nil // 默认从最开始
+// setCursorScript 只允许游标前进：按数值比较 Stream ID（毫秒时间戳-序号），新游标不大于当前值时保持不变
+// 返回更新后的游标
+var setCursorScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur then
+	local cms, cseq = string.match(cur, '^(%d+)-(%d+)$')
+	local nms, nseq = string.match(ARGV[2], '^(%d+)-(%d+)$')
+	if cms then
+		cms, cseq, nms, nseq = tonumber(cms), tonumber(cseq), tonumber(nms), tonumber(nseq)
+		if nms < cms or (nms == cms and nseq <= cseq) then
+			return cur
+		end
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return ARGV[2]
+`)
+
+// advanceLowWaterScript 只允许低水位前进，比较方式与 setCursorScript 相同，返回更新后的低水位
+var advanceLowWaterScript = redis.NewScript(`
+local cur = redis.call('GET', KEYS[1])
+if cur then
+	local cms, cseq = string.match(cur, '^(%d+)-(%d+)$')
+	local nms, nseq = string.match(ARGV[1], '^(%d+)-(%d+)$')
+	if cms then
+		cms, cseq, nms, nseq = tonumber(cms), tonumber(cseq), tonumber(nms), tonumber(nseq)
+		if nms < cms or (nms == cms and nseq <= cseq) then
+			return cur
+		end
+	end
+end
+redis.call('SET', KEYS[1], ARGV[1])
+return ARGV[1]
+`)
+
+// ConversationCursors 用户在各会话中的已读游标
+type ConversationCursors struct {
+	cursors  map[string]string // 会话ID（private:{peer} / group:{id}）-> Stream ID
+	fallback string            // 已读低水位：所有会话在该位置及之前的消息都已读
+}
+
+// Get 获取指定会话的已读游标，会话没有独立游标或游标低于低水位时返回低水位
+func (c *ConversationCursors) Get(conversationID string) string {
+	if cursor, ok := c.cursors[conversationID]; ok && CompareStreamIDs(cursor, c.fallback) > 0 {
+		return cursor
+	}
+	return c.fallback
+}
+
+// Min 返回所有会话中最小的游标，用于确定 Stream 的读取起点
+// 没有独立游标的会话以低水位为游标，因此结果即为低水位
+func (c *ConversationCursors) Min() string {
+	return c.fallback
+}
+
+// GetConversationCursors 获取用户所有会话的已读游标
+// cursor:conv:{user_id} 是一个 Hash，field 为会话ID；
+// cursor:user:{user_id} 为已读低水位（由 AdvanceReadLowWaterMark 推进，旧版的用户级游标沿用该键），
+// 作为没有独立游标的会话的默认值
+func (so *StreamOperator) GetConversationCursors(ctx context.Context, userID string) (*ConversationCursors, error) {
+	cursors := &ConversationCursors{cursors: map[string]string{}, fallback: "0-0"}
+
+	pipe := so.rdb.Pipeline()
+	convCmd := pipe.HGetAll(ctx, fmt.Sprintf("cursor:conv:%s", userID))
+	lowWaterCmd := pipe.Get(ctx, fmt.Sprintf("cursor:user:%s", userID))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		logger.Error("Error getting conversation cursors", zap.Error(err), zap.String("user_id", userID))
+		return cursors, err
+	}
+
+	if lowWater, err := lowWaterCmd.Result(); err == nil {
+		if _, _, ok := parseStreamID(lowWater); ok {
+			cursors.fallback = lowWater
+		}
+	}
+	for conversationID, cursor := range convCmd.Val() {
+		cursors.cursors[conversationID] = cursor
 	}
+
+	return cursors, nil
+}
+
+// AdvanceReadLowWaterMark 推进用户的已读低水位（只允许前进）
+// 调用方需保证所有会话在 streamID 及之前都没有未读消息，之后的增量拉取从低水位开始读取 Stream
+func (so *StreamOperator) AdvanceReadLowWaterMark(ctx context.Context, userID, streamID string) error {
+	if _, _, ok := parseStreamID(streamID); !ok {
+		return fmt.Errorf("invalid stream id: %s", streamID)
+	}
+	if err := advanceLowWaterScript.Run(ctx, so.rdb, []string{fmt.Sprintf("cursor:user:%s", userID)}, streamID).Err(); err != nil {
+		logger.Error("Error advancing read low-water mark", zap.Error(err), zap.String("user_id", userID))
+		return err
+	}
+	return nil
+}
+
+// SetConversationCursor 设置会话的已读游标（只允许前进），返回更新后的游标
+func (so *StreamOperator) SetConversationCursor(ctx context.Context, userID, conversationID, newCursor string) (string, error) {
+	if _, _, ok := parseStreamID(newCursor); !ok {
+		return "", fmt.Errorf("invalid stream id: %s", newCursor)
+	}
+
+	key := fmt.Sprintf("cursor:conv:%s", userID)
+	cursor, err := setCursorScript.Run(ctx, so.rdb, []string{key}, conversationID, newCursor).Text()
 	if err != nil {
-		logger.Error("Error getting user cursor", zap.Error(err), zap.String("user_id", userID))
-		return "0-0", err
+		logger.Error("Error setting conversation cursor", zap.Error(err),
+			zap.String("user_id", userID),
+			zap.String("conversation_id", conversationID))
+		return "", err
 	}
+
+	logger.Debug("Conversation cursor updated",
+		zap.String("user_id", userID),
+		zap.String("conversation_id", conversationID),
+		zap.String("cursor", cursor))
 	return cursor, nil
 }
 
-// SetUserCursor 设置用户级已读游标（只允许前进）
-func (so *StreamOperator) SetUserCursor(ctx context.Context, userID, newCursor string) error {
-	key := fmt.Sprintf("cursor:user:%s", userID)
+// ==================== 私聊已读回执 ====================
+
+// advanceReceiptScript 只允许已读位置前进：ARGV[2] 为 "{stream_id}|..."，按读者 Stream 中的 ID 比较
+// 返回 1 表示已更新，0 表示新位置不比当前位置新
+var advanceReceiptScript = redis.NewScript(`
+local cur = redis.call('HGET', KEYS[1], ARGV[1])
+if cur then
+	local cms, cseq = string.match(cur, '^(%d+)-(%d+)|')
+	local nms, nseq = string.match(ARGV[2], '^(%d+)-(%d+)|')
+	if cms and nms then
+		cms, cseq, nms, nseq = tonumber(cms), tonumber(cseq), tonumber(nms), tonumber(nseq)
+		if nms < cms or (nms == cms and nseq <= cseq) then
+			return 0
+		end
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+// ReadReceipt 私聊中读者已读到的、由对方发送的最后一条消息
+type ReadReceipt struct {
+	StreamID  string // 该消息在读者 Stream 中的ID（用于保证只前进）
+	MsgID     string
+	CreatedAt int64
+	ReadAt    int64
+}
 
-	currentCursor, _ := so.GetUserCursor(ctx, userID)
+// readReceiptKey 发送者收到的已读回执：receipt:private:{sender_id}，field 为读者ID
+func readReceiptKey(senderID string) string {
+	return fmt.Sprintf("receipt:private:%s", senderID)
+}
 
-	if compareCursors(newCursor, currentCursor) <= 0 {
-		logger.Debug("Cursor not updated (would move backward)",
-			zap.String("user_id", userID),
-			zap.String("current", currentCursor),
-			zap.String("new", newCursor))
-		return nil // 幂等，直接返回成功
+// AdvanceReadReceipt 记录 readerID 已读到 senderID 发送的某条消息（只允许前进），返回是否有更新
+func (so *StreamOperator) AdvanceReadReceipt(ctx context.Context, senderID, readerID string, receipt ReadReceipt) (bool, error) {
+	value := fmt.Sprintf("%s|%s|%d|%d", receipt.StreamID, receipt.MsgID, receipt.CreatedAt, receipt.ReadAt)
+	updated, err := advanceReceiptScript.Run(ctx, so.rdb, []string{readReceiptKey(senderID)}, readerID, value).Int()
+	if err != nil {
+		logger.Error("Error advancing read receipt", zap.Error(err),
+			zap.String("sender_id", senderID),
+			zap.String("reader_id", readerID))
+		return false, err
 	}
+	return updated == 1, nil
+}
 
-	err := so.rdb.Set(ctx, key, newCursor, 0).Err()
+// GetReadReceipts 获取 senderID 发送的消息在各私聊会话中的已读位置（key 为读者ID）
+func (so *StreamOperator) GetReadReceipts(ctx context.Context, senderID string) (map[string]ReadReceipt, error) {
+	values, err := so.rdb.HGetAll(ctx, readReceiptKey(senderID)).Result()
 	if err != nil {
-		logger.Error("Error setting user cursor", zap.Error(err))
-		return err
+		return nil, err
 	}
 
-	logger.Info("User cursor updated",
-		zap.String("user_id", userID),
-		zap.String("cursor", newCursor))
-	return nil
+	receipts := make(map[string]ReadReceipt, len(values))
+	for readerID, v := range values {
+		streamID, msgID, createdAt, readAt, ok := parseReceipt(v)
+		if !ok {
+			continue
+		}
+		receipts[readerID] = ReadReceipt{
+			StreamID:  streamID,
+			MsgID:     msgID,
+			CreatedAt: createdAt,
+			ReadAt:    readAt,
+		}
+	}
+	return receipts, nil
+}
+
+// parseReceipt 解析回执的存储格式 "{stream_id}|{msg_id}|{created_at}|{时间}"
+func parseReceipt(v string) (streamID, msgID string, createdAt, at int64, ok bool) {
+	parts := strings.SplitN(v, "|", 4)
+	if len(parts) != 4 {
+		return "", "", 0, 0, false
+	}
+	createdAt, _ = strconv.ParseInt(parts[2], 10, 64)
+	at, _ = strconv.ParseInt(parts[3], 10, 64)
+	return parts[0], parts[1], createdAt, at, true
+}
+
+// ==================== 私聊送达回执 ====================
+
+// DeliveryReceipt 私聊中接收者设备已收到的、由对方发送的最后一条消息
+type DeliveryReceipt struct {
+	StreamID    string // 该消息在接收者 Stream 中的ID（用于保证只前进）
+	MsgID       string
+	CreatedAt   int64
+	DeliveredAt int64
+}
+
+// deliveryReceiptKey 发送者收到的送达回执：receipt:delivered:{sender_id}，field 为接收者ID
+func deliveryReceiptKey(senderID string) string {
+	return fmt.Sprintf("receipt:delivered:%s", senderID)
+}
+
+// AdvanceDeliveryReceipt 记录 receiverID 的设备已收到 senderID 发送的某条消息（只允许前进），返回是否有更新
+func (so *StreamOperator) AdvanceDeliveryReceipt(ctx context.Context, senderID, receiverID string, receipt DeliveryReceipt) (bool, error) {
+	value := fmt.Sprintf("%s|%s|%d|%d", receipt.StreamID, receipt.MsgID, receipt.CreatedAt, receipt.DeliveredAt)
+	updated, err := advanceReceiptScript.Run(ctx, so.rdb, []string{deliveryReceiptKey(senderID)}, receiverID, value).Int()
+	if err != nil {
+		logger.Error("Error advancing delivery receipt", zap.Error(err),
+			zap.String("sender_id", senderID),
+			zap.String("receiver_id", receiverID))
+		return false, err
+	}
+	return updated == 1, nil
+}
+
+// GetDeliveryReceipts 获取 senderID 发送的消息在各私聊会话中的送达位置（key 为接收者ID）
+func (so *StreamOperator) GetDeliveryReceipts(ctx context.Context, senderID string) (map[string]DeliveryReceipt, error) {
+	values, err := so.rdb.HGetAll(ctx, deliveryReceiptKey(senderID)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	receipts := make(map[string]DeliveryReceipt, len(values))
+	for receiverID, v := range values {
+		streamID, msgID, createdAt, deliveredAt, ok := parseReceipt(v)
+		if !ok {
+			continue
+		}
+		receipts[receiverID] = DeliveryReceipt{
+			StreamID:    streamID,
+			MsgID:       msgID,
+			CreatedAt:   createdAt,
+			DeliveredAt: deliveredAt,
+		}
+	}
+	return receipts, nil
+}
+
+// resyncKey 设备有未确认送达的推送时的补拉标记：delivery:resync:{user_id}:{device_id}，值为最早未确认的 Stream ID
+func resyncKey(userID, deviceID string) string {
+	return fmt.Sprintf("delivery:resync:%s:%s", userID, deviceID)
+}
+
+// MarkResync 标记设备需要在重新连接时补拉消息，已有标记时保留原标记（更早的未确认消息）
+func (so *StreamOperator) MarkResync(ctx context.Context, userID, deviceID, streamID string, ttl time.Duration) error {
+	return so.rdb.SetNX(ctx, resyncKey(userID, deviceID), streamID, ttl).Err()
+}
+
+// TakeResync 取出并清除设备的补拉标记，没有标记时返回空字符串
+func (so *StreamOperator) TakeResync(ctx context.Context, userID, deviceID string) (string, error) {
+	streamID, err := so.rdb.GetDel(ctx, resyncKey(userID, deviceID)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return streamID, err
+}
+
+// ==================== 群聊已读位置 ====================
+
+// advanceGroupReadScript 只允许已读位置前进（按消息发送时间比较），返回 {原位置, 是否更新}
+var advanceGroupReadScript = redis.NewScript(`
+local cur = tonumber(redis.call('HGET', KEYS[1], ARGV[1]) or '0')
+local new = tonumber(ARGV[2])
+if new <= cur then
+	return {cur, 0}
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+return {cur, 1}
+`)
+
+// groupReadPositionKey 群成员的已读位置：group:read:pos:{group_id}，field 为成员ID，值为已读的最后一条消息的发送时间（秒）
+// 每个成员的游标是其个人 Stream 中的ID，无法跨成员比较，因此以消息发送时间作为统一的已读位置
+func groupReadPositionKey(groupID string) string {
+	return fmt.Sprintf("group:read:pos:%s", groupID)
+}
+
+// AdvanceGroupReadPosition 将成员的已读位置前进到 createdAt，返回原位置以及是否有更新
+func (so *StreamOperator) AdvanceGroupReadPosition(ctx context.Context, groupID, userID string, createdAt int64) (int64, bool, error) {
+	res, err := advanceGroupReadScript.Run(ctx, so.rdb, []string{groupReadPositionKey(groupID)}, userID, createdAt).Int64Slice()
+	if err != nil {
+		logger.Error("Error advancing group read position", zap.Error(err),
+			zap.String("group_id", groupID),
+			zap.String("user_id", userID))
+		return 0, false, err
+	}
+	return res[0], res[1] == 1, nil
 }
 
-// compareCursors 比较两个 Redis Stream ID
-// 返回: -1 if a < b, 0 if a == b, 1 if a > b
-func compareCursors(a, b string) int {
-	if a == b {
+// GetGroupReadPositions 获取群内所有成员的已读位置
+func (so *StreamOperator) GetGroupReadPositions(ctx context.Context, groupID string) (map[string]int64, error) {
+	values, err := so.rdb.HGetAll(ctx, groupReadPositionKey(groupID)).Result()
+	if err != nil {
+		return nil, err
+	}
+	positions := make(map[string]int64, len(values))
+	for userID, v := range values {
+		if pos, err := strconv.ParseInt(v, 10, 64); err == nil {
+			positions[userID] = pos
+		}
+	}
+	return positions, nil
+}
+
+// RestoreGroupReadPositions 从数据库备份恢复已读位置（只补充 Redis 中缺失的成员，不覆盖更新的位置）
+func (so *StreamOperator) RestoreGroupReadPositions(ctx context.Context, groupID string, positions map[string]int64) error {
+	if len(positions) == 0 {
+		return nil
+	}
+	key := groupReadPositionKey(groupID)
+	pipe := so.rdb.Pipeline()
+	for userID, pos := range positions {
+		pipe.HSetNX(ctx, key, userID, pos)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// CompareStreamIDs 按数值比较两个 Redis Stream ID（格式为 毫秒时间戳-序号）
+// 返回: -1 if a < b, 0 if a == b, 1 if a > b；无法解析的 ID 视为最小
+func CompareStreamIDs(a, b string) int {
+	ams, aseq, aok := parseStreamID(a)
+	bms, bseq, bok := parseStreamID(b)
+
+	switch {
+	case !aok && !bok:
 		return 0
+	case !aok:
+		return -1
+	case !bok:
+		return 1
 	}
-	if a > b {
+
+	if ams != bms {
+		if ams < bms {
+			return -1
+		}
 		return 1
 	}
-	return -1
+	if aseq != bseq {
+		if aseq < bseq {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+// ValidStreamID 判断是否为合法的 Stream ID
+func ValidStreamID(id string) bool {
+	_, _, ok := parseStreamID(id)
+	return ok
+}
+
+// parseStreamID 解析 Stream ID，省略序号时按 0 处理
+func parseStreamID(id string) (ms, seq uint64, ok bool) {
+	msPart, seqPart, hasSeq := strings.Cut(id, "-")
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return 0, 0, false
+	}
+	if hasSeq {
+		seq, err = strconv.ParseUint(seqPart, 10, 64)
+		if err != nil {
+			return 0, 0, false
+		}
+	}
+	return ms, seq, true
 }
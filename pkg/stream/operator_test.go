@@ -0,0 +1,332 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+
+	"ChatIM/pkg/logger"
+)
+
+func newTestOperator(t *testing.T) (*StreamOperator, *redis.Client, *miniredis.Miniredis) {
+	t.Helper()
+	logger.Logger = zap.NewNop()
+
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return NewStreamOperator(rdb), rdb, mr
+}
+
+// receiveNotification 等待 NotificationChannel 上的一条通知，超时返回 nil
+func receiveNotification(t *testing.T, sub *redis.PubSub) map[string]interface{} {
+	t.Helper()
+	select {
+	case msg := <-sub.Channel():
+		var notification map[string]interface{}
+		if err := json.Unmarshal([]byte(msg.Payload), &notification); err != nil {
+			t.Fatalf("notification is not valid JSON: %v: %s", err, msg.Payload)
+		}
+		return notification
+	case <-time.After(200 * time.Millisecond):
+		return nil
+	}
+}
+
+func TestAddMessagePublishesStreamIDs(t *testing.T) {
+	ctx := context.Background()
+	notification := []byte(`{"msg_id":"m1","content":"say \"hi\"","payload":null,"created_at":1700000000}`)
+
+	tests := []struct {
+		name        string
+		broken      string // 预先写成字符串的 Stream 键，使对应 XADD 失败
+		send        func(so *StreamOperator) (string, error)
+		wantErr     bool
+		wantPublish bool
+		wantLabels  []string
+	}{
+		{
+			name: "private",
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddPrivateMessage(ctx, "m1", "a", "b", "hi", "text", "", 0, &Delivery{Notification: notification})
+			},
+			wantPublish: true,
+			wantLabels:  []string{"a", "b"},
+		},
+		{
+			name:   "private receiver stream fails",
+			broken: "stream:private:b",
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddPrivateMessage(ctx, "m1", "a", "b", "hi", "text", "", 0, &Delivery{Notification: notification})
+			},
+			wantErr: true,
+		},
+		{
+			name:   "private sender stream fails",
+			broken: "stream:private:a",
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddPrivateMessage(ctx, "m1", "a", "b", "hi", "text", "", 0, &Delivery{Notification: notification})
+			},
+			wantPublish: true,
+			wantLabels:  []string{"b"},
+		},
+		{
+			name:   "group member stream fails",
+			broken: "stream:private:c",
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddGroupMessageToMembers(ctx, "m1", "g", "a", "hi", "text", "", []string{"a", "b", "c"}, nil, 0, &Delivery{Notification: notification})
+			},
+			wantPublish: true,
+			wantLabels:  []string{"a", "b"},
+		},
+		{
+			name: "read fanout group",
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddGroupMessage(ctx, "m1", "g", "a", "hi", "text", "", nil, 0, &Delivery{Notification: notification})
+			},
+			wantPublish: true,
+			wantLabels:  []string{StreamIDsAll},
+		},
+		{
+			name:   "read fanout group stream fails",
+			broken: GroupStreamKey("g"),
+			send: func(so *StreamOperator) (string, error) {
+				return so.AddGroupMessage(ctx, "m1", "g", "a", "hi", "text", "", nil, 0, &Delivery{Notification: notification})
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			so, rdb, mr := newTestOperator(t)
+			if tt.broken != "" {
+				mr.Set(tt.broken, "not a stream")
+			}
+			sub := rdb.Subscribe(ctx, NotificationChannel)
+			defer sub.Close()
+			if _, err := sub.Receive(ctx); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := tt.send(so)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			got := receiveNotification(t, sub)
+			if !tt.wantPublish {
+				if got != nil {
+					t.Fatalf("published %v, want no notification", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("no notification published")
+			}
+			if got["content"] != `say "hi"` || got["msg_id"] != "m1" {
+				t.Errorf("notification fields changed: %v", got)
+			}
+
+			streamIDs, _ := got["stream_ids"].(map[string]interface{})
+			if len(streamIDs) != len(tt.wantLabels) {
+				t.Fatalf("stream_ids = %v, want labels %v", streamIDs, tt.wantLabels)
+			}
+			for _, label := range tt.wantLabels {
+				key := "stream:private:" + label
+				if label == StreamIDsAll {
+					key = GroupStreamKey("g")
+				}
+				entries, err := rdb.XRange(ctx, key, "-", "+").Result()
+				if err != nil || len(entries) != 1 {
+					t.Fatalf("%s entries = %v, %v", key, entries, err)
+				}
+				if streamIDs[label] != entries[0].ID {
+					t.Errorf("stream_ids[%s] = %v, want %s", label, streamIDs[label], entries[0].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageStateExpiresWithMessage(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now().Unix()
+
+	tests := []struct {
+		name      string
+		createdAt int64
+		wantTTL   time.Duration // 0 表示键不存在
+	}{
+		{name: "new message", createdAt: now, wantTTL: messageStateTTL},
+		{name: "message sent a week ago", createdAt: now - 7*24*3600, wantTTL: 24 * time.Hour},
+		{name: "message past the window", createdAt: now - 9*24*3600},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			so, rdb, _ := newTestOperator(t)
+
+			if err := so.MarkMessageRecalled(ctx, "m1", "a", now, tt.createdAt); err != nil {
+				t.Fatal(err)
+			}
+			if err := so.SaveMessageEdit(ctx, "m2", MessageEdit{Content: "x", EditedBy: "a", EditedAt: now}, tt.createdAt); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := so.AddReaction(ctx, "m3", "👍", "a", tt.createdAt); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, key := range []string{"msg:recall:m1", "msg:edit:m2", "msg:reaction:m3:👍", "msg:reactions:m3"} {
+				ttl := rdb.TTL(ctx, key).Val()
+				if tt.wantTTL == 0 {
+					if n := rdb.Exists(ctx, key).Val(); n != 0 {
+						t.Errorf("%s exists with ttl %v, want expired", key, ttl)
+					}
+					continue
+				}
+				if ttl > tt.wantTTL || ttl < tt.wantTTL-time.Minute {
+					t.Errorf("%s ttl = %v, want about %v", key, ttl, tt.wantTTL)
+				}
+			}
+		})
+	}
+}
+
+func TestMessageStateExpired(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name      string
+		createdAt int64
+		want      bool
+	}{
+		{"unknown send time", 0, false},
+		{"just sent", now.Unix(), false},
+		{"inside window", now.Add(-MaxStreamAge + time.Hour).Unix(), false},
+		{"past window", now.Add(-MaxStreamAge - time.Hour).Unix(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MessageStateExpired(tt.createdAt); got != tt.want {
+				t.Errorf("MessageStateExpired(%d) = %v, want %v", tt.createdAt, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareStreamIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1700000000000-0", "1700000000000-0", 0},
+		{"1700000000000-1", "1700000000000-0", 1},
+		{"1700000000000-9", "1700000000000-10", -1}, // 序号按数值而不是字符串比较
+		{"999-0", "1000-0", -1},                     // 毫秒按数值比较
+		{"1700000000000", "1700000000000-0", 0},     // 省略序号按 0 处理
+		{"1700000000001", "1700000000000-5", 1},
+		{"0-0", "1-0", -1},
+		{"invalid", "0-0", -1}, // 无法解析的ID小于任何有效ID
+		{"0-0", "invalid", 1},
+		{"invalid", "", 0},
+	}
+
+	for _, tt := range tests {
+		if got := CompareStreamIDs(tt.a, tt.b); got != tt.want {
+			t.Errorf("CompareStreamIDs(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestConversationCursors(t *testing.T) {
+	ctx := context.Background()
+	so, rdb, _ := newTestOperator(t)
+
+	// 已读低水位（旧版用户级游标）作为没有独立游标的会话的默认值
+	if err := rdb.Set(ctx, "cursor:user:u1", "1000-0", 0).Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name         string
+		conversation string
+		cursor       string
+		want         string
+		wantErr      bool
+	}{
+		{name: "first cursor", conversation: "private:u2", cursor: "2000-0", want: "2000-0"},
+		{name: "advance sequence", conversation: "private:u2", cursor: "2000-1", want: "2000-1"},
+		{name: "same cursor kept", conversation: "private:u2", cursor: "2000-1", want: "2000-1"},
+		{name: "older cursor ignored", conversation: "private:u2", cursor: "1999-5", want: "2000-1"},
+		{name: "sequence compared numerically", conversation: "private:u2", cursor: "2000-10", want: "2000-10"},
+		{name: "smaller sequence ignored", conversation: "private:u2", cursor: "2000-9", want: "2000-10"},
+		{name: "independent conversation", conversation: "group:g1", cursor: "1500-0", want: "1500-0"},
+		{name: "cursor below low-water mark", conversation: "group:g2", cursor: "500-0", want: "500-0"},
+		{name: "invalid stream id", conversation: "group:g1", cursor: "abc", wantErr: true},
+	}
+
+	for _, tt := range steps {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := so.SetConversationCursor(ctx, "u1", tt.conversation, tt.cursor)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetConversationCursor(%q) error = %v, wantErr %v", tt.cursor, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SetConversationCursor(%q) = %q, want %q", tt.cursor, got, tt.want)
+			}
+		})
+	}
+
+	cursors, err := so.GetConversationCursors(ctx, "u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for conversation, want := range map[string]string{
+		"private:u2": "2000-10",
+		"group:g1":   "1500-0",
+		"private:u3": "1000-0", // 没有独立游标，使用低水位
+		"group:g2":   "1000-0", // 游标低于低水位
+	} {
+		if got := cursors.Get(conversation); got != want {
+			t.Errorf("Get(%q) = %q, want %q", conversation, got, want)
+		}
+	}
+	if got := cursors.Min(); got != "1000-0" {
+		t.Errorf("Min() = %q, want 1000-0", got)
+	}
+
+	// 低水位只允许前进
+	for _, step := range []struct{ lowWater, want string }{
+		{"1200-0", "1200-0"},
+		{"1100-0", "1200-0"},
+		{"1200-1", "1200-1"},
+	} {
+		if err := so.AdvanceReadLowWaterMark(ctx, "u1", step.lowWater); err != nil {
+			t.Fatal(err)
+		}
+		cursors, err := so.GetConversationCursors(ctx, "u1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cursors.Min(); got != step.want {
+			t.Errorf("after AdvanceReadLowWaterMark(%q) Min() = %q, want %q", step.lowWater, got, step.want)
+		}
+	}
+	if err := so.AdvanceReadLowWaterMark(ctx, "u1", "abc"); err == nil {
+		t.Error("AdvanceReadLowWaterMark accepted an invalid stream id")
+	}
+
+	// 没有任何游标的用户从头开始读取
+	empty, err := so.GetConversationCursors(ctx, "u9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Get("private:u1") != "0-0" || empty.Min() != "0-0" {
+		t.Errorf("empty cursors = %q / %q, want 0-0", empty.Get("private:u1"), empty.Min())
+	}
+}
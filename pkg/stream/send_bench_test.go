@@ -0,0 +1,105 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 私聊发送在 Redis 上的耗时（对应 chatim_message_send_duration_seconds 中 Redis 部分），除 ns/op 外报告 p50 / p99：
+//   - sequential: 原实现，两次 XADD、每个会话列表各 ZSCORE + ZADD + EXPIRE、一次 PUBLISH，共 9 次往返
+//   - pipelined:  AddPrivateMessage 携带 Delivery，Stream 写入、会话列表更新和通知在一个 pipeline 中提交，1 次往返
+//
+// rtt=0 时 miniredis 与客户端在同一进程内，往返开销很小，pipelined 反而比 sequential 慢：
+// CPU profile 中约 2/3 的时间在 miniredis 的 EVAL，它每次执行 Lua 脚本（写入 + 通知脚本、会话列表脚本）都新建 gopher-lua 解释器，
+// 真实 Redis 缓存已编译的脚本，没有这部分开销。因此另用 rtt=200µs 模拟跨主机部署时的网络往返。
+//
+// go test -run '^$' -bench BenchmarkPrivateSend ./pkg/stream/
+
+// legacyUpdateConversationTime 原 UpdateConversationTime 的实现：读取置顶状态后写入，三次往返
+func legacyUpdateConversationTime(ctx context.Context, rdb *redis.Client, userID, conversationID string) {
+	key := fmt.Sprintf("conversation:list:%s", userID)
+	score := float64(time.Now().UnixMilli())
+	if rdb.ZScore(ctx, key, conversationID).Val() > 10000000000000 {
+		score = 10000000000000 + score
+	}
+	rdb.ZAdd(ctx, key, redis.Z{Score: score, Member: conversationID})
+	rdb.Expire(ctx, key, conversationListTTL)
+}
+
+func benchNotification(msgID, toUserID string) []byte {
+	notification, _ := json.Marshal(map[string]interface{}{
+		"msg_id":     msgID,
+		"to_user_id": toUserID,
+		"type":       "private",
+		"content":    "hello",
+	})
+	return notification
+}
+
+// reportLatency 报告每次发送耗时的 p50 / p99
+func reportLatency(b *testing.B, samples []time.Duration) {
+	if len(samples) == 0 {
+		return
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	percentile := func(p float64) float64 {
+		return float64(samples[int(float64(len(samples)-1)*p)].Nanoseconds())
+	}
+	b.ReportMetric(percentile(0.50), "p50-ns")
+	b.ReportMetric(percentile(0.99), "p99-ns")
+}
+
+func BenchmarkPrivateSend(b *testing.B) {
+	ctx := context.Background()
+	const from, to = "user-a", "user-b"
+
+	for _, rtt := range []time.Duration{0, 200 * time.Microsecond} {
+		b.Run(fmt.Sprintf("sequential/rtt=%s", rtt), func(b *testing.B) {
+			_, rdb := newBenchOperatorWithRTT(b, rtt)
+			samples := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				msgID := fmt.Sprintf("msg-%d", i)
+				values := map[string]interface{}{"id": msgID, "from_user_id": from, "to_user_id": to, "content": "hello", "type": "private"}
+				rdb.XAdd(ctx, &redis.XAddArgs{Stream: "stream:private:" + from, Values: values})
+				rdb.XAdd(ctx, &redis.XAddArgs{Stream: "stream:private:" + to, Values: values})
+				legacyUpdateConversationTime(ctx, rdb, from, "private:"+to)
+				legacyUpdateConversationTime(ctx, rdb, to, "private:"+from)
+				rdb.Publish(ctx, NotificationChannel, benchNotification(msgID, to))
+				samples = append(samples, time.Since(start))
+			}
+			b.StopTimer()
+			reportLatency(b, samples)
+		})
+
+		b.Run(fmt.Sprintf("pipelined/rtt=%s", rtt), func(b *testing.B) {
+			so, _ := newBenchOperatorWithRTT(b, rtt)
+			samples := make([]time.Duration, 0, b.N)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				start := time.Now()
+				msgID := fmt.Sprintf("msg-%d", i)
+				_, err := so.AddPrivateMessage(ctx, msgID, from, to, "hello", "text", "", 0, &Delivery{
+					Conversations: []ConversationTouch{
+						{UserID: from, ConversationID: "private:" + to},
+						{UserID: to, ConversationID: "private:" + from},
+					},
+					Notification: benchNotification(msgID, to),
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				samples = append(samples, time.Since(start))
+			}
+			b.StopTimer()
+			reportLatency(b, samples)
+		})
+	}
+}